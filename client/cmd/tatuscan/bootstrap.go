@@ -0,0 +1,102 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+	"github.com/kardianos/service"
+)
+
+// runBootstrap implements `tatuscan bootstrap`, run by the macOS package
+// postinstall (and usable by hand on any OS): it prepares the agent
+// directories, writes the given settings, asks for the server URL when run
+// from a terminal without configuration, then installs and (re)starts the
+// service
+func runBootstrap(args []string) int {
+	fs := flag.NewFlagSet("bootstrap", flag.ContinueOnError)
+	serverURL := fs.String("url", "", "Server base URL written to the settings file")
+	token := fs.String("token", "", "Server token written to the settings file")
+	tags := fs.String("tags", "", "Comma-separated tags written to the settings file")
+	noStart := fs.Bool("no-start", false, "Install the service without starting it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := internal.PrepareDirs(); err != nil {
+		fmt.Fprintf(os.Stderr, "bootstrap: %v\n", err)
+		return 1
+	}
+
+	values := make(map[string]string)
+	for key, value := range map[string]string{"TATUSCAN_URL": *serverURL, "TATUSCAN_TOKEN": *token, "TATUSCAN_TAGS": *tags} {
+		if value = strings.TrimSpace(value); value != "" {
+			values[key] = value
+		}
+	}
+	internal.ReloadConfig(context.Background())
+	if len(values) == 0 && internal.Cfg.ServerURL == "" && isTerminal(os.Stdin) {
+		fmt.Print("TatuScan server URL (empty to configure later): ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			values["TATUSCAN_URL"] = line
+		}
+	}
+	if len(values) > 0 {
+		if target := values["TATUSCAN_URL"]; target != "" {
+			if _, err := internal.NewSender(target); err != nil {
+				fmt.Fprintf(os.Stderr, "bootstrap: invalid server URL: %v\n", err)
+				return 2
+			}
+		}
+		path, err := internal.WriteConfigFile(values)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bootstrap: write %s: %v\n", path, err)
+			return 1
+		}
+		fmt.Printf("Settings written to %s\n", path)
+		internal.ReloadConfig(context.Background())
+	}
+
+	s, err := service.New(&program{interval: defaultInterval}, serviceConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bootstrap: %v\n", err)
+		return 1
+	}
+	status, err := s.Status()
+	if err == service.ErrNotInstalled {
+		if err := s.Install(); err != nil {
+			fmt.Fprintf(os.Stderr, "bootstrap: install service: %v\n", err)
+			return 1
+		}
+		fmt.Println("Service installed")
+	}
+	if !*noStart {
+		// Upgrades restart the running agent with the new binary
+		start := s.Start
+		if status == service.StatusRunning {
+			start = s.Restart
+		}
+		if err := start(); err != nil {
+			fmt.Fprintf(os.Stderr, "bootstrap: start service: %v\n", err)
+			return 1
+		}
+		fmt.Println("Service started")
+	}
+	if internal.Cfg.ServerURL == "" {
+		fmt.Printf("The agent waits for %s: write it to %s or install a configuration profile\n", envServerURL, internal.ConfigFilePath())
+	}
+	return 0
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
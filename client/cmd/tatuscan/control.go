@@ -0,0 +1,47 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// runControl implements `tatuscan control`: it sends a command to the agent
+// running on this machine over the local control API and prints the answer
+func runControl(args []string) int {
+	fs := flag.NewFlagSet("control", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "Time allowed for the agent to answer")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan control [-timeout 10s] status|report|collect|reload")
+		return 2
+	}
+	log.SetOutput(os.Stderr)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	resp, err := internal.Control(ctx, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "control: %v\n", err)
+		return 1
+	}
+	if resp.Message != "" {
+		fmt.Println(resp.Message)
+	}
+	if len(resp.Data) > 0 {
+		var out bytes.Buffer
+		if err := json.Indent(&out, resp.Data, "", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "control: %v\n", err)
+			return 1
+		}
+		fmt.Println(out.String())
+	}
+	return 0
+}
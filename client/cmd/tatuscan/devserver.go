@@ -0,0 +1,173 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// maxPayloadSize bounds the request body accepted by the dev server
+const maxPayloadSize = 4 << 20
+
+// runDevServer implements `tatuscan devserver`: a local stand-in for the
+// server that validates and pretty-prints every payload it receives
+func runDevServer(args []string) int {
+	fs := flag.NewFlagSet("devserver", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8040", "Address to listen on")
+	fs.StringVar(&devSecret, "hmac-secret", os.Getenv("TATUSCAN_HMAC_SECRET"), "Reject payloads not signed with this secret")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/machines", handleDevPayload)
+	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"status": "healthy"})
+	})
+
+	fmt.Printf("TatuScan dev server listening on http://%s (set TATUSCAN_URL=http://%s)\n", *listen, *listen)
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "devserver: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// handleDevPayload validates a POSTed payload, prints it and answers like the
+// real server: 201 when valid, 400 with the problems otherwise
+func handleDevPayload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]any{"error": "only POST is supported"})
+		return
+	}
+	reader := io.Reader(r.Body)
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid gzip body"})
+			return
+		}
+		defer zr.Close()
+		reader = zr
+	}
+	body, err := io.ReadAll(io.LimitReader(reader, maxPayloadSize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+
+	if devSecret != "" && !internal.VerifyPayloadSignature(body, r.Header.Get(internal.SignatureHeader), devSecret) {
+		fmt.Printf("--- %s POST %s from %s: invalid or missing %s\n", time.Now().Format(time.RFC3339), r.URL.Path, r.RemoteAddr, internal.SignatureHeader)
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid payload signature"})
+		return
+	}
+
+	body, delta, err := devReports.merge(body)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+		return
+	}
+
+	info, problems := internal.ValidatePayload(body)
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, body, "", "  ") != nil {
+		pretty.Reset()
+		pretty.Write(body)
+	}
+
+	fmt.Printf("--- %s POST %s from %s (%s, %d bytes)\n",
+		time.Now().Format(time.RFC3339), r.URL.Path, r.RemoteAddr, r.UserAgent(), len(body))
+	fmt.Println(pretty.String())
+	if len(problems) > 0 {
+		fmt.Println("INVALID:")
+		for _, p := range problems {
+			fmt.Printf("  - %s\n", p)
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid payload", "problems": problems})
+		return
+	}
+	devReports.store(info.MachineID, body)
+	if delta {
+		fmt.Printf("VALID (delta merged with the last report): %s (%s)\n", info.Hostname, info.MachineID)
+	} else {
+		fmt.Printf("VALID: %s (%s)\n", info.Hostname, info.MachineID)
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"message": "payload accepted", "machine_id": info.MachineID})
+}
+
+// devSecret verifies the payload signatures when set
+var devSecret string
+
+// devReports keeps the last valid report of each machine, the base of the
+// delta reports sent with TATUSCAN_DELTA
+var devReports = &reportStore{last: map[string]map[string]json.RawMessage{}}
+
+// reportStore holds reports by machine ID
+type reportStore struct {
+	mu   sync.Mutex
+	last map[string]map[string]json.RawMessage
+}
+
+// merge completes a delta report (marked "delta": true) with the last
+// report of its machine, like the real server; other bodies are returned
+// unchanged
+func (s *reportStore) merge(body []byte) ([]byte, bool, error) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil || string(fields["delta"]) != "true" {
+		return body, false, nil
+	}
+	var machineID string
+	json.Unmarshal(fields["machine_id"], &machineID)
+
+	s.mu.Lock()
+	base, ok := s.last[machineID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, true, fmt.Errorf("no full report stored for %q; send a full report", machineID)
+	}
+	merged := make(map[string]json.RawMessage, len(base))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		if string(value) == "null" {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	delete(merged, "delta")
+	body, err := json.Marshal(merged)
+	return body, true, err
+}
+
+// store records the last valid report of a machine
+func (s *reportStore) store(machineID string, body []byte) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return
+	}
+	s.mu.Lock()
+	s.last[machineID] = fields
+	s.mu.Unlock()
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
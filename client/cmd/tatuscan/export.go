@@ -0,0 +1,54 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// runExport implements `tatuscan export`: it collects once and prints the
+// payload in the format of another inventory tool, without sending it
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "Output format ("+strings.Join(internal.ExportFormats(), ", ")+")")
+	output := fs.String("o", "", "Write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan export [-format json|ansible|sccm] [-o file]")
+		return 2
+	}
+	log.SetOutput(os.Stderr)
+
+	info, err := internal.CollectData(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	doc, err := internal.Export(info, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 2
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	data = append(data, '\n')
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(*output, data, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,86 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// runHealthcheck implements `tatuscan healthcheck` for deployment scripts
+// and container healthchecks: it checks the configuration, the server
+// (connectivity, TLS, credentials) and a collection, printing one line per
+// check, and exits 1 when any of them fails
+func runHealthcheck(args []string) int {
+	fs := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 15*time.Second, "Time allowed for the server checks")
+	send := fs.Bool("send", false, "Also send the collected payload, checking the whole path")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan healthcheck [-timeout 15s] [-send]")
+		return 2
+	}
+	// The report goes to stdout; errors are logged apart from it
+	log.SetOutput(os.Stderr)
+	log.SetLevel(logrus.ErrorLevel)
+	internal.ReloadConfig(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	failed := false
+	report := func(check, detail string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("FAIL  %-13s %v\n", check, err)
+			return
+		}
+		fmt.Printf("ok    %-13s %s\n", check, detail)
+	}
+
+	target := internal.Cfg.ServerURL
+	if target == "" {
+		if target = internal.DiscoverServer(ctx); target == "" {
+			report("configuration", "", fmt.Errorf("%s is not set in the environment or %s, and no server was discovered", envServerURL, internal.ConfigFilePath()))
+		} else {
+			report("configuration", "server discovered at "+target, nil)
+		}
+	} else {
+		report("configuration", envServerURL+"="+target, nil)
+	}
+	if target != "" {
+		detail, err := internal.CheckServer(ctx, target)
+		report("server", detail, err)
+	}
+
+	info, err := internal.CollectData(context.Background())
+	if err == nil {
+		detail := fmt.Sprintf("machine %s (%s)", info.MachineID, info.Hostname)
+		if len(info.Warnings) > 0 {
+			detail += fmt.Sprintf(", %d warning(s)", len(info.Warnings))
+		}
+		report("collection", detail, nil)
+		for _, w := range info.Warnings {
+			fmt.Printf("      %-13s warning %s: %s\n", "", w.Code, w.Message)
+		}
+	} else {
+		report("collection", "", err)
+	}
+
+	if *send && target != "" && err == nil {
+		sender, err := internal.NewSender(target)
+		if err == nil {
+			err = sender.Send(ctx, info)
+		}
+		report("send", "payload accepted", err)
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+	"github.com/kardianos/service"
+)
+
+// runInstall implements `tatuscan install`: it installs the service with the
+// server URL and interval in its definition (registry environment on
+// Windows, Environment= in the systemd unit, EnvironmentVariables in the
+// launchd plist), so the service can report from its first start. The token
+// goes to the settings file instead, readable by administrators only, since
+// unit files and plists are world-readable.
+func runInstall(args []string) int {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	serverURL := fs.String("url", "", "Server base URL set in the service environment")
+	interval := fs.String("interval", "", "Collection interval set in the service environment (ex.: 60s, 2m)")
+	token := fs.String("token", "", "Server token written to the settings file")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan install [-url URL] [-interval 60s] [-token TOKEN]")
+		return 2
+	}
+
+	env := make(map[string]string)
+	if target := strings.TrimSpace(*serverURL); target != "" {
+		if _, err := internal.NewSender(target); err != nil {
+			fmt.Fprintf(os.Stderr, "install: invalid server URL: %v\n", err)
+			return 2
+		}
+		env[envServerURL] = target
+	}
+	if value := strings.TrimSpace(*interval); value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "install: invalid interval %q\n", value)
+			return 2
+		}
+		env["TATUSCAN_INTERVAL"] = value
+	}
+	if value := strings.TrimSpace(*token); value != "" {
+		if err := internal.PrepareDirs(); err != nil {
+			fmt.Fprintf(os.Stderr, "install: %v\n", err)
+			return 1
+		}
+		path, err := internal.WriteConfigFile(map[string]string{"TATUSCAN_TOKEN": value})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "install: write %s: %v\n", path, err)
+			return 1
+		}
+		fmt.Printf("Token written to %s\n", path)
+	}
+
+	config := serviceConfig()
+	if len(env) > 0 {
+		config.EnvVars = env
+	}
+	s, err := service.New(&program{interval: defaultInterval}, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "install: %v\n", err)
+		return 1
+	}
+	// The agent ID identifies this installation from now on
+	if _, err := internal.EnsureAgentID(); err != nil {
+		log.Warnf("Error to generate the agent ID: %v", err)
+	}
+	if err := s.Install(); err != nil {
+		fmt.Fprintf(os.Stderr, "install: %v\n", err)
+		return 1
+	}
+	fmt.Println("Service installed")
+	if env[envServerURL] == "" && internal.Cfg.ServerURL == "" {
+		fmt.Printf("The agent waits for %s: pass -url or write it to %s\n", envServerURL, internal.ConfigFilePath())
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// runLaunchd implements `tatuscan launchd`: it prints the LaunchDaemon plist
+// of the agent, kept alive by launchd, logging to a file and carrying the
+// TATUSCAN_* variables of the current environment (secrets excluded), or
+// installs and loads it with -install
+func runLaunchd(args []string) int {
+	fs := flag.NewFlagSet("launchd", flag.ContinueOnError)
+	serverURL := fs.String("url", "", "Server base URL set in the plist environment")
+	interval := fs.String("interval", "", "Collection interval set in the plist environment (ex.: 60s, 2m)")
+	logPath := fs.String("log", "", "File receiving the agent output (default /Library/Logs/TatuScan/tatuscan.log)")
+	program := fs.String("program", "", "Agent executable (default: this executable)")
+	install := fs.Bool("install", false, "Install the plist in "+internal.LaunchdPlistPath+" and load it (macOS, as root)")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan launchd [-url URL] [-interval 60s] [-log FILE] [-program PATH] [-install]")
+		return 2
+	}
+
+	values := make(map[string]string)
+	if target := strings.TrimSpace(*serverURL); target != "" {
+		if _, err := internal.NewSender(target); err != nil {
+			fmt.Fprintf(os.Stderr, "launchd: invalid server URL: %v\n", err)
+			return 2
+		}
+		values[envServerURL] = target
+	}
+	if value := strings.TrimSpace(*interval); value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "launchd: invalid interval %q\n", value)
+			return 2
+		}
+		values["TATUSCAN_INTERVAL"] = value
+	}
+	job := internal.LaunchdJob{Program: *program, Env: internal.LaunchdEnv(values), LogPath: *logPath}
+	if job.Program == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "launchd: %v\n", err)
+			return 1
+		}
+		job.Program = exe
+	}
+
+	if !*install {
+		os.Stdout.Write(job.Plist())
+		return 0
+	}
+	if runtime.GOOS != "darwin" {
+		fmt.Fprintln(os.Stderr, "launchd: -install is only available on macOS")
+		return 2
+	}
+	if err := internal.InstallLaunchdJob(job); err != nil {
+		fmt.Fprintf(os.Stderr, "launchd: %v\n", err)
+		return 1
+	}
+	fmt.Printf("LaunchDaemon installed in %s and loaded\n", internal.LaunchdPlistPath)
+	return 0
+}
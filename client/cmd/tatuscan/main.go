@@ -3,12 +3,9 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"flag"
-	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
@@ -22,120 +19,318 @@ import (
 )
 
 const (
-	defaultInterval    = 60 * time.Second
-	envServerURL       = "TATUSCAN_URL"
-	envCollectInterval = "TATUSCAN_INTERVAL"
-	agentVersion       = "0.0.1"
+	defaultInterval = 60 * time.Second
+	envServerURL    = "TATUSCAN_URL"
+)
+
+// Build metadata, injected by the build scripts with
+// -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    string
+	buildDate string
 )
 
 var log *logrus.Logger // Logger global
 
-// getServerURL retrieves the base server URL from environment variable
-func getServerURL() string {
-	log.Debug("Getting ServerURL from environment variable")
-	base := os.Getenv(envServerURL)
+// getSender builds the transport for the destination in TATUSCAN_URL
+func getSender() internal.Sender {
+	log.Debug("Getting ServerURL from configuration")
+	base := internal.Cfg.ServerURL
 	if base == "" {
-		log.Fatalf("Environment variable %s not defined; is mandatory", envServerURL)
-	}
-	base = strings.TrimRight(base, "/")
-	url := base + "/api/machines"
-	log.Debugf("Final ServerURL: %s", url)
-	return url
-}
-
-// sendData sends collected data to the server
-func sendData(info internal.MachineInfo, serverURL string) error {
-	log.Info("Sending data to server")
-	data, err := json.Marshal(info)
-	if err != nil {
-		log.Errorf("Error to serialize data: %v", err)
-		return err
+		base = internal.DiscoverServer(context.Background())
 	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest(http.MethodPost, serverURL, bytes.NewBuffer(data))
-	if err != nil {
-		log.Errorf("Error to create HTTP request: %v", err)
-		return err
+	if base == "" {
+		log.Fatalf("%s not defined in the environment or tatuscan.env and no server announced with DNS-SD; is mandatory", envServerURL)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", fmt.Sprintf("TatuScan/%s (%s)", agentVersion, runtime.GOOS))
-
-	resp, err := client.Do(req)
+	sender, err := internal.NewSender(base)
 	if err != nil {
-		log.Errorf("Error to send data: %v", err)
-		return err
-	}
-	defer resp.Body.Close()
-
-	// Accept 200 (OK) and 201 (Created) as valid responses
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		err := fmt.Errorf("server returned status: %d", resp.StatusCode)
-		log.Error(err)
-		return err
+		log.Fatalf("Invalid value for %s: %v", envServerURL, err)
 	}
-
-	log.Info("Data sent successfully")
-	return nil
+	return sender
 }
 
+// followInterval lets the configuration change the collection interval at
+// run time; false when -interval was given
+var followInterval bool
+
 // runAgent runs the main agent loop with context and ticker for immediate shutdown
-func runAgent(ctx context.Context, serverURL string, interval time.Duration) {
+func runAgent(ctx context.Context, sender internal.Sender, interval time.Duration) {
 	log.Info("Starting agent in repetitive mode (daemon or service)")
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
+	// Ticks come every interval from tickBase, for `tatuscan status`
+	tickBase := time.Now()
+	nextTick := func() time.Time {
+		return tickBase.Add((time.Since(tickBase)/interval + 1) * interval)
+	}
 
-	// Execute one cycle immediately when starting
-	doCycle := func() {
+	// Cycles run on work, which outlives ctx by TATUSCAN_SHUTDOWN_TIMEOUT:
+	// a stop lets the collection or upload in flight complete
+	work, release := internal.DrainContext(ctx)
+	defer release()
+
+	// Execute one cycle immediately when starting. After a network change
+	// the payload is only sent when the addresses actually moved. With
+	// TATUSCAN_SEND_INTERVAL, the cycles in between are only aggregated;
+	// network changes and server requests are sent at once. Cycles held
+	// back by a server backoff are aggregated too. With
+	// TATUSCAN_HEARTBEAT_CYCLES, the cycles between full inventories only
+	// collect what a heartbeat carries, unless the machine changed.
+	var last, unsent internal.MachineInfo
+	var window internal.SampleWindow
+	doCycle := func(networkChange, force bool) {
+		started := time.Now()
+		defer func() {
+			if internal.RecordCycle(time.Since(started), interval) {
+				// Drop the tick queued during the overrun
+				select {
+				case <-ticker.C:
+				default:
+				}
+			}
+		}()
 		log.Debug("Starting collection and send cycle")
-		info, err := internal.CollectData()
+		collect := internal.CollectData
+		if internal.HeartbeatDue(networkChange || force) {
+			collect = internal.CollectHeartbeat
+		}
+		info, err := collect(work)
+		if err == nil && info.Heartbeat && internal.InventoryChanged(info) {
+			log.Info("Machine changed since the last inventory; collecting it in full")
+			info, err = internal.CollectData(work)
+		}
 		if err != nil {
 			log.Errorf("Error to collect data: %v", err)
 			return
 		}
-		if err := sendData(info, serverURL); err != nil {
+		internal.RecordReport(info)
+		if networkChange && internal.SameNetwork(last, info) {
+			log.Debug("Network change did not move the machine; nothing to send")
+			return
+		}
+		now := time.Now()
+		window.Add(info, now)
+		unsent = info
+		if !networkChange && !force && !window.Due(now) {
+			log.Debug("Cycle aggregated until the next send")
+			return
+		}
+		if until, deferred := internal.ReportDeferred(now); deferred && !force {
+			log.Infof("Report deferred until %s as advised by the server", until.Format(time.RFC3339))
+			return
+		}
+		info.Summary = window.Summary()
+		err = sender.Send(work, info)
+		internal.RecordSend(err)
+		if err != nil {
 			log.Errorf("Error to send data: %v", err)
 			return
 		}
+		window.Sent(now)
+		last = info
 		log.Debug("Cycle completed")
 	}
 
-	doCycle()
+	// On stop, send the cycles aggregated since the last send (by
+	// TATUSCAN_SEND_INTERVAL or a failed send), unless the server asked
+	// to hold reports back
+	flush := func() {
+		if !window.Pending() {
+			return
+		}
+		if _, deferred := internal.ReportDeferred(time.Now()); deferred {
+			log.Info("Aggregated cycles dropped on stop: reports deferred by the server")
+			return
+		}
+		log.Info("Sending the aggregated cycles before stopping")
+		unsent.Summary = window.Summary()
+		if err := sender.Send(work, unsent); err != nil {
+			log.Errorf("Error to send data: %v", err)
+		}
+	}
+
+	// Refresh the site configuration overlay, when one is configured
+	var reload <-chan time.Time
+	if cfg := internal.Cfg; cfg.RemoteConfigURL != "" {
+		reloadTicker := time.NewTicker(cfg.RemoteConfigInterval)
+		defer reloadTicker.Stop()
+		reload = reloadTicker.C
+	}
+
+	changes := internal.WatchNetworkChanges(ctx)
+	internal.ServeControl(ctx)
+
+	doCycle(false, false)
 
 	for {
-		select {
-		case <-ctx.Done():
+		internal.RecordSchedule(nextTick(), window.Unsent())
+		// A stop wins over ticks and events ready at the same time
+		if ctx.Err() != nil {
 			log.Info("Stopping agent by cancellation signal")
+			flush()
 			return
+		}
+		select {
+		case <-ctx.Done():
+			continue
+		case <-reload:
+			log.Debug("Reloading site configuration")
+			internal.ReloadConfig(ctx)
+		case <-internal.ReloadRequests():
+			log.Info("Configuration reload requested over the control API")
+			internal.ReloadConfig(ctx)
+		case <-internal.CollectRequests():
+			log.Info("Collection requested by a server task or the control API")
+			doCycle(false, true)
+		case <-changes:
+			log.Info("Network change detected, re-evaluating IP and identity")
+			doCycle(true, false)
+		case <-ticker.C:
+			doCycle(false, false)
+		}
+		// The site overlay or the server may have changed the interval
+		if followInterval {
+			d := internal.Cfg.Interval
+			if d <= 0 {
+				d = defaultInterval
+			}
+			if d != interval {
+				log.Infof("Collection interval changed to %s", d)
+				interval = d
+				ticker.Reset(d)
+				tickBase = time.Now()
+			}
+		}
+	}
+}
+
+// waitForSender reloads the configuration every minute until TATUSCAN_URL
+// is set (by tatuscan.env, a configuration profile or the site overlay) or
+// a server is discovered, returning nil when ctx is cancelled first
+func waitForSender(ctx context.Context) internal.Sender {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for internal.Cfg.ServerURL == "" {
+		if base := internal.DiscoverServer(ctx); base != "" {
+			sender, err := internal.NewSender(base)
+			if err == nil {
+				return sender
+			}
+			log.Warnf("Ignoring discovered server %s: %v", base, err)
+		}
+		log.Warnf("%s not configured yet; waiting for %s or a configuration profile", envServerURL, internal.ConfigFilePath())
+		select {
+		case <-ctx.Done():
+			return nil
 		case <-ticker.C:
-			doCycle()
+			internal.ReloadConfig(ctx)
 		}
 	}
+	return getSender()
+}
+
+// serviceConfig describes the agent service for every service manager
+func serviceConfig() *service.Config {
+	return &service.Config{
+		Name:        "TatuScanAgent",
+		DisplayName: "TatuScan Agent",
+		Description: "TatuScan monitoring agent",
+		Option: service.KeyValue{
+			"RunAtLoad": true, // launchd: start at boot, not only on demand
+		},
+	}
 }
 
 // program implements the service interface
 type program struct {
-	serverURL string
-	interval  time.Duration
-	cancel    context.CancelFunc
+	sender   internal.Sender // nil until TATUSCAN_URL is configured
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{} // closed when the agent loop returns
 }
 
 func (p *program) Start(s service.Service) error {
 	log.Debugf("Starting TatuScan agent as service on OS: %s", runtime.GOOS)
 	ctx, cancel := context.WithCancel(context.Background())
 	p.cancel = cancel
-	go runAgent(ctx, p.serverURL, p.interval)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		sender := p.sender
+		if sender == nil {
+			if sender = waitForSender(ctx); sender == nil {
+				return
+			}
+		}
+		runAgent(ctx, sender, p.interval)
+	}()
 	return nil
 }
 
 func (p *program) Stop(s service.Service) error {
 	log.Debug("Stopping TatuScan agent")
-	if p.cancel != nil {
-		p.cancel()
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	// Let the cycle in flight and the final flush complete; the agent loop
+	// gives up on them after TATUSCAN_SHUTDOWN_TIMEOUT
+	select {
+	case <-p.done:
+	case <-time.After(internal.Cfg.ShutdownTimeout + 5*time.Second):
+		log.Warn("Agent did not stop in time; exiting anyway")
 	}
 	return nil
 }
 
+// manageService runs the service commands (install, start, stop, restart,
+// uninstall) in order. It does not take the instance lock: the running
+// service holds it while stop or restart are asked of it.
+func manageService(s service.Service, actions []string) error {
+	for _, action := range actions {
+		log.Debugf("Managing service command: %s", action)
+		if action == "install" {
+			// The agent ID identifies this installation from now on
+			if _, err := internal.EnsureAgentID(); err != nil {
+				log.Warnf("Error to generate the agent ID: %v", err)
+			}
+		}
+		if err := service.Control(s, action); err != nil {
+			return err
+		}
+		if action == "uninstall" {
+			if err := internal.RemoveEventLogSource(); err != nil {
+				log.Warnf("Error to remove the Event Log source: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// dispatchServiceCommands runs the service commands in args and reports
+// true, or, without commands, takes the instance lock for the agent run.
+// The commands run before the lock: the service they control holds it.
+func dispatchServiceCommands(s service.Service, args []string) (bool, error) {
+	if len(args) > 0 {
+		return true, manageService(s, args)
+	}
+	log.Debug("Checking single instance")
+	internal.EnsureSingleInstance()
+	return false, nil
+}
+
+// useJSONLogs switches the logger to JSON lines carrying the machine_id,
+// cycle and collector of the running collection
+func useJSONLogs(log *logrus.Logger) {
+	var formatter logrus.Formatter = internal.NewLogFormatter(internal.LogFormatJSON, true)
+	if internal.Cfg.LogDedup {
+		formatter = internal.NewDedupFormatter(formatter, internal.Cfg.LogDedupWindow)
+	}
+	log.SetFormatter(formatter)
+	log.AddHook(internal.LogContextHook{})
+}
+
 func main() {
 	// Configure the global logger
 	log = logrus.New()
@@ -147,12 +342,53 @@ func main() {
 
 	// Configure logger for internal package
 	internal.SetLogger(log)
+	internal.SetBuildInfo(internal.BuildInfo{Version: version, Commit: commit, BuildDate: buildDate})
+	internal.SetConfig(internal.LoadConfig())
+	if internal.Cfg.LogDedup {
+		log.SetFormatter(internal.NewDedupFormatter(log.Formatter, internal.Cfg.LogDedupWindow))
+	}
+
+	// Subcommands have their own flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "version":
+			os.Exit(runVersion(os.Args[2:]))
+		case "install":
+			os.Exit(runInstall(os.Args[2:]))
+		case "launchd":
+			os.Exit(runLaunchd(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		case "devserver":
+			os.Exit(runDevServer(os.Args[2:]))
+		case "schema":
+			os.Exit(runSchema(os.Args[2:]))
+		case "bootstrap":
+			os.Exit(runBootstrap(os.Args[2:]))
+		case "healthcheck":
+			os.Exit(runHealthcheck(os.Args[2:]))
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "control":
+			os.Exit(runControl(os.Args[2:]))
+		case "status":
+			os.Exit(runStatus(os.Args[2:]))
+		}
+	}
 
 	// Configure the flags
 	logLevel := flag.String("l", "", "Set log level (debug, info, warn, error, fatal)")
 	daemonMode := flag.Bool("d", false, "Run in daemon mode (repeat collection in cycles)")
 	intervalFlag := flag.String("interval", "", "Collection interval (ex.: 60s, 2m). Env: TATUSCAN_INTERVAL")
+	resetID := flag.Bool("reset-id", false, "Discard the cached MachineID and compute a new one")
+	dryRun := flag.Bool("dry-run", false, "Collect once and print the payload to stdout without sending it")
+	logFormatFlag := flag.String("log-format", "", "Log format (text, json). Env: TATUSCAN_LOG_FORMAT")
+	logFileFlag := flag.String("log-file", "", "Write the logs to a rotated file instead of stdout. Env: TATUSCAN_LOG_FILE")
 	flag.Parse()
+	if *dryRun {
+		// Keep stdout for the payload
+		log.SetOutput(os.Stderr)
+	}
 
 	// Set log level based on flag
 	if *logLevel != "" {
@@ -180,60 +416,99 @@ func main() {
 		log.SetLevel(logrus.WarnLevel)
 	}
 
-	// Ensure single instance of the agent
-	log.Debug("Checking single instance")
-	internal.EnsureSingleInstance()
+	// Move MSI properties to the settings file on the first run, then
+	// merge the site configuration overlay, now that logging is set up
+	internal.ApplyInstallerProperties()
+	internal.ReloadConfig(context.Background())
+	logFormat := internal.Cfg.LogFormat
+	if *logFormatFlag != "" {
+		format, ok := internal.ParseLogFormat(*logFormatFlag)
+		if !ok {
+			log.Fatalf("Invalid log format: %s. Use text or json", *logFormatFlag)
+		}
+		logFormat = format
+	}
+	if logFormat == internal.LogFormatJSON {
+		useJSONLogs(log)
+	}
+	if internal.Cfg.LogOutput == internal.LogOutputSyslog && !*dryRun {
+		if err := internal.UseSyslog(log, logFormat); err != nil {
+			log.Warnf("Error to use syslog, logging to stdout: %v", err)
+		}
+	}
+	logFile := internal.Cfg.LogFile
+	if *logFileFlag != "" {
+		logFile = *logFileFlag
+	}
+	if logFile != "" && !*dryRun {
+		if internal.Cfg.LogOutput == internal.LogOutputSyslog {
+			log.Warnf("Log file %s ignored, logging to syslog", logFile)
+		} else if file, err := internal.OpenLogFile(logFile); err != nil {
+			log.Warnf("Error to open the log file, logging to stdout: %v", err)
+		} else {
+			log.SetOutput(file)
+		}
+	}
 
-	// Get server URL (mandatory)
-	log.Debug("Getting ServerURL")
-	serverURL := getServerURL()
+	// Discard cached MachineID if requested
+	if *resetID {
+		log.Info("Resetting cached MachineID")
+		if err := internal.ResetMachineID(); err != nil {
+			log.Fatalf("Error to reset MachineID: %v", err)
+		}
+	}
+
+	// Show what would leave the machine, without sending it
+	if *dryRun {
+		info, err := internal.CollectData(context.Background())
+		if err != nil {
+			log.Errorf("Error to collect data: %v", err)
+			os.Exit(1)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			log.Errorf("Error to serialize data: %v", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	// Determine collection interval (flag > env > default)
 	interval := defaultInterval
+	followInterval = *intervalFlag == ""
 	if *intervalFlag != "" {
 		if d, err := time.ParseDuration(*intervalFlag); err == nil {
 			interval = d
 		} else {
 			log.Fatalf("Invalid value for -interval: %v", err)
 		}
-	} else if env := strings.TrimSpace(os.Getenv(envCollectInterval)); env != "" {
-		if d, err := time.ParseDuration(env); err == nil {
-			interval = d
-		} else {
-			log.Fatalf("Invalid value for %s: %v", envCollectInterval, err)
-		}
-	}
-
-	// Service configuration
-	log.Debug("Configuring service")
-	svcConfig := &service.Config{
-		Name:        "TatuScanAgent",
-		DisplayName: "TatuScan Agent",
-		Description: "TatuScan monitoring agent",
+	} else if internal.Cfg.Interval > 0 {
+		interval = internal.Cfg.Interval
 	}
 
 	// Create program for the service
 	log.Debug("Creating service program")
-	prg := &program{serverURL: serverURL, interval: interval}
-	s, err := service.New(prg, svcConfig)
+	prg := &program{interval: interval}
+	s, err := service.New(prg, serviceConfig())
 	if err != nil {
 		log.Fatalf("Error to create service: %v", err)
 	}
 
-	// Manage service commands (ex.: install, start, stop)
-	if flag.NArg() > 0 {
-		for _, arg := range flag.Args() {
-			log.Debugf("Managing service command: %s", arg)
-			err = service.Control(s, arg)
-			if err != nil {
-				log.Fatalf("Error to control service: %v", err)
-			}
-		}
+	// Manage service commands (ex.: install, start, stop), or make sure
+	// this is the only agent running
+	if managed, err := dispatchServiceCommands(s, flag.Args()); err != nil {
+		log.Fatalf("Error to control service: %v", err)
+	} else if managed {
 		return
 	}
 
 	// Execute the program
 	if service.Interactive() {
+		// Get server URL (mandatory) and its transport
+		log.Debug("Getting ServerURL")
+		sender := getSender()
+
 		// Interactive mode: behavior depends on -d flag
 		if *daemonMode {
 			log.Info("Running in daemon mode (repetition activated via -d)")
@@ -245,16 +520,16 @@ func main() {
 				<-sigs
 				cancel()
 			}()
-			runAgent(ctx, serverURL, interval)
+			runAgent(ctx, sender, interval)
 		} else {
 			// Default behavior: execute single collection
 			log.Info("Running single collection")
-			info, err := internal.CollectData()
+			info, err := internal.CollectData(context.Background())
 			if err != nil {
 				log.Errorf("Error to collect data: %v", err)
 				os.Exit(1)
 			}
-			if err := sendData(info, serverURL); err != nil {
+			if err := sender.Send(context.Background(), info); err != nil {
 				log.Errorf("Error to send data: %v", err)
 				os.Exit(1)
 			}
@@ -262,7 +537,17 @@ func main() {
 			os.Exit(0)
 		}
 	} else {
-		// Service mode: run in cycles (Windows or Linux with systemd)
+		// Service mode: run in cycles (Windows or Linux with systemd). A
+		// service installed before its configuration waits for it in Start.
+		// A Windows service has no console: failures go to the Event Log.
+		if runtime.GOOS == "windows" {
+			if err := internal.UseEventLog(log, logFormat); err != nil {
+				log.Warnf("Error to open the Event Log: %v", err)
+			}
+		}
+		if internal.Cfg.ServerURL != "" {
+			prg.sender = getSender()
+		}
 		log.Debug("Running as service (repetition automatically activated)")
 		err = s.Run()
 		if err != nil {
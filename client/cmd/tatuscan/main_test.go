@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+	"github.com/kardianos/service"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeService records the actions asked of the service manager
+type fakeService struct {
+	service.Service
+	actions []string
+}
+
+func (s *fakeService) Start() error     { s.actions = append(s.actions, "start"); return nil }
+func (s *fakeService) Stop() error      { s.actions = append(s.actions, "stop"); return nil }
+func (s *fakeService) Restart() error   { s.actions = append(s.actions, "restart"); return nil }
+func (s *fakeService) Install() error   { s.actions = append(s.actions, "install"); return nil }
+func (s *fakeService) Uninstall() error { s.actions = append(s.actions, "uninstall"); return nil }
+
+func TestServiceCommandsWhileAgentRuns(t *testing.T) {
+	log = logrus.New()
+	log.SetOutput(io.Discard)
+	internal.SetLogger(log)
+	internal.SetConfig(internal.Config{StateDir: t.TempDir()})
+	lock := `Local\TatuScanAgentTest`
+	if runtime.GOOS != "windows" {
+		lock = filepath.Join(t.TempDir(), "tatuscan.lock")
+	}
+	internal.SetInstanceLock(lock)
+	t.Cleanup(func() {
+		internal.ReleaseSingleInstance()
+		internal.SetInstanceLock("")
+	})
+
+	// The agent run takes the instance lock
+	if managed, err := dispatchServiceCommands(&fakeService{}, nil); managed || err != nil {
+		t.Fatalf("agent run: managed %v, err %v", managed, err)
+	}
+
+	// Stop and restart must still reach the service manager; taking the
+	// lock first would exit here
+	s := &fakeService{}
+	if managed, err := dispatchServiceCommands(s, []string{"stop", "restart"}); !managed || err != nil {
+		t.Fatalf("service commands: managed %v, err %v", managed, err)
+	}
+	if strings.Join(s.actions, ",") != "stop,restart" {
+		t.Errorf("service actions %v", s.actions)
+	}
+	if _, err := dispatchServiceCommands(s, []string{"reboot"}); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
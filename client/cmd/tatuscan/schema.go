@@ -0,0 +1,27 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// runSchema implements `tatuscan schema`: it prints the JSON Schema of the
+// payload sent by this agent version
+func runSchema(args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan schema")
+		return 2
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(internal.PayloadSchema()); err != nil {
+		fmt.Fprintf(os.Stderr, "schema: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// simulateStats counts the outcome of simulated sends in a cycle
+type simulateStats struct {
+	sent    atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+	latency atomic.Int64 // total nanoseconds of successful sends
+}
+
+// runSimulate implements `tatuscan simulate`: it sends synthetic payloads for
+// a fleet of fake machines, spreading them evenly over each interval
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	machines := fs.Int("machines", 100, "Number of synthetic machines")
+	interval := fs.Duration("interval", time.Minute, "Interval between payloads of each machine")
+	baseURL := fs.String("url", "", "Server base URL. Env: "+envServerURL)
+	workers := fs.Int("concurrency", 50, "Maximum concurrent requests")
+	seed := fs.Int64("seed", 1, "Seed of the synthetic identities (same seed, same machines)")
+	cycles := fs.Int("cycles", 0, "Stop after this many cycles (0 runs until interrupted)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	// Per-request logs would flood the output; only failures are shown
+	log.SetLevel(logrus.WarnLevel)
+	if *machines <= 0 || *interval <= 0 || *workers <= 0 {
+		fmt.Fprintln(os.Stderr, "simulate: -machines, -interval and -concurrency must be positive")
+		return 2
+	}
+
+	base := *baseURL
+	if base == "" {
+		base = os.Getenv(envServerURL)
+	}
+	if base == "" {
+		fmt.Fprintf(os.Stderr, "simulate: server URL not defined; use -url or %s\n", envServerURL)
+		return 2
+	}
+	sender, err := internal.NewSender(base)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+	}()
+
+	fleet := internal.NewSimulatedFleet(*machines, *seed)
+	fmt.Printf("Simulating %d machines every %s against %s\n", len(fleet), *interval, base)
+
+	jobs := make(chan *internal.SimulatedMachine, *workers)
+	var stats *simulateStats
+	var statsMu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range jobs {
+				statsMu.Lock()
+				s := stats
+				statsMu.Unlock()
+				start := time.Now()
+				if err := sender.Send(ctx, m.Next()); err != nil {
+					s.failed.Add(1)
+					continue
+				}
+				s.sent.Add(1)
+				s.latency.Add(int64(time.Since(start)))
+			}
+		}()
+	}
+
+	// Each machine is dispatched at a fixed offset within the interval
+	step := *interval / time.Duration(len(fleet))
+	for cycle := 1; *cycles == 0 || cycle <= *cycles; cycle++ {
+		current := &simulateStats{}
+		statsMu.Lock()
+		stats = current
+		statsMu.Unlock()
+
+		started := time.Now()
+		for i, m := range fleet {
+			if wait := time.Until(started.Add(time.Duration(i) * step)); wait > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(wait):
+				}
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			select {
+			case jobs <- m:
+			default:
+				// Workers saturated: the server is slower than the offered load
+				current.dropped.Add(1)
+			}
+		}
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Until(started.Add(*interval))):
+			}
+		}
+		printSimulateStats(cycle, current)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+	return 0
+}
+
+// printSimulateStats prints a one-line summary of a cycle
+func printSimulateStats(cycle int, s *simulateStats) {
+	sent := s.sent.Load()
+	var avg time.Duration
+	if sent > 0 {
+		avg = time.Duration(s.latency.Load() / sent)
+	}
+	fmt.Printf("cycle %d: sent=%d failed=%d dropped=%d avg_latency=%s\n",
+		cycle, sent, s.failed.Load(), s.dropped.Load(), avg.Round(time.Millisecond))
+}
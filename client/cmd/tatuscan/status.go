@@ -0,0 +1,81 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// runStatus implements `tatuscan status`: it asks the running agent for its
+// state over the local control API and prints it, exiting 1 when the agent
+// cannot be reached or its last send failed
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 10*time.Second, "Time allowed for the agent to answer")
+	asJSON := fs.Bool("json", false, "Print the status as JSON")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan status [-timeout 10s] [-json]")
+		return 2
+	}
+	log.SetOutput(os.Stderr)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	resp, err := internal.Control(ctx, internal.ControlStatus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "status: %v\n", err)
+		return 1
+	}
+	var status internal.AgentStatus
+	if err := json.Unmarshal(resp.Data, &status); err != nil {
+		fmt.Fprintf(os.Stderr, "status: invalid answer from the agent: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		data, _ := json.MarshalIndent(status, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printStatus(status)
+	}
+	if status.LastError != "" {
+		return 1
+	}
+	return 0
+}
+
+// printStatus prints the agent state, one line per item
+func printStatus(s internal.AgentStatus) {
+	or := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+	fmt.Printf("%-16s running (pid %d, version %s, since %s)\n", "agent", s.PID, s.Version, s.Started)
+	fmt.Printf("%-16s %s\n", "machine_id", or(s.MachineID, "-"))
+	fmt.Printf("%-16s %s\n", "server", or(s.Server, "-"))
+	fmt.Printf("%-16s %s\n", "last collection", or(s.LastCollection, "never"))
+	fmt.Printf("%-16s %s\n", "last send", or(s.LastSend, "never"))
+	if s.LastError != "" {
+		fmt.Printf("%-16s %s\n", "last error", s.LastError)
+	}
+	fmt.Printf("%-16s %s\n", "next run", or(s.NextRun, "-"))
+	fmt.Printf("%-16s %d\n", "unsent cycles", s.UnsentCycles)
+	if s.DeferredUntil != "" {
+		fmt.Printf("%-16s %s\n", "deferred until", s.DeferredUntil)
+	}
+	if s.Agent != nil {
+		fmt.Printf("%-16s %dms (%d overruns)\n", "last cycle", s.Agent.LastCycleMs, s.Agent.Overruns)
+	}
+	fmt.Println("config")
+	for _, key := range internal.SettingNames(s.Config) {
+		fmt.Printf("  %s=%s\n", key, s.Config[key])
+	}
+}
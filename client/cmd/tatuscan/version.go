@@ -0,0 +1,43 @@
+//go:build windows || linux || darwin
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// runVersion implements `tatuscan version`: it prints the build metadata
+// also reported in the payload and User-Agent
+func runVersion(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the build metadata as JSON")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: tatuscan version [-json]")
+		return 2
+	}
+	build := internal.AgentBuild()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(build); err != nil {
+			fmt.Fprintf(os.Stderr, "version: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Printf("tatuscan %s\n", build.Version)
+	if build.Commit != "" {
+		fmt.Printf("commit:     %s\n", build.Commit)
+	}
+	if build.BuildDate != "" {
+		fmt.Printf("built:      %s\n", build.BuildDate)
+	}
+	fmt.Printf("go:         %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return 0
+}
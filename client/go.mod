@@ -4,15 +4,15 @@ go 1.23.0
 
 require (
 	github.com/StackExchange/wmi v1.2.1
+	github.com/go-ole/go-ole v1.2.6
 	github.com/kardianos/service v1.2.2
 	github.com/shirou/gopsutil/v3 v3.21.10
 	github.com/sirupsen/logrus v1.8.1
+	golang.org/x/sys v0.32.0
 )
 
 require (
-	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/lufia/plan9stats v0.0.0-20211012122336-39d0f177ccd0 // indirect
 	github.com/tklauser/go-sysconf v0.3.9 // indirect
 	github.com/tklauser/numcpus v0.3.0 // indirect
-	golang.org/x/sys v0.32.0 // indirect
 )
@@ -0,0 +1,5 @@
+// Package integration holds the end-to-end tests of the agent against the
+// reference server. They are built with the integration tag and need a
+// running server: `make client-integration` starts one in a container and
+// sets TATUSCAN_INTEGRATION_URL.
+package integration
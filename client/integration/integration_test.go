@@ -0,0 +1,274 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// serverURL returns the base URL of the server under test
+func serverURL(t *testing.T) string {
+	t.Helper()
+	url := strings.TrimRight(os.Getenv("TATUSCAN_INTEGRATION_URL"), "/")
+	if url == "" {
+		t.Skip("TATUSCAN_INTEGRATION_URL is not set; run make client-integration")
+	}
+	return url
+}
+
+// hmacSecret is the payload secret the server was started with
+func hmacSecret() string {
+	return os.Getenv("TATUSCAN_INTEGRATION_HMAC_SECRET")
+}
+
+// setupAgent configures the internal package like the agent does, with a
+// private state directory
+func setupAgent(t *testing.T) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	internal.SetLogger(logger)
+	internal.SetConfig(internal.Config{StateDir: t.TempDir(), HMACSecret: hmacSecret()})
+}
+
+// simulatedMachine returns a synthetic machine unique to the test, removed
+// from the server when the test ends
+func simulatedMachine(t *testing.T, base string, seed int64) *internal.SimulatedMachine {
+	t.Helper()
+	m := internal.NewSimulatedFleet(1, seed)[0]
+	t.Cleanup(func() { deleteMachine(base, m.MachineID()) })
+	return m
+}
+
+// send delivers info through the transport selected by target
+func send(t *testing.T, target string, info internal.MachineInfo) {
+	t.Helper()
+	sender, err := internal.NewSender(target)
+	if err != nil {
+		t.Fatalf("NewSender(%s): %v", target, err)
+	}
+	if err := sender.Send(context.Background(), info); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+// storedMachine returns the record the server keeps for machineID
+func storedMachine(t *testing.T, base, machineID string) map[string]any {
+	t.Helper()
+	resp, err := http.Get(base + "/api/machines")
+	if err != nil {
+		t.Fatalf("list machines: %v", err)
+	}
+	defer resp.Body.Close()
+	var list struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode machine list: %v", err)
+	}
+	for _, item := range list.Items {
+		if item["machine_id"] == machineID {
+			return item
+		}
+	}
+	t.Fatalf("machine %s not stored", machineID)
+	return nil
+}
+
+// deleteMachine removes a record created by a test
+func deleteMachine(base, machineID string) {
+	req, err := http.NewRequest(http.MethodDelete, base+"/api/machines/"+machineID, nil)
+	if err != nil {
+		return
+	}
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// postRaw posts body as is, signed when sign is set, returning the status
+func postRaw(t *testing.T, base string, body []byte, sign bool) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, base+"/api/machines", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sign && hmacSecret() != "" {
+		req.Header.Set(internal.SignatureHeader, internal.PayloadSignature(body, hmacSecret()))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// assertStored compares the stored record with the fields of info
+func assertStored(t *testing.T, base string, info internal.MachineInfo) {
+	t.Helper()
+	got := storedMachine(t, base, info.MachineID)
+	want := map[string]any{
+		"hostname":        info.Hostname,
+		"ip":              info.IP,
+		"os":              info.OS,
+		"os_version":      info.OSVersion,
+		"memory_total_mb": float64(info.MemoryTotalMB),
+		"memory_used_mb":  float64(info.MemoryUsedMB),
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("stored %s = %v, want %v", key, got[key], value)
+		}
+	}
+}
+
+func TestHTTPTransport(t *testing.T) {
+	base := serverURL(t)
+	setupAgent(t)
+	m := simulatedMachine(t, base, 548001)
+
+	first := m.Next()
+	send(t, base, first)
+	assertStored(t, base, first)
+
+	// A second report updates the same record
+	second := m.Next()
+	second.IP = "10.254.0.2"
+	send(t, base, second)
+	assertStored(t, base, second)
+}
+
+func TestCompressedPayload(t *testing.T) {
+	base := serverURL(t)
+	setupAgent(t)
+	internal.Cfg.CompressThreshold = 1
+	m := simulatedMachine(t, base, 548002)
+
+	info := m.Next()
+	send(t, base, info)
+	assertStored(t, base, info)
+}
+
+func TestDeltaReports(t *testing.T) {
+	base := serverURL(t)
+	setupAgent(t)
+	internal.Cfg.Delta = true
+	m := simulatedMachine(t, base, 548003)
+
+	full := m.Next()
+	send(t, base, full)
+	delta := m.Next()
+	delta.IP = "10.254.0.3"
+	send(t, base, delta)
+	assertStored(t, base, delta)
+
+	// The server asks for a full report when it has no base for a delta
+	body, _ := json.Marshal(map[string]any{"delta": true, "machine_id": strings.Repeat("f", 64), "cpu_percent": 1})
+	if status := postRaw(t, base, body, true); status != http.StatusConflict {
+		t.Errorf("delta for an unknown machine: status %d, want 409", status)
+	}
+}
+
+func TestPayloadSignature(t *testing.T) {
+	base := serverURL(t)
+	if hmacSecret() == "" {
+		t.Skip("TATUSCAN_INTEGRATION_HMAC_SECRET is not set")
+	}
+	setupAgent(t)
+	m := simulatedMachine(t, base, 548004)
+	body, _ := json.Marshal(m.Next())
+	if status := postRaw(t, base, body, false); status != http.StatusUnauthorized {
+		t.Errorf("unsigned payload: status %d, want 401", status)
+	}
+	if status := postRaw(t, base, body, true); status != http.StatusCreated {
+		t.Errorf("signed payload: status %d, want 201", status)
+	}
+}
+
+// buildAgent compiles the agent binary once per test
+func buildAgent(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "tatuscan")
+	cmd := exec.Command("go", "build", "-o", bin, "../cmd/tatuscan")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build agent: %v\n%s", err, output)
+	}
+	return bin
+}
+
+// runAgent runs the agent binary with the server settings in its
+// environment, returning stdout
+func runAgent(t *testing.T, bin, base string, args ...string) ([]byte, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Env = append(os.Environ(),
+		"TATUSCAN_URL="+base,
+		"TATUSCAN_HMAC_SECRET="+hmacSecret(),
+		"TATUSCAN_STATE_DIR="+t.TempDir(),
+		"TATUSCAN_CONFIG_DIR="+t.TempDir(),
+		"TATUSCAN_CACHE_DIR="+t.TempDir(),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return output, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return output, nil
+}
+
+func TestAgentBinary(t *testing.T) {
+	base := serverURL(t)
+	setupAgent(t)
+	bin := buildAgent(t)
+
+	t.Run("simulate", func(t *testing.T) {
+		const seed = 548005
+		fleet := internal.NewSimulatedFleet(3, seed)
+		for _, m := range fleet {
+			t.Cleanup(func() { deleteMachine(base, m.MachineID()) })
+		}
+		if _, err := runAgent(t, bin, base, "simulate", "-machines", "3", "-cycles", "1", "-interval", "1s", "-seed", fmt.Sprint(seed)); err != nil {
+			t.Fatalf("simulate: %v", err)
+		}
+		for _, m := range fleet {
+			storedMachine(t, base, m.MachineID())
+		}
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		output, err := runAgent(t, bin, base, "-dry-run")
+		if err != nil {
+			t.Skipf("this host cannot be collected (no physical interface?): %v", err)
+		}
+		var info internal.MachineInfo
+		if err := json.Unmarshal(output, &info); err != nil {
+			t.Fatalf("dry-run output: %v", err)
+		}
+		t.Cleanup(func() { deleteMachine(base, info.MachineID) })
+		if _, err := runAgent(t, bin, base); err != nil {
+			t.Fatalf("agent run: %v", err)
+		}
+		if got := storedMachine(t, base, info.MachineID); got["hostname"] != info.Hostname {
+			t.Errorf("stored hostname = %v, want %s", got["hostname"], info.Hostname)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// agentIDFileName is the state file holding the agent UUID
+const agentIDFileName = "agent-id.json"
+
+// agentIDPattern matches a lowercase RFC 4122 version 4 UUID
+var agentIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// agentIDState is the content persisted in the agent ID file
+type agentIDState struct {
+	AgentID   string `json:"agent_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+// newAgentID returns a random version 4 UUID
+func newAgentID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// EnsureAgentID returns the agent UUID, generating and persisting it on
+// first use (at install time, or the first cycle of older installs). Unlike
+// the MachineID it does not depend on the hardware: a reinstall gets a new
+// one, a hardware change keeps it.
+func EnsureAgentID() (string, error) {
+	path := statePath(agentIDFileName)
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var state agentIDState
+		if json.Unmarshal(data, &state) == nil && agentIDPattern.MatchString(state.AgentID) {
+			return state.AgentID, nil
+		}
+		Log.Warnf("Replacing invalid agent ID file %s", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	id, err := newAgentID()
+	if err != nil {
+		return "", err
+	}
+	data, err = json.MarshalIndent(agentIDState{AgentID: id, CreatedAt: time.Now().Format(time.RFC3339)}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		return "", err
+	}
+	Log.Infof("Agent ID %s persisted in %s", id, path)
+	return id, nil
+}
+
+// collectAgentID fills the agent UUID; without a state directory it is left
+// empty rather than changing on every run
+func collectAgentID(ctx context.Context, info *MachineInfo) error {
+	id, err := EnsureAgentID()
+	if err != nil {
+		addWarning(ctx, WarnStateNotPersisted, "agent ID not persisted: %v", err)
+		return err
+	}
+	info.AgentID = id
+	return nil
+}
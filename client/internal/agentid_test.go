@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEnsureAgentID(t *testing.T) {
+	setupTestAgent(t)
+	first, err := EnsureAgentID()
+	if err != nil || !agentIDPattern.MatchString(first) {
+		t.Fatalf("EnsureAgentID = %q, %v", first, err)
+	}
+	if again, _ := EnsureAgentID(); again != first {
+		t.Errorf("agent ID changed from %s to %s", first, again)
+	}
+
+	// Resetting the MachineID keeps the installation identity
+	if err := ResetMachineID(); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := EnsureAgentID(); again != first {
+		t.Errorf("agent ID changed after a MachineID reset")
+	}
+
+	// A damaged file is replaced by a new ID
+	if err := os.WriteFile(statePath(agentIDFileName), []byte(`{"agent_id": "x"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if replaced, err := EnsureAgentID(); err != nil || replaced == first || !agentIDPattern.MatchString(replaced) {
+		t.Errorf("replaced agent ID = %q, %v", replaced, err)
+	}
+}
@@ -0,0 +1,74 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AgentStats reports the health of the agent itself. Cycle figures describe
+// the previous cycle, as the current one is still running when collected.
+type AgentStats struct {
+	LastCycleMs  int64 `json:"last_cycle_ms"`
+	Overruns     int   `json:"overruns,omitempty"`      // cycles longer than the interval since start
+	SkippedTicks int   `json:"skipped_ticks,omitempty"` // intervals dropped by those cycles
+}
+
+var agentStatsState struct {
+	sync.Mutex
+	stats       AgentStats
+	cycles      int
+	lastOverrun time.Duration // pending cycle_overrun warning, zero when none
+	interval    time.Duration
+}
+
+// RecordCycle records the duration of a collection and send cycle. A cycle
+// longer than interval is logged and reported as cycle_overrun in the next
+// payload; it returns true so the caller drops the tick queued meanwhile
+// instead of starting the next cycle right away.
+func RecordCycle(elapsed, interval time.Duration) bool {
+	agentStatsState.Lock()
+	defer agentStatsState.Unlock()
+	agentStatsState.cycles++
+	agentStatsState.stats.LastCycleMs = elapsed.Milliseconds()
+	if interval <= 0 || elapsed <= interval {
+		return false
+	}
+	skipped := int(elapsed / interval)
+	agentStatsState.stats.Overruns++
+	agentStatsState.stats.SkippedTicks += skipped
+	agentStatsState.lastOverrun, agentStatsState.interval = elapsed, interval
+	Log.WithFields(logrus.Fields{
+		"duration":      elapsed.Round(time.Millisecond),
+		"interval":      interval,
+		"skipped_ticks": skipped,
+	}).Warn("Collection cycle took longer than the interval; skipping queued cycles")
+	return true
+}
+
+// agentStats returns the stats of the previous cycles, nil before the first
+// cycle completes (single collections report no stats)
+func agentStats() *AgentStats {
+	agentStatsState.Lock()
+	defer agentStatsState.Unlock()
+	if agentStatsState.cycles == 0 {
+		return nil
+	}
+	stats := agentStatsState.stats
+	return &stats
+}
+
+// checkCycleOverrun warns once about a previous cycle that overran
+func checkCycleOverrun(ctx context.Context) {
+	agentStatsState.Lock()
+	elapsed, interval := agentStatsState.lastOverrun, agentStatsState.interval
+	agentStatsState.lastOverrun = 0
+	agentStatsState.Unlock()
+	if elapsed > 0 {
+		addWarning(ctx, WarnCycleOverrun, "previous cycle took %s, longer than the %s interval", elapsed.Round(time.Second), interval)
+	}
+}
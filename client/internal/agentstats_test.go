@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecordCycleOverrun(t *testing.T) {
+	setupTestAgent(t)
+	// Start from no cycles and no warnings whatever ran before
+	reset := func() {
+		agentStatsState.Lock()
+		defer agentStatsState.Unlock()
+		agentStatsState.stats, agentStatsState.cycles = AgentStats{}, 0
+		agentStatsState.lastOverrun, agentStatsState.interval = 0, 0
+	}
+	reset()
+	takeWarnings()
+	t.Cleanup(reset)
+	if agentStats() != nil {
+		t.Fatal("expected no stats before the first cycle")
+	}
+
+	if RecordCycle(20*time.Second, time.Minute) {
+		t.Error("a cycle shorter than the interval must not overrun")
+	}
+	checkCycleOverrun(context.Background())
+	if warnings := takeWarnings(); len(warnings) != 0 {
+		t.Errorf("unexpected warnings %+v", warnings)
+	}
+
+	if !RecordCycle(150*time.Second, time.Minute) {
+		t.Error("expected an overrun")
+	}
+	want := AgentStats{LastCycleMs: 150000, Overruns: 1, SkippedTicks: 2}
+	if got := agentStats(); got == nil || *got != want {
+		t.Errorf("agentStats = %+v, want %+v", got, want)
+	}
+	checkCycleOverrun(context.Background())
+	checkCycleOverrun(context.Background()) // reported once
+	if warnings := takeWarnings(); len(warnings) != 1 || warnings[0].Code != WarnCycleOverrun {
+		t.Errorf("expected one cycle_overrun, got %+v", warnings)
+	}
+}
@@ -0,0 +1,103 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+// MetricsSummary aggregates the metrics of the cycles collected since the
+// last send, when TATUSCAN_SEND_INTERVAL spaces sends out
+type MetricsSummary struct {
+	Samples       int        `json:"samples"`
+	WindowSeconds int64      `json:"window_seconds"`
+	CPUPercent    MetricStat `json:"cpu_percent"`
+	MemoryUsedMB  MetricStat `json:"memory_used_mb"`
+}
+
+// MetricStat is the minimum, average and maximum of a metric
+type MetricStat struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+// metricAccumulator collects the samples of one metric
+type metricAccumulator struct {
+	min, max, sum float64
+}
+
+// add records a sample; count is the number of samples including it
+func (a *metricAccumulator) add(value float64, count int) {
+	if count == 1 || value < a.min {
+		a.min = value
+	}
+	if count == 1 || value > a.max {
+		a.max = value
+	}
+	a.sum += value
+}
+
+// stat returns the rounded statistics over count samples
+func (a *metricAccumulator) stat(count int) MetricStat {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return MetricStat{Min: round(a.min), Avg: round(a.sum / float64(count)), Max: round(a.max)}
+}
+
+// SampleWindow holds the cycles collected between two sends. Its zero value
+// is ready to use and due, so the first cycle is always sent.
+type SampleWindow struct {
+	sent    time.Time // last successful send
+	started time.Time
+	lastAt  time.Time
+	samples int
+	cpu     metricAccumulator
+	memory  metricAccumulator
+}
+
+// Add records the metrics of a collected payload
+func (w *SampleWindow) Add(info MachineInfo, now time.Time) {
+	if w.samples == 0 {
+		w.started = now
+	}
+	w.samples++
+	w.lastAt = now
+	w.cpu.add(info.CPUPercent, w.samples)
+	w.memory.add(float64(info.MemoryUsedMB), w.samples)
+}
+
+// Due reports whether a payload should be sent: every cycle without
+// TATUSCAN_SEND_INTERVAL, otherwise once it has passed since the last send
+func (w *SampleWindow) Due(now time.Time) bool {
+	return Cfg.SendInterval <= 0 || w.sent.IsZero() || now.Sub(w.sent) >= Cfg.SendInterval
+}
+
+// Summary returns the aggregate of the window, nil below two samples where
+// the payload metrics already tell everything
+func (w *SampleWindow) Summary() *MetricsSummary {
+	if w.samples < 2 {
+		return nil
+	}
+	return &MetricsSummary{
+		Samples:       w.samples,
+		WindowSeconds: int64(w.lastAt.Sub(w.started).Seconds()),
+		CPUPercent:    w.cpu.stat(w.samples),
+		MemoryUsedMB:  w.memory.stat(w.samples),
+	}
+}
+
+// Pending reports whether cycles were collected since the last send
+func (w *SampleWindow) Pending() bool {
+	return w.samples > 0
+}
+
+// Unsent returns the number of cycles collected since the last send
+func (w *SampleWindow) Unsent() int {
+	return w.samples
+}
+
+// Sent starts a new window after a successful send
+func (w *SampleWindow) Sent(now time.Time) {
+	*w = SampleWindow{sent: now}
+}
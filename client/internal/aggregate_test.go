@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampleWindow(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.SendInterval = 5 * time.Minute
+	start := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	var w SampleWindow
+	if !w.Due(start) {
+		t.Fatal("the first cycle must be sent")
+	}
+	w.Add(MachineInfo{CPUPercent: 10, MemoryUsedMB: 1000}, start)
+	if w.Summary() != nil {
+		t.Error("no summary expected for a single sample")
+	}
+	w.Sent(start)
+
+	for i, cpu := range []float64{20, 80, 50} {
+		now := start.Add(time.Duration(i+1) * 2 * time.Minute)
+		w.Add(MachineInfo{CPUPercent: cpu, MemoryUsedMB: uint64(1000 + 100*i)}, now)
+		if due := w.Due(now); due != (i == 2) {
+			t.Errorf("cycle %d: due = %v", i, due)
+		}
+	}
+	want := MetricsSummary{
+		Samples:       3,
+		WindowSeconds: 240,
+		CPUPercent:    MetricStat{Min: 20, Avg: 50, Max: 80},
+		MemoryUsedMB:  MetricStat{Min: 1000, Avg: 1100, Max: 1200},
+	}
+	if got := w.Summary(); got == nil || *got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+
+	Cfg.SendInterval = 0
+	if !w.Due(start) {
+		t.Error("without TATUSCAN_SEND_INTERVAL every cycle is due")
+	}
+}
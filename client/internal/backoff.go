@@ -0,0 +1,123 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxServerBackoff bounds how long a server reply can hold reports back,
+// so a misconfigured server cannot silence the fleet for good
+const maxServerBackoff = 24 * time.Hour
+
+// defaultBackoffPeriod is how long an advised interval applies when the
+// server gives none
+const defaultBackoffPeriod = time.Hour
+
+// ServerBackoff is the hint an overloaded server may return in its reply to
+// slow the agents down for a while, without changing their configuration
+type ServerBackoff struct {
+	RetryAfter string `json:"retry_after,omitempty"` // Go duration: no report before it elapses
+	Interval   string `json:"interval,omitempty"`    // Go duration: minimum time between reports
+	For        string `json:"for,omitempty"`         // Go duration the interval applies (default 1h)
+}
+
+// backoffState holds the server advice; reports are deferred until until,
+// then sent at most every interval until intervalUntil
+var backoffState struct {
+	sync.Mutex
+	until         time.Time
+	interval      time.Duration
+	intervalUntil time.Time
+	lastSent      time.Time
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now), date.After(now)
+	}
+	return 0, false
+}
+
+// deferReports holds the reports back for d, capped at maxServerBackoff;
+// a shorter advice does not cut an earlier longer one
+func deferReports(d time.Duration, now time.Time) {
+	d = min(d, maxServerBackoff)
+	backoffState.Lock()
+	defer backoffState.Unlock()
+	if until := now.Add(d); until.After(backoffState.until) {
+		backoffState.until = until
+		Log.Warnf("Server asked to hold reports back for %s", d.Round(time.Second))
+	}
+}
+
+// applyServerBackoff applies the backoff hint of a server reply
+func applyServerBackoff(hint ServerBackoff, now time.Time) error {
+	if hint.RetryAfter != "" {
+		d, err := time.ParseDuration(hint.RetryAfter)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid retry_after %q", hint.RetryAfter)
+		}
+		deferReports(d, now)
+	}
+	if hint.Interval == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(hint.Interval)
+	if err != nil || interval < minServerInterval {
+		return fmt.Errorf("invalid interval %q", hint.Interval)
+	}
+	period := defaultBackoffPeriod
+	if hint.For != "" {
+		if period, err = time.ParseDuration(hint.For); err != nil || period <= 0 {
+			return fmt.Errorf("invalid period %q", hint.For)
+		}
+	}
+	interval, period = min(interval, maxServerBackoff), min(period, maxServerBackoff)
+
+	backoffState.Lock()
+	defer backoffState.Unlock()
+	if backoffState.interval != interval || now.After(backoffState.intervalUntil) {
+		Log.Warnf("Server asked for one report every %s for %s", interval, period)
+	}
+	backoffState.interval, backoffState.intervalUntil = interval, now.Add(period)
+	return nil
+}
+
+// recordReportSent notes an accepted report, the start of the advised
+// interval
+func recordReportSent(now time.Time) {
+	backoffState.Lock()
+	defer backoffState.Unlock()
+	backoffState.lastSent = now
+}
+
+// ReportDeferred reports whether the server advice holds the next report
+// back at now, and until when. Reports requested by the server itself
+// (tasks) are not subject to it.
+func ReportDeferred(now time.Time) (time.Time, bool) {
+	backoffState.Lock()
+	defer backoffState.Unlock()
+	if now.Before(backoffState.until) {
+		return backoffState.until, true
+	}
+	if now.Before(backoffState.intervalUntil) && !backoffState.lastSent.IsZero() {
+		if next := backoffState.lastSent.Add(backoffState.interval); now.Before(next) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
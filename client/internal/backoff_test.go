@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// resetBackoff clears the server advice and the last report time left by
+// earlier tests, and again after the test
+func resetBackoff(t *testing.T) {
+	reset := func() {
+		backoffState.Lock()
+		defer backoffState.Unlock()
+		backoffState.until, backoffState.intervalUntil, backoffState.lastSent = time.Time{}, time.Time{}, time.Time{}
+		backoffState.interval = 0
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for value, want := range map[string]time.Duration{
+		"120":                           2 * time.Minute,
+		"Thu, 01 Jan 2026 12:05:00 GMT": 5 * time.Minute,
+	} {
+		if d, ok := parseRetryAfter(value, now); !ok || d != want {
+			t.Errorf("parseRetryAfter(%q) = %s, %v", value, d, ok)
+		}
+	}
+	for _, value := range []string{"", "0", "soon", "Thu, 01 Jan 2026 11:00:00 GMT"} {
+		if _, ok := parseRetryAfter(value, now); ok {
+			t.Errorf("parseRetryAfter(%q) should be ignored", value)
+		}
+	}
+}
+
+func TestServerBackoff(t *testing.T) {
+	setupTestAgent(t)
+	resetBackoff(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := applyServerBackoff(ServerBackoff{Interval: "5s"}, now); err == nil {
+		t.Error("expected an interval below the minimum to be rejected")
+	}
+	if err := applyServerBackoff(ServerBackoff{RetryAfter: "2m", Interval: "10m", For: "1h"}, now); err != nil {
+		t.Fatalf("applyServerBackoff: %v", err)
+	}
+	if until, ok := ReportDeferred(now.Add(time.Minute)); !ok || !until.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("expected reports deferred by retry_after, got %s %v", until, ok)
+	}
+	if _, ok := ReportDeferred(now.Add(3 * time.Minute)); ok {
+		t.Error("expected the first report allowed after retry_after")
+	}
+	recordReportSent(now.Add(3 * time.Minute))
+	if until, ok := ReportDeferred(now.Add(5 * time.Minute)); !ok || !until.Equal(now.Add(13*time.Minute)) {
+		t.Errorf("expected the advised interval, got %s %v", until, ok)
+	}
+	if _, ok := ReportDeferred(now.Add(2 * time.Hour)); ok {
+		t.Error("expected the advice to expire")
+	}
+}
+
+func TestHTTPSenderRetryAfter(t *testing.T) {
+	setupTestAgent(t)
+	resetBackoff(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err == nil {
+		t.Error("expected an error on 503")
+	}
+	if until, ok := ReportDeferred(time.Now()); !ok || time.Until(until) < 9*time.Minute {
+		t.Errorf("expected reports deferred by Retry-After, got %s %v", until, ok)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BatteryInfo describes a laptop battery
+type BatteryInfo struct {
+	Name          string  `json:"name"`
+	ChargePercent float64 `json:"charge_percent"`
+	Status        string  `json:"status,omitempty"`
+	CycleCount    int     `json:"cycle_count,omitempty"`
+	HealthPercent float64 `json:"health_percent,omitempty"`
+}
+
+// batteryHealth returns the full charge capacity as a percentage of the
+// design capacity, or 0 when either is unknown
+func batteryHealth(full, design float64) float64 {
+	if full <= 0 || design <= 0 {
+		return 0
+	}
+	return math.Round(full/design*1000) / 10
+}
+
+// batteryFromIOReg builds a battery from AppleSmartBattery ioreg properties.
+// On Apple Silicon MaxCapacity/CurrentCapacity are percentages and the mAh
+// values are in AppleRawMaxCapacity.
+func batteryFromIOReg(props map[string]string) *BatteryInfo {
+	if !strings.EqualFold(props["BatteryInstalled"], "Yes") {
+		return nil
+	}
+	number := func(key string) float64 {
+		v, err := strconv.ParseFloat(props[key], 64)
+		if err != nil {
+			return 0
+		}
+		return v
+	}
+	battery := &BatteryInfo{Name: "InternalBattery"}
+
+	current, max := number("CurrentCapacity"), number("MaxCapacity")
+	if max > 0 {
+		battery.ChargePercent = math.Round(current/max*1000) / 10
+	}
+
+	full := number("AppleRawMaxCapacity")
+	if full == 0 {
+		full = max
+	}
+	battery.HealthPercent = batteryHealth(full, number("DesignCapacity"))
+	battery.CycleCount = int(number("CycleCount"))
+
+	switch {
+	case strings.EqualFold(props["FullyCharged"], "Yes"):
+		battery.Status = "full"
+	case strings.EqualFold(props["IsCharging"], "Yes"):
+		battery.Status = "charging"
+	case strings.EqualFold(props["ExternalConnected"], "Yes"):
+		battery.Status = "not charging"
+	default:
+		battery.Status = "discharging"
+	}
+	return battery
+}
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package internal
+
+// getBatteries reads the internal battery from the AppleSmartBattery service
+func getBatteries() []BatteryInfo {
+	output, err := runCommand("ioreg", "-rn", "AppleSmartBattery")
+	if err != nil {
+		Log.Debugf("Error to execute ioreg: %v", err)
+		return nil
+	}
+	battery := batteryFromIOReg(parseIORegProperties(string(output)))
+	if battery == nil {
+		return nil
+	}
+	Log.Debugf("Battery detected: %+v", *battery)
+	return []BatteryInfo{*battery}
+}
@@ -0,0 +1,62 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readPowerSupply reads an attribute from /sys/class/power_supply/<name>
+func readPowerSupply(name, attr string) string {
+	data, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "power_supply", name, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// getBatteries reads system batteries from the power_supply class, skipping
+// device-scoped ones (wireless mice, keyboards)
+func getBatteries() []BatteryInfo {
+	entries, err := os.ReadDir(filepath.Join(sysfsRoot, "class", "power_supply"))
+	if err != nil {
+		Log.Debugf("Error to read power_supply: %v", err)
+		return nil
+	}
+	number := func(name, attr string) float64 {
+		v, err := strconv.ParseFloat(readPowerSupply(name, attr), 64)
+		if err != nil {
+			return 0
+		}
+		return v
+	}
+
+	var batteries []BatteryInfo
+	for _, e := range entries {
+		name := e.Name()
+		if readPowerSupply(name, "type") != "Battery" || readPowerSupply(name, "scope") == "Device" {
+			continue
+		}
+		if present := readPowerSupply(name, "present"); present == "0" {
+			continue
+		}
+		battery := BatteryInfo{
+			Name:          name,
+			ChargePercent: number(name, "capacity"),
+			Status:        strings.ToLower(readPowerSupply(name, "status")),
+			CycleCount:    int(number(name, "cycle_count")),
+		}
+		// Drivers report either energy (µWh) or charge (µAh) counters
+		if design := number(name, "energy_full_design"); design > 0 {
+			battery.HealthPercent = batteryHealth(number(name, "energy_full"), design)
+		} else {
+			battery.HealthPercent = batteryHealth(number(name, "charge_full"), number(name, "charge_full_design"))
+		}
+		Log.Debugf("Battery detected: %+v", battery)
+		batteries = append(batteries, battery)
+	}
+	return batteries
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestBatteryHealth(t *testing.T) {
+	if h := batteryHealth(42150000, 50000000); h != 84.3 {
+		t.Errorf("health = %v, want 84.3", h)
+	}
+	if h := batteryHealth(100, 0); h != 0 {
+		t.Errorf("health without design capacity = %v, want 0", h)
+	}
+}
+
+func TestBatteryFromIORegAppleSilicon(t *testing.T) {
+	props := parseIORegProperties(`+-o AppleSmartBattery  <class AppleSmartBattery>
+    {
+      "BatteryInstalled" = Yes
+      "CurrentCapacity" = 76
+      "MaxCapacity" = 100
+      "AppleRawMaxCapacity" = 4382
+      "DesignCapacity" = 4563
+      "CycleCount" = 212
+      "IsCharging" = No
+      "ExternalConnected" = No
+      "FullyCharged" = No
+    }`)
+	battery := batteryFromIOReg(props)
+	if battery == nil {
+		t.Fatal("expected a battery")
+	}
+	expected := BatteryInfo{Name: "InternalBattery", ChargePercent: 76, Status: "discharging", CycleCount: 212, HealthPercent: 96}
+	if *battery != expected {
+		t.Errorf("battery = %+v, want %+v", *battery, expected)
+	}
+}
+
+func TestBatteryFromIORegNotInstalled(t *testing.T) {
+	if battery := batteryFromIOReg(map[string]string{"BatteryInstalled": "No"}); battery != nil {
+		t.Errorf("expected nil, got %+v", battery)
+	}
+}
+
+func TestGetBatteriesLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("power_supply is Linux only")
+	}
+	setupTestAgent(t)
+	write := func(rel, value string) {
+		path := filepath.Join(sysfsRoot, "class", "power_supply", rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("AC/type", "Mains")
+	write("BAT0/type", "Battery")
+	write("BAT0/present", "1")
+	write("BAT0/capacity", "81")
+	write("BAT0/status", "Charging")
+	write("BAT0/cycle_count", "318")
+	write("BAT0/energy_full", "41000000")
+	write("BAT0/energy_full_design", "57000000")
+	write("hidpp_battery_0/type", "Battery")
+	write("hidpp_battery_0/scope", "Device")
+
+	batteries := getBatteries()
+	if len(batteries) != 1 {
+		t.Fatalf("expected 1 battery, got %+v", batteries)
+	}
+	expected := BatteryInfo{Name: "BAT0", ChargePercent: 81, Status: "charging", CycleCount: 318, HealthPercent: 71.9}
+	if batteries[0] != expected {
+		t.Errorf("battery = %+v, want %+v", batteries[0], expected)
+	}
+}
@@ -0,0 +1,80 @@
+//go:build windows
+
+package internal
+
+import (
+	"github.com/StackExchange/wmi"
+)
+
+// win32BatteryStatus maps Win32_Battery.BatteryStatus codes
+var win32BatteryStatus = map[uint16]string{
+	1: "discharging",
+	2: "not charging",
+	3: "full",
+	4: "low",
+	5: "critical",
+	6: "charging",
+	7: "charging",
+	8: "charging",
+	9: "charging",
+}
+
+// getBatteries reads batteries from Win32_Battery, adding cycle count and
+// health from the root\WMI battery classes (listed in the same order)
+func getBatteries() []BatteryInfo {
+	type win32Battery struct {
+		DeviceID                 *string
+		Name                     *string
+		EstimatedChargeRemaining *uint16
+		BatteryStatus            *uint16
+	}
+	Log.Debug("Querying Win32_Battery via WMI")
+	var dst []win32Battery
+	if err := wmiQuery(wmi.CreateQuery(&dst, "", "Win32_Battery"), &dst, ""); err != nil {
+		Log.Debugf("Error to query Win32_Battery: %v", err)
+		return nil
+	}
+
+	type staticData struct{ DesignedCapacity *uint32 }
+	type fullCapacity struct{ FullChargedCapacity *uint32 }
+	type cycleCount struct{ CycleCount *uint32 }
+	var designs []staticData
+	var fulls []fullCapacity
+	var cycles []cycleCount
+	// root\WMI classes are optional: many drivers do not implement them
+	if err := wmiQuery(wmi.CreateQuery(&designs, "", "BatteryStaticData"), &designs, `root\WMI`); err != nil {
+		Log.Debugf("Error to query BatteryStaticData: %v", err)
+	}
+	if err := wmiQuery(wmi.CreateQuery(&fulls, "", "BatteryFullChargedCapacity"), &fulls, `root\WMI`); err != nil {
+		Log.Debugf("Error to query BatteryFullChargedCapacity: %v", err)
+	}
+	if err := wmiQuery(wmi.CreateQuery(&cycles, "", "BatteryCycleCount"), &cycles, `root\WMI`); err != nil {
+		Log.Debugf("Error to query BatteryCycleCount: %v", err)
+	}
+
+	var batteries []BatteryInfo
+	for i, b := range dst {
+		battery := BatteryInfo{}
+		switch {
+		case b.Name != nil:
+			battery.Name = *b.Name
+		case b.DeviceID != nil:
+			battery.Name = *b.DeviceID
+		}
+		if b.EstimatedChargeRemaining != nil {
+			battery.ChargePercent = float64(*b.EstimatedChargeRemaining)
+		}
+		if b.BatteryStatus != nil {
+			battery.Status = win32BatteryStatus[*b.BatteryStatus]
+		}
+		if i < len(cycles) && cycles[i].CycleCount != nil {
+			battery.CycleCount = int(*cycles[i].CycleCount)
+		}
+		if i < len(designs) && i < len(fulls) && designs[i].DesignedCapacity != nil && fulls[i].FullChargedCapacity != nil {
+			battery.HealthPercent = batteryHealth(float64(*fulls[i].FullChargedCapacity), float64(*designs[i].DesignedCapacity))
+		}
+		Log.Debugf("Battery detected: %+v", battery)
+		batteries = append(batteries, battery)
+	}
+	return batteries
+}
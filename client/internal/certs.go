@@ -0,0 +1,165 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultCertExpiryDays is how far ahead certificate expiry is reported
+const defaultCertExpiryDays = 30
+
+// ExpiringCert is a machine certificate expired or expiring within
+// TATUSCAN_CERT_EXPIRY_DAYS
+type ExpiringCert struct {
+	Subject    string `json:"subject"`
+	Issuer     string `json:"issuer"`
+	NotAfter   string `json:"not_after"`  // RFC3339
+	DaysLeft   int    `json:"days_left"`  // negative when expired
+	Thumbprint string `json:"thumbprint"` // SHA-1, as shown by Windows
+	Store      string `json:"store"`      // store name or file path
+}
+
+// parseCertificates parses the certificates of PEM data, or of a single DER
+// certificate when data holds no PEM block
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	if len(certs) == 0 {
+		if cert, err := x509.ParseCertificate(data); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// certExpiry returns the certificate when it expires within days of now,
+// nil otherwise. CA certificates are skipped: trust anchors are renewed by
+// OS updates, not by the machine owner.
+func certExpiry(cert *x509.Certificate, store string, now time.Time, days float64) *ExpiringCert {
+	if cert.IsCA {
+		return nil
+	}
+	left := cert.NotAfter.Sub(now).Hours() / 24
+	if left > days {
+		return nil
+	}
+	sum := sha1.Sum(cert.Raw)
+	return &ExpiringCert{
+		Subject:    cert.Subject.String(),
+		Issuer:     cert.Issuer.String(),
+		NotAfter:   cert.NotAfter.UTC().Format(time.RFC3339),
+		DaysLeft:   int(math.Floor(left)),
+		Thumbprint: hex.EncodeToString(sum[:]),
+		Store:      store,
+	}
+}
+
+// sortExpiringCerts orders certificates by expiry, dropping the same
+// certificate found in several stores
+func sortExpiringCerts(certs []ExpiringCert) []ExpiringCert {
+	sort.SliceStable(certs, func(i, j int) bool {
+		if certs[i].NotAfter != certs[j].NotAfter {
+			return certs[i].NotAfter < certs[j].NotAfter
+		}
+		return certs[i].Thumbprint < certs[j].Thumbprint
+	})
+	seen := make(map[string]bool)
+	out := certs[:0]
+	for _, c := range certs {
+		if !seen[c.Thumbprint] {
+			seen[c.Thumbprint] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// getExpiringCerts returns the machine certificates expiring within
+// TATUSCAN_CERT_EXPIRY_DAYS, from the platform stores and TATUSCAN_CERT_PATHS
+func getExpiringCerts() ([]ExpiringCert, error) {
+	if Cfg.CertExpiryDays <= 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	var expiring []ExpiringCert
+	add := func(store string, certs []*x509.Certificate) {
+		for _, cert := range certs {
+			if e := certExpiry(cert, store, now, Cfg.CertExpiryDays); e != nil {
+				expiring = append(expiring, *e)
+			}
+		}
+	}
+	err := platformCertificates(add)
+	for _, path := range Cfg.CertPaths {
+		scanCertPath(path, add)
+	}
+	return sortExpiringCerts(expiring), err
+}
+
+// maxCertFileSize skips files too large to be certificates or bundles
+const maxCertFileSize = 1 << 20
+
+// certExtensions are the file extensions scanned in certificate directories
+var certExtensions = []string{".pem", ".crt", ".cer", ".der"}
+
+// scanCertPath reads the certificates of a file, or of the certificate
+// files under a directory, passing them to add with their path as store
+func scanCertPath(path string, add func(store string, certs []*x509.Certificate)) {
+	info, err := os.Stat(path)
+	if err != nil {
+		Log.Debugf("Certificate path %s not readable: %v", path, err)
+		return
+	}
+	if !info.IsDir() {
+		if data, err := os.ReadFile(path); err == nil {
+			add(path, parseCertificates(data))
+		}
+		return
+	}
+	filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !hasAnySuffix(strings.ToLower(file), certExtensions) {
+			return nil
+		}
+		// entries are often symlinks into a shared archive or bundle
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() || info.Size() > maxCertFileSize {
+			return nil
+		}
+		if data, err := os.ReadFile(file); err == nil {
+			add(file, parseCertificates(data))
+		}
+		return nil
+	})
+}
+
+// hasAnySuffix reports whether s ends with one of suffixes
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+//go:build darwin
+
+package internal
+
+import (
+	"crypto/x509"
+	"fmt"
+)
+
+// systemKeychain holds the machine certificates of macOS
+const systemKeychain = "/Library/Keychains/System.keychain"
+
+// platformCertificates exports the certificates of the System keychain
+func platformCertificates(add func(store string, certs []*x509.Certificate)) error {
+	output, err := runCommand("security", "find-certificate", "-a", "-p", systemKeychain)
+	if err != nil {
+		return fmt.Errorf("security find-certificate: %w", err)
+	}
+	add(systemKeychain, parseCertificates(output))
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package internal
+
+import (
+	"crypto/x509"
+	"path/filepath"
+)
+
+// linuxCertDirs are the certificate directories of Debian, Red Hat and
+// certbot, under /etc
+var linuxCertDirs = []string{"ssl/certs", "pki/tls/certs", "letsencrypt/live"}
+
+// platformCertificates scans the system certificate directories
+func platformCertificates(add func(store string, certs []*x509.Certificate)) error {
+	for _, dir := range linuxCertDirs {
+		scanCertPath(filepath.Join(etcRoot, dir), add)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package internal
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetExpiringCertsScansPaths(t *testing.T) {
+	setupTestAgent(t)
+	dir := t.TempDir()
+	der := testCert(t, "wifi", time.Now().AddDate(0, 0, 5), false)
+	certFile := filepath.Join(dir, "wifi.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the same certificate through a symlink is reported once
+	if err := os.Symlink(certFile, filepath.Join(dir, "link.crt")); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), der, 0o644)
+
+	origEtc := etcRoot
+	etcRoot = t.TempDir()
+	t.Cleanup(func() { etcRoot = origEtc })
+	SetConfig(Config{CertExpiryDays: 30, CertPaths: []string{dir}})
+
+	certs, err := getExpiringCerts()
+	if err != nil {
+		t.Fatalf("getExpiringCerts: %v", err)
+	}
+	if len(certs) != 1 || certs[0].Subject != "CN=wifi" {
+		t.Errorf("unexpected certificates: %+v", certs)
+	}
+
+	SetConfig(Config{CertExpiryDays: 0, CertPaths: []string{dir}})
+	if certs, _ := getExpiringCerts(); certs != nil {
+		t.Errorf("expected no scan when disabled, got %+v", certs)
+	}
+}
@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testCert returns a self-signed DER certificate valid until notAfter
+func testCert(t *testing.T, cn string, notAfter time.Time, isCA bool) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notAfter.AddDate(-1, 0, 0),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func TestParseCertificates(t *testing.T) {
+	der := testCert(t, "host.example", time.Now().AddDate(0, 0, 10), false)
+	pemData := append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("x")}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	if certs := parseCertificates(pemData); len(certs) != 1 || certs[0].Subject.CommonName != "host.example" {
+		t.Errorf("PEM: unexpected certificates %v", certs)
+	}
+	if certs := parseCertificates(der); len(certs) != 1 {
+		t.Errorf("DER: unexpected certificates %v", certs)
+	}
+	if certs := parseCertificates([]byte("not a certificate")); certs != nil {
+		t.Errorf("garbage: unexpected certificates %v", certs)
+	}
+}
+
+func TestCertExpiry(t *testing.T) {
+	now := time.Now()
+	parse := func(der []byte) *x509.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+
+	soon := certExpiry(parse(testCert(t, "rdp", now.Add(10*24*time.Hour+time.Hour), false)), "MY", now, 30)
+	if soon == nil || soon.DaysLeft != 10 || soon.Subject != "CN=rdp" || len(soon.Thumbprint) != 40 {
+		t.Errorf("expiring certificate: got %+v", soon)
+	}
+	expired := certExpiry(parse(testCert(t, "old", now.Add(-36*time.Hour), false)), "MY", now, 30)
+	if expired == nil || expired.DaysLeft != -2 {
+		t.Errorf("expired certificate: got %+v", expired)
+	}
+	if c := certExpiry(parse(testCert(t, "later", now.AddDate(1, 0, 0), false)), "MY", now, 30); c != nil {
+		t.Errorf("certificate beyond the window reported: %+v", c)
+	}
+	if c := certExpiry(parse(testCert(t, "root", now.AddDate(0, 0, 1), true)), "MY", now, 30); c != nil {
+		t.Errorf("CA certificate reported: %+v", c)
+	}
+}
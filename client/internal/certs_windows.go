@@ -0,0 +1,61 @@
+//go:build windows
+
+package internal
+
+import (
+	"crypto/x509"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// windowsCertStores are the LocalMachine stores holding machine
+// certificates: Personal (802.1X, IIS, ...) and the RDP listener certificate
+var windowsCertStores = []string{"MY", "Remote Desktop"}
+
+// readSystemCertStore returns the certificates of a LocalMachine store
+func readSystemCertStore(name string) ([]*x509.Certificate, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	flags := uint32(windows.CERT_SYSTEM_STORE_LOCAL_MACHINE | windows.CERT_STORE_READONLY_FLAG | windows.CERT_STORE_OPEN_EXISTING_FLAG)
+	store, err := windows.CertOpenStore(windows.CERT_STORE_PROV_SYSTEM_W, 0, 0, flags, uintptr(unsafe.Pointer(namePtr)))
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CertCloseStore(store, 0)
+
+	var certs []*x509.Certificate
+	var ctx *windows.CertContext
+	for {
+		// fails with CRYPT_E_NOT_FOUND past the last certificate
+		ctx, err = windows.CertEnumCertificatesInStore(store, ctx)
+		if err != nil {
+			break
+		}
+		der := unsafe.Slice(ctx.EncodedCert, ctx.Length)
+		if cert, err := x509.ParseCertificate(append([]byte(nil), der...)); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs, nil
+}
+
+// platformCertificates reads the LocalMachine certificate stores; a store
+// missing on this machine (no RDP certificate yet) is skipped
+func platformCertificates(add func(store string, certs []*x509.Certificate)) error {
+	for _, name := range windowsCertStores {
+		certs, err := readSystemCertStore(name)
+		if err != nil {
+			if name == windowsCertStores[0] {
+				return fmt.Errorf("open LocalMachine\\%s store: %w", name, err)
+			}
+			Log.Debugf("Certificate store LocalMachine\\%s not available: %v", name, err)
+			continue
+		}
+		add(`LocalMachine\`+name, certs)
+	}
+	return nil
+}
@@ -0,0 +1,609 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultCollectTimeout bounds a collection cycle, and
+// defaultCollectorTimeout each collector in it; the OS tools run by
+// collectors have their own 30s limit
+const (
+	defaultCollectTimeout   = 2 * time.Minute
+	defaultCollectorTimeout = time.Minute
+)
+
+// collector is a step of the collection pipeline filling part of MachineInfo.
+// OS differences live in small platform providers (platformOSVersion,
+// isVirtualPlatformInterface, platformMACs) implemented per OS.
+type collector struct {
+	name     string
+	required bool     // a failing required collector aborts the collection
+	personal bool     // reports user-identifying data, skipped in strict privacy
+	needs    []string // collectors whose output it reads, run before it
+	collect  func(ctx context.Context, info *MachineInfo) error
+}
+
+// collectors lists the pipeline steps; the ones whose needs are met run
+// concurrently, in waves
+var collectors = []collector{
+	{name: "host", collect: collectHost},
+	{name: "smbios", collect: collectSMBIOS},
+	{name: "firmware", collect: collectFirmware},
+	{name: "virtualization", needs: []string{"smbios"}, collect: collectVirtualization},
+	{name: "container", collect: collectContainer},
+	{name: "image", collect: collectImage},
+	{name: "tags", collect: collectTags},
+	{name: "warranty", personal: true, needs: []string{"smbios"}, collect: collectWarranty},
+	{name: "network", required: true, collect: collectNetwork},
+	{name: "agent_id", collect: collectAgentID},
+	{name: "interfaces", collect: collectInterfaces},
+	{name: "public_ip", personal: true, collect: collectPublicIP},
+	{name: "wifi", personal: true, needs: []string{"network"}, collect: collectWiFi},
+	{name: "addressing", needs: []string{"network"}, collect: collectAddressing},
+	{name: "metrics", collect: collectMetrics},
+	{name: "cpu", collect: collectCPU},
+	{name: "watchlist", personal: true, collect: collectWatchlist},
+	{name: "services", collect: collectServices},
+	{name: "endpoint_security", collect: collectEndpointSecurity},
+	{name: "listeners", personal: true, collect: collectListeners},
+	{name: "neighbors", personal: true, needs: []string{"network"}, collect: collectNeighbors},
+	{name: "osquery", personal: true, collect: collectOsquery},
+	{name: "custom", personal: true, collect: collectCustom},
+	{name: "updates", collect: collectUpdates},
+	{name: "packages", personal: true, collect: collectPackages},
+	{name: "hotfixes", collect: collectHotfixes},
+	{name: "windows_update", collect: collectWindowsUpdate},
+	{name: "certificates", personal: true, collect: collectCertificates},
+	{name: "startup", personal: true, collect: collectStartup},
+	{name: "sensors", collect: collectSensors},
+	{name: "batteries", collect: collectBatteries},
+	{name: "disks", collect: collectDisks},
+	{name: "storage", collect: collectStorage},
+	{name: "pci", collect: collectPCI},
+	{name: "health", needs: []string{"metrics", "sensors", "disks"}, collect: collectHealth},
+}
+
+// CollectData collects machine information running the pipeline collectors,
+// independent ones concurrently, each within TATUSCAN_COLLECTOR_TIMEOUT and
+// the whole cycle within TATUSCAN_COLLECT_TIMEOUT. A collector past its
+// deadline is abandoned, so a stuck WMI query or sysfs read cannot stall
+// the agent; the report keeps what was collected, unless a required
+// collector did not complete.
+func CollectData(ctx context.Context) (MachineInfo, error) {
+	return collectData(ctx, nil)
+}
+
+// collectData runs the enabled collectors, only the ones named in only when
+// it is not empty
+func collectData(ctx context.Context, only []string) (MachineInfo, error) {
+	Log.Info("Starting data collection")
+	if Cfg.CollectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Cfg.CollectTimeout)
+		defer cancel()
+	}
+	logContext.cycle.Add(1)
+	started := time.Now()
+	info := MachineInfo{SchemaVersion: SchemaVersion, Timestamp: started.Format(time.RFC3339)}
+	takeWarnings() // discard leftovers from an aborted collection
+	checkClockSkew(ctx)
+	checkCycleOverrun(ctx)
+
+	var pending []collector
+	for _, c := range collectors {
+		if !collectorEnabled(c) {
+			Log.Debugf("Collector %s disabled by configuration or privacy preset", c.name)
+			continue
+		}
+		if only != nil && !containsString(only, c.name) {
+			continue
+		}
+		pending = append(pending, c)
+	}
+	info.Collectors = collectorNames(pending)
+	for len(pending) > 0 {
+		if ctx.Err() != nil {
+			Log.Warnf("Collection interrupted after %s: %v; skipping collectors %s",
+				time.Since(started).Round(time.Second), ctx.Err(), strings.Join(collectorNames(pending), ", "))
+			for _, c := range pending {
+				if c.required {
+					err := fmt.Errorf("collection interrupted before collector %s: %w", c.name, ctx.Err())
+					info.Warnings = sortWarnings(takeWarnings())
+					return info, err
+				}
+			}
+			break
+		}
+		var wave []collector
+		wave, pending = nextWave(pending)
+		if err := runWave(ctx, wave, &info, started); err != nil {
+			info.Warnings = sortWarnings(takeWarnings())
+			return info, err
+		}
+	}
+	info.Agent = agentStats()
+	build := agentBuild
+	info.Build = &build
+	info.TaskResults = pendingTaskResults()
+	logContext.collector.Store("privacy")
+	applyPrivacy(withCollector(ctx, "privacy"), &info)
+	logContext.collector.Store("")
+	info.Warnings = sortWarnings(takeWarnings())
+
+	Log.Debugf("Data collected: %+v", info)
+	return info, nil
+}
+
+// parseDisabledCollectors parses TATUSCAN_DISABLE_COLLECTORS, a
+// comma-separated list of collector names; required collectors cannot be
+// turned off
+func parseDisabledCollectors(value string) []string {
+	var disabled []string
+	for _, name := range splitList(strings.ToLower(value)) {
+		i := slices.IndexFunc(collectors, func(c collector) bool { return c.name == name })
+		if i < 0 || collectors[i].required {
+			if Log != nil {
+				Log.Warnf("Collector %q cannot be disabled, ignored", name)
+			}
+			continue
+		}
+		disabled = append(disabled, name)
+	}
+	return disabled
+}
+
+// collectHost fills hostname, OS and OS version
+func collectHost(ctx context.Context, info *MachineInfo) error {
+	Log.Debug("Collecting basic host information")
+	info.OS = runtime.GOOS
+	var err error
+	info.Hostname, err = os.Hostname()
+	if err != nil {
+		Log.Warnf("Error to collect hostname: %v", err)
+		info.Hostname = "Unknown"
+	}
+	Log.Debugf("OS detected: %s, Hostname: %s", info.OS, info.Hostname)
+	if err == nil {
+		info.FQDN, info.DNSSuffixes = platformFQDN(ctx, info.Hostname)
+		Log.Debugf("FQDN detected: %s", info.FQDN)
+	}
+
+	info.OSVersion = platformOSVersion()
+	Log.Debugf("OSVersion detected: %s", info.OSVersion)
+	return nil
+}
+
+// collectSMBIOS fills the SMBIOS identity (serial, manufacturer, model, UUID)
+func collectSMBIOS(ctx context.Context, info *MachineInfo) error {
+	smbios := getSMBIOSInfo(ctx)
+	info.SerialNumber = smbios.SerialNumber
+	info.Manufacturer = smbios.Manufacturer
+	info.Model = smbios.Model
+	info.HardwareRev = smbios.HardwareRevision
+	info.ProductUUID = smbios.ProductUUID
+	return nil
+}
+
+// collectFirmware fills the mainboard and BIOS/UEFI identity
+func collectFirmware(ctx context.Context, info *MachineInfo) error {
+	firmware := getFirmwareInfo(ctx)
+	if firmware != (FirmwareInfo{}) {
+		info.Firmware = &firmware
+	}
+	return nil
+}
+
+// collectVirtualization fills is_virtual and hypervisor
+func collectVirtualization(_ context.Context, info *MachineInfo) error {
+	info.IsVirtual, info.Hypervisor = getVirtualization(info.Manufacturer, info.Model)
+	return nil
+}
+
+// collectContainer fills the container runtime the agent runs in
+func collectContainer(ctx context.Context, info *MachineInfo) error {
+	info.Container = getContainer(ctx)
+	return nil
+}
+
+// collectImage fills the deployment image markers (optional)
+func collectImage(_ context.Context, info *MachineInfo) error {
+	info.Image = getImageInfo()
+	return nil
+}
+
+// collectTags fills the administrator-defined tags and labels (optional)
+func collectTags(_ context.Context, info *MachineInfo) error {
+	info.Tags = Cfg.Tags
+	info.Labels = Cfg.Labels
+	return nil
+}
+
+// collectWarranty fills warranty/purchase data for the serial number (optional)
+func collectWarranty(_ context.Context, info *MachineInfo) error {
+	info.Warranty = getWarrantyInfo(info.SerialNumber)
+	return nil
+}
+
+// collectInterfaces fills per-interface details
+func collectInterfaces(_ context.Context, info *MachineInfo) error {
+	info.Interfaces = getInterfaceDetails()
+	return nil
+}
+
+// collectPublicIP fills the egress address seen from the internet (optional)
+func collectPublicIP(ctx context.Context, info *MachineInfo) error {
+	ip, err := getPublicIP(ctx)
+	info.PublicIP = ip
+	return err
+}
+
+// collectWiFi fills the Wi-Fi link when the primary interface is wireless
+func collectWiFi(_ context.Context, info *MachineInfo) error {
+	name := primaryInterface(info)
+	if name == "" || !isWirelessInterface(name) {
+		return nil
+	}
+	wifi, err := getWiFiInfo(name)
+	info.WiFi = wifi
+	return err
+}
+
+// collectAddressing fills whether the primary address came from DHCP
+func collectAddressing(ctx context.Context, info *MachineInfo) error {
+	name := primaryInterface(info)
+	if name == "" {
+		return nil
+	}
+	info.Addressing = getAddressing(ctx, name, info.IP)
+	return nil
+}
+
+// collectMetrics fills CPU and memory usage
+func collectMetrics(_ context.Context, info *MachineInfo) error {
+	commonInfo := collectCommonMetrics()
+	info.CPUPercent = commonInfo.CPUPercent
+	info.MemoryTotalMB = commonInfo.MemoryTotalMB
+	info.MemoryUsedMB = commonInfo.MemoryUsedMB
+	return nil
+}
+
+// collectCPU fills the processor model, core counts and frequency
+func collectCPU(ctx context.Context, info *MachineInfo) error {
+	info.CPU = getCPUInfo(ctx)
+	return nil
+}
+
+// collectWatchlist fills watchlisted processes and their connections (optional)
+func collectWatchlist(_ context.Context, info *MachineInfo) error {
+	info.Watchlist = getWatchlist()
+	return nil
+}
+
+// collectServices fills the configured and failed services (optional)
+func collectServices(_ context.Context, info *MachineInfo) error {
+	info.Services = getServices()
+	return nil
+}
+
+// collectEndpointSecurity fills the Defender and EDR agent health (optional)
+func collectEndpointSecurity(_ context.Context, info *MachineInfo) error {
+	info.Endpoint = getEndpointSecurity()
+	return nil
+}
+
+// collectListeners fills the ports open to the network (optional)
+func collectListeners(_ context.Context, info *MachineInfo) error {
+	info.Listeners = getListeningPorts()
+	return nil
+}
+
+// collectNeighbors fills the devices seen on the primary subnet (optional)
+func collectNeighbors(_ context.Context, info *MachineInfo) error {
+	neighbors, err := getNeighbors(info.IP)
+	info.Neighbors = neighbors
+	return err
+}
+
+// collectOsquery fills the results of the configured osquery queries
+// (optional)
+func collectOsquery(ctx context.Context, info *MachineInfo) error {
+	results, err := getOsquery(ctx)
+	info.Osquery = results
+	return err
+}
+
+// collectCustom fills the objects printed by the custom scripts (optional)
+func collectCustom(ctx context.Context, info *MachineInfo) error {
+	results, err := getCustom(ctx)
+	info.Custom = results
+	return err
+}
+
+// collectUpdates fills the pending OS updates (optional)
+func collectUpdates(_ context.Context, info *MachineInfo) error {
+	updates, err := getUpdates()
+	info.Updates = updates
+	return err
+}
+
+// collectSensors fills hardware temperatures and fan speeds (optional)
+func collectSensors(_ context.Context, info *MachineInfo) error {
+	info.Sensors = getSensorInfo()
+	return nil
+}
+
+// collectBatteries fills the laptop batteries (absent on desktops)
+func collectBatteries(_ context.Context, info *MachineInfo) error {
+	info.Batteries = getBatteries()
+	return nil
+}
+
+// collectStorage fills the physical disks
+func collectStorage(ctx context.Context, info *MachineInfo) error {
+	info.Storage = getStorageDevices(ctx)
+	return nil
+}
+
+// collectPackages fills the installed packages (optional)
+func collectPackages(_ context.Context, info *MachineInfo) error {
+	packages, err := getPackages()
+	info.Packages = packages
+	return err
+}
+
+// collectHotfixes fills the installed Windows updates
+func collectHotfixes(ctx context.Context, info *MachineInfo) error {
+	info.Hotfixes = getHotfixes(ctx)
+	return nil
+}
+
+// collectWindowsUpdate fills the Windows Update client settings
+func collectWindowsUpdate(ctx context.Context, info *MachineInfo) error {
+	info.WindowsUpdate = getWindowsUpdateSettings(ctx)
+	return nil
+}
+
+// collectCertificates fills the machine certificates about to expire
+func collectCertificates(ctx context.Context, info *MachineInfo) error {
+	certs, err := getExpiringCerts()
+	info.Certificates = certs
+	if err != nil {
+		// the files and other stores were still scanned
+		Log.Warnf("Error to read certificate store: %v", err)
+		addWarning(ctx, WarnCollectorFailed, "certificates: %v", err)
+	}
+	return nil
+}
+
+// collectStartup fills the programs started at boot or logon
+func collectStartup(_ context.Context, info *MachineInfo) error {
+	info.Startup = getStartupItems()
+	return nil
+}
+
+// collectPCI fills the PCI and PCIe devices
+func collectPCI(ctx context.Context, info *MachineInfo) error {
+	info.PCI = getPCIDevices(ctx)
+	return nil
+}
+
+// collectDisks fills filesystem usage and fill forecasts
+func collectDisks(_ context.Context, info *MachineInfo) error {
+	disks, err := getDisks()
+	info.Disks = disks
+	return err
+}
+
+// collectHealth evaluates the thresholds over the readings collected so far
+func collectHealth(_ context.Context, info *MachineInfo) error {
+	info.Health = evaluateHealth(info)
+	return nil
+}
+
+// interfaceScan is the result of filtering the host interfaces
+type interfaceScan struct {
+	MACs       []string
+	Candidates []ipCandidate
+	Addresses  []InterfaceAddress
+	Links      []LinkTopology
+}
+
+// scanInterfaces keeps physical interfaces (named, with a globally
+// administered MAC, UP, non-loopback, non-virtual and with an IPv4 address).
+// A bond, bridge or VLAN with an IPv4 address is kept too, identified by the
+// MACs of the physical interfaces under it.
+func scanInterfaces(ctx context.Context, interfaces []NetInterface) interfaceScan {
+	var scan interfaceScan
+	locallyAdministered := 0
+	for _, iface := range interfaces {
+		name, hw, flags := iface.Name(), iface.HardwareAddr(), iface.Flags()
+		if name == "" {
+			Log.Debugf("Interface without name, ignored")
+			continue
+		}
+
+		// Basic flags
+		if hw.String() == "" {
+			Log.Debugf("Interface %s ignored: empty MAC", name)
+			continue
+		}
+		if (flags & net.FlagLoopback) != 0 {
+			Log.Debugf("Interface %s ignored: loopback", name)
+			continue
+		}
+		if (flags & net.FlagUp) == 0 {
+			Log.Debugf("Interface %s ignored: interface DOWN", name)
+			continue
+		}
+
+		// Virtual by name (and platform signals such as sysfs), unless it
+		// aggregates physical interfaces
+		var link *LinkTopology
+		var members []linkMember
+		if isVirtualPlatformInterface(name) {
+			var kind string
+			kind, members = platformLinkMembers(name)
+			if len(members) == 0 {
+				Log.Debugf("Interface %s ignored: virtual", name)
+				continue
+			}
+			link = &LinkTopology{Interface: name, Kind: kind}
+			for _, m := range members {
+				link.Members = append(link.Members, m.Name)
+			}
+		}
+
+		// Locally administered MAC - typical of virtuals/containers; a bond
+		// or bridge may have one, its members identify the machine
+		if link == nil && isLocallyAdministeredMAC(hw) {
+			Log.Debugf("Interface %s ignored: locally administered MAC (%s)", name, hw)
+			locallyAdministered++
+			continue
+		}
+
+		// Valid IP (IPv4 non-loopback)
+		addrs, err := iface.Addrs()
+		if err != nil {
+			Log.Errorf("Error to collect addresses from interface %s: %v", name, err)
+			continue
+		}
+		hasValidIP := false
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				hasValidIP = true
+				scan.Candidates = append(scan.Candidates, ipCandidate{
+					Interface: name,
+					IP:        ipnet.IP,
+					Wireless:  isWirelessInterface(name),
+				})
+				break
+			}
+		}
+		if !hasValidIP {
+			Log.Debugf("Interface %s ignored: no valid IPv4", name)
+			continue
+		}
+		scan.Addresses = append(scan.Addresses, interfaceAddresses(name, addrs)...)
+
+		if link != nil {
+			scan.Links = append(scan.Links, *link)
+			for _, m := range members {
+				mac, err := net.ParseMAC(m.MAC)
+				if err != nil || isLocallyAdministeredMAC(mac) || containsString(scan.MACs, mac.String()) {
+					continue
+				}
+				scan.MACs = append(scan.MACs, mac.String())
+				Log.Debugf("Physical MAC included: %s (interface %s under %s %s)", mac, m.Name, link.Kind, name)
+			}
+			continue
+		}
+
+		// MAC collected
+		mac := hw.String()
+		if containsString(scan.MACs, mac) {
+			continue
+		}
+		scan.MACs = append(scan.MACs, mac)
+		Log.Debugf("Physical MAC included: %s (interface %s)", mac, name)
+	}
+	if len(scan.MACs) == 0 && locallyAdministered > 0 {
+		addWarning(ctx, WarnOnlyLocallyAdministeredMACs, "%d interface(s) ignored for having a locally administered MAC and no physical MAC found", locallyAdministered)
+	}
+	return scan
+}
+
+// computeMachineID hashes the sorted physical MACs with SHA-256, keyed
+// (HMAC-SHA256) by TATUSCAN_MACHINE_ID_SALT or the pseudonym key, see
+// machineIDKey, so that the IDs cannot be matched against known MAC lists
+// and differ between organizations
+func computeMachineID(macs []string) string {
+	sorted := append([]string(nil), macs...)
+	sort.Strings(sorted) // Sort for consistency
+	idInput := strings.Join(sorted, "|")
+	Log.Debugf("MACs used for MachineID: %s", idInput)
+	if key, _ := machineIDKey(); key != nil {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(idInput))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	hash := sha256.Sum256([]byte(idInput))
+	return hex.EncodeToString(hash[:])
+}
+
+// normalizeMACs returns the MACs in lowercase colon form, sorted and without
+// duplicates, for matching against switch and NAC data
+func normalizeMACs(macs []string) []string {
+	var normalized []string
+	for _, mac := range macs {
+		if hw, err := net.ParseMAC(mac); err == nil {
+			mac = hw.String()
+		} else {
+			mac = strings.ToLower(mac)
+		}
+		if !containsString(normalized, mac) {
+			normalized = append(normalized, mac)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// collectNetwork fills the primary IP, addresses, MachineID and cohort
+func collectNetwork(ctx context.Context, info *MachineInfo) error {
+	Log.Debug("Collecting MAC and IP addresses")
+	interfaces, err := interfaceLister()
+	if err != nil {
+		Log.Errorf("Error to collect network interfaces: %v", err)
+		return fmt.Errorf("failed to collect network interfaces: %v", err)
+	}
+	scan := scanInterfaces(ctx, interfaces)
+
+	if primary, ok := selectPrimaryIP(scan.Candidates); ok {
+		info.IP = primary.IP.String()
+		Log.Debugf("Selected interface %s with IP %s", primary.Interface, info.IP)
+	}
+	if info.IP == "" {
+		Log.Warnf("No valid IPv4 address found")
+		addWarning(ctx, WarnNoIPv4, "no physical interface has an IPv4 address")
+	}
+	info.Addresses = scan.Addresses
+	info.Links = scan.Links
+
+	macAddresses, err := platformMACs(ctx, scan.MACs)
+	if err != nil {
+		return err
+	}
+	if len(macAddresses) == 0 {
+		Log.Errorf("No physical MAC address found; failed to generate MachineID")
+		return fmt.Errorf("no physical MAC address available")
+	}
+
+	// Machine ID generation: Use all physical MAC addresses
+	Log.Debug("Generating MachineID based on physical MACs")
+	if _, err := machineIDKey(); err != nil {
+		// The plain hash could be recomputed from the MACs
+		addWarning(ctx, WarnPseudonymKeyUnavailable, "MachineID not generated: %v", err)
+		return fmt.Errorf("pseudonym key unavailable for the MachineID: %w", err)
+	}
+	computedID := computeMachineID(macAddresses)
+	Log.Debugf("MachineID generated: %s", computedID)
+	if Cfg.MACAddresses {
+		info.MACAddresses = normalizeMACs(macAddresses)
+	}
+	info.MachineID = resolveMachineID(ctx, computedID, info)
+	info.Cohort = machineCohort(info.MachineID)
+	return nil
+}
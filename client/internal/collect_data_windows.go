@@ -3,136 +3,41 @@
 package internal
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
+	"errors"
 	"fmt"
 	"net"
-	"os"
-	"runtime"
-	"sort"
 	"strings"
-	"time"
 
 	"github.com/StackExchange/wmi"
 )
 
-// virtualInterfacePatterns lists prefixes/suffixes of virtual network interfaces
-var virtualInterfacePatterns = []string{
-	// Linux (kept for function compatibility)
-	"docker", "veth", "br-", "tun", "tap", "vmnet", "macvlan", "ipvlan", "wg", "wireguard", "dummy",
-	// Windows
-	"virtual", "vpn", "hyper-v", "vmware", "virtualbox", "teredo",
+// platformOSVersion returns the Windows release name
+func platformOSVersion() string {
+	return getOSVersionWindows()
 }
 
-// isVirtualInterface checks if an interface is virtual based on its name
-func isVirtualInterface(name string) bool {
-	nameLower := strings.ToLower(name)
-	for _, pattern := range virtualInterfacePatterns {
-		if strings.Contains(nameLower, pattern) {
-			return true
-		}
-	}
-	return false
+// isVirtualPlatformInterface relies on the adapter name patterns
+func isVirtualPlatformInterface(name string) bool {
+	return isVirtualInterface(name)
 }
 
-// isLocallyAdministeredMAC indicates if MAC has "locally administered" bit (typical of virtuals)
-func isLocallyAdministeredMAC(hw net.HardwareAddr) bool {
-	if len(hw) == 0 {
-		return false
-	}
-	return (hw[0] & 0x02) == 0x02
-}
-
-// collectData collects machine information for Windows
-func CollectData() (MachineInfo, error) {
-	Log.Info("Starting data collection")
-	info := MachineInfo{Timestamp: time.Now().Format(time.RFC3339)}
-
-	// Hostname and basic OS
-	Log.Debug("Collecting basic host information")
-	info.OS = runtime.GOOS
-	var err error
-	info.Hostname, err = os.Hostname()
-	if err != nil {
-		Log.Warnf("Error to collect hostname: %v", err)
-		info.Hostname = "Unknown"
-	}
-	Log.Debugf("OS detected: %s, Hostname: %s", info.OS, info.Hostname)
-
-	// OS Version
-	info.OSVersion = getOSVersionWindows()
-	Log.Debugf("OSVersion detected: %s", info.OSVersion)
-
-	// IP Address and MAC Addresses
-	Log.Debug("Collecting MAC and IP addresses")
-	macAddresses, err := collectMACsWindows()
+// platformMACs collects physical MACs via WMI (with its own fallback), since
+// Windows adapters without an IPv4 address still identify the machine
+func platformMACs(ctx context.Context, scanned []string) ([]string, error) {
+	macAddresses, err := collectMACsWindows(ctx)
 	if err != nil {
 		Log.Errorf("Error to collect MACs: %v", err)
-		return info, fmt.Errorf("failed to collect MAC addresses: %v", err)
-	}
-
-	// Collect IP using net.Interfaces() (considering only non-virtual and UP NICs)
-	Log.Debug("Starting IP collection on Windows")
-	var ipAddress string
-	interfaces, err := net.Interfaces()
-	if err != nil {
-		Log.Warnf("Error to collect network interfaces: %v", err)
-	} else {
-		for _, iface := range interfaces {
-			if iface.Name == "" || (iface.Flags&net.FlagLoopback) != 0 || (iface.Flags&net.FlagUp) == 0 || isVirtualInterface(iface.Name) {
-				continue
-			}
-			addrs, err := iface.Addrs()
-			if err != nil {
-				continue
-			}
-			for _, addr := range addrs {
-				if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
-					ipAddress = ipnet.IP.String()
-					Log.Debugf("IP found: %s", ipAddress)
-					break
-				}
-			}
-			if ipAddress != "" {
-				break
-			}
-		}
-	}
-
-	if ipAddress == "" {
-		Log.Warnf("No valid IPv4 address found")
+		return nil, fmt.Errorf("failed to collect MAC addresses: %v", err)
 	}
-	info.IP = ipAddress
-
-	if len(macAddresses) == 0 {
-		Log.Errorf("No physical MAC address found; failed to generate MachineID")
-		return info, fmt.Errorf("no physical MAC address available")
-	}
-
-	// Machine ID generation: Use all physical MAC addresses
-	Log.Debug("Generating MachineID based on physical MACs")
-	sort.Strings(macAddresses) // Sort for consistency
-	idInput := strings.Join(macAddresses, "|")
-	Log.Debugf("MACs used for MachineID: %s", idInput)
-	hash := sha256.Sum256([]byte(idInput))
-	info.MachineID = hex.EncodeToString(hash[:])
-	Log.Debugf("MachineID generated: %s", info.MachineID)
-
-	// Collect common metrics (CPU, Memory)
-	commonInfo := collectCommonMetrics()
-	info.CPUPercent = commonInfo.CPUPercent
-	info.MemoryTotalMB = commonInfo.MemoryTotalMB
-	info.MemoryUsedMB = commonInfo.MemoryUsedMB
-
-	Log.Debugf("Data collected: %+v", info)
-	return info, nil
+	return macAddresses, nil
 }
 
 // collectMACsWindows collects physical MACs on Windows.
 // 1) Try WMI with broad filter (MACAddress != NULL).
 // 2) Filter virtuals / disabled / locally-administered in Go.
 // 3) If WMI fails or returns empty, fallback via net.Interfaces().
-func collectMACsWindows() ([]string, error) {
+func collectMACsWindows(ctx context.Context) ([]string, error) {
 	// --- Attempt 1: WMI (broad query) ---
 	type adapter struct {
 		MACAddress      *string
@@ -144,8 +49,8 @@ func collectMACsWindows() ([]string, error) {
 	Log.Debug("Querying Win32_NetworkAdapter via WMI (broad query)")
 	var result []adapter
 
-	q := wmi.CreateQuery(&result, `WHERE MACAddress IS NOT NULL`)
-	wmiErr := wmi.Query(q, &result)
+	q := wmi.CreateQuery(&result, `WHERE MACAddress IS NOT NULL`, "Win32_NetworkAdapter")
+	wmiErr := wmiQuery(q, &result, "")
 	if wmiErr == nil {
 		macs := make([]string, 0, len(result))
 		for _, r := range result {
@@ -190,8 +95,11 @@ func collectMACsWindows() ([]string, error) {
 			return macs, nil
 		}
 		Log.Warn("WMI returned empty after filters; proceeding to fallback via net.Interfaces()")
+	} else if errors.Is(wmiErr, errWMIDisabled) {
+		Log.Debug("WMI disabled; collecting MACs via net.Interfaces()")
 	} else {
 		Log.Warnf("WMI query failed (%v); proceeding to fallback via net.Interfaces()", wmiErr)
+		addWarning(ctx, WarnWMIUnavailable, "Win32_NetworkAdapter query failed: %v", wmiErr)
 	}
 
 	// --- Fallback: net.Interfaces() ---
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"net"
 	"testing"
 )
@@ -10,12 +11,14 @@ type MockInterface struct {
 	name         string
 	flags        net.Flags
 	hardwareAddr net.HardwareAddr
+	mtu          int
 	addrs        []net.Addr
 }
 
 func (m MockInterface) Name() string                   { return m.name }
 func (m MockInterface) Flags() net.Flags               { return m.flags }
 func (m MockInterface) HardwareAddr() net.HardwareAddr { return m.hardwareAddr }
+func (m MockInterface) MTU() int                       { return m.mtu }
 func (m MockInterface) Addrs() ([]net.Addr, error)     { return m.addrs, nil }
 
 // createMockIPv6Addr creates a mock IPv6 address
@@ -130,6 +133,7 @@ func TestIPv6OnlyScenarios(t *testing.T) {
 		},
 	}
 
+	setupTestAgent(t)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Test individual interface validation logic
@@ -159,13 +163,15 @@ func TestIPv6OnlyScenarios(t *testing.T) {
 				}
 			}
 
-			// Note: We can't easily test the full CollectData() function without mocking
-			// the net.Interfaces() call, but we can test the individual components
+			// Run the real selection logic over the mocked interfaces
+			ifaces := make([]NetInterface, len(tt.interfaces))
+			for i, iface := range tt.interfaces {
+				ifaces[i] = iface
+			}
+			scan := scanInterfaces(context.Background(), ifaces)
 			t.Logf("Scenario: %s", tt.description)
-			if tt.expectError {
-				t.Logf("  Expected to fail with current implementation (IPv4 requirement)")
-			} else {
-				t.Logf("  Expected to succeed")
+			if failed := len(scan.MACs) == 0; failed != tt.expectError {
+				t.Errorf("scan found %d physical MACs; expectError=%v", len(scan.MACs), tt.expectError)
 			}
 		})
 	}
@@ -254,8 +260,7 @@ func TestCurrentIPv4Requirement(t *testing.T) {
 
 		t.Log("CURRENT LIMITATION: The CollectData() function requires IPv4 addresses")
 		t.Log("Lines that enforce this:")
-		t.Log("  Linux: collect_data_linux.go:133 - ipnet.IP.To4() != nil")
-		t.Log("  Windows: collect_data_windows.go:90 - ipnet.IP.To4() != nil")
+		t.Log("  All platforms: collect.go scanInterfaces - ipnet.IP.To4() != nil")
 		t.Log("")
 		t.Log("TODO: Update these lines to also accept valid IPv6 global unicast addresses")
 		t.Log("Suggested fix: Accept addresses where:")
@@ -171,3 +171,11 @@ func BenchmarkMACFiltering(b *testing.B) {
 		}
 	}
 }
+
+func TestNormalizeMACs(t *testing.T) {
+	got := normalizeMACs([]string{"00-E0-4C-12-34-56", "00:1B:21:12:34:56", "00:e0:4c:12:34:56"})
+	want := []string{"00:1b:21:12:34:56", "00:e0:4c:12:34:56"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("normalizeMACs = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// withInterfaces replaces the interface lister for the duration of a test
+func withInterfaces(t *testing.T, ifaces ...MockInterface) {
+	t.Helper()
+	orig := interfaceLister
+	interfaceLister = func() ([]NetInterface, error) {
+		result := make([]NetInterface, len(ifaces))
+		for i, iface := range ifaces {
+			result[i] = iface
+		}
+		return result, nil
+	}
+	t.Cleanup(func() { interfaceLister = orig })
+}
+
+func TestCollectNetworkWithMockedInterfaces(t *testing.T) {
+	setupTestAgent(t)
+	up := net.FlagUp | net.FlagBroadcast | net.FlagMulticast
+	withInterfaces(t,
+		MockInterface{name: "lo", flags: net.FlagUp | net.FlagLoopback, addrs: []net.Addr{createMockIPv4Addr("127.0.0.1")}},
+		MockInterface{name: "docker0", flags: up, hardwareAddr: mustParseMAC("00:1b:21:00:00:09"), addrs: []net.Addr{createMockIPv4Addr("172.17.0.1")}},
+		MockInterface{name: "wlan0", flags: up, hardwareAddr: mustParseMAC("00:e0:4c:12:34:56"), addrs: []net.Addr{createMockIPv4Addr("192.168.0.20")}},
+		MockInterface{name: "eth0", flags: up, hardwareAddr: mustParseMAC("00:1b:21:12:34:56"), addrs: []net.Addr{createMockIPv4Addr("10.0.0.5"), createMockIPv6Addr("2001:db8::5")}},
+		MockInterface{name: "eth1", flags: net.FlagBroadcast, hardwareAddr: mustParseMAC("00:1b:21:12:34:57")},
+	)
+
+	var info MachineInfo
+	if err := collectNetwork(context.Background(), &info); err != nil {
+		t.Fatalf("collectNetwork: %v", err)
+	}
+	if info.IP != "192.168.0.20" {
+		t.Errorf("IP = %s, want first enumerated 192.168.0.20", info.IP)
+	}
+	if len(info.Addresses) != 3 {
+		t.Errorf("addresses = %+v, want 3 entries", info.Addresses)
+	}
+	if want := computeMachineID([]string{"00:1b:21:12:34:56", "00:e0:4c:12:34:56"}); info.MachineID != want {
+		t.Errorf("MachineID = %s, want %s", info.MachineID, want)
+	}
+	if info.MACAddresses != nil {
+		t.Errorf("MAC addresses sent by default: %v", info.MACAddresses)
+	}
+
+	// Wired preference applies to the mocked wlan0 by name
+	Cfg.PreferWired = true
+	Cfg.MACAddresses = true
+	info = MachineInfo{}
+	if err := collectNetwork(context.Background(), &info); err != nil {
+		t.Fatalf("collectNetwork: %v", err)
+	}
+	if info.IP != "10.0.0.5" {
+		t.Errorf("IP with wired preference = %s, want 10.0.0.5", info.IP)
+	}
+	if want := []string{"00:1b:21:12:34:56", "00:e0:4c:12:34:56"}; !reflect.DeepEqual(info.MACAddresses, want) {
+		t.Errorf("MAC addresses = %v, want %v", info.MACAddresses, want)
+	}
+}
+
+func TestCollectNetworkWithoutPhysicalInterfaces(t *testing.T) {
+	setupTestAgent(t)
+	withInterfaces(t,
+		MockInterface{name: "eth0", flags: net.FlagUp, hardwareAddr: mustParseMAC("02:42:ac:11:00:02"), addrs: []net.Addr{createMockIPv4Addr("172.17.0.2")}},
+	)
+	var info MachineInfo
+	if err := collectNetwork(context.Background(), &info); err == nil {
+		t.Error("expected error when only locally administered MACs exist")
+	}
+}
+
+func TestCollectNetworkListerError(t *testing.T) {
+	setupTestAgent(t)
+	orig := interfaceLister
+	interfaceLister = func() ([]NetInterface, error) { return nil, errors.New("boom") }
+	defer func() { interfaceLister = orig }()
+
+	var info MachineInfo
+	if err := collectNetwork(context.Background(), &info); err == nil {
+		t.Error("expected error from failing interface lister")
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds the OS tools run by collectors
+const commandTimeout = 30 * time.Second
+
+// localeVariables are replaced in the environment of OS tools
+var localeVariables = []string{"LANG", "LANGUAGE", "LC_ALL", "LC_MESSAGES", "LC_NUMERIC", "LC_TIME", "LC_CTYPE"}
+
+// commandEnv returns environ with the locale forced to commandLocale, so
+// tool output keeps English keywords and C number/date formats
+func commandEnv(environ []string) []string {
+	env := make([]string, 0, len(environ)+3)
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if !containsString(localeVariables, strings.ToUpper(key)) {
+			env = append(env, kv)
+		}
+	}
+	return append(env, "LC_ALL="+commandLocale, "LANG="+commandLocale, "LANGUAGE=C")
+}
+
+// runCommand runs an OS tool whose output is parsed by a collector. The
+// locale is forced (see commandEnv), output is decoded to UTF-8 from the
+// console code page on Windows, and stderr is included in errors. Output
+// is returned with the error too, for tools using exit codes as results.
+func runCommand(name string, args ...string) ([]byte, error) {
+	return runCommandContext(context.Background(), name, args...)
+}
+
+// runCommandContext is runCommand killing the tool when ctx is done
+func runCommandContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = commandEnv(os.Environ())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	output = decodeCommandOutput(output)
+	if err != nil {
+		if msg := strings.TrimSpace(string(decodeCommandOutput(stderr.Bytes()))); msg != "" {
+			return output, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return output, fmt.Errorf("%s: %w", name, err)
+	}
+	return output, nil
+}
@@ -0,0 +1,6 @@
+//go:build darwin
+
+package internal
+
+// commandLocale is C: macOS has no C.UTF-8 and its tools print UTF-8 anyway
+const commandLocale = "C"
@@ -0,0 +1,7 @@
+//go:build linux
+
+package internal
+
+// commandLocale is C.UTF-8, keeping non-ASCII names (unit descriptions,
+// labels) intact; glibc without it falls back to plain C
+const commandLocale = "C.UTF-8"
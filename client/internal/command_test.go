@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCommandEnvForcesLocale(t *testing.T) {
+	env := commandEnv([]string{"PATH=/usr/bin", "LANG=pt_BR.UTF-8", "LC_NUMERIC=pt_BR.UTF-8", "language=pt_BR", "HOME=/root"})
+	joined := strings.Join(env, "\n")
+	for _, unwanted := range []string{"pt_BR", "language="} {
+		if strings.Contains(joined, unwanted) {
+			t.Errorf("environment still contains %q: %v", unwanted, env)
+		}
+	}
+	for _, wanted := range []string{"PATH=/usr/bin", "HOME=/root", "LC_ALL=" + commandLocale, "LANG=" + commandLocale} {
+		if !strings.Contains(joined, wanted) {
+			t.Errorf("environment lacks %q: %v", wanted, env)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	output, err := runCommand("sh", "-c", `echo "$LC_ALL"`)
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != commandLocale {
+		t.Errorf("LC_ALL = %q, want %q", got, commandLocale)
+	}
+
+	_, err = runCommand("sh", "-c", "echo boom >&2; exit 3")
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected error with stderr, got %v", err)
+	}
+}
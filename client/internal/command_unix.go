@@ -0,0 +1,8 @@
+//go:build linux || darwin
+
+package internal
+
+// decodeCommandOutput returns output as is: Unix tools print UTF-8
+func decodeCommandOutput(output []byte) []byte {
+	return output
+}
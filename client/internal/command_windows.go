@@ -0,0 +1,48 @@
+//go:build windows
+
+package internal
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/sys/windows"
+)
+
+// commandLocale only matters for ported Unix tools: Windows tools follow
+// the system UI language, so collectors should prefer WMI and the registry
+const commandLocale = "C"
+
+var (
+	kernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procGetOEMCP           = kernel32.NewProc("GetOEMCP")
+	procGetConsoleOutputCP = kernel32.NewProc("GetConsoleOutputCP")
+)
+
+// consoleCodePage returns the code page console tools write with: the
+// console output code page when attached to a console (interactive runs),
+// the OEM code page otherwise (services), e.g. 850 on Portuguese installs
+func consoleCodePage() uint32 {
+	if cp, _, _ := procGetConsoleOutputCP.Call(); cp != 0 {
+		return uint32(cp)
+	}
+	cp, _, _ := procGetOEMCP.Call()
+	return uint32(cp)
+}
+
+// decodeCommandOutput converts tool output to UTF-8. Output that is
+// already valid UTF-8 (ASCII, or tools honoring chcp 65001) is kept.
+func decodeCommandOutput(output []byte) []byte {
+	if len(output) == 0 || utf8.Valid(output) {
+		return output
+	}
+	cp := consoleCodePage()
+	n, err := windows.MultiByteToWideChar(cp, 0, &output[0], int32(len(output)), nil, 0)
+	if err != nil || n == 0 {
+		return output
+	}
+	wide := make([]uint16, n)
+	if _, err := windows.MultiByteToWideChar(cp, 0, &output[0], int32(len(output)), &wide[0], n); err != nil {
+		return output
+	}
+	return []byte(windows.UTF16ToString(wide))
+}
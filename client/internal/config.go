@@ -0,0 +1,467 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the agent settings read from TATUSCAN_* variables
+type Config struct {
+	// ServerURL is the payload destination (TATUSCAN_URL)
+	ServerURL string
+	// Token is sent as a bearer token to http(s) destinations
+	Token string
+	// Tags are administrator-defined labels attached to the payload
+	Tags []string
+	// Labels are administrator-defined key/value tags (site=lab3), from
+	// key=value items of TATUSCAN_TAGS and TATUSCAN_TAG_<KEY> variables
+	Labels map[string]string
+	// Interval is the collection interval (zero when not configured)
+	Interval time.Duration
+	// CollectTimeout bounds a collection cycle and CollectorTimeout each
+	// collector in it (zero: no limit)
+	CollectTimeout   time.Duration
+	CollectorTimeout time.Duration
+	// ShutdownTimeout bounds the wait for an in-flight cycle and the flush
+	// of the aggregated cycles when the agent stops
+	ShutdownTimeout time.Duration
+	// Control serves the local control API (unix socket or named pipe) in
+	// daemon and service mode
+	Control bool
+	// Discovery looks the server up with DNS-SD when ServerURL is empty
+	Discovery bool
+	// SendInterval spaces sends out, aggregating the cycles in between
+	// (zero sends every cycle)
+	SendInterval time.Duration
+	// HMACSecret signs payloads sent to http(s) destinations, letting the
+	// server reject reports that do not come from an agent
+	HMACSecret string
+	// CompressThreshold is the payload size in bytes from which the body is
+	// sent gzip-compressed (zero sends it uncompressed)
+	CompressThreshold int
+	// Delta sends only the fields changed since the last accepted report,
+	// with a full report every DeltaFullSync (zero: only when needed)
+	Delta         bool
+	DeltaFullSync time.Duration
+	// CollectorIntervals runs the listed collectors at most once per
+	// interval, reusing their last result in the cycles between
+	CollectorIntervals map[string]time.Duration
+	// Headers are static headers sent with every request to the server,
+	// the site overlay, GLPI and Snipe-IT, by canonical name
+	Headers map[string]string
+	// OfflineSamples bounds the metric samples kept while reports cannot be
+	// delivered, replayed in metric_history on reconnect (zero: none)
+	OfflineSamples int
+	// HeartbeatCycles sends the full inventory every HeartbeatCycles cycles,
+	// or on changes, and a heartbeat in between (zero: every cycle)
+	HeartbeatCycles int
+	// LogOutput is where the agent logs go: LogOutputStdout or
+	// LogOutputSyslog (Linux and macOS)
+	LogOutput string
+	// LogFormat is LogFormatText or LogFormatJSON (overridden by -log-format)
+	LogFormat string
+	// LogFile receives the logs instead of stdout (overridden by -log-file),
+	// rotated at LogFileMaxSizeMB and keeping LogFileMaxBackups files for
+	// at most LogFileMaxAge (a zero size or count disables that limit)
+	LogFile           string
+	LogFileMaxSizeMB  int
+	LogFileMaxAge     time.Duration
+	LogFileMaxBackups int
+	// LogDedup holds back repeated warnings and errors for LogDedupWindow
+	LogDedup       bool
+	LogDedupWindow time.Duration
+	// StateDir is where persistent agent state (identity, spool) is kept
+	StateDir string
+	// ConfigDir holds administrator-provided configuration files
+	ConfigDir string
+	// CacheDir holds data that can be deleted at any time
+	CacheDir string
+	// RemoteConfigURL serves a signed KEY=VALUE overlay merged over the
+	// local settings; RemoteConfigKey is the base64 Ed25519 public key
+	RemoteConfigURL      string
+	RemoteConfigKey      string
+	RemoteConfigInterval time.Duration
+	// ImageMarkerFile is a KEY=VALUE file written by the deployment system
+	ImageMarkerFile string
+	// ImageRegistryKey is a HKLM registry key holding image markers (Windows)
+	ImageRegistryKey string
+	// WarrantyCSV is a CSV file with a "serial" column and purchase data
+	WarrantyCSV string
+	// WarrantyHook is an executable called with the serial number that
+	// prints a JSON object with warranty data
+	WarrantyHook        string
+	WarrantyHookTimeout time.Duration
+	// Watchlist holds process names reported with their network connections
+	Watchlist []string
+	// VirtualPatterns replaces the default virtual interface name patterns
+	VirtualPatterns []string
+	// VirtualPatternsExtra extends the virtual interface name patterns
+	VirtualPatternsExtra []string
+	// MachineIDSalt keys the MachineID hash, per organization or server
+	MachineIDSalt string
+	// MachineIDMigrate replaces the cached MachineID when the computed one
+	// changes, sending the replaced one as previous_machine_id
+	MachineIDMigrate bool
+	// MACAddresses sends the physical MACs behind MachineID and the MACs of
+	// the interfaces, neighbors and access point in clear
+	MACAddresses bool
+	// PreferWired ranks wired interfaces before wireless for the primary IP
+	PreferWired bool
+	// PreferSubnets ranks primary IP candidates by the first matching subnet
+	PreferSubnets []*net.IPNet
+	// ExcludeInterfaces never supply the primary IP (names or glob patterns)
+	ExcludeInterfaces []string
+	// NetworkWatch sends an update as soon as the host addresses change
+	NetworkWatch bool
+	// Listeners enables the listening ports inventory
+	Listeners bool
+	// PublicIPEndpoint is the http(s) URL or stun:host[:port] probed for
+	// the egress address (empty disables the probe)
+	PublicIPEndpoint string
+	// PublicIPInterval is the minimum time between public IP probes
+	PublicIPInterval time.Duration
+	// Privacy is the privacy preset ("strict", "pseudonymous" or empty)
+	Privacy string
+	// Redact maps the field classes of TATUSCAN_REDACT to "hash" or "omit"
+	Redact map[string]string
+	// PseudonymKeyFile holds the HMAC key of the pseudonymous preset
+	// (default: generated in the state directory)
+	PseudonymKeyFile string
+	// Disks enables the filesystem usage report and its local history
+	Disks bool
+	// DiskThreshold is the used percentage flagged as over_threshold
+	DiskThreshold float64
+	// Sensors enables temperature and fan speed collection
+	Sensors bool
+	// Services holds Windows service names or glob patterns to report
+	Services []string
+	// EndpointSecurity enables the Defender status report (Windows)
+	EndpointSecurity bool
+	// EDRServices holds third-party EDR service names checked for running
+	EDRServices []string
+	// Neighbors reports the devices of the ARP table of the primary subnet
+	Neighbors bool
+	// NeighborsSweep probes the subnet before reading the ARP table
+	NeighborsSweep bool
+	// DisabledCollectors are turned off, for privacy or on hosts where they
+	// misbehave
+	DisabledCollectors []string
+	// WMI allows WMI queries (Windows); off, the collectors use their
+	// fallbacks or report nothing
+	WMI bool
+	// OsqueryQueries are osquery SQL queries by name, from the
+	// TATUSCAN_OSQUERY_<NAME> variables, merged into the payload
+	OsqueryQueries map[string]string
+	// Osqueryi is the osqueryi executable (default: PATH and the package
+	// install locations)
+	Osqueryi string
+	// Scripts are the custom script collectors by name, from the
+	// TATUSCAN_SCRIPT_<NAME> variables, merged into the payload
+	Scripts map[string]ScriptCollector
+	// Updates enables the pending OS updates check
+	Updates bool
+	// UpdatesNames adds the names of pending updates to the report
+	UpdatesNames bool
+	// UpdatesInterval is the minimum time between update checks
+	UpdatesInterval time.Duration
+	// CertExpiryDays reports machine certificates expiring within that many
+	// days; 0 disables the scan
+	CertExpiryDays float64
+	// CertPaths are certificate files or directories scanned besides the
+	// system stores
+	CertPaths []string
+	// Packages enables the installed packages inventory
+	Packages bool
+	// PackagesInterval is the minimum time between package inventories
+	PackagesInterval time.Duration
+	// HealthCPUTemp, HealthDiskTemp (Celsius), HealthMemory (used percent)
+	// and HealthDiskDays (fill forecast) mark the health degraded; 0 disables
+	HealthCPUTemp  float64
+	HealthDiskTemp float64
+	HealthMemory   float64
+	HealthDiskDays float64
+	// Tasks lists the built-in commands the server may queue; empty disables
+	// remote tasks
+	Tasks []string
+	// TasksKey is the base64 Ed25519 public key verifying queued tasks
+	TasksKey string
+	// SnipeITModelID and SnipeITStatusID are given to the assets created
+	// by the snipeit:// destination (0 only updates existing assets)
+	SnipeITModelID  int
+	SnipeITStatusID int
+	// SnipeITFields maps payload values (ip, mac, ...) to custom field
+	// columns of Snipe-IT assets
+	SnipeITFields map[string]string
+}
+
+// Cfg is the configuration used by internal functions
+var Cfg Config
+
+// SetConfig sets the configuration to be used by internal functions
+func SetConfig(cfg Config) {
+	Cfg = cfg
+}
+
+// LoadConfig builds the configuration from the process environment and
+// the tatuscan.env settings file in the config directory
+func LoadConfig() Config {
+	return loadConfig(configEnv())
+}
+
+// loadConfig builds the configuration from a key/value source
+func loadConfig(env map[string]string) Config {
+	dirs := defaultDirs()
+	tags, labels := parseTags(env)
+	return Config{
+		ServerURL:            strings.TrimSpace(env["TATUSCAN_URL"]),
+		Token:                strings.TrimSpace(env["TATUSCAN_TOKEN"]),
+		Tags:                 tags,
+		Labels:               labels,
+		Interval:             parseDurationOr(env["TATUSCAN_INTERVAL"], 0),
+		CollectTimeout:       parseDurationOr(env["TATUSCAN_COLLECT_TIMEOUT"], defaultCollectTimeout),
+		CollectorTimeout:     parseDurationOr(env["TATUSCAN_COLLECTOR_TIMEOUT"], defaultCollectorTimeout),
+		ShutdownTimeout:      parseDurationOr(env["TATUSCAN_SHUTDOWN_TIMEOUT"], defaultShutdownTimeout),
+		Control:              parseBoolOr(env["TATUSCAN_CONTROL"], true),
+		Discovery:            parseBoolOr(env["TATUSCAN_DISCOVERY"], true),
+		SendInterval:         parseDurationOr(env["TATUSCAN_SEND_INTERVAL"], 0),
+		HMACSecret:           strings.TrimSpace(env["TATUSCAN_HMAC_SECRET"]),
+		CompressThreshold:    int(parseThresholdOr(env["TATUSCAN_COMPRESS_THRESHOLD"], 0)),
+		Delta:                parseBoolOr(env["TATUSCAN_DELTA"], false),
+		DeltaFullSync:        parseDurationOr(env["TATUSCAN_DELTA_FULL_SYNC"], defaultDeltaFullSync),
+		HeartbeatCycles:      int(parseThresholdOr(env["TATUSCAN_HEARTBEAT_CYCLES"], 0)),
+		Headers:              parseHeaders(env),
+		OfflineSamples:       int(parseThresholdOr(env["TATUSCAN_OFFLINE_SAMPLES"], defaultOfflineSamples)),
+		LogOutput:            parseLogOutput(env["TATUSCAN_LOG_OUTPUT"]),
+		LogFormat:            parseLogFormat(env["TATUSCAN_LOG_FORMAT"]),
+		LogFile:              strings.TrimSpace(env["TATUSCAN_LOG_FILE"]),
+		LogFileMaxSizeMB:     int(parseThresholdOr(env["TATUSCAN_LOG_FILE_MAX_SIZE"], defaultLogFileMaxSizeMB)),
+		LogFileMaxAge:        parseDurationOr(env["TATUSCAN_LOG_FILE_MAX_AGE"], defaultLogFileMaxAge),
+		LogFileMaxBackups:    int(parseThresholdOr(env["TATUSCAN_LOG_FILE_MAX_BACKUPS"], defaultLogFileMaxBackups)),
+		LogDedup:             parseBoolOr(env["TATUSCAN_LOG_DEDUP"], true),
+		LogDedupWindow:       parseDurationOr(env["TATUSCAN_LOG_DEDUP_WINDOW"], defaultLogDedupWindow),
+		StateDir:             stringOr(env["TATUSCAN_STATE_DIR"], dirs.State),
+		ConfigDir:            stringOr(env["TATUSCAN_CONFIG_DIR"], dirs.Config),
+		CacheDir:             stringOr(env["TATUSCAN_CACHE_DIR"], dirs.Cache),
+		RemoteConfigURL:      strings.TrimSpace(env["TATUSCAN_CONFIG_URL"]),
+		RemoteConfigKey:      strings.TrimSpace(env["TATUSCAN_CONFIG_KEY"]),
+		RemoteConfigInterval: parseDurationOr(env["TATUSCAN_CONFIG_INTERVAL"], defaultRemoteConfigInterval),
+		ImageMarkerFile:      strings.TrimSpace(env["TATUSCAN_IMAGE_FILE"]),
+		ImageRegistryKey:     strings.TrimSpace(env["TATUSCAN_IMAGE_REGISTRY"]),
+		WarrantyCSV:          strings.TrimSpace(env["TATUSCAN_WARRANTY_CSV"]),
+		WarrantyHook:         strings.TrimSpace(env["TATUSCAN_WARRANTY_HOOK"]),
+		WarrantyHookTimeout:  parseDurationOr(env["TATUSCAN_WARRANTY_HOOK_TIMEOUT"], defaultWarrantyHookTimeout),
+		Watchlist:            splitList(env["TATUSCAN_WATCHLIST"]),
+		VirtualPatterns:      splitList(env["TATUSCAN_VIRTUAL_PATTERNS"]),
+		VirtualPatternsExtra: splitList(env["TATUSCAN_VIRTUAL_PATTERNS_EXTRA"]),
+		MachineIDSalt:        strings.TrimSpace(env["TATUSCAN_MACHINE_ID_SALT"]),
+		MachineIDMigrate:     parseBoolOr(env["TATUSCAN_MACHINE_ID_MIGRATE"], false),
+		MACAddresses:         parseBoolOr(env["TATUSCAN_MAC_ADDRESSES"], false),
+		PreferWired:          parseBoolOr(env["TATUSCAN_PREFER_WIRED"], false),
+		PreferSubnets:        parseSubnets(env["TATUSCAN_PREFER_SUBNETS"]),
+		ExcludeInterfaces:    splitList(env["TATUSCAN_EXCLUDE_INTERFACES"]),
+		NetworkWatch:         parseBoolOr(env["TATUSCAN_NETWORK_WATCH"], true),
+		Listeners:            parseBoolOr(env["TATUSCAN_LISTENERS"], false),
+		PublicIPEndpoint:     strings.TrimSpace(env["TATUSCAN_PUBLIC_IP"]),
+		PublicIPInterval:     parseDurationOr(env["TATUSCAN_PUBLIC_IP_INTERVAL"], defaultPublicIPInterval),
+		Privacy:              parsePrivacy(env["TATUSCAN_PRIVACY"]),
+		Redact:               parseRedact(env["TATUSCAN_REDACT"]),
+		PseudonymKeyFile:     strings.TrimSpace(env["TATUSCAN_PSEUDONYM_KEY_FILE"]),
+		Disks:                parseBoolOr(env["TATUSCAN_DISKS"], true),
+		DiskThreshold:        parsePercentOr(env["TATUSCAN_DISK_THRESHOLD"], defaultDiskThreshold),
+		Sensors:              parseBoolOr(env["TATUSCAN_SENSORS"], false),
+		Services:             splitList(env["TATUSCAN_SERVICES"]),
+		EndpointSecurity:     parseBoolOr(env["TATUSCAN_ENDPOINT_SECURITY"], false),
+		EDRServices:          splitList(env["TATUSCAN_EDR_SERVICES"]),
+		Neighbors:            parseBoolOr(env["TATUSCAN_NEIGHBORS"], false),
+		NeighborsSweep:       parseBoolOr(env["TATUSCAN_NEIGHBORS_SWEEP"], false),
+		DisabledCollectors:   parseDisabledCollectors(env["TATUSCAN_DISABLE_COLLECTORS"]),
+		CollectorIntervals:   parseCollectorIntervals(env["TATUSCAN_COLLECTOR_INTERVALS"]),
+		WMI:                  parseBoolOr(env["TATUSCAN_WMI"], true),
+		OsqueryQueries:       parseOsqueryQueries(env),
+		Osqueryi:             strings.TrimSpace(env["TATUSCAN_OSQUERYI"]),
+		Scripts:              parseScriptCollectors(env),
+		Updates:              parseBoolOr(env["TATUSCAN_UPDATES"], false),
+		UpdatesNames:         parseBoolOr(env["TATUSCAN_UPDATES_NAMES"], false),
+		UpdatesInterval:      parseDurationOr(env["TATUSCAN_UPDATES_INTERVAL"], defaultUpdatesInterval),
+		CertExpiryDays:       parseThresholdOr(env["TATUSCAN_CERT_EXPIRY_DAYS"], defaultCertExpiryDays),
+		CertPaths:            splitList(env["TATUSCAN_CERT_PATHS"]),
+		Packages:             parseBoolOr(env["TATUSCAN_PACKAGES"], false),
+		PackagesInterval:     parseDurationOr(env["TATUSCAN_PACKAGES_INTERVAL"], defaultPackagesInterval),
+		HealthCPUTemp:        parseThresholdOr(env["TATUSCAN_HEALTH_CPU_TEMP"], defaultHealthCPUTemp),
+		HealthDiskTemp:       parseThresholdOr(env["TATUSCAN_HEALTH_DISK_TEMP"], defaultHealthDiskTemp),
+		HealthMemory:         parseThresholdOr(env["TATUSCAN_HEALTH_MEMORY"], defaultHealthMemory),
+		HealthDiskDays:       parseThresholdOr(env["TATUSCAN_HEALTH_DISK_DAYS"], defaultHealthDiskDays),
+		Tasks:                splitList(env["TATUSCAN_TASKS"]),
+		TasksKey:             strings.TrimSpace(env["TATUSCAN_TASKS_KEY"]),
+		SnipeITModelID:       parseID(env["TATUSCAN_SNIPEIT_MODEL_ID"]),
+		SnipeITStatusID:      parseID(env["TATUSCAN_SNIPEIT_STATUS_ID"]),
+		SnipeITFields:        parseSnipeITFields(env["TATUSCAN_SNIPEIT_FIELDS"]),
+	}
+}
+
+// stringOr returns the trimmed value, or fallback when empty
+func stringOr(value, fallback string) string {
+	if value = strings.TrimSpace(value); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseBoolOr parses a boolean, returning fallback when empty or invalid
+func parseBoolOr(value string, fallback bool) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		if Log != nil {
+			Log.Warnf("Invalid boolean %q, using %v", value, fallback)
+		}
+		return fallback
+	}
+	return b
+}
+
+// parsePercentOr parses a percentage between 0 and 100, returning fallback
+// when empty or invalid
+func parsePercentOr(value string, fallback float64) float64 {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	if value == "" {
+		return fallback
+	}
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil || p <= 0 || p > 100 {
+		if Log != nil {
+			Log.Warnf("Invalid percentage %q, using %v", value, fallback)
+		}
+		return fallback
+	}
+	return p
+}
+
+// parseThresholdOr parses a non-negative number, where 0 disables the check,
+// returning fallback when empty or invalid
+func parseThresholdOr(value string, fallback float64) float64 {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "%")
+	if value == "" {
+		return fallback
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		if Log != nil {
+			Log.Warnf("Invalid threshold %q, using %v", value, fallback)
+		}
+		return fallback
+	}
+	return v
+}
+
+// parseSubnets parses a comma-separated CIDR list, skipping invalid entries
+func parseSubnets(value string) []*net.IPNet {
+	var subnets []*net.IPNet
+	for _, item := range splitList(value) {
+		_, subnet, err := net.ParseCIDR(item)
+		if err != nil {
+			if Log != nil {
+				Log.Warnf("Invalid subnet %q ignored: %v", item, err)
+			}
+			continue
+		}
+		subnets = append(subnets, subnet)
+	}
+	return subnets
+}
+
+// splitList splits a comma-separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// tagVariablePrefix starts the per-key tag variables (TATUSCAN_TAG_SITE=lab3)
+const tagVariablePrefix = "TATUSCAN_TAG_"
+
+// parseTags splits TATUSCAN_TAGS into plain tags and key=value labels, then
+// adds the TATUSCAN_TAG_<KEY> variables, which win over list items. Keys are
+// lowercased and limited to letters, digits, '_', '-' and '.'.
+func parseTags(env map[string]string) ([]string, map[string]string) {
+	var tags []string
+	labels := map[string]string{}
+	addLabel := func(key, value string) {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !validTagKey(key) {
+			if Log != nil {
+				Log.Warnf("Invalid tag key %q ignored", key)
+			}
+			return
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+	for _, item := range splitList(env["TATUSCAN_TAGS"]) {
+		if key, value, ok := strings.Cut(item, "="); ok {
+			addLabel(key, value)
+		} else {
+			tags = append(tags, item)
+		}
+	}
+	for name, value := range env {
+		if key, ok := strings.CutPrefix(name, tagVariablePrefix); ok {
+			addLabel(key, value)
+		}
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+	return tags, labels
+}
+
+// validTagKey reports whether key is a non-empty lowercase tag key
+func validTagKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDurationOr parses a duration, returning fallback when empty or invalid
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		if Log != nil {
+			Log.Warnf("Invalid duration %q, using %s", value, fallback)
+		}
+		return fallback
+	}
+	return d
+}
+
+// environMap converts KEY=VALUE pairs into a map
+func environMap(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
@@ -0,0 +1,154 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// configFileName is the settings file kept in the config directory, with
+// the same KEY=VALUE syntax as .env files
+const configFileName = "tatuscan.env"
+
+// configFilePath returns the settings file location for env
+func configFilePath(env map[string]string) string {
+	return filepath.Join(stringOr(env["TATUSCAN_CONFIG_DIR"], defaultDirs().Config), configFileName)
+}
+
+// ConfigFilePath returns the settings file location
+func ConfigFilePath() string {
+	return configFilePath(environMap(os.Environ()))
+}
+
+// configEnv layers the settings sources, lowest precedence first: the
+// settings file, the managed configuration (MDM profile) and the process
+// environment
+func configEnv() map[string]string {
+	env := environMap(os.Environ())
+	return mergeEnv(mergeEnv(readConfigFile(configFilePath(env)), managedConfig()), env)
+}
+
+// readConfigFile reads a settings file, returning nil when it is missing
+// or invalid
+func readConfigFile(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) && Log != nil {
+			Log.Warnf("Error to read %s: %v", path, err)
+		}
+		return nil
+	}
+	values, err := parseEnvFile(data)
+	if err != nil {
+		if Log != nil {
+			Log.Warnf("Error to parse %s: %v", path, err)
+		}
+		return nil
+	}
+	return values
+}
+
+// WriteConfigFile sets values in the settings file, creating it readable
+// only by the agent since it may hold TATUSCAN_TOKEN, and returns its path
+func WriteConfigFile(values map[string]string) (string, error) {
+	path := ConfigFilePath()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return path, err
+	}
+	return path, writeFileAtomic(path, setEnvFileValues(data, values), 0o600)
+}
+
+// managedValues converts a managed preferences document (a configuration
+// profile payload decoded from JSON) to settings: TATUSCAN_* keys only,
+// with booleans and numbers formatted and arrays joined with commas
+func managedValues(doc map[string]any) map[string]string {
+	values := make(map[string]string)
+	for key, value := range doc {
+		if !strings.HasPrefix(key, "TATUSCAN_") {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case bool, float64:
+			values[key] = fmt.Sprint(v)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		}
+	}
+	return values
+}
+
+// mergeEnv returns base with the values of top on top
+func mergeEnv(base, top map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(top))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range top {
+		merged[key] = value
+	}
+	return merged
+}
+
+// parseEnvFile parses KEY=VALUE lines; blank lines and # comments are
+// ignored and values may be quoted
+func parseEnvFile(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// setEnvFileValues replaces the KEY=VALUE lines of values in a settings
+// file and appends the missing keys, keeping comments and other settings
+func setEnvFileValues(data []byte, values map[string]string) []byte {
+	pending := make(map[string]string, len(values))
+	for key, value := range values {
+		pending[key] = value
+	}
+	var lines []string
+	if text := strings.TrimRight(string(data), "\n"); text != "" {
+		lines = strings.Split(text, "\n")
+	}
+	for i, line := range lines {
+		key, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if value, set := pending[strings.TrimSpace(key)]; ok && set {
+			lines[i] = strings.TrimSpace(key) + "=" + value
+			delete(pending, strings.TrimSpace(key))
+		}
+	}
+	keys := make([]string, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, key+"="+pending[key])
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetEnvFileValues(t *testing.T) {
+	data := []byte("# managed by the installer\nTATUSCAN_URL=http://old:8040\nTATUSCAN_SENSORS=true\n")
+	got := string(setEnvFileValues(data, map[string]string{
+		"TATUSCAN_URL":   "https://tatuscan.example.com",
+		"TATUSCAN_TOKEN": "s3cret",
+		"TATUSCAN_TAGS":  "lab,floor-2",
+	}))
+	want := "# managed by the installer\nTATUSCAN_URL=https://tatuscan.example.com\nTATUSCAN_SENSORS=true\n" +
+		"TATUSCAN_TAGS=lab,floor-2\nTATUSCAN_TOKEN=s3cret\n"
+	if got != want {
+		t.Errorf("setEnvFileValues:\n%s\nwant:\n%s", got, want)
+	}
+	if got := string(setEnvFileValues(nil, map[string]string{"TATUSCAN_URL": "x"})); got != "TATUSCAN_URL=x\n" {
+		t.Errorf("new file: %q", got)
+	}
+}
+
+func TestLoadConfigReadsSettingsFile(t *testing.T) {
+	setupTestAgent(t)
+	dir := t.TempDir()
+	file := "TATUSCAN_URL=https://tatuscan.example.com\nTATUSCAN_TOKEN='s3cret'\nTATUSCAN_TAGS=lab, floor-2\nTATUSCAN_INTERVAL=5m\n"
+	if err := os.WriteFile(filepath.Join(dir, configFileName), []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TATUSCAN_CONFIG_DIR", dir)
+	t.Setenv("TATUSCAN_INTERVAL", "2m")
+
+	cfg := LoadConfig()
+	if cfg.ServerURL != "https://tatuscan.example.com" || cfg.Token != "s3cret" {
+		t.Errorf("settings file not applied: %+v", cfg)
+	}
+	if len(cfg.Tags) != 2 || cfg.Tags[1] != "floor-2" {
+		t.Errorf("tags = %v", cfg.Tags)
+	}
+	if cfg.Interval.String() != "2m0s" {
+		t.Errorf("environment should win over the file, interval = %s", cfg.Interval)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	setupTestAgent(t)
+	tags, labels := parseTags(map[string]string{
+		"TATUSCAN_TAGS":       "lab, Site=lab2,owner = physics,bad key=x",
+		"TATUSCAN_TAG_SITE":   "lab3",
+		"TATUSCAN_TAG_RACK.U": "12",
+	})
+	if !reflect.DeepEqual(tags, []string{"lab"}) {
+		t.Errorf("tags = %v", tags)
+	}
+	want := map[string]string{"site": "lab3", "owner": "physics", "rack.u": "12"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+	if _, labels := parseTags(map[string]string{"TATUSCAN_TAGS": "lab"}); labels != nil {
+		t.Errorf("expected no labels, got %v", labels)
+	}
+}
+
+func TestManagedValues(t *testing.T) {
+	got := managedValues(map[string]any{
+		"TATUSCAN_URL":      "https://tatuscan.example.com",
+		"TATUSCAN_SENSORS":  true,
+		"TATUSCAN_SERVICES": []any{"sshd", "veeam*"},
+		"PayloadUUID":       "ignored",
+	})
+	want := map[string]string{
+		"TATUSCAN_URL":      "https://tatuscan.example.com",
+		"TATUSCAN_SENSORS":  "true",
+		"TATUSCAN_SERVICES": "sshd,veeam*",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("managedValues = %v", got)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+}
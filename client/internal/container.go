@@ -0,0 +1,46 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"strings"
+)
+
+// containerSignatures maps substrings of /proc/self/cgroup and
+// /proc/self/mountinfo to container runtimes, most specific first
+var containerSignatures = []struct{ pattern, name string }{
+	{"kubepods", "kubernetes"},
+	{"/kubelet/pods/", "kubernetes"},
+	{"libpod", "podman"},
+	{"/containers/storage/", "podman"},
+	{"/docker/", "docker"},
+	{"docker-", "docker"},
+	{"/lxc/", "lxc"},
+	{"lxc.payload", "lxc"},
+	{"/containerd/", "containerd"},
+	{"cri-containerd", "containerd"},
+}
+
+// containerFromText identifies a container runtime from cgroup or mountinfo
+// contents, returning "" when no signature is found
+func containerFromText(text string) string {
+	for _, sig := range containerSignatures {
+		if strings.Contains(text, sig.pattern) {
+			return sig.name
+		}
+	}
+	return ""
+}
+
+// getContainer returns the container runtime the agent runs in, or "" on a
+// host. Inside containers the MACs and interfaces are the container's own,
+// so MachineID and IP do not describe the host.
+func getContainer(ctx context.Context) string {
+	container := platformContainer()
+	if container != "" {
+		Log.Warnf("Running inside a %s container: MachineID and interfaces describe the container, not the host", container)
+		addWarning(ctx, WarnRunningInContainer, "running inside a %s container", container)
+	}
+	return container
+}
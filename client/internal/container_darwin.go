@@ -0,0 +1,8 @@
+//go:build darwin
+
+package internal
+
+// platformContainer always returns "": macOS has no native containers
+func platformContainer() string {
+	return ""
+}
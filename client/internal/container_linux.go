@@ -0,0 +1,51 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// environValue returns a variable from a NUL-separated /proc/<pid>/environ
+func environValue(path, key string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range strings.Split(string(data), "\x00") {
+		if k, v, ok := strings.Cut(entry, "="); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// platformContainer checks, in order, Kubernetes service variables, runtime
+// marker files, the "container" variable set by lxc/nspawn/podman for PID 1,
+// and the cgroup and mount paths of the agent process
+func platformContainer() string {
+	if environValue(filepath.Join(procRoot, "self", "environ"), "KUBERNETES_SERVICE_HOST") != "" {
+		return "kubernetes"
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, ".dockerenv")); err == nil {
+		return "docker"
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "run", ".containerenv")); err == nil {
+		return "podman"
+	}
+	if v := environValue(filepath.Join(procRoot, "1", "environ"), "container"); v != "" {
+		return v
+	}
+	for _, name := range []string{"cgroup", "mountinfo"} {
+		data, err := os.ReadFile(filepath.Join(procRoot, "self", name))
+		if err != nil {
+			continue
+		}
+		if container := containerFromText(string(data)); container != "" {
+			return container
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestContainerFromText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"12:memory:/docker/4f2a9c1e0b7d\n0::/\n", "docker"},
+		{"0::/kubepods.slice/kubepods-burstable.slice/cri-containerd-ab12.scope\n", "kubernetes"},
+		{"0::/machine.slice/libpod-7c1d.scope/container\n", "podman"},
+		{"0::/lxc.payload.web01/\n", "lxc"},
+		{"812 790 0:45 / / rw - overlay overlay rw,lowerdir=/var/lib/docker/overlay2/l/X\n" +
+			"820 812 8:1 /var/lib/docker/containers/4f2a/hostname /etc/hostname rw - ext4 /dev/sda1 rw\n", "docker"},
+		{"0::/user.slice/user-1000.slice/session-2.scope\n", ""},
+	}
+	for _, tt := range tests {
+		if got := containerFromText(tt.text); got != tt.want {
+			t.Errorf("containerFromText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPlatformContainerLinux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("container detection reads Linux procfs")
+	}
+	setupTestAgent(t)
+	origRoot, origProc := rootDir, procRoot
+	t.Cleanup(func() { rootDir, procRoot = origRoot, origProc })
+	rootDir = t.TempDir()
+	procRoot = filepath.Join(rootDir, "proc")
+	write := func(rel, value string) {
+		path := filepath.Join(rootDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("proc/self/cgroup", "0::/init.scope\n")
+	if got := platformContainer(); got != "" {
+		t.Fatalf("host detected as %q", got)
+	}
+
+	write("proc/1/environ", "PATH=/usr/bin\x00container=systemd-nspawn\x00")
+	if got := platformContainer(); got != "systemd-nspawn" {
+		t.Errorf("container = %q, want systemd-nspawn", got)
+	}
+
+	write(".dockerenv", "")
+	if got := platformContainer(); got != "docker" {
+		t.Errorf("container = %q, want docker", got)
+	}
+
+	write("proc/self/environ", "HOME=/root\x00KUBERNETES_SERVICE_HOST=10.96.0.1\x00")
+	if got := platformContainer(); got != "kubernetes" {
+		t.Errorf("container = %q, want kubernetes", got)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package internal
+
+import "golang.org/x/sys/windows/registry"
+
+// platformContainer detects Windows containers (process and Hyper-V
+// isolation), which define the ContainerType value
+func platformContainer() string {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control`, registry.QUERY_VALUE)
+	if err != nil {
+		return ""
+	}
+	defer k.Close()
+	if _, _, err := k.GetIntegerValue("ContainerType"); err == nil {
+		return "windows"
+	}
+	return ""
+}
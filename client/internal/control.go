@@ -0,0 +1,276 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Commands of the local control API
+const (
+	ControlStatus  = "status"  // agent state and the outcome of the last cycle
+	ControlReport  = "report"  // last collected payload
+	ControlCollect = "collect" // run a cycle now
+	ControlReload  = "reload"  // reload the configuration
+)
+
+// controlTimeout bounds a control connection, request and response
+const controlTimeout = 10 * time.Second
+
+// controlRequest is the single JSON object a client writes
+type controlRequest struct {
+	Command string `json:"command"`
+}
+
+// ControlResponse is the single JSON object the agent answers with
+type ControlResponse struct {
+	OK      bool            `json:"ok"`
+	Error   string          `json:"error,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// AgentStatus is the answer to the status command
+type AgentStatus struct {
+	Version        string      `json:"version"`
+	PID            int         `json:"pid"`
+	Started        string      `json:"started"`
+	Server         string      `json:"server,omitempty"`
+	MachineID      string      `json:"machine_id,omitempty"`
+	LastCollection string      `json:"last_collection,omitempty"`
+	LastSend       string      `json:"last_send,omitempty"` // last successful send
+	LastError      string      `json:"last_error,omitempty"`
+	NextRun        string      `json:"next_run,omitempty"`
+	UnsentCycles   int         `json:"unsent_cycles"` // collected, held until the next send
+	DeferredUntil  string      `json:"deferred_until,omitempty"`
+	Agent          *AgentStats `json:"agent,omitempty"`
+	// Config holds the effective TATUSCAN_* settings, secrets masked
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// controlState keeps what the control API reports about the agent loop
+var controlState struct {
+	sync.Mutex
+	started   time.Time
+	report    *MachineInfo
+	collected time.Time
+	sent      time.Time
+	lastError string
+	next      time.Time
+	unsent    int
+}
+
+// reloadNow carries the reload requests of the control API to the agent
+// loop, which owns the configuration
+var reloadNow = make(chan struct{}, 1)
+
+// ReloadRequests delivers the reload requests to the agent loop
+func ReloadRequests() <-chan struct{} {
+	return reloadNow
+}
+
+// RecordReport keeps the payload of the last cycle for the control API
+func RecordReport(info MachineInfo) {
+	controlState.Lock()
+	defer controlState.Unlock()
+	controlState.report = &info
+	controlState.collected = time.Now()
+}
+
+// RecordSend keeps the outcome of the last send for the control API
+func RecordSend(err error) {
+	controlState.Lock()
+	defer controlState.Unlock()
+	if err != nil {
+		controlState.lastError = err.Error()
+		return
+	}
+	controlState.sent, controlState.lastError = time.Now(), ""
+}
+
+// RecordSchedule keeps the time of the next cycle and the number of cycles
+// not sent yet for the control API
+func RecordSchedule(next time.Time, unsent int) {
+	controlState.Lock()
+	defer controlState.Unlock()
+	controlState.next, controlState.unsent = next, unsent
+}
+
+// ServeControl serves the local control API until ctx is done. The socket
+// or pipe only accepts the local administrators (root on Linux and macOS).
+func ServeControl(ctx context.Context) {
+	if !Cfg.Control {
+		return
+	}
+	l, err := listenControl()
+	if err != nil {
+		Log.Warnf("Control API unavailable: %v", err)
+		return
+	}
+	controlState.Lock()
+	controlState.started = time.Now()
+	controlState.Unlock()
+	Log.Debugf("Control API listening on %s", controlAddress())
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+					Log.Warnf("Control API stopped: %v", err)
+				}
+				return
+			}
+			go serveControlConn(conn)
+		}
+	}()
+}
+
+// serveControlConn answers the one request of a control connection
+func serveControlConn(conn net.Conn) {
+	defer conn.Close()
+	// Named pipes have no deadlines; a stuck client only holds its goroutine
+	conn.SetDeadline(time.Now().Add(controlTimeout))
+	var req controlRequest
+	resp := ControlResponse{Error: "invalid request"}
+	if err := json.NewDecoder(conn).Decode(&req); err == nil {
+		resp = handleControl(req)
+	}
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		Log.Debugf("Error to answer a control request: %v", err)
+	}
+}
+
+// handleControl runs one control command
+func handleControl(req controlRequest) ControlResponse {
+	Log.Debugf("Control command %q", req.Command)
+	switch req.Command {
+	case ControlStatus:
+		return controlData(agentStatus())
+	case ControlReport:
+		controlState.Lock()
+		report := controlState.report
+		controlState.Unlock()
+		if report == nil {
+			return ControlResponse{Error: "no cycle completed yet"}
+		}
+		return controlData(report)
+	case ControlCollect:
+		msg, _ := requestCollection()
+		return ControlResponse{OK: true, Message: string(msg)}
+	case ControlReload:
+		select {
+		case reloadNow <- struct{}{}:
+		default: // one is already pending
+		}
+		return ControlResponse{OK: true, Message: "reload scheduled"}
+	}
+	return ControlResponse{Error: fmt.Sprintf("unknown command %q", req.Command)}
+}
+
+// agentStatus describes the running agent
+func agentStatus() AgentStatus {
+	controlState.Lock()
+	defer controlState.Unlock()
+	status := AgentStatus{
+		Version:      agentBuild.Version,
+		PID:          os.Getpid(),
+		Started:      controlState.started.UTC().Format(time.RFC3339),
+		Server:       Cfg.ServerURL,
+		LastError:    controlState.lastError,
+		UnsentCycles: controlState.unsent,
+		Agent:        agentStats(),
+		Config:       effectiveSettings(),
+	}
+	if !controlState.next.IsZero() {
+		status.NextRun = controlState.next.UTC().Format(time.RFC3339)
+	}
+	if until, deferred := ReportDeferred(time.Now()); deferred {
+		status.DeferredUntil = until.UTC().Format(time.RFC3339)
+	}
+	if controlState.report != nil {
+		status.MachineID = controlState.report.MachineID
+		status.LastCollection = controlState.collected.UTC().Format(time.RFC3339)
+	}
+	if !controlState.sent.IsZero() {
+		status.LastSend = controlState.sent.UTC().Format(time.RFC3339)
+	}
+	return status
+}
+
+// effectiveSettings returns the TATUSCAN_* settings the agent runs with,
+// from every configuration layer, with the secrets masked
+func effectiveSettings() map[string]string {
+	configLayers.Lock()
+	site := configLayers.site
+	if site == nil {
+		site = configEnv()
+	}
+	env := mergeEnv(mergeEnv(site, configLayers.server), configLayers.rollout)
+	configLayers.Unlock()
+	settings := map[string]string{}
+	for key, value := range env {
+		if !strings.HasPrefix(key, "TATUSCAN_") {
+			continue
+		}
+		if secretSetting(key) && value != "" {
+			value = "(set)"
+		}
+		settings[key] = value
+	}
+	return settings
+}
+
+// SettingNames returns the keys of settings in order, for printing
+func SettingNames(settings map[string]string) []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// controlData wraps v as the data of a successful response
+func controlData(v any) ControlResponse {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ControlResponse{Error: err.Error()}
+	}
+	return ControlResponse{OK: true, Data: data}
+}
+
+// Control sends a command to the agent running on this machine
+func Control(ctx context.Context, command string) (ControlResponse, error) {
+	conn, err := dialControl(ctx)
+	if err != nil {
+		return ControlResponse{}, fmt.Errorf("cannot reach the agent at %s; is it running with TATUSCAN_CONTROL enabled?: %w", controlAddress(), err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	var resp ControlResponse
+	if err := json.NewEncoder(conn).Encode(controlRequest{Command: command}); err != nil {
+		return resp, err
+	}
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return resp, fmt.Errorf("invalid answer from the agent: %w", err)
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,82 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// resetControlState forgets the cycles recorded for the control API
+func resetControlState(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		controlState.Lock()
+		controlState.report, controlState.lastError = nil, ""
+		controlState.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestHandleControl(t *testing.T) {
+	setupTestAgent(t)
+	resetControlState(t)
+
+	if resp := handleControl(controlRequest{Command: ControlReport}); resp.OK {
+		t.Errorf("report before the first cycle: %+v", resp)
+	}
+	RecordReport(MachineInfo{MachineID: "abc123", Hostname: "lab-01"})
+	RecordSend(errors.New("server unreachable"))
+	RecordSchedule(time.Date(2026, 10, 16, 12, 5, 0, 0, time.UTC), 3)
+	configLayers.Lock()
+	site := configLayers.site
+	configLayers.site = map[string]string{"TATUSCAN_URL": "https://inventory.example.com", "TATUSCAN_TOKEN": "s3cret", "HOME": "/root"}
+	configLayers.Unlock()
+	t.Cleanup(func() {
+		configLayers.Lock()
+		configLayers.site = site
+		configLayers.Unlock()
+	})
+
+	resp := handleControl(controlRequest{Command: ControlStatus})
+	var status AgentStatus
+	if err := json.Unmarshal(resp.Data, &status); !resp.OK || err != nil {
+		t.Fatalf("status: %+v (%v)", resp, err)
+	}
+	if status.MachineID != "abc123" || status.LastCollection == "" || status.LastSend != "" || status.LastError != "server unreachable" ||
+		status.NextRun != "2026-10-16T12:05:00Z" || status.UnsentCycles != 3 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if len(status.Config) != 2 || status.Config["TATUSCAN_TOKEN"] != "(set)" || status.Config["TATUSCAN_URL"] != "https://inventory.example.com" {
+		t.Errorf("unexpected settings: %v", status.Config)
+	}
+
+	resp = handleControl(controlRequest{Command: ControlReport})
+	var report MachineInfo
+	if err := json.Unmarshal(resp.Data, &report); !resp.OK || err != nil || report.Hostname != "lab-01" {
+		t.Errorf("report: %+v (%v)", resp, err)
+	}
+
+	// Collect and reload are handed to the agent loop
+	if resp := handleControl(controlRequest{Command: ControlCollect}); !resp.OK {
+		t.Errorf("collect: %+v", resp)
+	}
+	select {
+	case <-CollectRequests():
+	default:
+		t.Error("collection not requested")
+	}
+	if resp := handleControl(controlRequest{Command: ControlReload}); !resp.OK {
+		t.Errorf("reload: %+v", resp)
+	}
+	select {
+	case <-ReloadRequests():
+	default:
+		t.Error("reload not requested")
+	}
+
+	if resp := handleControl(controlRequest{Command: "shutdown"}); resp.OK || resp.Error == "" {
+		t.Errorf("unknown command accepted: %+v", resp)
+	}
+}
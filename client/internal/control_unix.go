@@ -0,0 +1,42 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"context"
+	"net"
+	"os"
+)
+
+// controlSocket is the control API socket, in the state directory
+const controlSocket = "tatuscan.sock"
+
+// controlAddress returns the path of the control socket
+func controlAddress() string {
+	return statePath(controlSocket)
+}
+
+// listenControl creates the control socket, readable by its owner only.
+// The instance lock is held, so a socket left by a crash is stale.
+func listenControl() (net.Listener, error) {
+	if err := ensurePrivateDir(Cfg.StateDir); err != nil {
+		return nil, err
+	}
+	path := controlAddress()
+	os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// dialControl connects to the control socket
+func dialControl(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", controlAddress())
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestControlSocket(t *testing.T) {
+	setupTestAgent(t)
+	resetControlState(t)
+	Cfg.Control = true
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ServeControl(ctx)
+
+	st, err := os.Stat(controlAddress())
+	if err != nil {
+		t.Fatalf("control socket: %v", err)
+	}
+	if st.Mode().Perm() != 0o600 {
+		t.Errorf("control socket mode %v", st.Mode().Perm())
+	}
+
+	reqCtx, done := context.WithTimeout(ctx, 5*time.Second)
+	defer done()
+	resp, err := Control(reqCtx, ControlStatus)
+	if err != nil || !resp.OK || len(resp.Data) == 0 {
+		t.Fatalf("status: %+v (%v)", resp, err)
+	}
+	if _, err := Control(reqCtx, ControlReport); err == nil || err.Error() != "no cycle completed yet" {
+		t.Errorf("report before the first cycle: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// controlPipe is the named pipe of the control API
+const controlPipe = `\\.\pipe\tatuscan`
+
+// controlPipeSDDL grants the pipe to SYSTEM and the local administrators
+const controlPipeSDDL = "D:P(A;;GA;;;SY)(A;;GA;;;BA)"
+
+// controlAddress returns the name of the control pipe
+func controlAddress() string {
+	return controlPipe
+}
+
+// pipeAddr is the net.Addr of both ends of the control pipe
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn is a connected pipe instance. Pipes are opened for synchronous
+// I/O, so the deadline methods of the file report ErrNoDeadline.
+type pipeConn struct {
+	*os.File
+	server bool
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return pipeAddr(c.Name()) }
+func (c *pipeConn) RemoteAddr() net.Addr { return pipeAddr(c.Name()) }
+
+// Close lets the client read the answer before the server end goes away
+func (c *pipeConn) Close() error {
+	if c.server {
+		h := windows.Handle(c.Fd())
+		windows.FlushFileBuffers(h)
+		windows.DisconnectNamedPipe(h)
+	}
+	return c.File.Close()
+}
+
+// pipeListener accepts the clients of the control pipe, one instance each
+type pipeListener struct {
+	sa     *windows.SecurityAttributes
+	next   windows.Handle // instance waiting for the next client
+	closed atomic.Bool
+}
+
+// listenControl creates the first instance of the control pipe; failing
+// when another process already owns the name
+func listenControl() (net.Listener, error) {
+	sd, err := windows.SecurityDescriptorFromString(controlPipeSDDL)
+	if err != nil {
+		return nil, err
+	}
+	l := &pipeListener{sa: &windows.SecurityAttributes{
+		Length:             uint32(unsafe.Sizeof(windows.SecurityAttributes{})),
+		SecurityDescriptor: sd,
+	}}
+	if l.next, err = l.create(windows.FILE_FLAG_FIRST_PIPE_INSTANCE); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// create makes a new instance of the pipe
+func (l *pipeListener) create(flags uint32) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(controlPipe)
+	if err != nil {
+		return 0, err
+	}
+	return windows.CreateNamedPipe(name,
+		windows.PIPE_ACCESS_DUPLEX|flags,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES, 4096, 4096, 0, l.sa)
+}
+
+// Accept waits for a client on the pending instance, then creates the
+// instance for the next one
+func (l *pipeListener) Accept() (net.Conn, error) {
+	h := l.next
+	if h == 0 {
+		return nil, net.ErrClosed
+	}
+	if l.closed.Load() {
+		windows.CloseHandle(h)
+		l.next = 0
+		return nil, net.ErrClosed
+	}
+	err := windows.ConnectNamedPipe(h, nil)
+	if l.closed.Load() {
+		windows.CloseHandle(h)
+		l.next = 0
+		return nil, net.ErrClosed
+	}
+	if err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+		windows.CloseHandle(h)
+		l.next = 0
+		return nil, err
+	}
+	if l.next, err = l.create(0); err != nil {
+		l.next = 0
+		Log.Warnf("Error to create a control pipe instance: %v", err)
+	}
+	return &pipeConn{File: os.NewFile(uintptr(h), controlPipe), server: true}, nil
+}
+
+// Close stops Accept. ConnectNamedPipe blocks without overlapped I/O, so
+// the listener connects to itself to release it.
+func (l *pipeListener) Close() error {
+	if l.closed.Swap(true) {
+		return nil
+	}
+	if f, err := os.OpenFile(controlPipe, os.O_RDWR, 0); err == nil {
+		f.Close()
+	}
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr { return pipeAddr(controlPipe) }
+
+// dialControl connects to the control pipe, waiting while every instance
+// is busy
+func dialControl(ctx context.Context) (net.Conn, error) {
+	for {
+		f, err := os.OpenFile(controlPipe, os.O_RDWR, 0)
+		if err == nil {
+			return &pipeConn{File: f}, nil
+		}
+		if !errors.Is(err, windows.ERROR_PIPE_BUSY) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,45 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// CPUInfo describes the processors for capacity planning
+type CPUInfo struct {
+	Model         string  `json:"model,omitempty"`
+	PhysicalCores int     `json:"physical_cores,omitempty"`
+	LogicalCores  int     `json:"logical_cores"`
+	BaseMHz       float64 `json:"base_mhz,omitempty"` // nominal frequency, not the current one
+}
+
+// getCPUInfo returns the processor model, core counts and nominal
+// frequency; the counts are kept when the model cannot be read. Windows
+// reads the model and frequency through WMI, skipped with TATUSCAN_WMI=false.
+func getCPUInfo(ctx context.Context) *CPUInfo {
+	logical, err := cpu.CountsWithContext(ctx, true)
+	if err != nil || logical == 0 {
+		logical = runtime.NumCPU()
+	}
+	info := &CPUInfo{LogicalCores: logical}
+	if physical, err := cpu.CountsWithContext(ctx, false); err == nil {
+		info.PhysicalCores = physical
+	}
+	if runtime.GOOS == "windows" && !Cfg.WMI {
+		return info
+	}
+
+	processors, err := cpu.InfoWithContext(ctx)
+	if err != nil || len(processors) == 0 {
+		Log.Warnf("Error to collect the CPU model: %v", err)
+		return info
+	}
+	info.Model = strings.Join(strings.Fields(processors[0].ModelName), " ")
+	info.BaseMHz = processors[0].Mhz
+	return info
+}
@@ -0,0 +1,122 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// defaultDeltaFullSync is the default time between full reports in delta
+// mode
+const defaultDeltaFullSync = 24 * time.Hour
+
+// deltaFileName is the state file caching the last report accepted by the
+// server, the base of delta reports
+const deltaFileName = "last-report.json"
+
+// deltaKeys are always sent in a delta report, changed or not
+var deltaKeys = []string{"schema_version", "machine_id", "timestamp"}
+
+// deltaState is the content persisted in the delta file
+type deltaState struct {
+	// FullAt is when the last full report was accepted
+	FullAt time.Time `json:"full_at"`
+	// Report is the last accepted report, field by field
+	Report map[string]json.RawMessage `json:"report"`
+}
+
+// deltaPayload returns the body to send for the serialized payload data:
+// with TATUSCAN_DELTA, only the fields changed since the last accepted
+// report plus schema_version, machine_id, timestamp and "delta": true, or
+// data itself when a full report is due (first report, other machine, or
+// TATUSCAN_DELTA_FULL_SYNC elapsed since the last full one)
+func deltaPayload(data []byte, now time.Time) ([]byte, bool) {
+	if !Cfg.Delta {
+		return data, false
+	}
+	state := loadDeltaState()
+	if state == nil || (Cfg.DeltaFullSync > 0 && now.Sub(state.FullAt) >= Cfg.DeltaFullSync) {
+		return data, false
+	}
+	var current map[string]json.RawMessage
+	if err := json.Unmarshal(data, &current); err != nil || !bytes.Equal(current["machine_id"], state.Report["machine_id"]) {
+		return data, false
+	}
+	body, err := json.Marshal(buildDelta(state.Report, current))
+	if err != nil {
+		return data, false
+	}
+	Log.Debugf("Delta report of %d bytes instead of %d", len(body), len(data))
+	return body, true
+}
+
+// buildDelta keeps the fields of current that differ from previous, sends
+// fields no longer present as null and marks the report as a delta
+func buildDelta(previous, current map[string]json.RawMessage) map[string]json.RawMessage {
+	delta := map[string]json.RawMessage{"delta": json.RawMessage("true")}
+	for key, value := range current {
+		if old, ok := previous[key]; !ok || !bytes.Equal(old, value) {
+			delta[key] = value
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			delta[key] = json.RawMessage("null")
+		}
+	}
+	for _, key := range deltaKeys {
+		if value, ok := current[key]; ok {
+			delta[key] = value
+		}
+	}
+	return delta
+}
+
+// recordSentReport caches the serialized payload data accepted by the
+// server as the base of the next delta; full tells whether it was sent whole
+func recordSentReport(data []byte, full bool, now time.Time) {
+	if !Cfg.Delta {
+		return
+	}
+	state := &deltaState{FullAt: now}
+	if !full {
+		if previous := loadDeltaState(); previous != nil {
+			state.FullAt = previous.FullAt
+		}
+	}
+	if err := json.Unmarshal(data, &state.Report); err != nil {
+		return
+	}
+	encoded, err := json.Marshal(state)
+	if err == nil {
+		err = writeFileAtomic(statePath(deltaFileName), encoded, 0o644)
+	}
+	if err != nil {
+		Log.Warnf("Error to cache the sent report, the next one is sent in full: %v", err)
+		clearDeltaState()
+	}
+}
+
+// clearDeltaState drops the cached report, so the next report is sent in full
+func clearDeltaState() {
+	if err := os.Remove(statePath(deltaFileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		Log.Debugf("Error to remove %s: %v", deltaFileName, err)
+	}
+}
+
+// loadDeltaState reads the cached report, nil when missing or invalid
+func loadDeltaState() *deltaState {
+	data, err := os.ReadFile(statePath(deltaFileName))
+	if err != nil {
+		return nil
+	}
+	var state deltaState
+	if json.Unmarshal(data, &state) != nil || len(state.Report) == 0 {
+		return nil
+	}
+	return &state
+}
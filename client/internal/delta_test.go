@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBuildDelta(t *testing.T) {
+	previous := map[string]json.RawMessage{
+		"machine_id":  json.RawMessage(`"abc"`),
+		"timestamp":   json.RawMessage(`"2026-03-20T12:00:00Z"`),
+		"hostname":    json.RawMessage(`"lab-01"`),
+		"cpu_percent": json.RawMessage(`10`),
+		"tags":        json.RawMessage(`["lab"]`),
+	}
+	current := map[string]json.RawMessage{
+		"machine_id":  json.RawMessage(`"abc"`),
+		"timestamp":   json.RawMessage(`"2026-03-20T12:05:00Z"`),
+		"hostname":    json.RawMessage(`"lab-01"`),
+		"cpu_percent": json.RawMessage(`35`),
+	}
+	delta := buildDelta(previous, current)
+	want := map[string]string{
+		"delta":       "true",
+		"machine_id":  `"abc"`,
+		"timestamp":   `"2026-03-20T12:05:00Z"`,
+		"cpu_percent": "35",
+		"tags":        "null",
+	}
+	if len(delta) != len(want) {
+		t.Fatalf("delta = %s, want the keys of %v", delta, want)
+	}
+	for key, value := range want {
+		if string(delta[key]) != value {
+			t.Errorf("%s = %s, want %s", key, delta[key], value)
+		}
+	}
+}
+
+func TestHTTPSenderDelta(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Delta = true
+	Cfg.DeltaFullSync = time.Hour
+
+	var received []map[string]json.RawMessage
+	known := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fields map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&fields)
+		received = append(received, fields)
+		if string(fields["delta"]) == "true" && !known {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		known = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	info := MachineInfo{MachineID: "abc", Hostname: "lab-01", OS: "linux", CPUPercent: 10}
+	send := func() map[string]json.RawMessage {
+		t.Helper()
+		if err := sender.Send(context.Background(), info); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		return received[len(received)-1]
+	}
+
+	if first := send(); first["delta"] != nil || string(first["hostname"]) != `"lab-01"` {
+		t.Fatalf("first report must be full: %s", first)
+	}
+	info.CPUPercent = 35
+	second := send()
+	if string(second["delta"]) != "true" || string(second["cpu_percent"]) != "35" || second["hostname"] != nil || string(second["machine_id"]) != `"abc"` {
+		t.Errorf("unexpected delta report: %s", second)
+	}
+
+	// A server without the base answers 409 and gets a full report
+	known = false
+	received = nil
+	info.CPUPercent = 40
+	if last := send(); len(received) != 2 || last["delta"] != nil || string(last["hostname"]) != `"lab-01"` {
+		t.Errorf("expected a full report after 409, got %d requests, last %s", len(received), last)
+	}
+
+	// The periodic full sync is due once DeltaFullSync elapsed
+	state := loadDeltaState()
+	state.FullAt = state.FullAt.Add(-2 * time.Hour)
+	data, _ := json.Marshal(state)
+	if err := writeFileAtomic(statePath(deltaFileName), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if last := send(); last["delta"] != nil {
+		t.Errorf("expected a full sync, got %s", last)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build linux
+
+package internal
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// boardVendors names the manufacturers behind the vendor prefix of the
+// device-tree "compatible" property of common single-board computers
+var boardVendors = map[string]string{
+	"raspberrypi": "Raspberry Pi Ltd",
+	"hardkernel":  "Hardkernel",
+	"radxa":       "Radxa",
+	"pine64":      "Pine64",
+	"friendlyarm": "FriendlyElec",
+	"xunlong":     "Shenzhen Xunlong (Orange Pi)",
+	"nvidia":      "NVIDIA",
+	"beagle":      "BeagleBoard.org",
+}
+
+// readDeviceTree reads a device-tree property, a NUL-terminated string
+// list, from /proc/device-tree or its sysfs target
+func readDeviceTree(name string) []string {
+	data, err := os.ReadFile(filepath.Join(procRoot, "device-tree", name))
+	if err != nil {
+		data, err = os.ReadFile(filepath.Join(sysfsRoot, "firmware", "devicetree", "base", name))
+	}
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, value := range strings.Split(string(data), "\x00") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// applyDeviceTree identifies ARM boards without DMI (Raspberry Pi and other
+// single-board computers) from the device-tree model and compatible
+// properties, and the board serial and revision code from /proc/cpuinfo
+func applyDeviceTree(info *SMBIOSInfo) {
+	model := readDeviceTree("model")
+	if len(model) == 0 {
+		return
+	}
+	info.Model = model[0]
+	if compatible := readDeviceTree("compatible"); len(compatible) > 0 {
+		vendor, _, _ := strings.Cut(compatible[0], ",")
+		info.Manufacturer = vendor
+		if name, ok := boardVendors[vendor]; ok {
+			info.Manufacturer = name
+		}
+	}
+	fields := cpuinfoBoardFields()
+	if info.SerialNumber == "" {
+		if serial := readDeviceTree("serial-number"); len(serial) > 0 {
+			info.SerialNumber = serial[0]
+		} else {
+			info.SerialNumber = fields["Serial"]
+		}
+	}
+	if strings.Trim(info.SerialNumber, "0") == "" {
+		info.SerialNumber = ""
+	}
+	info.HardwareRevision = fields["Revision"]
+	Log.Debugf("Board identified from the device tree: %s", info.Model)
+}
+
+// cpuinfoBoardFields returns the board section of /proc/cpuinfo on ARM
+// (Hardware, Revision, Serial, Model), printed after the processors
+func cpuinfoBoardFields() map[string]string {
+	fields := make(map[string]string)
+	f, err := os.Open(filepath.Join(procRoot, "cpuinfo"))
+	if err != nil {
+		return fields
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch key = strings.TrimSpace(key); key {
+		case "Hardware", "Revision", "Serial", "Model":
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,175 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Addressing methods of the primary interface
+const (
+	AddressingDHCP   = "dhcp"
+	AddressingStatic = "static"
+)
+
+// AddressingInfo tells how the primary interface got its address
+type AddressingInfo struct {
+	Interface    string `json:"interface"`
+	Method       string `json:"method"` // dhcp or static
+	DHCPServer   string `json:"dhcp_server,omitempty"`
+	LeaseExpires string `json:"lease_expires,omitempty"` // RFC3339
+}
+
+// dhcpLease is the part of a DHCP lease reported
+type dhcpLease struct {
+	Address string
+	Server  string
+	Expires time.Time
+}
+
+// addressingOfLease reports a lease as DHCP addressing
+func addressingOfLease(name string, lease *dhcpLease) *AddressingInfo {
+	info := &AddressingInfo{Interface: name, Method: AddressingDHCP, DHCPServer: lease.Server}
+	if !lease.Expires.IsZero() {
+		info.LeaseExpires = lease.Expires.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// parseKeyValues parses KEY=VALUE lines, skipping comments and sections
+func parseKeyValues(data string) map[string]string {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == '[' {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// parseNMDeviceLease parses the [dhcp4] section of a NetworkManager device
+// state file (/run/NetworkManager/devices/<ifindex>); nil without a lease
+func parseNMDeviceLease(data string) *dhcpLease {
+	var section strings.Builder
+	inDHCP := false
+	for _, line := range strings.Split(data, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[") {
+			inDHCP = trimmed == "[dhcp4]"
+			continue
+		}
+		if inDHCP {
+			section.WriteString(line + "\n")
+		}
+	}
+	values := parseKeyValues(section.String())
+	if values["ip_address"] == "" && values["dhcp_server_identifier"] == "" {
+		return nil
+	}
+	lease := &dhcpLease{Address: values["ip_address"], Server: values["dhcp_server_identifier"]}
+	if expiry, err := strconv.ParseInt(values["expiry"], 10, 64); err == nil && expiry > 0 {
+		lease.Expires = time.Unix(expiry, 0)
+	}
+	return lease
+}
+
+// parseNetworkdLease parses a systemd-networkd lease
+// (/run/systemd/netif/leases/<ifindex>); the lifetime counts from modified,
+// when the lease was written
+func parseNetworkdLease(data string, modified time.Time) *dhcpLease {
+	values := parseKeyValues(data)
+	if values["ADDRESS"] == "" {
+		return nil
+	}
+	lease := &dhcpLease{Address: values["ADDRESS"], Server: values["SERVER_ADDRESS"]}
+	if lifetime, err := strconv.ParseInt(values["LIFETIME"], 10, 64); err == nil && lifetime > 0 && !modified.IsZero() {
+		lease.Expires = modified.Add(time.Duration(lifetime) * time.Second)
+	}
+	return lease
+}
+
+// parseDhclientLeases returns the last lease of an interface in a dhclient
+// leases file, the one in use; nil when there is none
+func parseDhclientLeases(data, name string) *dhcpLease {
+	var last, current *dhcpLease
+	currentName := ""
+	for _, line := range strings.Split(data, "\n") {
+		// statements end with ";", possibly followed by a comment
+		line, _, _ = strings.Cut(strings.TrimSpace(line), ";")
+		switch {
+		case strings.HasPrefix(line, "lease {"):
+			current, currentName = &dhcpLease{}, ""
+		case line == "}":
+			if current != nil && currentName == name && current.Address != "" {
+				last = current
+			}
+			current = nil
+		case current == nil:
+		case strings.HasPrefix(line, "interface "):
+			currentName = strings.Trim(strings.TrimPrefix(line, "interface "), `"`)
+		case strings.HasPrefix(line, "fixed-address "):
+			current.Address = strings.TrimPrefix(line, "fixed-address ")
+		case strings.HasPrefix(line, "option dhcp-server-identifier "):
+			current.Server = strings.TrimPrefix(line, "option dhcp-server-identifier ")
+		case strings.HasPrefix(line, "expire "):
+			current.Expires = parseDhclientTime(strings.TrimPrefix(line, "expire "))
+		}
+	}
+	return last
+}
+
+// parseDhclientTime parses a dhclient lease time, "4 2026/10/15 21:03:12"
+// in UTC or "epoch 1760562192", and zero for "never"
+func parseDhclientTime(value string) time.Time {
+	fields := strings.Fields(value)
+	if len(fields) >= 2 && fields[0] == "epoch" {
+		if seconds, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+		return time.Time{}
+	}
+	if len(fields) < 3 {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006/01/02 15:04:05", fields[1]+" "+fields[2])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// parseDHCPPacket parses `ipconfig getpacket <interface>` on macOS, which
+// prints the last DHCP reply; nil when the interface is not using DHCP
+func parseDHCPPacket(output string) *dhcpLease {
+	var lease *dhcpLease
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case key == "yiaddr":
+			if lease == nil {
+				lease = &dhcpLease{}
+			}
+			lease.Address = value
+		case strings.HasPrefix(key, "server_identifier"):
+			if lease == nil {
+				lease = &dhcpLease{}
+			}
+			lease.Server = value
+		}
+	}
+	return lease
+}
@@ -0,0 +1,21 @@
+//go:build darwin
+
+package internal
+
+import "context"
+
+// getAddressing asks ipconfig for the last DHCP reply of the interface;
+// without one the address was configured manually. macOS does not expose
+// when the lease was obtained, so its expiry is not reported.
+func getAddressing(_ context.Context, name, _ string) *AddressingInfo {
+	output, err := runCommand("ipconfig", "getpacket", name)
+	if err != nil || len(output) == 0 {
+		// ipconfig exits non-zero when the interface has no DHCP packet
+		return &AddressingInfo{Interface: name, Method: AddressingStatic}
+	}
+	lease := parseDHCPPacket(string(output))
+	if lease == nil {
+		return &AddressingInfo{Interface: name, Method: AddressingStatic}
+	}
+	return addressingOfLease(name, lease)
+}
@@ -0,0 +1,138 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// dhclientLeaseGlobs are where dhclient keeps leases on Debian, Red Hat and
+// under NetworkManager
+var dhclientLeaseGlobs = []string{
+	"var/lib/dhcp/dhclient*.leases",
+	"var/lib/dhclient/*.lease*",
+	"var/lib/NetworkManager/dhclient-*.lease",
+}
+
+// addressLifetime reports whether the kernel holds ip as a dynamic address
+// and until when; a test seam
+var addressLifetime = netlinkAddressLifetime
+
+// getAddressing tells whether the interface address came from DHCP, from
+// the lease of NetworkManager, systemd-networkd or dhclient, or else from
+// the kernel, where addresses added by a DHCP client expire
+func getAddressing(_ context.Context, name, ip string) *AddressingInfo {
+	if lease := findDHCPLease(name, ip); lease != nil {
+		return addressingOfLease(name, lease)
+	}
+	dynamic, expires, found := addressLifetime(ip)
+	if !found {
+		Log.Debugf("Address %s of %s not found; addressing not collected", ip, name)
+		return nil
+	}
+	if !dynamic {
+		return &AddressingInfo{Interface: name, Method: AddressingStatic}
+	}
+	return addressingOfLease(name, &dhcpLease{Address: ip, Expires: expires})
+}
+
+// findDHCPLease looks for the lease of the interface holding ip
+func findDHCPLease(name, ip string) *dhcpLease {
+	matches := func(lease *dhcpLease) bool {
+		return lease != nil && (lease.Address == "" || lease.Address == ip)
+	}
+	if index := readSysfsValue(sysfsRoot, "class", "net", name, "ifindex"); index != "" {
+		if data, err := os.ReadFile(filepath.Join(rootDir, "run", "NetworkManager", "devices", index)); err == nil {
+			if lease := parseNMDeviceLease(string(data)); matches(lease) {
+				return lease
+			}
+		}
+		path := filepath.Join(rootDir, "run", "systemd", "netif", "leases", index)
+		if data, err := os.ReadFile(path); err == nil {
+			var modified time.Time
+			if fi, err := os.Stat(path); err == nil {
+				modified = fi.ModTime()
+			}
+			if lease := parseNetworkdLease(string(data), modified); matches(lease) {
+				return lease
+			}
+		}
+	}
+	for _, pattern := range dhclientLeaseGlobs {
+		paths, _ := filepath.Glob(filepath.Join(rootDir, pattern))
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if lease := parseDhclientLeases(string(data), name); matches(lease) {
+				return lease
+			}
+		}
+	}
+	return nil
+}
+
+// netlinkAddressLifetime finds ip among the kernel IPv4 addresses: one
+// without IFA_F_PERMANENT was added with a lifetime, by a DHCP client
+func netlinkAddressLifetime(ip string) (dynamic bool, expires time.Time, found bool) {
+	rib, err := syscall.NetlinkRIB(unix.RTM_GETADDR, unix.AF_INET)
+	if err != nil {
+		Log.Debugf("Error to read addresses from netlink: %v", err)
+		return false, time.Time{}, false
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return false, time.Time{}, false
+	}
+	for _, msg := range msgs {
+		if msg.Header.Type != unix.RTM_NEWADDR || len(msg.Data) < unix.SizeofIfAddrmsg {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(&msg)
+		if err != nil {
+			continue
+		}
+		flags := uint32(msg.Data[2])
+		var local, address string
+		var valid uint32
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case unix.IFA_LOCAL:
+				local = net.IP(attr.Value).String()
+			case unix.IFA_ADDRESS:
+				address = net.IP(attr.Value).String()
+			case unix.IFA_FLAGS:
+				if len(attr.Value) >= 4 {
+					flags = binary.NativeEndian.Uint32(attr.Value)
+				}
+			case unix.IFA_CACHEINFO:
+				if len(attr.Value) >= unix.SizeofIfaCacheinfo {
+					valid = binary.NativeEndian.Uint32(attr.Value[4:8])
+				}
+			}
+		}
+		if local == "" {
+			local = address
+		}
+		if local != ip {
+			continue
+		}
+		if flags&unix.IFA_F_PERMANENT != 0 {
+			return false, time.Time{}, true
+		}
+		if valid != 0 && valid != ^uint32(0) {
+			expires = time.Now().Add(time.Duration(valid) * time.Second).Truncate(time.Second)
+		}
+		return true, expires, true
+	}
+	return false, time.Time{}, false
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNMDeviceLease(t *testing.T) {
+	data := "[device]\nmanaged=true\n\n[dhcp4]\ndhcp_lease_time=86400\ndhcp_server_identifier=10.20.0.1\nexpiry=1790000000\nip_address=10.20.1.15\n\n[dhcp6]\nip_address=2001:db8::15\n"
+	lease := parseNMDeviceLease(data)
+	if lease == nil || lease.Address != "10.20.1.15" || lease.Server != "10.20.0.1" || !lease.Expires.Equal(time.Unix(1790000000, 0)) {
+		t.Errorf("lease = %+v", lease)
+	}
+	if lease := parseNMDeviceLease("[device]\nmanaged=true\n"); lease != nil {
+		t.Errorf("lease without dhcp4 = %+v, want nil", lease)
+	}
+}
+
+func TestParseNetworkdLease(t *testing.T) {
+	modified := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	lease := parseNetworkdLease("# This is private data. Do not parse.\nADDRESS=192.168.1.50\nNETMASK=255.255.255.0\nSERVER_ADDRESS=192.168.1.1\nLIFETIME=3600\n", modified)
+	if lease == nil || lease.Address != "192.168.1.50" || lease.Server != "192.168.1.1" || !lease.Expires.Equal(modified.Add(time.Hour)) {
+		t.Errorf("lease = %+v", lease)
+	}
+}
+
+func TestParseDhclientLeases(t *testing.T) {
+	data := `lease {
+  interface "eth0";
+  fixed-address 10.0.0.4;
+  option dhcp-server-identifier 10.0.0.1;
+  expire 3 2026/10/14 09:00:00;
+}
+lease {
+  interface "eth1";
+  fixed-address 172.16.0.9;
+  option dhcp-server-identifier 172.16.0.1;
+  expire 4 2026/10/15 09:00:00;
+}
+lease {
+  interface "eth0";
+  fixed-address 10.0.0.5;
+  option subnet-mask 255.255.255.0;
+  option dhcp-server-identifier 10.0.0.2;
+  renew 4 2026/10/15 21:03:12;
+  expire epoch 1792191792; # Fri Oct 16 23:03:12 2026
+}
+`
+	lease := parseDhclientLeases(data, "eth0")
+	if lease == nil || lease.Address != "10.0.0.5" || lease.Server != "10.0.0.2" || !lease.Expires.Equal(time.Unix(1792191792, 0)) {
+		t.Errorf("eth0 lease = %+v", lease)
+	}
+	lease = parseDhclientLeases(data, "eth1")
+	if lease == nil || !lease.Expires.Equal(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("eth1 lease = %+v", lease)
+	}
+	if lease := parseDhclientLeases(data, "eth2"); lease != nil {
+		t.Errorf("eth2 lease = %+v, want nil", lease)
+	}
+}
+
+func TestParseDHCPPacket(t *testing.T) {
+	output := `op = BOOTREPLY
+htype = 1
+ciaddr = 0.0.0.0
+yiaddr = 192.168.1.100
+siaddr = 192.168.1.1
+options:
+Options count is 3
+dhcp_message_type (uint8): ACK 0x5
+server_identifier (ip): 192.168.1.1
+lease_time (uint32): 0x15180
+`
+	lease := parseDHCPPacket(output)
+	if lease == nil || lease.Address != "192.168.1.100" || lease.Server != "192.168.1.1" {
+		t.Errorf("lease = %+v", lease)
+	}
+	if lease := parseDHCPPacket(""); lease != nil {
+		t.Errorf("empty output = %+v, want nil", lease)
+	}
+}
+
+func TestAddressingOfLease(t *testing.T) {
+	info := addressingOfLease("eth0", &dhcpLease{Server: "10.0.0.1", Expires: time.Unix(1790000000, 0)})
+	want := AddressingInfo{Interface: "eth0", Method: AddressingDHCP, DHCPServer: "10.0.0.1", LeaseExpires: "2026-09-21T14:13:20Z"}
+	if *info != want {
+		t.Errorf("addressing = %+v, want %+v", *info, want)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/StackExchange/wmi"
+)
+
+// getAddressing reads the DHCP settings of the adapter holding ip
+func getAddressing(ctx context.Context, name, ip string) *AddressingInfo {
+	type adapterConfiguration struct {
+		IPAddress        []string
+		DHCPEnabled      bool
+		DHCPServer       *string
+		DHCPLeaseExpires *time.Time
+	}
+	var configs []adapterConfiguration
+	q := wmi.CreateQuery(&configs, "WHERE IPEnabled = TRUE", "Win32_NetworkAdapterConfiguration")
+	if err := wmiQuery(q, &configs, ""); errors.Is(err, errWMIDisabled) {
+		Log.Debug("WMI disabled; addressing not collected")
+		return nil
+	} else if err != nil {
+		Log.Warnf("Error to query Win32_NetworkAdapterConfiguration: %v", err)
+		addWarning(ctx, WarnWMIUnavailable, "Win32_NetworkAdapterConfiguration query failed: %v", err)
+		return nil
+	}
+	for _, c := range configs {
+		if !containsString(c.IPAddress, ip) {
+			continue
+		}
+		if !c.DHCPEnabled {
+			return &AddressingInfo{Interface: name, Method: AddressingStatic}
+		}
+		lease := &dhcpLease{Address: ip}
+		if c.DHCPServer != nil {
+			lease.Server = *c.DHCPServer
+		}
+		if c.DHCPLeaseExpires != nil {
+			lease.Expires = *c.DHCPLeaseExpires
+		}
+		return addressingOfLease(name, lease)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// agentDirs are the directories where the agent may persist data:
+// State holds data that must survive restarts (identity, spool, history),
+// Config holds administrator-provided settings and Cache holds data that
+// can be safely deleted
+type agentDirs struct {
+	State  string
+	Config string
+	Cache  string
+}
+
+// defaultDirs returns the system-wide directories when running privileged
+// (service, root, elevated) and per-user directories otherwise, so
+// interactive runs work without administrator rights
+func defaultDirs() agentDirs {
+	if isPrivileged() {
+		return systemDirs()
+	}
+	return userDirs()
+}
+
+// ensurePrivateDir creates dir if needed and restricts it so that only the
+// agent and administrators can change its content
+func ensurePrivateDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create directory %s: %w", dir, err)
+	}
+	if err := restrictDir(dir); err != nil {
+		return fmt.Errorf("restrict permissions of %s: %w", dir, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file in a private directory through a temporary
+// file and a rename, so readers never see a partial file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := ensurePrivateDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// statePath returns the location of a file in the state directory
+func statePath(name string) string {
+	return filepath.Join(Cfg.StateDir, name)
+}
+
+// PrepareDirs creates the state, config and cache directories with
+// restricted permissions (owned by root when running as root)
+func PrepareDirs() error {
+	for _, dir := range []string{Cfg.StateDir, Cfg.ConfigDir, Cfg.CacheDir} {
+		if err := ensurePrivateDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+//go:build darwin
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// systemDirs uses the local domain of the macOS Library
+func systemDirs() agentDirs {
+	return agentDirs{
+		State:  "/Library/Application Support/TatuScan",
+		Config: "/Library/Preferences/TatuScan",
+		Cache:  "/Library/Caches/TatuScan",
+	}
+}
+
+// userDirs uses the user domain of the macOS Library
+func userDirs() agentDirs {
+	home, _ := os.UserHomeDir()
+	library := filepath.Join(home, "Library")
+	return agentDirs{
+		State:  filepath.Join(library, "Application Support", "TatuScan"),
+		Config: filepath.Join(library, "Preferences", "TatuScan"),
+		Cache:  filepath.Join(library, "Caches", "TatuScan"),
+	}
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// systemDirs follows the FHS locations for system services
+func systemDirs() agentDirs {
+	return agentDirs{
+		State:  "/var/lib/tatuscan",
+		Config: "/etc/tatuscan",
+		Cache:  "/var/cache/tatuscan",
+	}
+}
+
+// userDirs follows the XDG base directory specification
+func userDirs() agentDirs {
+	home, _ := os.UserHomeDir()
+	state := os.Getenv("XDG_STATE_HOME")
+	if state == "" {
+		state = filepath.Join(home, ".local", "state")
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		config = filepath.Join(home, ".config")
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		cache = filepath.Join(home, ".cache")
+	}
+	return agentDirs{
+		State:  filepath.Join(state, "tatuscan"),
+		Config: filepath.Join(config, "tatuscan"),
+		Cache:  filepath.Join(cache, "tatuscan"),
+	}
+}
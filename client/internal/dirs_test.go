@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomicCreatesPrivateDir(t *testing.T) {
+	setupTestAgent(t)
+	path := filepath.Join(t.TempDir(), "state", "nested", "data.json")
+	if err := writeFileAtomic(path, []byte(`{"a":1}`), 0o640); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte(`{"a":2}`), 0o640); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != `{"a":2}` {
+		t.Fatalf("content = %q, %v", data, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Dir(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm&0o022 != 0 {
+			t.Errorf("directory is group/world writable: %v", perm)
+		}
+	}
+}
+
+func TestLoadConfigDirs(t *testing.T) {
+	setupTestAgent(t)
+	cfg := loadConfig(map[string]string{"TATUSCAN_CACHE_DIR": " /tmp/tatuscan-cache "})
+	if cfg.CacheDir != "/tmp/tatuscan-cache" {
+		t.Errorf("CacheDir = %q", cfg.CacheDir)
+	}
+	defaults := defaultDirs()
+	if cfg.StateDir != defaults.State || cfg.ConfigDir != defaults.Config {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+	if defaults.State == "" || defaults.Config == "" || defaults.Cache == "" {
+		t.Errorf("empty default directory: %+v", defaults)
+	}
+}
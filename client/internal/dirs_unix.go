@@ -0,0 +1,29 @@
+//go:build linux || darwin
+
+package internal
+
+import "os"
+
+// isPrivileged reports whether the agent runs as root
+func isPrivileged() bool {
+	return os.Geteuid() == 0
+}
+
+// restrictDir removes group and world write permission from dir and, when
+// running as root, makes root its owner (it may have been created by an
+// unprivileged run or another tool)
+func restrictDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if isPrivileged() {
+		if err := os.Chown(dir, 0, 0); err != nil {
+			return err
+		}
+	}
+	if perm := info.Mode().Perm(); perm&0o022 != 0 {
+		return os.Chmod(dir, perm&^0o022)
+	}
+	return nil
+}
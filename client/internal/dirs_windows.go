@@ -0,0 +1,66 @@
+//go:build windows
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// privateDirSDDL grants full control to SYSTEM and Administrators and read
+// access to Users, without inheriting permissions from the parent
+const privateDirSDDL = "D:PAI(A;OICI;FA;;;SY)(A;OICI;FA;;;BA)(A;OICI;FRFX;;;BU)"
+
+// isPrivileged reports whether the agent runs as SYSTEM or elevated
+func isPrivileged() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// systemDirs lives under %ProgramData%\TatuScan
+func systemDirs() agentDirs {
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	root := filepath.Join(programData, "TatuScan")
+	return agentDirs{
+		State:  root,
+		Config: filepath.Join(root, "config"),
+		Cache:  filepath.Join(root, "cache"),
+	}
+}
+
+// userDirs lives under %LocalAppData%\TatuScan
+func userDirs() agentDirs {
+	local := os.Getenv("LocalAppData")
+	if local == "" {
+		home, _ := os.UserHomeDir()
+		local = filepath.Join(home, "AppData", "Local")
+	}
+	root := filepath.Join(local, "TatuScan")
+	return agentDirs{
+		State:  root,
+		Config: filepath.Join(root, "config"),
+		Cache:  filepath.Join(root, "cache"),
+	}
+}
+
+// restrictDir replaces the DACL of a system directory with privateDirSDDL;
+// per-user directories keep the user's inherited permissions
+func restrictDir(dir string) error {
+	if !isPrivileged() {
+		return nil
+	}
+	sd, err := windows.SecurityDescriptorFromString(privateDirSDDL)
+	if err != nil {
+		return err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(dir, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, dacl, nil)
+}
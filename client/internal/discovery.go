@@ -0,0 +1,283 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// discoveryService is the DNS-SD service type announced by the server
+	discoveryService = "_tatuscan._tcp"
+	// mdnsTimeout is how long mDNS answers are collected
+	mdnsTimeout = 2 * time.Second
+)
+
+// mdnsGroup is the IPv4 mDNS multicast address (RFC 6762)
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// DNS record types used by DNS-SD
+const (
+	dnsTypeA   = 1
+	dnsTypePTR = 12
+	dnsTypeTXT = 16
+	dnsTypeSRV = 33
+)
+
+// discoveredService is a server instance found through DNS-SD
+type discoveredService struct {
+	Target string // host name from the SRV record
+	Port   uint16
+	Addr   net.IP   // address of the target, when announced with it
+	TXT    []string // key=value entries (path, scheme)
+}
+
+// DiscoverServer looks for a server announced as _tatuscan._tcp when
+// TATUSCAN_URL is not set: first an SRV record in the DNS search domains,
+// then DNS-SD over mDNS on the local link. It returns the server URL, or
+// an empty string when discovery is disabled or found nothing.
+func DiscoverServer(ctx context.Context) string {
+	if !Cfg.Discovery {
+		return ""
+	}
+	svc, err := lookupDNSService(ctx)
+	if err == nil {
+		Log.Infof("Server discovered in DNS: %s", svc.url())
+		return svc.url()
+	}
+	Log.Debugf("No %s record in DNS: %v", discoveryService, err)
+	services, err := queryMDNS(ctx, mdnsTimeout)
+	if err != nil {
+		Log.Debugf("Error to query mDNS: %v", err)
+	}
+	for _, svc := range services {
+		if svc.Port != 0 {
+			Log.Infof("Server discovered with mDNS: %s", svc.url())
+			return svc.url()
+		}
+	}
+	return ""
+}
+
+// url builds the server base URL from the service and its TXT entries:
+// scheme=https switches to TLS and path= adds a base path
+func (s discoveredService) url() string {
+	scheme, path := "http", ""
+	for _, entry := range s.TXT {
+		key, value, _ := strings.Cut(entry, "=")
+		switch strings.ToLower(key) {
+		case "scheme":
+			if strings.EqualFold(value, "https") {
+				scheme = "https"
+			}
+		case "path":
+			path = "/" + strings.Trim(value, "/")
+		}
+	}
+	host := strings.TrimSuffix(s.Target, ".")
+	if s.Addr != nil {
+		host = s.Addr.String()
+	}
+	u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(s.Port))), Path: path}
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// lookupDNSService reads the SRV and TXT records of _tatuscan._tcp, the
+// relative name letting the resolver apply the DNS search domains
+func lookupDNSService(ctx context.Context) (discoveredService, error) {
+	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, "", "", discoveryService)
+	if err != nil {
+		return discoveredService{}, err
+	}
+	if len(addrs) == 0 {
+		return discoveredService{}, errors.New("no SRV record")
+	}
+	svc := discoveredService{Target: addrs[0].Target, Port: addrs[0].Port}
+	svc.TXT, _ = net.DefaultResolver.LookupTXT(ctx, discoveryService)
+	return svc, nil
+}
+
+// queryMDNS sends a PTR query for _tatuscan._tcp.local and collects the
+// answers until timeout. The query asks for unicast replies (QU bit), so
+// responders answer to this socket.
+func queryMDNS(ctx context.Context, timeout time.Duration) ([]discoveredService, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteTo(buildMDNSQuery(discoveryService+".local", dnsTypePTR), mdnsGroup); err != nil {
+		return nil, err
+	}
+
+	var services []discoveredService
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return services, nil
+			}
+			return services, err
+		}
+		found, err := parseMDNSResponse(buf[:n])
+		if err != nil {
+			Log.Debugf("Ignoring invalid mDNS answer: %v", err)
+			continue
+		}
+		services = append(services, found...)
+	}
+}
+
+// buildMDNSQuery encodes a single-question DNS query with the QU bit set
+func buildMDNSQuery(name string, qtype uint16) []byte {
+	msg := make([]byte, 12, 64)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+	for _, label := range strings.Split(strings.Trim(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, qtype)
+	return binary.BigEndian.AppendUint16(msg, 0x8000|1) // QU, class IN
+}
+
+// dnsRecord is a resource record of a DNS message
+type dnsRecord struct {
+	Name  string
+	Type  uint16
+	Data  []byte
+	Start int // offset of Data in the message, for compressed names
+}
+
+// parseMDNSResponse extracts the _tatuscan._tcp instances of an mDNS
+// response, joining the PTR, SRV, TXT and A records of all its sections
+func parseMDNSResponse(msg []byte) ([]discoveredService, error) {
+	if len(msg) < 12 {
+		return nil, errors.New("short message")
+	}
+	if msg[2]&0x80 == 0 {
+		return nil, nil // a query from another host
+	}
+	qdcount := int(binary.BigEndian.Uint16(msg[4:]))
+	rrcount := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) + int(binary.BigEndian.Uint16(msg[10:]))
+
+	off := 12
+	for i := 0; i < qdcount; i++ {
+		_, next, err := readDNSName(msg, off)
+		if err != nil || next+4 > len(msg) {
+			return nil, errors.New("truncated question")
+		}
+		off = next + 4
+	}
+	records := make([]dnsRecord, 0, rrcount)
+	for i := 0; i < rrcount; i++ {
+		name, next, err := readDNSName(msg, off)
+		if err != nil || next+10 > len(msg) {
+			return nil, errors.New("truncated record")
+		}
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		start := next + 10
+		if start+length > len(msg) {
+			return nil, errors.New("truncated record data")
+		}
+		records = append(records, dnsRecord{Name: strings.ToLower(name), Type: binary.BigEndian.Uint16(msg[next:]), Data: msg[start : start+length], Start: start})
+		off = start + length
+	}
+
+	var services []discoveredService
+	for _, ptr := range records {
+		if ptr.Type != dnsTypePTR || ptr.Name != discoveryService+".local." {
+			continue
+		}
+		instance, _, err := readDNSName(msg, ptr.Start)
+		if err != nil {
+			continue
+		}
+		instance = strings.ToLower(instance)
+		var svc discoveredService
+		for _, r := range records {
+			switch {
+			case r.Name == instance && r.Type == dnsTypeSRV && len(r.Data) >= 7:
+				svc.Port = binary.BigEndian.Uint16(r.Data[4:])
+				svc.Target, _, _ = readDNSName(msg, r.Start+6)
+			case r.Name == instance && r.Type == dnsTypeTXT:
+				svc.TXT = parseTXT(r.Data)
+			}
+		}
+		for _, r := range records {
+			if r.Type == dnsTypeA && len(r.Data) == 4 && svc.Target != "" && r.Name == strings.ToLower(svc.Target) {
+				svc.Addr = net.IP(append([]byte(nil), r.Data...))
+			}
+		}
+		services = append(services, svc)
+	}
+	return services, nil
+}
+
+// readDNSName decodes a possibly compressed name at off, returning it with
+// a trailing dot and the offset after it in the message
+func readDNSName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errors.New("name out of bounds")
+		}
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case length&0xc0 == 0xc0:
+			if off+1 >= len(msg) || jumps > 10 {
+				return "", 0, errors.New("invalid name pointer")
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+			jumps++
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errors.New("label out of bounds")
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// parseTXT splits TXT record data into its length-prefixed strings
+func parseTXT(data []byte) []string {
+	var entries []string
+	for len(data) > 0 {
+		n := int(data[0])
+		if 1+n > len(data) {
+			break
+		}
+		if n > 0 {
+			entries = append(entries, string(data[1:1+n]))
+		}
+		data = data[1+n:]
+	}
+	return entries
+}
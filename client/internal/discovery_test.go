@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// dnsName encodes a name without compression
+func dnsName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.Trim(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// dnsRR encodes a resource record with an already encoded owner name
+func dnsRR(owner []byte, rrtype uint16, data []byte) []byte {
+	b := append([]byte(nil), owner...)
+	b = binary.BigEndian.AppendUint16(b, rrtype)
+	b = binary.BigEndian.AppendUint16(b, 0x8001) // cache flush, IN
+	b = binary.BigEndian.AppendUint32(b, 120)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+	return append(b, data...)
+}
+
+func TestParseMDNSResponse(t *testing.T) {
+	msg := []byte{0, 0, 0x84, 0, 0, 0, 0, 1, 0, 0, 0, 3} // response, 1 answer, 3 additional
+	ptrOwner := len(msg)
+	msg = append(msg, dnsRR(dnsName("_tatuscan._tcp.local"), dnsTypePTR, dnsName("Lab Server._tatuscan._tcp.local"))...)
+
+	// The instance name points back into the PTR owner (compression)
+	instance := append([]byte{10}, "Lab Server"...)
+	instance = append(instance, 0xc0|byte(ptrOwner>>8), byte(ptrOwner))
+	srv := []byte{0, 0, 0, 0, 0x1f, 0x90} // priority, weight, port 8080
+	srv = append(srv, dnsName("lab-server.local")...)
+	msg = append(msg, dnsRR(instance, dnsTypeSRV, srv)...)
+	msg = append(msg, dnsRR(instance, dnsTypeTXT, append([]byte{10}, "path=/inv/"...))...)
+	msg = append(msg, dnsRR(dnsName("lab-server.local"), dnsTypeA, []byte{192, 168, 10, 5})...)
+
+	services, err := parseMDNSResponse(msg)
+	if err != nil || len(services) != 1 {
+		t.Fatalf("parseMDNSResponse = %+v, %v", services, err)
+	}
+	want := discoveredService{Target: "lab-server.local.", Port: 8080, Addr: net.IPv4(192, 168, 10, 5).To4(), TXT: []string{"path=/inv/"}}
+	if !reflect.DeepEqual(services[0], want) {
+		t.Errorf("service = %+v, want %+v", services[0], want)
+	}
+	if got := services[0].url(); got != "http://192.168.10.5:8080/inv" {
+		t.Errorf("url = %s", got)
+	}
+
+	// Queries seen on the multicast group are not answers
+	if services, _ := parseMDNSResponse(buildMDNSQuery("_tatuscan._tcp.local", dnsTypePTR)); services != nil {
+		t.Errorf("query parsed as answer: %+v", services)
+	}
+	if _, err := parseMDNSResponse(msg[:len(msg)-3]); err == nil {
+		t.Error("expected an error for a truncated message")
+	}
+}
+
+func TestDiscoveredServiceURL(t *testing.T) {
+	svc := discoveredService{Target: "inventory.school.example.", Port: 443, TXT: []string{"scheme=https"}}
+	if got := svc.url(); got != "https://inventory.school.example:443" {
+		t.Errorf("url = %s", got)
+	}
+}
@@ -0,0 +1,215 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+const (
+	// defaultDiskThreshold is the used percentage flagged as a breach
+	defaultDiskThreshold = 90.0
+	// diskHistoryFile keeps recent usage samples in the state directory
+	diskHistoryFile = "disk-history.json"
+	// diskSampleSpacing keeps one sample per hour whatever the interval
+	diskSampleSpacing = time.Hour
+	// diskHistoryWindow is how far back samples are kept for the trend
+	diskHistoryWindow = 14 * 24 * time.Hour
+	// diskForecastMinSpan avoids extrapolating from a few minutes of data
+	diskForecastMinSpan = 24 * time.Hour
+	// diskForecastMaxDays drops forecasts too far away to be meaningful
+	diskForecastMaxDays = 365
+)
+
+// pseudoFilesystems are never reported (memory, images, overlays)
+var pseudoFilesystems = map[string]bool{
+	"tmpfs": true, "devtmpfs": true, "squashfs": true, "overlay": true,
+	"iso9660": true, "udf": true, "autofs": true, "devfs": true,
+}
+
+// Disk is the usage of a mounted filesystem with its fill forecast
+type Disk struct {
+	Mount         string   `json:"mount"`
+	FSType        string   `json:"fstype,omitempty"`
+	TotalMB       uint64   `json:"total_mb"`
+	UsedMB        uint64   `json:"used_mb"`
+	UsedPercent   float64  `json:"used_percent"`
+	FullInDays    *float64 `json:"full_in_days,omitempty"`   // linear trend of the last two weeks
+	OverThreshold bool     `json:"over_threshold,omitempty"` // used_percent >= TATUSCAN_DISK_THRESHOLD
+}
+
+// diskSample is a usage measurement kept in the history file
+type diskSample struct {
+	Time int64  `json:"t"` // Unix seconds
+	Used uint64 `json:"used"`
+}
+
+// diskUsage is what the platform reports for a filesystem
+type diskUsage struct {
+	Mount  string
+	FSType string
+	Total  uint64
+	Used   uint64
+}
+
+// diskLister lists the mounted physical filesystems; replaced in tests
+var diskLister = func() ([]diskUsage, error) {
+	partitions, err := disk.Partitions(false)
+	if err != nil {
+		return nil, err
+	}
+	var usages []diskUsage
+	seen := map[string]bool{}
+	for _, p := range partitions {
+		if pseudoFilesystems[strings.ToLower(p.Fstype)] || seen[p.Mountpoint] || macOSSystemVolume(p.Mountpoint) {
+			continue
+		}
+		seen[p.Mountpoint] = true
+		usage, err := disk.Usage(p.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue // empty card readers and optical drives
+		}
+		usages = append(usages, diskUsage{Mount: p.Mountpoint, FSType: p.Fstype, Total: usage.Total, Used: usage.Used})
+	}
+	return usages, nil
+}
+
+// macOSSystemVolume reports the APFS helper volumes (VM, Preboot, Update)
+// mounted under /System/Volumes; the Data volume holds the user files
+func macOSSystemVolume(mount string) bool {
+	return strings.HasPrefix(mount, "/System/Volumes/") && mount != "/System/Volumes/Data"
+}
+
+// getDisks returns the filesystem usage when TATUSCAN_DISKS is on, with a
+// fill forecast computed from the samples kept in the state directory
+func getDisks() ([]Disk, error) {
+	if !Cfg.Disks {
+		return nil, nil
+	}
+	usages, err := diskLister()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	history := loadDiskHistory()
+	changed := false
+	var disks []Disk
+	for _, u := range usages {
+		samples, added := addDiskSample(history[u.Mount], u.Used, now)
+		history[u.Mount] = samples
+		changed = changed || added
+
+		percent := math.Round(float64(u.Used)/float64(u.Total)*1000) / 10
+		disks = append(disks, Disk{
+			Mount:         u.Mount,
+			FSType:        u.FSType,
+			TotalMB:       u.Total / (1024 * 1024),
+			UsedMB:        u.Used / (1024 * 1024),
+			UsedPercent:   percent,
+			FullInDays:    diskForecast(samples, u.Total),
+			OverThreshold: percent >= Cfg.DiskThreshold,
+		})
+	}
+	if changed {
+		// Mounts that are gone stop being tracked
+		for mount := range history {
+			if !containsDisk(disks, mount) {
+				delete(history, mount)
+			}
+		}
+		saveDiskHistory(history)
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Mount < disks[j].Mount })
+	return disks, nil
+}
+
+// containsDisk reports whether mount is among disks
+func containsDisk(disks []Disk, mount string) bool {
+	for _, d := range disks {
+		if d.Mount == mount {
+			return true
+		}
+	}
+	return false
+}
+
+// addDiskSample appends a sample when the last one is older than
+// diskSampleSpacing and drops samples outside the history window
+func addDiskSample(samples []diskSample, used uint64, now time.Time) ([]diskSample, bool) {
+	if n := len(samples); n > 0 && now.Sub(time.Unix(samples[n-1].Time, 0)) < diskSampleSpacing {
+		return samples, false
+	}
+	cutoff := now.Add(-diskHistoryWindow).Unix()
+	kept := samples[:0]
+	for _, s := range samples {
+		if s.Time >= cutoff {
+			kept = append(kept, s)
+		}
+	}
+	return append(kept, diskSample{Time: now.Unix(), Used: used}), true
+}
+
+// diskForecast fits a least-squares line to the samples and returns the days
+// until the used space reaches total, nil when usage is not growing or the
+// samples span less than a day
+func diskForecast(samples []diskSample, total uint64) *float64 {
+	n := float64(len(samples))
+	if len(samples) < 3 || time.Duration(samples[len(samples)-1].Time-samples[0].Time)*time.Second < diskForecastMinSpan {
+		return nil
+	}
+	// Times relative to the first sample keep the sums small
+	t0 := samples[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x, y := float64(s.Time-t0), float64(s.Used)
+		sumX, sumY, sumXY, sumXX = sumX+x, sumY+y, sumXY+x*y, sumXX+x*x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX) // bytes per second
+	if slope <= 0 || math.IsNaN(slope) {
+		return nil
+	}
+	last := samples[len(samples)-1]
+	free := float64(total) - float64(last.Used)
+	days := math.Max(free, 0) / slope / 86400
+	if days > diskForecastMaxDays {
+		return nil
+	}
+	days = math.Round(days*10) / 10
+	return &days
+}
+
+// loadDiskHistory reads the samples per mount, empty when absent or invalid
+func loadDiskHistory() map[string][]diskSample {
+	history := map[string][]diskSample{}
+	data, err := os.ReadFile(statePath(diskHistoryFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			Log.Warnf("Error to read disk history: %v", err)
+		}
+		return history
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		Log.Warnf("Ignoring invalid disk history %s", statePath(diskHistoryFile))
+		return map[string][]diskSample{}
+	}
+	return history
+}
+
+// saveDiskHistory persists the samples; a failure only loses the forecast
+func saveDiskHistory(history map[string][]diskSample) {
+	data, err := json.Marshal(history)
+	if err == nil {
+		err = writeFileAtomic(statePath(diskHistoryFile), data, 0o644)
+	}
+	if err != nil {
+		Log.Warnf("Error to persist disk history in %s: %v", Cfg.StateDir, err)
+	}
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+const gib = 1 << 30
+
+func TestDiskForecast(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+	growing := []diskSample{
+		{Time: start, Used: 80 * gib},
+		{Time: start + 86400, Used: 81 * gib},
+		{Time: start + 2*86400, Used: 82 * gib},
+	}
+	if got := diskForecast(growing, 100*gib); got == nil || *got != 18 {
+		t.Errorf("forecast = %v, want 18 days", got)
+	}
+
+	shrinking := []diskSample{{Time: start, Used: 82 * gib}, {Time: start + 86400, Used: 81 * gib}, {Time: start + 2*86400, Used: 80 * gib}}
+	if got := diskForecast(shrinking, 100*gib); got != nil {
+		t.Errorf("no forecast expected for shrinking usage, got %v", *got)
+	}
+	if got := diskForecast(growing[:2], 100*gib); got != nil {
+		t.Errorf("no forecast expected from two samples, got %v", *got)
+	}
+}
+
+func TestAddDiskSample(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	samples := []diskSample{
+		{Time: now.Add(-15 * 24 * time.Hour).Unix(), Used: 1}, // outside the window
+		{Time: now.Add(-2 * time.Hour).Unix(), Used: 2},
+	}
+	samples, added := addDiskSample(samples, 3, now)
+	if !added || len(samples) != 2 || samples[1].Used != 3 {
+		t.Fatalf("samples = %+v, added = %v", samples, added)
+	}
+	if _, added := addDiskSample(samples, 4, now.Add(10*time.Minute)); added {
+		t.Error("samples closer than the spacing must be skipped")
+	}
+}
+
+func TestGetDisks(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Disks, Cfg.DiskThreshold = true, 90
+	orig := diskLister
+	t.Cleanup(func() { diskLister = orig })
+	diskLister = func() ([]diskUsage, error) {
+		return []diskUsage{
+			{Mount: "/var", FSType: "xfs", Total: 100 * gib, Used: 95 * gib},
+			{Mount: "/", FSType: "ext4", Total: 50 * gib, Used: 10 * gib},
+		}, nil
+	}
+
+	// Seed a history growing 1 GiB per day on /var
+	now := time.Now()
+	saveDiskHistory(map[string][]diskSample{
+		"/var":  {{Time: now.Add(-72 * time.Hour).Unix(), Used: 92 * gib}, {Time: now.Add(-48 * time.Hour).Unix(), Used: 93 * gib}, {Time: now.Add(-24 * time.Hour).Unix(), Used: 94 * gib}},
+		"/mnt/": {{Time: now.Add(-2 * time.Hour).Unix(), Used: 1}},
+	})
+
+	disks, err := getDisks()
+	if err != nil || len(disks) != 2 {
+		t.Fatalf("getDisks = %+v, %v", disks, err)
+	}
+	root, data := disks[0], disks[1]
+	if root.Mount != "/" || root.UsedPercent != 20 || root.OverThreshold || root.FullInDays != nil {
+		t.Errorf("root = %+v", root)
+	}
+	if data.UsedMB != 95*1024 || !data.OverThreshold || data.FullInDays == nil || *data.FullInDays != 5 {
+		t.Errorf("/var = %+v (full in %v)", data, data.FullInDays)
+	}
+	if history := loadDiskHistory(); len(history["/var"]) != 4 || history["/mnt/"] != nil {
+		t.Errorf("history not updated: %+v", history)
+	}
+
+	Cfg.Disks = false
+	if disks, _ := getDisks(); disks != nil {
+		t.Errorf("expected nil when disabled, got %+v", disks)
+	}
+}
@@ -0,0 +1,94 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import "strings"
+
+// EndpointSecurity reports the health of the endpoint protection agents for
+// compliance dashboards
+type EndpointSecurity struct {
+	Defender  *DefenderStatus `json:"defender,omitempty"`
+	Antivirus []string        `json:"antivirus,omitempty"` // products registered in Windows Security Center
+	EDR       []EDRAgent      `json:"edr,omitempty"`
+	LSM       []LSMStatus     `json:"lsm,omitempty"` // SELinux and AppArmor (Linux)
+}
+
+// DefenderStatus is the state of Microsoft Defender Antivirus
+type DefenderStatus struct {
+	Enabled            bool   `json:"enabled"`
+	RealTimeProtection bool   `json:"real_time_protection"`
+	ProductVersion     string `json:"product_version,omitempty"`
+	EngineVersion      string `json:"engine_version,omitempty"`
+	SignatureVersion   string `json:"signature_version,omitempty"`
+	SignatureUpdated   string `json:"signature_updated,omitempty"`
+	SignatureAgeDays   int    `json:"signature_age_days"`
+}
+
+// EDRAgent is a configured third-party EDR service and whether it runs
+type EDRAgent struct {
+	Service string `json:"service"`
+	State   string `json:"state"` // service state, "not_found" when not installed
+	Running bool   `json:"running"`
+}
+
+// LSMStatus is the state of a Linux security module enforcing mandatory
+// access control
+type LSMStatus struct {
+	Name           string `json:"name"`                      // selinux or apparmor
+	Mode           string `json:"mode"`                      // enforcing, permissive or disabled
+	ConfiguredMode string `json:"configured_mode,omitempty"` // SELinux mode after the next boot
+	Policy         string `json:"policy,omitempty"`          // SELinux policy type (targeted, mls)
+	Enforced       *int   `json:"enforced_profiles,omitempty"`
+	Complain       *int   `json:"complain_profiles,omitempty"`
+}
+
+// Modes reported in LSMStatus.Mode
+const (
+	LSMEnforcing  = "enforcing"
+	LSMPermissive = "permissive"
+	LSMDisabled   = "disabled"
+)
+
+// getEndpointSecurity returns the Defender status when TATUSCAN_ENDPOINT_SECURITY
+// is set, the state of the TATUSCAN_EDR_SERVICES services and the Linux
+// security modules
+func getEndpointSecurity() *EndpointSecurity {
+	security := EndpointSecurity{LSM: platformLSM()}
+	if Cfg.EndpointSecurity {
+		defender, antivirus, err := platformDefender()
+		if err != nil {
+			Log.Warnf("Error to collect Defender status: %v", err)
+		}
+		security.Defender, security.Antivirus = defender, antivirus
+	}
+	if len(Cfg.EDRServices) > 0 {
+		all, err := platformServices()
+		if err != nil {
+			Log.Warnf("Error to check EDR services: %v", err)
+		} else {
+			security.EDR = edrAgents(all, Cfg.EDRServices)
+		}
+	}
+	if security.Defender == nil && security.Antivirus == nil && security.EDR == nil && security.LSM == nil {
+		return nil
+	}
+	return &security
+}
+
+// edrAgents reports each configured service, in configuration order
+func edrAgents(all []Service, names []string) []EDRAgent {
+	agents := make([]EDRAgent, 0, len(names))
+	for _, name := range names {
+		agent := EDRAgent{Service: name, State: "not_found"}
+		for _, s := range all {
+			if strings.EqualFold(s.Name, name) {
+				agent.State = s.State
+				// Windows reports "running", systemd the active state
+				agent.Running = s.State == "running" || s.State == "active"
+				break
+			}
+		}
+		agents = append(agents, agent)
+	}
+	return agents
+}
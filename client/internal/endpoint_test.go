@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEDRAgents(t *testing.T) {
+	all := []Service{
+		{Name: "CSFalconService", State: "running"},
+		{Name: "falcon-sensor", State: "active"},
+		{Name: "SentinelAgent", State: "stopped"},
+	}
+	got := edrAgents(all, []string{"csfalconservice", "falcon-sensor", "SentinelAgent", "CylanceSvc"})
+	want := []EDRAgent{
+		{Service: "csfalconservice", State: "running", Running: true},
+		{Service: "falcon-sensor", State: "active", Running: true},
+		{Service: "SentinelAgent", State: "stopped"},
+		{Service: "CylanceSvc", State: "not_found"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("edrAgents =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestGetEndpointSecurityDisabled(t *testing.T) {
+	setupTestAgent(t)
+	if got := getEndpointSecurity(); got != nil {
+		t.Errorf("expected nil when not configured, got %+v", got)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build linux || darwin
+
+package internal
+
+// platformDefender reports nothing: Defender status comes from WMI, only
+// available on Windows
+func platformDefender() (*DefenderStatus, []string, error) {
+	return nil, nil, nil
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package internal
+
+import (
+	"sort"
+	"time"
+
+	"github.com/StackExchange/wmi"
+)
+
+// platformDefender reads MSFT_MpComputerStatus and the antivirus products
+// registered in Security Center (absent on Windows Server)
+func platformDefender() (*DefenderStatus, []string, error) {
+	type mpComputerStatus struct {
+		AntivirusEnabled              bool
+		RealTimeProtectionEnabled     bool
+		AMProductVersion              string
+		AMEngineVersion               string
+		AntivirusSignatureVersion     string
+		AntivirusSignatureLastUpdated *time.Time
+		AntivirusSignatureAge         uint32
+	}
+	type antiVirusProduct struct {
+		DisplayName string
+	}
+
+	var products []antiVirusProduct
+	var antivirus []string
+	Log.Debug("Querying AntiVirusProduct via WMI")
+	if err := wmiQuery(wmi.CreateQuery(&products, "", "AntiVirusProduct"), &products, `root\SecurityCenter2`); err != nil {
+		Log.Debugf("Security Center not available: %v", err)
+	}
+	for _, p := range products {
+		antivirus = append(antivirus, p.DisplayName)
+	}
+	sort.Strings(antivirus)
+
+	Log.Debug("Querying MSFT_MpComputerStatus via WMI")
+	var status []mpComputerStatus
+	if err := wmiQuery(wmi.CreateQuery(&status, "", "MSFT_MpComputerStatus"), &status, `root\Microsoft\Windows\Defender`); err != nil {
+		return nil, antivirus, err
+	}
+	if len(status) == 0 {
+		return nil, antivirus, nil
+	}
+	s := status[0]
+	defender := &DefenderStatus{
+		Enabled:            s.AntivirusEnabled,
+		RealTimeProtection: s.RealTimeProtectionEnabled,
+		ProductVersion:     s.AMProductVersion,
+		EngineVersion:      s.AMEngineVersion,
+		SignatureVersion:   s.AntivirusSignatureVersion,
+		SignatureAgeDays:   int(s.AntivirusSignatureAge),
+	}
+	if s.AntivirusSignatureLastUpdated != nil {
+		defender.SignatureUpdated = s.AntivirusSignatureLastUpdated.UTC().Format(time.RFC3339)
+	}
+	return defender, antivirus, nil
+}
@@ -0,0 +1,154 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// exporters render a payload for tools coexisting with TatuScan, keyed by
+// the name given to `tatuscan export -format`
+var exporters = map[string]func(info MachineInfo) any{
+	"json":    func(info MachineInfo) any { return info },
+	"ansible": ansibleFacts,
+	"sccm":    sccmInventory,
+}
+
+// ExportFormats lists the formats accepted by Export
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for name := range exporters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Export converts a payload to the document of format, ready for JSON
+// encoding
+func Export(info MachineInfo, format string) (any, error) {
+	exporter, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (supported: %s)", format, strings.Join(ExportFormats(), ", "))
+	}
+	return exporter(info), nil
+}
+
+// ansibleSystems maps GOOS to the ansible_system fact
+var ansibleSystems = map[string]string{"linux": "Linux", "windows": "Win32NT", "darwin": "Darwin"}
+
+// ansibleFacts renders the payload with the names of the Ansible setup
+// facts, for a JSON fact cache or a facts.d script; fields without an
+// Ansible counterpart are kept under tatuscan_*
+func ansibleFacts(info MachineInfo) any {
+	facts := map[string]any{
+		"ansible_hostname":           info.Hostname,
+		"ansible_fqdn":               stringOr(info.FQDN, info.Hostname),
+		"ansible_system":             ansibleSystems[info.OS],
+		"ansible_memtotal_mb":        info.MemoryTotalMB,
+		"ansible_memfree_mb":         info.MemoryTotalMB - min(info.MemoryUsedMB, info.MemoryTotalMB),
+		"ansible_product_serial":     info.SerialNumber,
+		"ansible_product_name":       info.Model,
+		"ansible_product_uuid":       info.ProductUUID,
+		"ansible_system_vendor":      info.Manufacturer,
+		"ansible_default_ipv4":       map[string]string{"address": info.IP},
+		"tatuscan_machine_id":        info.MachineID,
+		"tatuscan_agent_id":          info.AgentID,
+		"tatuscan_os_version":        info.OSVersion,
+		"tatuscan_timestamp":         info.Timestamp,
+		"tatuscan_cpu_percent":       info.CPUPercent,
+		"tatuscan_tags":              info.Tags,
+		"tatuscan_labels":            info.Labels,
+		"ansible_all_ipv4_addresses": addressesOf(info.Addresses, false),
+		"ansible_all_ipv6_addresses": addressesOf(info.Addresses, true),
+	}
+	if info.IsVirtual {
+		facts["ansible_virtualization_role"] = "guest"
+		facts["ansible_virtualization_type"] = info.Hypervisor
+	} else {
+		facts["ansible_virtualization_role"] = "host"
+	}
+	var interfaces []string
+	for _, d := range info.Interfaces {
+		interfaces = append(interfaces, d.Name)
+		key := strings.NewReplacer("-", "_", " ", "_", ".", "_").Replace(d.Name)
+		facts["ansible_"+key] = map[string]any{"device": d.Name, "macaddress": strings.ToLower(d.MAC), "mtu": d.MTU, "active": d.Up, "speed": d.SpeedMbps}
+	}
+	facts["ansible_interfaces"] = interfaces
+	var mounts []map[string]any
+	for _, d := range info.Disks {
+		mounts = append(mounts, map[string]any{
+			"mount":          d.Mount,
+			"fstype":         d.FSType,
+			"size_total":     d.TotalMB * 1024 * 1024,
+			"size_available": (d.TotalMB - d.UsedMB) * 1024 * 1024,
+		})
+	}
+	facts["ansible_mounts"] = mounts
+	return map[string]any{"ansible_facts": facts}
+}
+
+// sccmInventory renders the payload as Configuration Manager hardware
+// inventory classes, each a list of instances with the WMI property names
+func sccmInventory(info MachineInfo) any {
+	const mb = 1024 * 1024
+	inventory := map[string]any{
+		"Win32_ComputerSystem": []map[string]any{{
+			"Name":                info.Hostname,
+			"Manufacturer":        info.Manufacturer,
+			"Model":               info.Model,
+			"TotalPhysicalMemory": info.MemoryTotalMB * mb,
+		}},
+		"Win32_OperatingSystem": []map[string]any{{
+			"Caption":            info.OSVersion,
+			"FreePhysicalMemory": (info.MemoryTotalMB - min(info.MemoryUsedMB, info.MemoryTotalMB)) * 1024, // KB
+		}},
+		"Win32_BIOS":                  []map[string]any{{"SerialNumber": info.SerialNumber}},
+		"Win32_ComputerSystemProduct": []map[string]any{{"UUID": strings.ToUpper(info.ProductUUID), "IdentifyingNumber": info.SerialNumber, "Name": info.Model, "Vendor": info.Manufacturer}},
+		"TatuScan_Agent":              []map[string]any{{"MachineID": info.MachineID, "AgentID": info.AgentID, "Timestamp": info.Timestamp}},
+	}
+
+	var adapters []map[string]any
+	for _, d := range info.Interfaces {
+		var ips []string
+		for _, a := range info.Addresses {
+			if a.Interface == d.Name {
+				ips = append(ips, a.IP)
+			}
+		}
+		adapters = append(adapters, map[string]any{
+			"Description": d.Name,
+			"MACAddress":  strings.ToUpper(strings.ReplaceAll(d.MAC, "-", ":")),
+			"IPAddress":   ips,
+			"IPEnabled":   d.Up && len(ips) > 0,
+		})
+	}
+	inventory["Win32_NetworkAdapterConfiguration"] = adapters
+
+	var disks []map[string]any
+	for _, d := range info.Disks {
+		disks = append(disks, map[string]any{
+			"DeviceID":   d.Mount,
+			"FileSystem": d.FSType,
+			"Size":       d.TotalMB * mb,
+			"FreeSpace":  (d.TotalMB - d.UsedMB) * mb,
+		})
+	}
+	inventory["Win32_LogicalDisk"] = disks
+	return inventory
+}
+
+// addressesOf lists the IPv4 or IPv6 addresses, nil when there are none
+func addressesOf(addresses []InterfaceAddress, v6 bool) []string {
+	var ips []string
+	for _, a := range addresses {
+		ip := net.ParseIP(a.IP)
+		if ip != nil && (ip.To4() == nil) == v6 {
+			ips = append(ips, a.IP)
+		}
+	}
+	return ips
+}
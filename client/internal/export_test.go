@@ -0,0 +1,40 @@
+package internal
+
+import "testing"
+
+// exportFixture is a collected payload with every exported field set
+var exportFixture = MachineInfo{
+	MachineID:     "4f1c2a9e0b7d3c5a8e6f1b2d4c3a5e7f9b0d2c4e6a8f1b3d5c7e9a0b2d4f6a8c",
+	AgentID:       "3f2b9c1e-7a4d-4e8f-9b21-5c6d7e8f9a0b",
+	Hostname:      "lab-pc-01",
+	FQDN:          "lab-pc-01.lab.example.edu",
+	IP:            "192.168.10.21",
+	Addresses:     []InterfaceAddress{{Interface: "eth0", IP: "192.168.10.21"}, {Interface: "eth0", IP: "2001:db8::21"}},
+	Interfaces:    []InterfaceDetail{{Name: "eth0", MAC: "00:1A:2B:3C:4D:5E", Up: true, MTU: 1500, SpeedMbps: 1000}},
+	OS:            "linux",
+	OSVersion:     "Ubuntu 24.04.1 LTS",
+	SerialNumber:  "PF3ABCDE",
+	Manufacturer:  "LENOVO",
+	Model:         "ThinkCentre M70q",
+	ProductUUID:   "4c4c4544-0042-3510-8052-b4c04f4e3132",
+	Tags:          []string{"lab"},
+	Labels:        map[string]string{"site": "lab3"},
+	Disks:         []Disk{{Mount: "/", FSType: "ext4", TotalMB: 244198, UsedMB: 61049, UsedPercent: 25}},
+	CPUPercent:    12.5,
+	MemoryTotalMB: 15872,
+	MemoryUsedMB:  6144,
+	Timestamp:     "2026-03-20T12:00:00Z",
+}
+
+func TestExportGolden(t *testing.T) {
+	for _, format := range []string{"ansible", "sccm"} {
+		doc, err := Export(exportFixture, format)
+		if err != nil {
+			t.Fatalf("Export(%s): %v", format, err)
+		}
+		assertGolden(t, "export-"+format, doc)
+	}
+	if _, err := Export(exportFixture, "mof"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readHwmon reads an attribute from a /sys/class/hwmon device
+func readHwmon(dir, attr string) string {
+	data, err := os.ReadFile(filepath.Join(dir, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// platformFans reads fan speeds from hwmon fan*_input attributes
+func platformFans() []FanReading {
+	inputs, err := filepath.Glob(filepath.Join(sysfsRoot, "class", "hwmon", "hwmon*", "fan*_input"))
+	if err != nil || len(inputs) == 0 {
+		return nil
+	}
+	var fans []FanReading
+	for _, input := range inputs {
+		dir := filepath.Dir(input)
+		fan := strings.TrimSuffix(filepath.Base(input), "_input")
+		rpm, err := strconv.ParseInt(readHwmon(dir, filepath.Base(input)), 10, 64)
+		if err != nil {
+			Log.Debugf("Error to read fan %s: %v", input, err)
+			continue
+		}
+		label := readHwmon(dir, fan+"_label")
+		if label == "" {
+			label = fan
+		}
+		sensor := label
+		if chip := readHwmon(dir, "name"); chip != "" {
+			sensor = chip + "_" + label
+		}
+		fans = append(fans, FanReading{Sensor: sensor, RPM: rpm})
+	}
+	sort.Slice(fans, func(i, j int) bool { return fans[i].Sensor < fans[j].Sensor })
+	return fans
+}
@@ -0,0 +1,9 @@
+//go:build windows || darwin
+
+package internal
+
+// platformFans is not available: Windows does not expose fan speeds through
+// standard WMI classes and the macOS SMC requires cgo
+func platformFans() []FanReading {
+	return nil
+}
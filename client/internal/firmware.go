@@ -0,0 +1,44 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"strings"
+	"time"
+)
+
+// FirmwareInfo identifies the mainboard and its BIOS/UEFI firmware, so
+// outdated firmware can be found across the fleet
+type FirmwareInfo struct {
+	BoardVendor string `json:"board_vendor,omitempty"`
+	BoardModel  string `json:"board_model,omitempty"`
+	Vendor      string `json:"vendor,omitempty"`
+	Version     string `json:"version,omitempty"`
+	ReleaseDate string `json:"release_date,omitempty"` // YYYY-MM-DD
+	BootMode    string `json:"boot_mode,omitempty"`    // uefi or bios
+}
+
+// Boot modes reported in FirmwareInfo.BootMode
+const (
+	BootModeUEFI = "uefi"
+	BootModeBIOS = "bios"
+)
+
+// firmwareDateLayouts are the release date formats of SMBIOS (DMI) and of
+// the WMI CIM_DATETIME values
+var firmwareDateLayouts = []string{"01/02/2006", "01/02/06", "20060102"}
+
+// parseFirmwareDate normalizes a firmware release date to YYYY-MM-DD,
+// returning "" when it cannot be parsed
+func parseFirmwareDate(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 14 && strings.Contains(value, ".") {
+		value = value[:8] // CIM_DATETIME: yyyymmddHHMMSS.mmmmmmsUUU
+	}
+	for _, layout := range firmwareDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.DateOnly)
+		}
+	}
+	return ""
+}
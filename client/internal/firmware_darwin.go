@@ -0,0 +1,41 @@
+//go:build darwin
+
+package internal
+
+import (
+	"context"
+	"strings"
+)
+
+// getFirmwareInfo reads the board identifier from IOKit and the system
+// firmware version from system_profiler; Macs have no BIOS, and their
+// firmware has no release date
+func getFirmwareInfo(ctx context.Context) FirmwareInfo {
+	var info FirmwareInfo
+	if output, err := runCommandContext(ctx, "ioreg", "-rd1", "-c", "IOPlatformExpertDevice"); err == nil {
+		props := parseIORegProperties(string(output))
+		info.BoardVendor = cleanSMBIOSValue(props["manufacturer"])
+		info.BoardModel = cleanSMBIOSValue(props["board-id"])
+		if info.BoardModel == "" {
+			info.BoardModel = cleanSMBIOSValue(props["target-type"])
+		}
+	}
+
+	output, err := runCommandContext(ctx, "system_profiler", "SPHardwareDataType")
+	if err != nil {
+		Log.Debugf("Error to execute system_profiler: %v", err)
+		return info
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		// "Boot ROM Version" on Intel Macs before macOS 11
+		if key == "System Firmware Version" || key == "Boot ROM Version" {
+			info.Version = strings.TrimSpace(value)
+			info.Vendor = "Apple Inc."
+		}
+	}
+	return info
+}
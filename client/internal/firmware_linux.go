@@ -0,0 +1,29 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// getFirmwareInfo reads the baseboard and BIOS fields of DMI from sysfs;
+// the boot mode is UEFI when the kernel exposes /sys/firmware/efi
+func getFirmwareInfo(_ context.Context) FirmwareInfo {
+	info := FirmwareInfo{
+		BoardVendor: readDMIField("board_vendor"),
+		BoardModel:  readDMIField("board_name"),
+		Vendor:      readDMIField("bios_vendor"),
+		Version:     readDMIField("bios_version"),
+		ReleaseDate: parseFirmwareDate(readDMIField("bios_date")),
+	}
+	if info == (FirmwareInfo{}) {
+		return info
+	}
+	info.BootMode = BootModeBIOS
+	if _, err := os.Stat(filepath.Join(sysfsRoot, "firmware", "efi")); err == nil {
+		info.BootMode = BootModeUEFI
+	}
+	return info
+}
@@ -0,0 +1,17 @@
+package internal
+
+import "testing"
+
+func TestParseFirmwareDate(t *testing.T) {
+	for value, want := range map[string]string{
+		"03/14/2023":                "2023-03-14",
+		"11/05/09":                  "2009-11-05",
+		"20230512000000.000000+000": "2023-05-12",
+		"":                          "",
+		"unknown":                   "",
+	} {
+		if got := parseFirmwareDate(value); got != want {
+			t.Errorf("parseFirmwareDate(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
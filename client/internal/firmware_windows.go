@@ -0,0 +1,80 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+
+	"github.com/StackExchange/wmi"
+	"golang.org/x/sys/windows/registry"
+)
+
+// getFirmwareInfo reads the baseboard and BIOS via WMI and the boot mode
+// from the PEFirmwareType value Windows records at boot
+func getFirmwareInfo(ctx context.Context) FirmwareInfo {
+	var info FirmwareInfo
+
+	type bios struct {
+		Manufacturer      *string
+		SMBIOSBIOSVersion *string
+		ReleaseDate       *string
+	}
+	var bioses []bios
+	if err := wmiQuery(wmi.CreateQuery(&bioses, "", "Win32_BIOS"), &bioses, ""); errors.Is(err, errWMIDisabled) {
+		Log.Debug("WMI disabled; firmware not collected")
+	} else if err != nil {
+		Log.Warnf("Error to query Win32_BIOS: %v", err)
+		addWarning(ctx, WarnWMIUnavailable, "Win32_BIOS query failed: %v", err)
+	} else if len(bioses) > 0 {
+		b := bioses[0]
+		if b.Manufacturer != nil {
+			info.Vendor = cleanSMBIOSValue(*b.Manufacturer)
+		}
+		if b.SMBIOSBIOSVersion != nil {
+			info.Version = cleanSMBIOSValue(*b.SMBIOSBIOSVersion)
+		}
+		if b.ReleaseDate != nil {
+			info.ReleaseDate = parseFirmwareDate(*b.ReleaseDate)
+		}
+	}
+
+	type baseBoard struct {
+		Manufacturer *string
+		Product      *string
+	}
+	var boards []baseBoard
+	if err := wmiQuery(wmi.CreateQuery(&boards, "", "Win32_BaseBoard"), &boards, ""); err != nil {
+		Log.Debugf("Error to query Win32_BaseBoard: %v", err)
+	} else if len(boards) > 0 {
+		if boards[0].Manufacturer != nil {
+			info.BoardVendor = cleanSMBIOSValue(*boards[0].Manufacturer)
+		}
+		if boards[0].Product != nil {
+			info.BoardModel = cleanSMBIOSValue(*boards[0].Product)
+		}
+	}
+
+	if info != (FirmwareInfo{}) {
+		info.BootMode = platformBootMode()
+	}
+	return info
+}
+
+// platformBootMode reads PEFirmwareType: 1 for BIOS, 2 for UEFI
+func platformBootMode() string {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control`, registry.QUERY_VALUE)
+	if err != nil {
+		return ""
+	}
+	defer key.Close()
+	switch value, _, err := key.GetIntegerValue("PEFirmwareType"); {
+	case err != nil:
+		return ""
+	case value == 2:
+		return BootModeUEFI
+	case value == 1:
+		return BootModeBIOS
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// updateGolden rewrites golden payloads: go test ./internal -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden payload files")
+
+// fixtureInterface is the JSON form of a mocked network interface
+type fixtureInterface struct {
+	Name  string   `json:"name"`
+	Flags []string `json:"flags"`
+	MAC   string   `json:"mac"`
+	MTU   int      `json:"mtu"`
+	Addrs []string `json:"addrs"`
+}
+
+// fixtureFlags maps fixture flag names to net.Flags
+var fixtureFlags = map[string]net.Flags{
+	"up":           net.FlagUp,
+	"broadcast":    net.FlagBroadcast,
+	"loopback":     net.FlagLoopback,
+	"pointtopoint": net.FlagPointToPoint,
+	"multicast":    net.FlagMulticast,
+}
+
+// loadFixtureInterfaces reads interfaces.json from a fixture directory
+func loadFixtureInterfaces(t *testing.T, dir string) []NetInterface {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "interfaces.json"))
+	if err != nil {
+		t.Fatalf("read interfaces fixture: %v", err)
+	}
+	var fixtures []fixtureInterface
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatalf("parse interfaces fixture: %v", err)
+	}
+
+	ifaces := make([]NetInterface, 0, len(fixtures))
+	for _, f := range fixtures {
+		mock := MockInterface{name: f.Name, mtu: f.MTU}
+		for _, flag := range f.Flags {
+			mock.flags |= fixtureFlags[flag]
+		}
+		if f.MAC != "" {
+			mock.hardwareAddr = mustParseMAC(f.MAC)
+		}
+		for _, cidr := range f.Addrs {
+			ip, ipnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				t.Fatalf("invalid fixture address %q: %v", cidr, err)
+			}
+			mock.addrs = append(mock.addrs, &net.IPNet{IP: ip, Mask: ipnet.Mask})
+		}
+		ifaces = append(ifaces, mock)
+	}
+	return ifaces
+}
+
+// loadFixtureConfig builds the config from the optional "env" file of a fixture
+func loadFixtureConfig(t *testing.T, dir string) Config {
+	t.Helper()
+	env := map[string]string{}
+	if data, err := os.ReadFile(filepath.Join(dir, "env")); err == nil {
+		env = parseKeyValueLines(string(data))
+	}
+	env["TATUSCAN_STATE_DIR"] = t.TempDir()
+	return loadConfig(env)
+}
+
+// normalizePayload clears fields that depend on the host running the tests
+func normalizePayload(info *MachineInfo) {
+	info.Timestamp = ""
+	info.Hostname = "fixture-host"
+	info.FQDN = "" // from the real hostname
+	info.AgentID = ""
+	info.CPU = nil
+	info.CPUPercent = 0
+	info.MemoryTotalMB = 0
+	info.MemoryUsedMB = 0
+	info.Disks = nil
+	info.Health = nil
+}
+
+// assertGolden compares a payload with testdata/golden/<name>.json
+func assertGolden(t *testing.T, name string, payload any) {
+	t.Helper()
+	got, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("payload differs from %s (run with -update to accept)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// assertValidPayload checks a payload against the generated JSON Schema
+func assertValidPayload(t *testing.T, payload any) {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if problems := PayloadSchema().Validate(doc); len(problems) > 0 {
+		t.Errorf("payload does not match the schema: %v", problems)
+	}
+}
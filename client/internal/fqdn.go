@@ -0,0 +1,45 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+)
+
+// fqdnLookupTimeout bounds the DNS lookup of the FQDN, so a missing DNS
+// server does not hold the host collector
+const fqdnLookupTimeout = 2 * time.Second
+
+// normalizeDomainName lowercases a DNS name and drops its root dot
+func normalizeDomainName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// lookupFQDN returns the canonical DNS name of hostname, "" when the
+// resolver has no dotted name for it
+func lookupFQDN(ctx context.Context, hostname string) string {
+	ctx, cancel := context.WithTimeout(ctx, fqdnLookupTimeout)
+	defer cancel()
+	cname, err := net.DefaultResolver.LookupCNAME(ctx, hostname)
+	if err != nil {
+		Log.Debugf("FQDN lookup of %s failed: %v", hostname, err)
+		return ""
+	}
+	if cname = normalizeDomainName(cname); strings.Contains(cname, ".") {
+		return cname
+	}
+	return ""
+}
+
+// appendSuffixes appends the DNS suffixes not yet in list, normalized
+func appendSuffixes(list []string, suffixes ...string) []string {
+	for _, suffix := range suffixes {
+		if suffix = normalizeDomainName(suffix); suffix != "" && !containsString(list, suffix) {
+			list = append(list, suffix)
+		}
+	}
+	return list
+}
@@ -0,0 +1,84 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hostsFQDN returns the first dotted name of the /etc/hosts entry naming
+// hostname, the way `hostname -f` finds it before asking DNS
+func hostsFQDN(hostname string) string {
+	f, err := os.Open(filepath.Join(etcRoot, "hosts"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		names := fields[1:]
+		found := false
+		for _, name := range names {
+			if strings.EqualFold(name, hostname) || strings.HasPrefix(strings.ToLower(name), strings.ToLower(hostname)+".") {
+				found = true
+			}
+		}
+		if !found {
+			continue
+		}
+		for _, name := range names {
+			if name = normalizeDomainName(name); strings.Contains(name, ".") && !strings.HasSuffix(name, ".localdomain") && name != "localhost.localdomain" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// resolvSuffixes returns the domain and search list of /etc/resolv.conf;
+// the last of the two directives wins, as in the resolver
+func resolvSuffixes() []string {
+	f, err := os.Open(filepath.Join(etcRoot, "resolv.conf"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var suffixes []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "domain", "search":
+			suffixes = appendSuffixes(nil, fields[1:]...)
+		}
+	}
+	return suffixes
+}
+
+// platformFQDN returns the FQDN of the host, from the hostname itself when
+// dotted, /etc/hosts or DNS, and the DNS search suffixes of the resolver
+func platformFQDN(ctx context.Context, hostname string) (string, []string) {
+	suffixes := resolvSuffixes()
+	switch {
+	case strings.Contains(hostname, "."):
+		return normalizeDomainName(hostname), suffixes
+	case hostname == "":
+		return "", suffixes
+	}
+	if fqdn := hostsFQDN(hostname); fqdn != "" {
+		return fqdn, suffixes
+	}
+	return lookupFQDN(ctx, hostname), suffixes
+}
@@ -0,0 +1,34 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlatformFQDN(t *testing.T) {
+	setupTestAgent(t)
+	origEtc := etcRoot
+	etcRoot = t.TempDir()
+	t.Cleanup(func() { etcRoot = origEtc })
+	os.WriteFile(filepath.Join(etcRoot, "hosts"), []byte("127.0.0.1 localhost\n"+
+		"127.0.1.1 lab-pc-01.lab.example.edu lab-pc-01 # set by the installer\n"), 0o644)
+	os.WriteFile(filepath.Join(etcRoot, "resolv.conf"), []byte("domain old.example.edu\n"+
+		"search Lab.Example.edu. example.edu lab.example.edu\nnameserver 10.0.0.1\n"), 0o644)
+
+	fqdn, suffixes := platformFQDN(context.Background(), "lab-pc-01")
+	if fqdn != "lab-pc-01.lab.example.edu" {
+		t.Errorf("fqdn = %q", fqdn)
+	}
+	if want := []string{"lab.example.edu", "example.edu"}; !reflect.DeepEqual(suffixes, want) {
+		t.Errorf("suffixes = %v, want %v", suffixes, want)
+	}
+
+	if fqdn, _ := platformFQDN(context.Background(), "Srv01.Example.EDU."); fqdn != "srv01.example.edu" {
+		t.Errorf("dotted hostname: fqdn = %q", fqdn)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// tcpipParametersKey holds the primary DNS suffix, the search list and the
+// connection-specific suffixes of each interface
+const tcpipParametersKey = `SYSTEM\CurrentControlSet\Services\Tcpip\Parameters`
+
+// dnsFullyQualifiedName returns the FQDN Windows assigns to the computer,
+// the host name and the primary DNS suffix
+func dnsFullyQualifiedName() string {
+	n := uint32(256)
+	buf := make([]uint16, n)
+	if err := windows.GetComputerNameEx(windows.ComputerNameDnsFullyQualified, &buf[0], &n); err != nil {
+		Log.Debugf("GetComputerNameEx: %v", err)
+		return ""
+	}
+	return normalizeDomainName(windows.UTF16ToString(buf[:n]))
+}
+
+// registrySuffixes returns the DNS suffixes of the TCP/IP parameters: the
+// primary suffix, the search list and the static or DHCP suffix of each
+// interface
+func registrySuffixes() []string {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, tcpipParametersKey, registry.QUERY_VALUE|registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil
+	}
+	defer k.Close()
+	var suffixes []string
+	for _, name := range []string{"Domain", "SearchList"} {
+		if value, _, err := k.GetStringValue(name); err == nil {
+			suffixes = appendSuffixes(suffixes, strings.Split(value, ",")...)
+		}
+	}
+	interfaces, err := registry.OpenKey(k, "Interfaces", registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return suffixes
+	}
+	defer interfaces.Close()
+	guids, _ := interfaces.ReadSubKeyNames(0)
+	for _, guid := range guids {
+		iface, err := registry.OpenKey(interfaces, guid, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		for _, name := range []string{"Domain", "DhcpDomain"} {
+			if value, _, err := iface.GetStringValue(name); err == nil {
+				suffixes = appendSuffixes(suffixes, value)
+			}
+		}
+		iface.Close()
+	}
+	return suffixes
+}
+
+// platformFQDN returns the FQDN of the computer, from its primary DNS suffix
+// or DNS, and the DNS suffixes configured in TCP/IP
+func platformFQDN(ctx context.Context, hostname string) (string, []string) {
+	suffixes := registrySuffixes()
+	if fqdn := dnsFullyQualifiedName(); strings.Contains(fqdn, ".") {
+		return fqdn, suffixes
+	}
+	if hostname == "" {
+		return "", suffixes
+	}
+	return lookupFQDN(ctx, hostname), suffixes
+}
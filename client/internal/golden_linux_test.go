@@ -0,0 +1,65 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestGoldenLinuxPayloads runs the whole pipeline against each fixture tree
+// under testdata/fixtures/linux and compares with the golden payloads
+func TestGoldenLinuxPayloads(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "fixtures", "linux", "*"))
+	if err != nil || len(dirs) == 0 {
+		t.Fatalf("no linux fixtures found: %v", err)
+	}
+
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		t.Run(name, func(t *testing.T) {
+			setupTestAgent(t)
+			SetConfig(loadFixtureConfig(t, dir))
+
+			origRoot, origSysfs, origProc, origEtc, origLister := rootDir, sysfsRoot, procRoot, etcRoot, interfaceLister
+			t.Cleanup(func() {
+				rootDir, sysfsRoot, procRoot, etcRoot, interfaceLister = origRoot, origSysfs, origProc, origEtc, origLister
+			})
+			rootDir = dir
+			sysfsRoot = filepath.Join(dir, "sys")
+			procRoot = filepath.Join(dir, "proc")
+			etcRoot = filepath.Join(dir, "etc")
+			origSystemctl := runSystemctl
+			t.Cleanup(func() { runSystemctl = origSystemctl })
+			runSystemctl = replaySystemctl(dir)
+			origLifetime := addressLifetime
+			t.Cleanup(func() { addressLifetime = origLifetime })
+			addressLifetime = func(string) (bool, time.Time, bool) { return false, time.Time{}, false }
+			ifaces := loadFixtureInterfaces(t, dir)
+			interfaceLister = func() ([]NetInterface, error) { return ifaces, nil }
+
+			if _, err := os.Stat(etcRoot); err != nil {
+				t.Fatalf("fixture %s has no etc tree", name)
+			}
+
+			info, err := CollectData(context.Background())
+			if err != nil {
+				t.Fatalf("CollectData: %v", err)
+			}
+			normalizePayload(&info)
+			assertValidPayload(t, info)
+			assertGolden(t, "linux-"+name, info)
+		})
+	}
+}
+
+// replaySystemctl serves systemctl output recorded as systemctl/<subcommand>.txt
+// in the fixture; a missing file behaves like a host without systemd
+func replaySystemctl(dir string) func(args ...string) ([]byte, error) {
+	return func(args ...string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, "systemctl", args[0]+".txt"))
+	}
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// wmiClassRe extracts the class name from a WQL query
+var wmiClassRe = regexp.MustCompile(`(?i)\bFROM\s+(\w+)`)
+
+// replayWMI answers WMI queries with testdata/fixtures/windows/<case>/wmi/<Class>.json
+func replayWMI(dir string) func(query string, dst interface{}, namespace string) error {
+	return func(query string, dst interface{}, namespace string) error {
+		m := wmiClassRe.FindStringSubmatch(query)
+		if m == nil {
+			return fmt.Errorf("unsupported query %q", query)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "wmi", m[1]+".json"))
+		if err != nil {
+			return fmt.Errorf("no fixture for %s: %w", m[1], err)
+		}
+		return json.Unmarshal(data, dst)
+	}
+}
+
+// TestGoldenWindowsPayloads replays recorded WMI results for each fixture under
+// testdata/fixtures/windows through the SMBIOS, firmware, network and interface collectors
+func TestGoldenWindowsPayloads(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "fixtures", "windows", "*"))
+	if err != nil || len(dirs) == 0 {
+		t.Fatalf("no windows fixtures found: %v", err)
+	}
+
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		t.Run(name, func(t *testing.T) {
+			setupTestAgent(t)
+			SetConfig(loadFixtureConfig(t, dir))
+
+			origQuery, origLister := wmiQuery, interfaceLister
+			t.Cleanup(func() { wmiQuery, interfaceLister = origQuery, origLister })
+			wmiQuery = replayWMI(dir)
+			ifaces := loadFixtureInterfaces(t, dir)
+			interfaceLister = func() ([]NetInterface, error) { return ifaces, nil }
+
+			info := MachineInfo{OS: "windows"}
+			for _, c := range []func(context.Context, *MachineInfo) error{collectSMBIOS, collectFirmware, collectNetwork, collectInterfaces} {
+				if err := c(context.Background(), &info); err != nil {
+					t.Fatalf("collector failed: %v", err)
+				}
+			}
+			if info.Firmware != nil {
+				// Read from the registry of the host running the tests
+				info.Firmware.BootMode = ""
+			}
+			normalizePayload(&info)
+			assertValidPayload(t, info)
+			assertGolden(t, "windows-"+name, info)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"net/http"
+	"strings"
+)
+
+// headerVariablePrefix starts the custom header variables
+// (TATUSCAN_HEADER_X_ORG_ID=42 sends X-Org-Id: 42)
+const headerVariablePrefix = "TATUSCAN_HEADER_"
+
+// reservedHeaders frame the request or are set by the agent itself
+var reservedHeaders = []string{"Host", "Connection", "Content-Length", "Content-Type", "Content-Encoding", "Transfer-Encoding", "User-Agent", SchemaHeader, SignatureHeader}
+
+// parseHeaders reads the TATUSCAN_HEADER_* variables: the rest of the
+// variable name, with underscores as dashes, is the header name
+func parseHeaders(env map[string]string) map[string]string {
+	headers := map[string]string{}
+	for variable, value := range env {
+		name, ok := strings.CutPrefix(variable, headerVariablePrefix)
+		if !ok {
+			continue
+		}
+		name = http.CanonicalHeaderKey(strings.ReplaceAll(name, "_", "-"))
+		value = strings.TrimSpace(value)
+		if !validHeaderName(name) || strings.ContainsAny(value, "\r\n") || containsHeader(reservedHeaders, name) {
+			if Log != nil {
+				Log.Warnf("Custom header %s ignored", variable)
+			}
+			continue
+		}
+		headers[name] = value
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// validHeaderName accepts letters, digits and inner dashes
+func validHeaderName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+		return false
+	}
+	for _, r := range name {
+		if r != '-' && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// containsHeader reports whether name is in headers, ignoring case
+func containsHeader(headers []string, name string) bool {
+	for _, h := range headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// setAgentHeaders sets the User-Agent and the custom headers on a request
+// to the agent destinations (server, site overlay, GLPI, Snipe-IT); the
+// credentials the agent sets afterwards take precedence over them
+func setAgentHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", userAgent())
+	for name, value := range Cfg.Headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseHeaders(t *testing.T) {
+	setupTestAgent(t)
+	headers := parseHeaders(map[string]string{
+		"TATUSCAN_HEADER_X_ORG_ID":          " 42 ",
+		"TATUSCAN_HEADER_X_GATEWAY_KEY":     "k3y",
+		"TATUSCAN_HEADER_CONTENT_TYPE":      "text/plain",
+		"TATUSCAN_HEADER_X_TATUSCAN_SCHEMA": "9",
+		"TATUSCAN_HEADER_BAD_NAME_":         "x",
+		"TATUSCAN_HEADER_X_SPLIT":           "a\r\nInjected: 1",
+		"TATUSCAN_TOKEN":                    "s3cret",
+	})
+	if len(headers) != 2 || headers["X-Org-Id"] != "42" || headers["X-Gateway-Key"] != "k3y" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+}
+
+func TestHTTPSenderHeaders(t *testing.T) {
+	setupTestAgent(t)
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	Cfg.Headers = map[string]string{"X-Org-Id": "42", "Authorization": "Gateway abc"}
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Get("X-Org-Id") != "42" || got.Get("Authorization") != "Gateway abc" || got.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers: %v", got)
+	}
+
+	// The agent credentials win over a custom header of the same name
+	Cfg.Token = "s3cret"
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Get("Authorization") != "Bearer s3cret" || got.Get("X-Org-Id") != "42" {
+		t.Errorf("unexpected headers: %v", got)
+	}
+}
@@ -0,0 +1,92 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// defaultHealthCPUTemp is the CPU temperature (Celsius) marked degraded
+	defaultHealthCPUTemp = 90.0
+	// defaultHealthDiskTemp is the drive temperature (Celsius) marked degraded
+	defaultHealthDiskTemp = 60.0
+	// defaultHealthMemory is the used memory percentage marked degraded
+	defaultHealthMemory = 95.0
+	// defaultHealthDiskDays is the fill forecast (days) marked degraded
+	defaultHealthDiskDays = 7.0
+)
+
+// cpuSensorPrefixes and diskSensorPrefixes classify the temperature sensors
+// by their lowercased key: Linux hwmon drivers and macOS SMC keys
+var (
+	cpuSensorPrefixes  = []string{"coretemp", "k10temp", "zenpower", "cpu", "soc_thermal", "tc0", "tc1"}
+	diskSensorPrefixes = []string{"nvme", "drivetemp"}
+)
+
+// Health is the local evaluation of the configured thresholds, so that a
+// server can alert on status alone
+type Health struct {
+	Status  string         `json:"status"` // ok or degraded
+	Reasons []HealthReason `json:"reasons,omitempty"`
+}
+
+// HealthReason is a threshold reached by a reading
+type HealthReason struct {
+	Check     string  `json:"check"`             // cpu_temp, disk_temp, memory, disk_usage or disk_full
+	Subject   string  `json:"subject,omitempty"` // sensor or mount
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
+
+// evaluateHealth checks the collected readings against the thresholds; a
+// threshold of 0 disables its check, and readings that were not collected
+// (sensors or disks off) are not evaluated
+func evaluateHealth(info *MachineInfo) *Health {
+	health := &Health{Status: "ok"}
+	add := func(check, subject string, value, threshold float64) {
+		health.Reasons = append(health.Reasons, HealthReason{Check: check, Subject: subject, Value: value, Threshold: threshold})
+	}
+
+	if info.Sensors != nil {
+		for _, t := range info.Sensors.Temperatures {
+			key := strings.ToLower(t.Sensor)
+			switch {
+			case Cfg.HealthCPUTemp > 0 && hasAnyPrefix(key, cpuSensorPrefixes) && t.Celsius >= Cfg.HealthCPUTemp:
+				add("cpu_temp", t.Sensor, t.Celsius, Cfg.HealthCPUTemp)
+			case Cfg.HealthDiskTemp > 0 && hasAnyPrefix(key, diskSensorPrefixes) && t.Celsius >= Cfg.HealthDiskTemp:
+				add("disk_temp", t.Sensor, t.Celsius, Cfg.HealthDiskTemp)
+			}
+		}
+	}
+	if Cfg.HealthMemory > 0 && info.MemoryTotalMB > 0 {
+		used := math.Round(float64(info.MemoryUsedMB)/float64(info.MemoryTotalMB)*1000) / 10
+		if used >= Cfg.HealthMemory {
+			add("memory", "", used, Cfg.HealthMemory)
+		}
+	}
+	for _, d := range info.Disks {
+		if d.OverThreshold {
+			add("disk_usage", d.Mount, d.UsedPercent, Cfg.DiskThreshold)
+		}
+		if Cfg.HealthDiskDays > 0 && d.FullInDays != nil && *d.FullInDays <= Cfg.HealthDiskDays {
+			add("disk_full", d.Mount, *d.FullInDays, Cfg.HealthDiskDays)
+		}
+	}
+
+	if len(health.Reasons) > 0 {
+		health.Status = "degraded"
+	}
+	return health
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateHealth(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.HealthCPUTemp, Cfg.HealthDiskTemp, Cfg.HealthMemory, Cfg.HealthDiskDays = 90, 60, 95, 7
+	Cfg.DiskThreshold = 90
+
+	fine := &MachineInfo{MemoryTotalMB: 1000, MemoryUsedMB: 500}
+	if got := evaluateHealth(fine); got.Status != "ok" || got.Reasons != nil {
+		t.Errorf("health = %+v, want ok", got)
+	}
+
+	days := 3.0
+	info := &MachineInfo{
+		MemoryTotalMB: 1000,
+		MemoryUsedMB:  960,
+		Sensors: &SensorInfo{Temperatures: []TemperatureReading{
+			{Sensor: "coretemp_package_id_0", Celsius: 92},
+			{Sensor: "nvme_composite", Celsius: 45},
+			{Sensor: "acpitz", Celsius: 99},
+		}},
+		Disks: []Disk{{Mount: "/var", UsedPercent: 93, OverThreshold: true, FullInDays: &days}},
+	}
+	want := []HealthReason{
+		{Check: "cpu_temp", Subject: "coretemp_package_id_0", Value: 92, Threshold: 90},
+		{Check: "memory", Value: 96, Threshold: 95},
+		{Check: "disk_usage", Subject: "/var", Value: 93, Threshold: 90},
+		{Check: "disk_full", Subject: "/var", Value: 3, Threshold: 7},
+	}
+	got := evaluateHealth(info)
+	if got.Status != "degraded" || !reflect.DeepEqual(got.Reasons, want) {
+		t.Errorf("health = %+v, want degraded with %+v", got, want)
+	}
+
+	// A threshold of 0 disables its check
+	Cfg.HealthCPUTemp, Cfg.HealthMemory, Cfg.HealthDiskDays = 0, 0, 0
+	if got := evaluateHealth(info); len(got.Reasons) != 1 || got.Reasons[0].Check != "disk_usage" {
+		t.Errorf("reasons with checks disabled = %+v", got.Reasons)
+	}
+}
@@ -0,0 +1,110 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// certExpiryWarning flags server certificates close to their expiry
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// CheckServer verifies that the destination accepts payloads without
+// sending one: http(s) servers must answer GET /api/health with the agent
+// credentials over a valid TLS certificate, file destinations must be
+// writable. It returns a short description of what was verified.
+func CheckServer(ctx context.Context, target string) (string, error) {
+	sender, err := NewSender(target)
+	if err != nil {
+		return "", err
+	}
+	switch s := sender.(type) {
+	case *httpSender:
+		return checkHTTPServer(ctx, strings.TrimSuffix(s.url, "/machines")+"/health", s.client)
+	case *fileSender:
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			return "", err
+		}
+		return "writable " + s.path, f.Close()
+	default:
+		return "no check available for this destination", nil
+	}
+}
+
+// checkHTTPServer probes the health endpoint of the server
+func checkHTTPServer(ctx context.Context, endpoint string, client *http.Client) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	setAgentHeaders(req)
+	if Cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+Cfg.Token)
+	}
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", diagnoseHTTPError(req.URL, err)
+	}
+	resp.Body.Close()
+	elapsed := time.Since(started).Round(time.Millisecond)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", fmt.Errorf("server rejected the credentials (HTTP %d); check TATUSCAN_TOKEN", resp.StatusCode)
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%s not found (HTTP 404); is TATUSCAN_URL the base URL of a TatuScan server?", endpoint)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("%s returned HTTP %d", endpoint, resp.StatusCode)
+	}
+
+	detail := fmt.Sprintf("%s answered in %s", endpoint, elapsed)
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		notAfter := resp.TLS.PeerCertificates[0].NotAfter
+		detail += fmt.Sprintf(", certificate valid until %s", notAfter.Format("2006-01-02"))
+		if time.Until(notAfter) < certExpiryWarning {
+			detail += " (expires soon)"
+		}
+	}
+	return detail, nil
+}
+
+// diagnoseHTTPError explains the common reasons a request fails before the
+// server answers
+func diagnoseHTTPError(u *url.URL, err error) error {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		dnsErr           *net.DNSError
+		netErr           net.Error
+	)
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return fmt.Errorf("TLS certificate of %s is signed by an unknown authority; install the CA in the system store: %w", u.Host, err)
+	case errors.As(err, &hostname):
+		return fmt.Errorf("TLS certificate does not match %s; check the host name in TATUSCAN_URL: %w", u.Hostname(), err)
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return fmt.Errorf("TLS certificate of %s is expired or not yet valid; check the server certificate and the local clock: %w", u.Host, err)
+	case errors.Is(err, http.ErrSchemeMismatch):
+		return fmt.Errorf("%s speaks plain HTTP; use http:// in TATUSCAN_URL: %w", u.Host, err)
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("cannot resolve %s; check DNS and the host name in TATUSCAN_URL: %w", u.Hostname(), err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("connection refused by %s; is the server running on that port?: %w", u.Host, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("no answer from %s; check firewalls and proxies: %w", u.Host, err)
+	}
+	return err
+}
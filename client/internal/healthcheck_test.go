@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckServer(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Token = "s3cret"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/health" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"status": "healthy"}`))
+	})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	ctx := context.Background()
+
+	if detail, err := CheckServer(ctx, srv.URL); err != nil || !strings.Contains(detail, "/api/health") {
+		t.Errorf("CheckServer = %q, %v", detail, err)
+	}
+	Cfg.Token = "wrong"
+	if _, err := CheckServer(ctx, srv.URL); err == nil || !strings.Contains(err.Error(), "TATUSCAN_TOKEN") {
+		t.Errorf("expected a credentials diagnosis, got %v", err)
+	}
+
+	// The test certificate is not trusted by the system store
+	tlsSrv := httptest.NewTLSServer(handler)
+	defer tlsSrv.Close()
+	if _, err := CheckServer(ctx, tlsSrv.URL); err == nil || !strings.Contains(err.Error(), "unknown authority") {
+		t.Errorf("expected an unknown authority diagnosis, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "payloads.jsonl")
+	if detail, err := CheckServer(ctx, "file:///"+strings.TrimPrefix(filepath.ToSlash(path), "/")); err != nil || !strings.Contains(detail, "writable") {
+		t.Errorf("file destination: %q, %v", detail, err)
+	}
+}
@@ -0,0 +1,99 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// heartbeatCollectors run in heartbeat cycles: identity, addresses and
+// the metrics the heartbeat carries
+var heartbeatCollectors = []string{"host", "network", "metrics"}
+
+// heartbeatKeys are the fields of a heartbeat payload
+var heartbeatKeys = []string{"schema_version", "machine_id", "timestamp", "cpu_percent", "memory_total_mb", "memory_used_mb", "metrics_summary", "metric_history", "task_results"}
+
+// heartbeatState tracks the full inventories delivered: the cycles since
+// the last one and a fingerprint of what it reported
+var heartbeatState struct {
+	sync.Mutex
+	cycles      int
+	fingerprint string
+}
+
+// HeartbeatDue reports whether the cycle may send a heartbeat instead of
+// the full inventory: TATUSCAN_HEARTBEAT_CYCLES is set, a full inventory
+// was delivered less than that many cycles ago and the cycle is not forced
+// by a network change or the server
+func HeartbeatDue(forced bool) bool {
+	if Cfg.HeartbeatCycles <= 0 {
+		return false
+	}
+	heartbeatState.Lock()
+	defer heartbeatState.Unlock()
+	heartbeatState.cycles++
+	return !forced && heartbeatState.fingerprint != "" && heartbeatState.cycles < Cfg.HeartbeatCycles
+}
+
+// CollectHeartbeat runs the heartbeat collectors only
+func CollectHeartbeat(ctx context.Context) (MachineInfo, error) {
+	info, err := collectData(ctx, heartbeatCollectors)
+	info.Heartbeat = true
+	return info, err
+}
+
+// InventoryChanged reports whether a heartbeat cycle found the hostname,
+// OS version, addresses or MachineID changed since the last full inventory
+func InventoryChanged(info MachineInfo) bool {
+	heartbeatState.Lock()
+	defer heartbeatState.Unlock()
+	return inventoryFingerprint(info) != heartbeatState.fingerprint
+}
+
+// inventoryFingerprint serializes the fields a heartbeat cycle watches
+func inventoryFingerprint(info MachineInfo) string {
+	data, _ := json.Marshal([]any{info.MachineID, info.Hostname, info.FQDN, info.OSVersion, info.IP, info.Addresses})
+	return string(data)
+}
+
+// ackInventory notes a delivered full inventory, restarting the count of
+// heartbeat cycles
+func ackInventory(info MachineInfo) {
+	if info.Heartbeat {
+		return
+	}
+	heartbeatState.Lock()
+	defer heartbeatState.Unlock()
+	heartbeatState.cycles = 0
+	heartbeatState.fingerprint = inventoryFingerprint(info)
+}
+
+// forgetInventory makes the next cycle send the full inventory, after the
+// server did not recognize a heartbeat
+func forgetInventory() {
+	heartbeatState.Lock()
+	defer heartbeatState.Unlock()
+	heartbeatState.fingerprint = ""
+}
+
+// heartbeatPayload reduces a serialized payload to the heartbeat fields,
+// marked with "heartbeat": true
+func heartbeatPayload(data []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return data
+	}
+	heartbeat := map[string]json.RawMessage{"heartbeat": json.RawMessage("true")}
+	for _, key := range heartbeatKeys {
+		if value, ok := fields[key]; ok {
+			heartbeat[key] = value
+		}
+	}
+	body, err := json.Marshal(heartbeat)
+	if err != nil {
+		return data
+	}
+	return body
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatCycles(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.HeartbeatCycles = 3
+	heartbeatState.cycles, heartbeatState.fingerprint = 0, ""
+	t.Cleanup(func() { heartbeatState.cycles, heartbeatState.fingerprint = 0, "" })
+
+	full := MachineInfo{MachineID: "abc", Hostname: "lab-01", IP: "10.0.0.5"}
+	if HeartbeatDue(false) {
+		t.Fatal("heartbeat due before any full inventory")
+	}
+	ackInventory(full)
+	if !HeartbeatDue(false) || !HeartbeatDue(false) {
+		t.Error("heartbeats expected in the cycles after a full inventory")
+	}
+	if HeartbeatDue(false) {
+		t.Error("full inventory expected every 3 cycles")
+	}
+	ackInventory(full)
+	if HeartbeatDue(true) {
+		t.Error("forced cycles must collect the full inventory")
+	}
+
+	heartbeat := full
+	heartbeat.Heartbeat = true
+	if InventoryChanged(heartbeat) {
+		t.Error("unchanged machine reported as changed")
+	}
+	heartbeat.IP = "10.0.0.6"
+	if !InventoryChanged(heartbeat) {
+		t.Error("address change not detected")
+	}
+	ackInventory(heartbeat)
+	if InventoryChanged(full) {
+		t.Error("a heartbeat must not replace the inventory fingerprint")
+	}
+
+	Cfg.HeartbeatCycles = 0
+	if HeartbeatDue(false) {
+		t.Error("heartbeats sent with TATUSCAN_HEARTBEAT_CYCLES unset")
+	}
+}
+
+func TestHTTPSenderHeartbeat(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.HeartbeatCycles = 10
+	heartbeatState.cycles, heartbeatState.fingerprint = 0, "known"
+	t.Cleanup(func() { heartbeatState.cycles, heartbeatState.fingerprint = 0, "" })
+
+	status := http.StatusOK
+	var fields map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields = nil
+		json.NewDecoder(r.Body).Decode(&fields)
+		w.Header().Set(SchemaHeader, "1")
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	info := MachineInfo{SchemaVersion: SchemaVersion, MachineID: "abc", Hostname: "lab-01", OS: "linux", CPUPercent: 12, MemoryTotalMB: 8192, Heartbeat: true}
+	if err := sender.Send(context.Background(), info); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if string(fields["heartbeat"]) != "true" || string(fields["cpu_percent"]) != "12" || fields["hostname"] != nil || string(fields["machine_id"]) != `"abc"` {
+		t.Errorf("unexpected heartbeat: %s", fields)
+	}
+
+	// A server without the inventory answers 409 and the next cycle is full
+	status = http.StatusConflict
+	if err := sender.Send(context.Background(), info); err == nil {
+		t.Error("expected an error for a rejected heartbeat")
+	}
+	if HeartbeatDue(false) {
+		t.Error("full inventory expected after a rejected heartbeat")
+	}
+}
+
+func TestHTTPSenderHeartbeatAppliesReply(t *testing.T) {
+	setupTestAgent(t)
+	resetBackoff(t)
+	Cfg.HeartbeatCycles = 10
+	heartbeatState.cycles, heartbeatState.fingerprint = 0, "known"
+	t.Cleanup(func() { heartbeatState.cycles, heartbeatState.fingerprint = 0, "" })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"backoff": {"retry_after": "10m"}}`))
+	}))
+	defer srv.Close()
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{MachineID: "abc", Heartbeat: true}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if until, ok := ReportDeferred(time.Now()); !ok || time.Until(until) < 9*time.Minute {
+		t.Errorf("heartbeat reply backoff ignored: %s %v", until, ok)
+	}
+}
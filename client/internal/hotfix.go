@@ -0,0 +1,51 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hotfix is an installed Windows update (KB), as listed by
+// Win32_QuickFixEngineering
+type Hotfix struct {
+	ID          string `json:"id"`                     // KB5034441
+	Description string `json:"description,omitempty"`  // Update, Security Update, ...
+	InstalledOn string `json:"installed_on,omitempty"` // YYYY-MM-DD
+}
+
+// parseHotfixDate normalizes the InstalledOn of a hotfix to YYYY-MM-DD. It is
+// usually M/D/YYYY, but some systems report a hex FILETIME instead.
+func parseHotfixDate(value string) string {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("1/2/2006", value); err == nil {
+		return t.Format(time.DateOnly)
+	}
+	if len(value) == 16 {
+		if ft, err := strconv.ParseUint(value, 16, 64); err == nil {
+			// 100-nanosecond intervals since 1601-01-01
+			const epochDelta = 116444736000000000
+			if ft > epochDelta {
+				return time.Unix(0, int64(ft-epochDelta)*100).UTC().Format(time.DateOnly)
+			}
+		}
+	}
+	return ""
+}
+
+// sortHotfixes orders hotfixes by ID, dropping the duplicates WMI reports
+// for updates installed more than once
+func sortHotfixes(hotfixes []Hotfix) []Hotfix {
+	sort.SliceStable(hotfixes, func(i, j int) bool { return hotfixes[i].ID < hotfixes[j].ID })
+	out := hotfixes[:0]
+	for i, h := range hotfixes {
+		if i > 0 && h.ID == out[len(out)-1].ID {
+			continue
+		}
+		out = append(out, h)
+	}
+	return out
+}
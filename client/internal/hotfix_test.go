@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHotfixDate(t *testing.T) {
+	cases := map[string]string{
+		"3/14/2024":        "2024-03-14",
+		"12/01/2023":       "2023-12-01",
+		"01d2f3a9b8c7d000": "2017-07-03",
+		"":                 "",
+		"not a date":       "",
+	}
+	for value, want := range cases {
+		if got := parseHotfixDate(value); got != want {
+			t.Errorf("parseHotfixDate(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestSortHotfixes(t *testing.T) {
+	got := sortHotfixes([]Hotfix{
+		{ID: "KB5034441", InstalledOn: "2024-01-10"},
+		{ID: "KB5011048"},
+		{ID: "KB5034441", InstalledOn: "2024-02-01"},
+	})
+	want := []Hotfix{{ID: "KB5011048"}, {ID: "KB5034441", InstalledOn: "2024-01-10"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortHotfixes = %v, want %v", got, want)
+	}
+}
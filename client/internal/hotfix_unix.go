@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package internal
+
+import "context"
+
+// getHotfixes returns nil: hotfixes are Windows updates
+func getHotfixes(_ context.Context) []Hotfix {
+	return nil
+}
+
+// getWindowsUpdateSettings returns nil: Windows Update only exists on Windows
+func getWindowsUpdateSettings(_ context.Context) *WindowsUpdateSettings {
+	return nil
+}
@@ -0,0 +1,45 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/StackExchange/wmi"
+)
+
+// getHotfixes lists the installed updates from Win32_QuickFixEngineering
+func getHotfixes(ctx context.Context) []Hotfix {
+	type quickFix struct {
+		HotFixID    string
+		Description *string
+		InstalledOn *string
+	}
+	var fixes []quickFix
+	if err := wmiQuery(wmi.CreateQuery(&fixes, "", "Win32_QuickFixEngineering"), &fixes, ""); errors.Is(err, errWMIDisabled) {
+		Log.Debug("WMI disabled; hotfixes not collected")
+		return nil
+	} else if err != nil {
+		Log.Warnf("Error to query Win32_QuickFixEngineering: %v", err)
+		addWarning(ctx, WarnWMIUnavailable, "Win32_QuickFixEngineering query failed: %v", err)
+		return nil
+	}
+	var hotfixes []Hotfix
+	for _, f := range fixes {
+		id := strings.TrimSpace(f.HotFixID)
+		if id == "" || strings.EqualFold(id, "File 1") { // placeholder of broken entries
+			continue
+		}
+		hotfix := Hotfix{ID: strings.ToUpper(id)}
+		if f.Description != nil {
+			hotfix.Description = strings.TrimSpace(*f.Description)
+		}
+		if f.InstalledOn != nil {
+			hotfix.InstalledOn = parseHotfixDate(*f.InstalledOn)
+		}
+		hotfixes = append(hotfixes, hotfix)
+	}
+	return sortHotfixes(hotfixes)
+}
@@ -0,0 +1,176 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// identityFileName is the state file holding the cached MachineID
+const identityFileName = "identity.json"
+
+// identityState is the content persisted in the identity file. After a
+// migration PreviousMachineID holds the replaced ID until a report carrying
+// it is delivered; a reset leaves only PreviousMachineID.
+type identityState struct {
+	MachineID         string `json:"machine_id,omitempty"`
+	CreatedAt         string `json:"created_at,omitempty"`
+	PreviousMachineID string `json:"previous_machine_id,omitempty"`
+	// Salt fingerprints the TATUSCAN_MACHINE_ID_SALT the ID was computed with
+	Salt string `json:"salt,omitempty"`
+}
+
+// machineIDKey returns the HMAC key of the MachineID: the
+// TATUSCAN_MACHINE_ID_SALT when set, otherwise the pseudonym key under the
+// pseudonymous preset, so the IDs of a dataset cannot be recomputed from
+// MACs. It is nil for the plain hash.
+func machineIDKey() ([]byte, error) {
+	if Cfg.MachineIDSalt != "" {
+		return []byte(Cfg.MachineIDSalt), nil
+	}
+	if Cfg.Privacy == PrivacyPseudonymous {
+		return pseudonymKey()
+	}
+	return nil, nil
+}
+
+// saltFingerprint identifies the MachineID key without storing it; empty
+// without a key
+func saltFingerprint() string {
+	key, _ := machineIDKey()
+	if key == nil {
+		return ""
+	}
+	hash := sha256.Sum256(append([]byte("tatuscan-machine-id-salt:"), key...))
+	return hex.EncodeToString(hash[:8])
+}
+
+// identityFilePath returns the location of the identity file
+func identityFilePath() string {
+	return statePath(identityFileName)
+}
+
+// isValidMachineID checks that an ID looks like a hex SHA-256 digest
+func isValidMachineID(id string) bool {
+	if len(id) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// loadIdentity reads the cached identity, returning nil when absent or invalid
+func loadIdentity() *identityState {
+	data, err := os.ReadFile(identityFilePath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			Log.Warnf("Error to read identity file: %v", err)
+		}
+		return nil
+	}
+	var state identityState
+	err = json.Unmarshal(data, &state)
+	if err == nil && state.MachineID == "" && isValidMachineID(state.PreviousMachineID) {
+		return &state // reset, the ID is computed again
+	}
+	if err != nil || !isValidMachineID(state.MachineID) {
+		Log.Warnf("Ignoring invalid identity file %s", identityFilePath())
+		return nil
+	}
+	return &state
+}
+
+// saveIdentity persists the identity in the state directory
+func saveIdentity(state identityState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(identityFilePath(), data, 0o644)
+}
+
+// resolveMachineID returns the cached MachineID when present, otherwise
+// persists and returns the freshly computed one. With
+// TATUSCAN_MACHINE_ID_MIGRATE a computed ID that differs from the cached
+// one replaces it, and the replaced ID is sent as previous_machine_id so the
+// server can merge both records; info carries it until delivered.
+func resolveMachineID(ctx context.Context, computed string, info *MachineInfo) string {
+	cached := loadIdentity()
+	if cached != nil && cached.Salt != saltFingerprint() {
+		// Sending the ID of the old salt would link both, which the salt
+		// exists to prevent
+		Log.Infof("MachineID salt changed; discarding cached MachineID %s", cached.MachineID)
+		cached = nil
+	}
+	if cached != nil && cached.MachineID != "" {
+		switch {
+		case cached.MachineID == computed:
+			Log.Debugf("Cached MachineID matches computed value")
+		case Cfg.MachineIDMigrate:
+			Log.Infof("MachineID changed from %s to %s; migrating", cached.MachineID, computed)
+			addWarning(ctx, WarnMachineIDDrift, "physical MACs changed; MachineID migrated from %s", cached.MachineID)
+			cached = &identityState{PreviousMachineID: cached.MachineID, Salt: cached.Salt}
+		default:
+			Log.Infof("Reusing cached MachineID %s (computed %s differs)", cached.MachineID, computed)
+			addWarning(ctx, WarnMachineIDDrift, "physical MACs changed since the MachineID was cached")
+		}
+		if cached.MachineID != "" {
+			info.PreviousID = cached.PreviousMachineID
+			return cached.MachineID
+		}
+	}
+
+	state := identityState{MachineID: computed, CreatedAt: time.Now().Format(time.RFC3339), Salt: saltFingerprint()}
+	if cached != nil && cached.PreviousMachineID != computed {
+		state.PreviousMachineID = cached.PreviousMachineID
+	}
+	info.PreviousID = state.PreviousMachineID
+	if err := saveIdentity(state); err != nil {
+		Log.Warnf("Error to persist MachineID in %s: %v", Cfg.StateDir, err)
+		addWarning(ctx, WarnStateNotPersisted, "MachineID not cached: %v", err)
+	} else {
+		Log.Debugf("MachineID persisted in %s", identityFilePath())
+	}
+	return computed
+}
+
+// ResetMachineID discards the cached MachineID so it is recomputed next
+// cycle, keeping it to be sent as previous_machine_id
+func ResetMachineID() error {
+	if cached := loadIdentity(); cached != nil {
+		previous := cached.MachineID
+		if previous == "" {
+			previous = cached.PreviousMachineID
+		}
+		return saveIdentity(identityState{PreviousMachineID: previous, Salt: cached.Salt})
+	}
+	err := os.Remove(identityFilePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ackMachineIDMigration forgets the previous MachineID once a report
+// carrying it was delivered
+func ackMachineIDMigration(delivered string) {
+	if delivered == "" {
+		return
+	}
+	cached := loadIdentity()
+	if cached == nil || cached.MachineID == "" || cached.PreviousMachineID != delivered {
+		return
+	}
+	cached.PreviousMachineID = ""
+	if err := saveIdentity(*cached); err != nil {
+		Log.Warnf("Error to clear the previous MachineID: %v", err)
+		return
+	}
+	Log.Infof("MachineID migration from %s delivered", delivered)
+}
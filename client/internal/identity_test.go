@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// setupTestAgent configures logger and config for tests using a temp state
+// dir and an empty sysfs tree, so results don't depend on the host
+func setupTestAgent(t *testing.T) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	SetLogger(logger)
+	SetConfig(Config{StateDir: t.TempDir()})
+	serverSchema.version = SchemaVersion
+
+	origSysfs := sysfsRoot
+	sysfsRoot = t.TempDir()
+	t.Cleanup(func() { sysfsRoot = origSysfs })
+}
+
+func TestResolveMachineIDPersistsAndReuses(t *testing.T) {
+	setupTestAgent(t)
+	first := strings.Repeat("a", 64)
+	second := strings.Repeat("b", 64)
+
+	var info MachineInfo
+	if got := resolveMachineID(context.Background(), first, &info); got != first {
+		t.Fatalf("first resolve = %s, want %s", got, first)
+	}
+	// A changed NIC set must not change the reported ID
+	if got := resolveMachineID(context.Background(), second, &info); got != first || info.PreviousID != "" {
+		t.Errorf("second resolve = %s (previous %q), want cached %s", got, info.PreviousID, first)
+	}
+
+	// A reset recomputes the ID and reports the discarded one
+	if err := ResetMachineID(); err != nil {
+		t.Fatalf("ResetMachineID: %v", err)
+	}
+	if got := resolveMachineID(context.Background(), second, &info); got != second || info.PreviousID != first {
+		t.Errorf("resolve after reset = %s (previous %q), want %s (previous %s)", got, info.PreviousID, second, first)
+	}
+}
+
+func TestResolveMachineIDMigrates(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.MachineIDMigrate = true
+	first := strings.Repeat("a", 64)
+	second := strings.Repeat("b", 64)
+
+	resolveMachineID(context.Background(), first, &MachineInfo{})
+	takeWarnings()
+	var info MachineInfo
+	if got := resolveMachineID(context.Background(), second, &info); got != second || info.PreviousID != first {
+		t.Fatalf("migrated resolve = %s (previous %q), want %s (previous %s)", got, info.PreviousID, second, first)
+	}
+	if warnings := takeWarnings(); len(warnings) != 1 || warnings[0].Code != WarnMachineIDDrift {
+		t.Errorf("expected machine_id_drift, got %+v", warnings)
+	}
+
+	// The previous ID is sent until a report carrying it is delivered
+	info = MachineInfo{}
+	if got := resolveMachineID(context.Background(), second, &info); got != second || info.PreviousID != first {
+		t.Errorf("resolve before delivery = %s (previous %q)", got, info.PreviousID)
+	}
+	ackMachineIDMigration(first)
+	info = MachineInfo{}
+	if got := resolveMachineID(context.Background(), second, &info); got != second || info.PreviousID != "" {
+		t.Errorf("resolve after delivery = %s (previous %q)", got, info.PreviousID)
+	}
+}
+
+func TestIsValidMachineID(t *testing.T) {
+	if isValidMachineID("abc") {
+		t.Error("short ID accepted")
+	}
+	if isValidMachineID(strings.Repeat("z", 64)) {
+		t.Error("non-hex ID accepted")
+	}
+	if !isValidMachineID(strings.Repeat("0f", 32)) {
+		t.Error("valid ID rejected")
+	}
+}
+
+func TestMachineIDSalt(t *testing.T) {
+	setupTestAgent(t)
+	macs := []string{"00:1b:21:12:34:56"}
+	plain := computeMachineID(macs)
+	Cfg.MachineIDSalt = "staging"
+	staging := computeMachineID(macs)
+	Cfg.MachineIDSalt = "production"
+	production := computeMachineID(macs)
+	if plain == staging || staging == production || !isValidMachineID(staging) {
+		t.Errorf("salted IDs must differ: %s %s %s", plain, staging, production)
+	}
+
+	// A cached ID computed with another salt is discarded without linking
+	// both in previous_machine_id
+	Cfg.MachineIDSalt = "staging"
+	resolveMachineID(context.Background(), staging, &MachineInfo{})
+	Cfg.MachineIDSalt = "production"
+	var info MachineInfo
+	if got := resolveMachineID(context.Background(), production, &info); got != production || info.PreviousID != "" {
+		t.Errorf("resolve after salt change = %s (previous %q), want %s", got, info.PreviousID, production)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build darwin
+
+package internal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ifconfigMediaRe matches media lines like "media: autoselect (1000baseT <full-duplex>)"
+var ifconfigMediaRe = regexp.MustCompile(`media:.*\((\d+)(G|M)?base[^\s<)]*\s*(?:<([^>]*)>)?`)
+
+// getInterfaceDetails reports speed and duplex parsed from ifconfig media lines
+func getInterfaceDetails() []InterfaceDetail {
+	Log.Debug("Collecting interface details via ifconfig")
+	details := baseInterfaceDetails()
+	for i := range details {
+		output, err := runCommand("ifconfig", details[i].Name)
+		if err != nil {
+			continue
+		}
+		m := ifconfigMediaRe.FindStringSubmatch(string(output))
+		if m == nil {
+			continue
+		}
+		if speed, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			if m[2] == "G" {
+				speed *= 1000
+			}
+			details[i].SpeedMbps = speed
+		}
+		for _, opt := range strings.Split(m[3], ",") {
+			switch strings.TrimSpace(opt) {
+			case "full-duplex":
+				details[i].Duplex = "full"
+			case "half-duplex":
+				details[i].Duplex = "half"
+			}
+		}
+	}
+	return details
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readSysfsNet reads an attribute from /sys/class/net/<iface>
+func readSysfsNet(name, attr string) string {
+	data, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "net", name, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// getInterfaceDetails reports speed, duplex, driver and vendor from sysfs
+func getInterfaceDetails() []InterfaceDetail {
+	Log.Debug("Collecting interface details from sysfs")
+	var details []InterfaceDetail
+	for _, d := range baseInterfaceDetails() {
+		if link, err := os.Readlink(filepath.Join(sysfsRoot, "class", "net", d.Name)); err == nil && strings.Contains(link, "/virtual/") {
+			continue
+		}
+		// speed is -1 (or unreadable) when the link is down
+		if speed, err := strconv.ParseInt(readSysfsNet(d.Name, "speed"), 10, 64); err == nil && speed > 0 {
+			d.SpeedMbps = speed
+		}
+		if duplex := readSysfsNet(d.Name, "duplex"); duplex != "unknown" {
+			d.Duplex = duplex
+		}
+		if driver, err := os.Readlink(filepath.Join(sysfsRoot, "class", "net", d.Name, "device", "driver")); err == nil {
+			d.Driver = filepath.Base(driver)
+		}
+		d.Vendor = readSysfsNet(d.Name, "device/vendor")
+		details = append(details, d)
+	}
+	return details
+}
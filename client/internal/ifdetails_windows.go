@@ -0,0 +1,95 @@
+//go:build windows
+
+package internal
+
+import (
+	"strings"
+
+	"github.com/StackExchange/wmi"
+)
+
+// getInterfaceDetails reports speed, duplex, driver and vendor via WMI, using
+// MSFT_NetAdapter (Windows 8+) and falling back to Win32_NetworkAdapter
+func getInterfaceDetails() []InterfaceDetail {
+	details := baseInterfaceDetails()
+	byName := make(map[string]*InterfaceDetail, len(details))
+	for i := range details {
+		byName[strings.ToLower(details[i].Name)] = &details[i]
+	}
+
+	type netAdapter struct {
+		Name              *string
+		Speed             *uint64
+		FullDuplex        *bool
+		MtuSize           *uint32
+		DriverDescription *string
+		DriverProvider    *string
+	}
+	Log.Debug("Querying MSFT_NetAdapter via WMI")
+	var adapters []netAdapter
+	q := wmi.CreateQuery(&adapters, "", "MSFT_NetAdapter")
+	err := wmiQuery(q, &adapters, `root\StandardCimv2`)
+	if err == nil {
+		for _, a := range adapters {
+			if a.Name == nil {
+				continue
+			}
+			d, ok := byName[strings.ToLower(*a.Name)]
+			if !ok {
+				continue
+			}
+			if a.Speed != nil && *a.Speed > 0 {
+				d.SpeedMbps = int64(*a.Speed / 1_000_000)
+			}
+			if a.FullDuplex != nil {
+				d.Duplex = "half"
+				if *a.FullDuplex {
+					d.Duplex = "full"
+				}
+			}
+			if a.MtuSize != nil && *a.MtuSize > 0 {
+				d.MTU = int(*a.MtuSize)
+			}
+			if a.DriverDescription != nil {
+				d.Driver = *a.DriverDescription
+			}
+			if a.DriverProvider != nil {
+				d.Vendor = *a.DriverProvider
+			}
+		}
+		return details
+	}
+	Log.Debugf("MSFT_NetAdapter unavailable (%v); falling back to Win32_NetworkAdapter", err)
+
+	type win32NetworkAdapter struct {
+		NetConnectionID *string
+		Speed           *uint64
+		ServiceName     *string
+		Manufacturer    *string
+	}
+	var legacy []win32NetworkAdapter
+	q = wmi.CreateQuery(&legacy, "WHERE NetConnectionID IS NOT NULL", "Win32_NetworkAdapter")
+	if err := wmiQuery(q, &legacy, ""); err != nil {
+		Log.Warnf("Error to query Win32_NetworkAdapter: %v", err)
+		return details
+	}
+	for _, a := range legacy {
+		if a.NetConnectionID == nil {
+			continue
+		}
+		d, ok := byName[strings.ToLower(*a.NetConnectionID)]
+		if !ok {
+			continue
+		}
+		if a.Speed != nil && *a.Speed > 0 {
+			d.SpeedMbps = int64(*a.Speed / 1_000_000)
+		}
+		if a.ServiceName != nil {
+			d.Driver = *a.ServiceName
+		}
+		if a.Manufacturer != nil {
+			d.Vendor = *a.Manufacturer
+		}
+	}
+	return details
+}
@@ -0,0 +1,86 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"net"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ipCandidate is an IPv4 address eligible to be reported as the primary IP
+type ipCandidate struct {
+	Interface string
+	IP        net.IP
+	Wireless  bool
+}
+
+// wirelessNamePatterns are name fragments of wireless adapters, used where the
+// OS offers no better signal
+var wirelessNamePatterns = []string{"wi-fi", "wifi", "wireless", "wlan", "802.11"}
+
+// isWirelessName checks an interface name against wirelessNamePatterns
+func isWirelessName(name string) bool {
+	nameLower := strings.ToLower(name)
+	for _, pattern := range wirelessNamePatterns {
+		if strings.Contains(nameLower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcludedInterface matches a name against TATUSCAN_EXCLUDE_INTERFACES
+// entries, which may be exact names or glob patterns (e.g. "wlan*")
+func isExcludedInterface(name string, excluded []string) bool {
+	for _, pattern := range excluded {
+		if strings.EqualFold(pattern, name) {
+			return true
+		}
+		if ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(name)); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// subnetRank returns the index of the first preferred subnet containing ip,
+// or len(subnets) when none does
+func subnetRank(ip net.IP, subnets []*net.IPNet) int {
+	for i, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return i
+		}
+	}
+	return len(subnets)
+}
+
+// selectPrimaryIP applies the interface selection policy: excluded interfaces
+// are dropped, then candidates in preferred subnets win, then wired over
+// wireless (when configured), keeping enumeration order as the tie-breaker
+func selectPrimaryIP(candidates []ipCandidate) (ipCandidate, bool) {
+	var eligible []ipCandidate
+	for _, c := range candidates {
+		if isExcludedInterface(c.Interface, Cfg.ExcludeInterfaces) {
+			Log.Debugf("Interface %s not eligible for primary IP: excluded by policy", c.Interface)
+			continue
+		}
+		eligible = append(eligible, c)
+	}
+	if len(eligible) == 0 {
+		return ipCandidate{}, false
+	}
+
+	sort.SliceStable(eligible, func(i, j int) bool {
+		ri, rj := subnetRank(eligible[i].IP, Cfg.PreferSubnets), subnetRank(eligible[j].IP, Cfg.PreferSubnets)
+		if ri != rj {
+			return ri < rj
+		}
+		if Cfg.PreferWired && eligible[i].Wireless != eligible[j].Wireless {
+			return !eligible[i].Wireless
+		}
+		return false
+	})
+	return eligible[0], true
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"net"
+	"testing"
+)
+
+func TestSelectPrimaryIP(t *testing.T) {
+	setupTestAgent(t)
+	defer SetConfig(Config{})
+
+	candidates := []ipCandidate{
+		{Interface: "wlp2s0", IP: net.ParseIP("192.168.0.20"), Wireless: true},
+		{Interface: "enp3s0", IP: net.ParseIP("10.20.1.15")},
+		{Interface: "enx001122", IP: net.ParseIP("172.16.5.3")},
+	}
+	_, labNet, _ := net.ParseCIDR("172.16.0.0/16")
+
+	tests := []struct {
+		name     string
+		cfg      Config
+		expected string
+	}{
+		{"Enumeration order by default", Config{}, "wlp2s0"},
+		{"Prefer wired", Config{PreferWired: true}, "enp3s0"},
+		{"Preferred subnet wins over wired", Config{PreferWired: true, PreferSubnets: []*net.IPNet{labNet}}, "enx001122"},
+		{"Exclude by glob", Config{ExcludeInterfaces: []string{"wl*"}}, "enp3s0"},
+		{"Exclude by name", Config{ExcludeInterfaces: []string{"WLP2S0", "enp3s0"}}, "enx001122"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetConfig(tt.cfg)
+			selected, ok := selectPrimaryIP(candidates)
+			if !ok || selected.Interface != tt.expected {
+				t.Errorf("selected %q (ok=%v), want %q", selected.Interface, ok, tt.expected)
+			}
+		})
+	}
+
+	SetConfig(Config{ExcludeInterfaces: []string{"*"}})
+	if _, ok := selectPrimaryIP(candidates); ok {
+		t.Error("expected no selection when all interfaces are excluded")
+	}
+}
@@ -0,0 +1,82 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"os"
+	"strings"
+)
+
+// ImageInfo describes the deployment image the machine was installed from
+type ImageInfo struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Date    string `json:"date,omitempty"`
+}
+
+// parseKeyValueLines parses KEY=VALUE lines, ignoring comments and quotes
+func parseKeyValueLines(data string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return values
+}
+
+// imageInfoFromValues maps marker keys (IMAGE_NAME or NAME, etc.) to ImageInfo
+func imageInfoFromValues(values map[string]string) *ImageInfo {
+	pick := func(keys ...string) string {
+		for _, key := range keys {
+			for k, v := range values {
+				if strings.EqualFold(k, key) && v != "" {
+					return v
+				}
+			}
+		}
+		return ""
+	}
+	info := &ImageInfo{
+		Name:    pick("IMAGE_NAME", "ImageName", "NAME"),
+		Version: pick("IMAGE_VERSION", "ImageVersion", "VERSION"),
+		Date:    pick("IMAGE_DATE", "ImageDate", "DATE"),
+	}
+	if *info == (ImageInfo{}) {
+		return nil
+	}
+	return info
+}
+
+// getImageInfo reads the image markers from the configured file or registry key
+func getImageInfo() *ImageInfo {
+	if Cfg.ImageMarkerFile != "" {
+		Log.Debugf("Reading image marker file %s", Cfg.ImageMarkerFile)
+		data, err := os.ReadFile(Cfg.ImageMarkerFile)
+		if err != nil {
+			Log.Warnf("Error to read image marker file: %v", err)
+		} else if info := imageInfoFromValues(parseKeyValueLines(string(data))); info != nil {
+			Log.Debugf("Image detected: %+v", *info)
+			return info
+		}
+	}
+
+	if Cfg.ImageRegistryKey != "" {
+		Log.Debugf("Reading image marker registry key %s", Cfg.ImageRegistryKey)
+		values, err := readImageRegistry(Cfg.ImageRegistryKey)
+		if err != nil {
+			Log.Warnf("Error to read image marker registry key: %v", err)
+		} else if info := imageInfoFromValues(values); info != nil {
+			Log.Debugf("Image detected: %+v", *info)
+			return info
+		}
+	}
+
+	return nil
+}
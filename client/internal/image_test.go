@@ -0,0 +1,32 @@
+package internal
+
+import "testing"
+
+func TestImageInfoFromMarkerFile(t *testing.T) {
+	data := `# written by deployment
+IMAGE_NAME="lab-golden"
+IMAGE_VERSION=2025.1
+IMAGE_DATE='2025-02-10'
+`
+	info := imageInfoFromValues(parseKeyValueLines(data))
+	if info == nil {
+		t.Fatal("imageInfoFromValues returned nil")
+	}
+	expected := ImageInfo{Name: "lab-golden", Version: "2025.1", Date: "2025-02-10"}
+	if *info != expected {
+		t.Errorf("image = %+v, want %+v", *info, expected)
+	}
+}
+
+func TestImageInfoFromRegistryValues(t *testing.T) {
+	info := imageInfoFromValues(map[string]string{"ImageName": "win11-lab", "ImageVersion": "3"})
+	if info == nil || info.Name != "win11-lab" || info.Version != "3" || info.Date != "" {
+		t.Errorf("unexpected image info: %+v", info)
+	}
+}
+
+func TestImageInfoWithoutMarkers(t *testing.T) {
+	if info := imageInfoFromValues(map[string]string{"OTHER": "x"}); info != nil {
+		t.Errorf("expected nil, got %+v", info)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build linux || darwin
+
+package internal
+
+import "fmt"
+
+// readImageRegistry is not supported outside Windows
+func readImageRegistry(key string) (map[string]string, error) {
+	return nil, fmt.Errorf("registry markers are only supported on Windows")
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// readImageRegistry reads all string values under a HKLM key such as
+// HKLM\SOFTWARE\Org\Image (the HKLM\ prefix is optional)
+func readImageRegistry(key string) (map[string]string, error) {
+	path := key
+	for _, prefix := range []string{`HKLM\`, `HKEY_LOCAL_MACHINE\`} {
+		if len(path) >= len(prefix) && strings.EqualFold(path[:len(prefix)], prefix) {
+			path = path[len(prefix):]
+			break
+		}
+	}
+
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", key, err)
+	}
+	defer k.Close()
+
+	names, err := k.ReadValueNames(0)
+	if err != nil {
+		return nil, fmt.Errorf("list values of %s: %w", key, err)
+	}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		if v, _, err := k.GetStringValue(name); err == nil {
+			values[name] = v
+		}
+	}
+	return values, nil
+}
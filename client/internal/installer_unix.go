@@ -0,0 +1,6 @@
+//go:build linux || darwin
+
+package internal
+
+// ApplyInstallerProperties is a no-op: Unix packages ship tatuscan.env
+func ApplyInstallerProperties() {}
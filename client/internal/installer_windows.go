@@ -0,0 +1,49 @@
+//go:build windows
+
+package internal
+
+import "golang.org/x/sys/windows/registry"
+
+// installerRegistryKey holds the properties given to the MSI (SERVERURL,
+// TOKEN, TAGS) until the agent moves them to the settings file
+const installerRegistryKey = `SOFTWARE\TatuScan\Installer`
+
+// installerProperties maps installer registry values to settings
+var installerProperties = map[string]string{
+	"ServerURL": "TATUSCAN_URL",
+	"Token":     "TATUSCAN_TOKEN",
+	"Tags":      "TATUSCAN_TAGS",
+}
+
+// ApplyInstallerProperties writes the properties left by the MSI into the
+// settings file in the config directory, then deletes them from the
+// registry so the token only lives in the restricted config directory
+func ApplyInstallerProperties() {
+	if !isPrivileged() {
+		return
+	}
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, installerRegistryKey, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return
+	}
+	values := make(map[string]string)
+	for name, key := range installerProperties {
+		if value, _, err := k.GetStringValue(name); err == nil && value != "" {
+			values[key] = value
+		}
+	}
+	k.Close()
+	if len(values) == 0 {
+		return
+	}
+
+	path, err := WriteConfigFile(values)
+	if err != nil {
+		Log.Errorf("Error to write installer properties to %s: %v", path, err)
+		return
+	}
+	Log.Infof("Installer properties written to %s", path)
+	if err := registry.DeleteKey(registry.LOCAL_MACHINE, installerRegistryKey); err != nil {
+		Log.Warnf("Error to delete installer properties: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LaunchdLabel is the label of the agent LaunchDaemon, the service name
+// also used by `tatuscan install`, so start/stop/uninstall manage either
+const LaunchdLabel = "TatuScanAgent"
+
+// LaunchdPlistPath is where the LaunchDaemon is installed
+const LaunchdPlistPath = "/Library/LaunchDaemons/" + LaunchdLabel + ".plist"
+
+// defaultLaunchdLog is the log of the LaunchDaemon output
+const defaultLaunchdLog = "/Library/Logs/TatuScan/tatuscan.log"
+
+// secretSettings never go to a LaunchDaemon plist, which is world-readable,
+// and are masked by the status command
+var secretSettings = []string{"TATUSCAN_TOKEN", "TATUSCAN_HMAC_SECRET", "TATUSCAN_MACHINE_ID_SALT"}
+
+// secretSetting reports whether key is a secret setting; custom headers are
+// secret too, as they often carry gateway credentials
+func secretSetting(key string) bool {
+	return slices.Contains(secretSettings, key) || strings.HasPrefix(key, headerVariablePrefix)
+}
+
+// LaunchdJob describes the agent LaunchDaemon
+type LaunchdJob struct {
+	Program string
+	Args    []string
+	Env     map[string]string
+	LogPath string // stdout and stderr; defaultLaunchdLog when empty
+}
+
+// LaunchdEnv returns the TATUSCAN_* variables of the current environment
+// with values on top, without the secret settings
+func LaunchdEnv(values map[string]string) map[string]string {
+	env := make(map[string]string)
+	for key, value := range mergeEnv(environMap(os.Environ()), values) {
+		if strings.HasPrefix(key, "TATUSCAN_") && !secretSetting(key) && value != "" {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// logPath returns LogPath or the default log
+func (j LaunchdJob) logPath() string {
+	if j.LogPath == "" {
+		return defaultLaunchdLog
+	}
+	return j.LogPath
+}
+
+// Plist renders the LaunchDaemon property list: started at boot, restarted
+// by launchd when it exits, with the environment and the log paths set
+func (j LaunchdJob) Plist() []byte {
+	logPath := j.logPath()
+	var b bytes.Buffer
+	str := func(indent, value string) {
+		b.WriteString(indent + "<string>")
+		xml.EscapeText(&b, []byte(value))
+		b.WriteString("</string>\n")
+	}
+	key := func(indent, name string) {
+		b.WriteString(indent + "<key>")
+		xml.EscapeText(&b, []byte(name))
+		b.WriteString("</key>\n")
+	}
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	key("\t", "Label")
+	str("\t", LaunchdLabel)
+	key("\t", "ProgramArguments")
+	b.WriteString("\t<array>\n")
+	for _, arg := range append([]string{j.Program}, j.Args...) {
+		str("\t\t", arg)
+	}
+	b.WriteString("\t</array>\n")
+	if len(j.Env) > 0 {
+		names := make([]string, 0, len(j.Env))
+		for name := range j.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		key("\t", "EnvironmentVariables")
+		b.WriteString("\t<dict>\n")
+		for _, name := range names {
+			key("\t\t", name)
+			str("\t\t", j.Env[name])
+		}
+		b.WriteString("\t</dict>\n")
+	}
+	key("\t", "RunAtLoad")
+	b.WriteString("\t<true/>\n")
+	key("\t", "KeepAlive")
+	b.WriteString("\t<true/>\n")
+	// Throttle restarts of an agent failing at start
+	key("\t", "ThrottleInterval")
+	b.WriteString("\t<integer>30</integer>\n")
+	key("\t", "StandardOutPath")
+	str("\t", logPath)
+	key("\t", "StandardErrorPath")
+	str("\t", logPath)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes()
+}
+
+// InstallLaunchdJob writes the LaunchDaemon plist and (re)loads it into the
+// system domain of launchd
+func InstallLaunchdJob(j LaunchdJob) error {
+	if err := os.MkdirAll(filepath.Dir(j.logPath()), 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	if err := os.WriteFile(LaunchdPlistPath, j.Plist(), 0o644); err != nil {
+		return err
+	}
+	// A loaded job keeps its old definition until it is booted out
+	runCommand("launchctl", "bootout", "system/"+LaunchdLabel)
+	if _, err := runCommand("launchctl", "bootstrap", "system", LaunchdPlistPath); err != nil {
+		return fmt.Errorf("launchctl bootstrap: %w", err)
+	}
+	return nil
+}
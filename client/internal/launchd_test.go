@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestLaunchdPlist(t *testing.T) {
+	t.Setenv("TATUSCAN_TOKEN", "secret")
+	t.Setenv("TATUSCAN_TAGS", "lab&physics")
+	env := LaunchdEnv(map[string]string{"TATUSCAN_URL": "https://tatuscan.example.com"})
+	if _, ok := env["TATUSCAN_TOKEN"]; ok || env["TATUSCAN_TAGS"] != "lab&physics" {
+		t.Fatalf("unexpected environment: %v", env)
+	}
+
+	plist := string(LaunchdJob{Program: "/usr/local/bin/tatuscan", Env: env}.Plist())
+	for _, want := range []string{
+		"<key>KeepAlive</key>\n\t<true/>",
+		"<string>lab&amp;physics</string>",
+		"<key>TATUSCAN_URL</key>\n\t\t<string>https://tatuscan.example.com</string>",
+		"<string>" + defaultLaunchdLog + "</string>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist lacks %q:\n%s", want, plist)
+		}
+	}
+	if err := xml.Unmarshal([]byte(plist), new(struct{})); err != nil {
+		t.Errorf("plist is not well-formed XML: %v", err)
+	}
+}
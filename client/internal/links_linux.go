@@ -0,0 +1,76 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxLinkDepth bounds the lower links followed, as in a VLAN over a bond
+// enslaved to a bridge
+const maxLinkDepth = 4
+
+// linkKind returns the DEVTYPE of a network device (bond, bridge, vlan)
+func linkKind(name string) string {
+	data, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "net", name, "uevent"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if kind, ok := strings.CutPrefix(line, "DEVTYPE="); ok {
+			return strings.TrimSpace(kind)
+		}
+	}
+	return ""
+}
+
+// lowerLinks lists the devices directly under a network device: the
+// lower_* links, or the brif directory of bridges on older kernels
+func lowerLinks(name string) []string {
+	base := filepath.Join(sysfsRoot, "class", "net", name)
+	var lowers []string
+	links, _ := filepath.Glob(filepath.Join(base, "lower_*"))
+	for _, link := range links {
+		lowers = append(lowers, strings.TrimPrefix(filepath.Base(link), "lower_"))
+	}
+	if len(lowers) == 0 {
+		ports, _ := os.ReadDir(filepath.Join(base, "brif"))
+		for _, port := range ports {
+			lowers = append(lowers, port.Name())
+		}
+	}
+	return lowers
+}
+
+// collectLinkMembers appends the physical devices under name
+func collectLinkMembers(name string, depth int, members []linkMember) []linkMember {
+	for _, lower := range lowerLinks(name) {
+		if isVirtualLinuxBySysfs(lower) {
+			if depth < maxLinkDepth {
+				members = collectLinkMembers(lower, depth+1, members)
+			}
+			continue
+		}
+		base := filepath.Join(sysfsRoot, "class", "net", lower)
+		mac := readSysfsValue(base, "bonding_slave", "perm_hwaddr")
+		if mac == "" {
+			mac = readSysfsValue(base, "address")
+		}
+		members = append(members, linkMember{Name: lower, MAC: mac})
+	}
+	return members
+}
+
+// platformLinkMembers resolves the physical interfaces under a bond, bridge
+// or VLAN through sysfs
+func platformLinkMembers(name string) (string, []linkMember) {
+	kind := linkKind(name)
+	switch kind {
+	case "bond", "bridge", "vlan":
+	default:
+		return "", nil
+	}
+	return kind, collectLinkMembers(name, 1, nil)
+}
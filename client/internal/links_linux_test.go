@@ -0,0 +1,98 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeNetDevice creates a sysfs network device under devices/<parent> and
+// its class/net link, with the given attribute files
+func writeNetDevice(t *testing.T, root, parent, name string, attrs map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, "devices", parent, "net", name)
+	for attr, value := range attrs {
+		path := filepath.Join(dir, attr)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "class", "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..", "..", "devices", parent, "net", name), filepath.Join(root, "class", "net", name)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// linkLower adds a lower_<lower> link to an upper device
+func linkLower(t *testing.T, root, upper, lower string) {
+	t.Helper()
+	if err := os.Symlink(filepath.Join("..", lower), filepath.Join(root, "class", "net", upper, "lower_"+lower)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlatformLinkMembers(t *testing.T) {
+	setupTestAgent(t)
+	orig := sysfsRoot
+	sysfsRoot = t.TempDir()
+	t.Cleanup(func() { sysfsRoot = orig })
+
+	bondMAC := "00:1b:21:aa:00:01"
+	writeNetDevice(t, sysfsRoot, "pci0000:00/0000:00:1f.6", "eth0", map[string]string{
+		"address": bondMAC, "bonding_slave/perm_hwaddr": "00:1b:21:aa:00:01",
+	})
+	writeNetDevice(t, sysfsRoot, "pci0000:00/0000:00:1c.0", "eth1", map[string]string{
+		"address": bondMAC, "bonding_slave/perm_hwaddr": "00:1b:21:aa:00:02",
+	})
+	writeNetDevice(t, sysfsRoot, "virtual", "bond0", map[string]string{"uevent": "DEVTYPE=bond\nINTERFACE=bond0", "address": bondMAC})
+	linkLower(t, sysfsRoot, "bond0", "eth0")
+	linkLower(t, sysfsRoot, "bond0", "eth1")
+	writeNetDevice(t, sysfsRoot, "virtual", "bond0.20", map[string]string{"uevent": "DEVTYPE=vlan\nINTERFACE=bond0.20"})
+	linkLower(t, sysfsRoot, "bond0.20", "bond0")
+	writeNetDevice(t, sysfsRoot, "virtual", "veth1a2b", map[string]string{"uevent": "INTERFACE=veth1a2b"})
+
+	kind, members := platformLinkMembers("bond0")
+	want := []linkMember{{Name: "eth0", MAC: "00:1b:21:aa:00:01"}, {Name: "eth1", MAC: "00:1b:21:aa:00:02"}}
+	if kind != "bond" || !reflect.DeepEqual(members, want) {
+		t.Errorf("bond0 = %q %+v, want bond %+v", kind, members, want)
+	}
+	if kind, members := platformLinkMembers("bond0.20"); kind != "vlan" || !reflect.DeepEqual(members, want) {
+		t.Errorf("bond0.20 = %q %+v, want vlan over the bond slaves", kind, members)
+	}
+	if kind, members := platformLinkMembers("veth1a2b"); kind != "" || members != nil {
+		t.Errorf("veth1a2b = %q %+v, want nothing", kind, members)
+	}
+
+	// The bond carries the address and its slaves identify the machine
+	up := net.FlagUp | net.FlagBroadcast | net.FlagMulticast
+	withInterfaces(t,
+		MockInterface{name: "eth0", flags: up, hardwareAddr: mustParseMAC(bondMAC)},
+		MockInterface{name: "eth1", flags: up, hardwareAddr: mustParseMAC(bondMAC)},
+		MockInterface{name: "bond0", flags: up, hardwareAddr: mustParseMAC(bondMAC), addrs: []net.Addr{createMockIPv4Addr("10.0.0.5")}},
+		MockInterface{name: "veth1a2b", flags: up, hardwareAddr: mustParseMAC("00:1b:21:aa:00:09"), addrs: []net.Addr{createMockIPv4Addr("172.18.0.1")}},
+	)
+	var info MachineInfo
+	if err := collectNetwork(context.Background(), &info); err != nil {
+		t.Fatalf("collectNetwork: %v", err)
+	}
+	if info.IP != "10.0.0.5" {
+		t.Errorf("IP = %s, want 10.0.0.5", info.IP)
+	}
+	if want := computeMachineID([]string{"00:1b:21:aa:00:01", "00:1b:21:aa:00:02"}); info.MachineID != want {
+		t.Errorf("MachineID = %s, want %s", info.MachineID, want)
+	}
+	wantLinks := []LinkTopology{{Interface: "bond0", Kind: "bond", Members: []string{"eth0", "eth1"}}}
+	if !reflect.DeepEqual(info.Links, wantLinks) {
+		t.Errorf("links = %+v, want %+v", info.Links, wantLinks)
+	}
+}
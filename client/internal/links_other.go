@@ -0,0 +1,9 @@
+//go:build windows || darwin
+
+package internal
+
+// platformLinkMembers resolves nothing: Windows reports teamed adapters as
+// physical through WMI, and macOS bonds are not resolved
+func platformLinkMembers(name string) (string, []linkMember) {
+	return "", nil
+}
@@ -0,0 +1,98 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"net"
+	"sort"
+	"syscall"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ListeningPort is a TCP socket in LISTEN state or an unconnected UDP socket
+type ListeningPort struct {
+	Protocol string `json:"protocol"` // tcp, tcp6, udp, udp6
+	Address  string `json:"address"`
+	Port     uint32 `json:"port"`
+	PID      int32  `json:"pid,omitempty"`
+	Process  string `json:"process,omitempty"`
+}
+
+// socketLister lists the inet sockets of every process; tests replace it
+var socketLister = func() ([]psnet.ConnectionStat, error) {
+	return psnet.Connections("inet")
+}
+
+// processName returns the name of a process, or "" when it is gone or
+// not accessible; tests replace it
+var processName = func(pid int32) string {
+	p, err := process.NewProcess(pid)
+	if err != nil {
+		return ""
+	}
+	name, _ := p.Name()
+	return name
+}
+
+// listeningPorts keeps the listening sockets not bound to loopback,
+// deduplicated (SO_REUSEPORT) and sorted by protocol, port and address
+func listeningPorts(conns []psnet.ConnectionStat) []ListeningPort {
+	seen := make(map[ListeningPort]bool)
+	var ports []ListeningPort
+	for _, c := range conns {
+		listening := c.Status == "LISTEN" || (c.Type == syscall.SOCK_DGRAM && c.Raddr.IP == "")
+		if !listening {
+			continue
+		}
+		if ip := net.ParseIP(c.Laddr.IP); ip != nil && ip.IsLoopback() {
+			continue
+		}
+		port := ListeningPort{Protocol: connectionProtocol(c), Address: c.Laddr.IP, Port: c.Laddr.Port, PID: c.Pid}
+		if seen[port] {
+			continue
+		}
+		seen[port] = true
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.Address < b.Address
+	})
+	return ports
+}
+
+// getListeningPorts reports the ports open to the network with their owning
+// process when TATUSCAN_LISTENERS is enabled (owners of sockets of other
+// users are only visible when running privileged)
+func getListeningPorts() []ListeningPort {
+	if !Cfg.Listeners {
+		return nil
+	}
+	Log.Debug("Listing listening sockets")
+	conns, err := socketLister()
+	if err != nil {
+		Log.Warnf("Error to list sockets: %v", err)
+		return nil
+	}
+	ports := listeningPorts(conns)
+	names := make(map[int32]string)
+	for i := range ports {
+		pid := ports[i].PID
+		if pid == 0 {
+			continue
+		}
+		if _, ok := names[pid]; !ok {
+			names[pid] = processName(pid)
+		}
+		ports[i].Process = names[pid]
+	}
+	return ports
+}
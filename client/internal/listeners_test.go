@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+func TestGetListeningPorts(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Listeners = true
+	origLister, origName := socketLister, processName
+	t.Cleanup(func() { socketLister, processName = origLister, origName })
+
+	tcp := func(ip string, port uint32, status string, pid int32) psnet.ConnectionStat {
+		return psnet.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Laddr: psnet.Addr{IP: ip, Port: port}, Status: status, Pid: pid}
+	}
+	udp := psnet.ConnectionStat{Family: syscall.AF_INET6, Type: syscall.SOCK_DGRAM, Laddr: psnet.Addr{IP: "::", Port: 69}, Pid: 300}
+	established := tcp("10.0.0.5", 22, "ESTABLISHED", 100)
+	established.Raddr = psnet.Addr{IP: "10.0.0.9", Port: 50000}
+	socketLister = func() ([]psnet.ConnectionStat, error) {
+		return []psnet.ConnectionStat{
+			tcp("0.0.0.0", 22, "LISTEN", 100),
+			tcp("0.0.0.0", 21, "LISTEN", 200),
+			tcp("0.0.0.0", 21, "LISTEN", 200), // SO_REUSEPORT duplicate
+			tcp("127.0.0.1", 5432, "LISTEN", 400),
+			established,
+			udp,
+		}, nil
+	}
+	processName = func(pid int32) string {
+		return map[int32]string{100: "sshd", 200: "vsftpd", 300: "in.tftpd"}[pid]
+	}
+
+	want := []ListeningPort{
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 21, PID: 200, Process: "vsftpd"},
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 22, PID: 100, Process: "sshd"},
+		{Protocol: "udp6", Address: "::", Port: 69, PID: 300, Process: "in.tftpd"},
+	}
+	if got := getListeningPorts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("getListeningPorts =\n%+v\nwant\n%+v", got, want)
+	}
+
+	Cfg.Listeners = false
+	if got := getListeningPorts(); got != nil {
+		t.Errorf("expected nil when disabled, got %+v", got)
+	}
+}
@@ -0,0 +1,103 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLogDedupWindow is how long identical warnings and errors are held
+// back before a summary line is written
+const defaultLogDedupWindow = time.Hour
+
+// maxDedupEntries bounds the messages tracked at once, so messages embedding
+// changing values cannot grow the table without limit
+const maxDedupEntries = 256
+
+// dedupState tracks one distinct message
+type dedupState struct {
+	first      time.Time // start of the current window
+	suppressed int
+}
+
+// DedupFormatter wraps a formatter and suppresses repeated warnings and
+// errors: the first occurrence is written, identical ones within the window
+// are counted, and the next one after the window is written with the count.
+// A server down for hours then logs once per window instead of every cycle.
+type DedupFormatter struct {
+	logrus.Formatter
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*dedupState
+}
+
+// NewDedupFormatter returns a DedupFormatter over base; a window of zero
+// or less disables the suppression
+func NewDedupFormatter(base logrus.Formatter, window time.Duration) *DedupFormatter {
+	return &DedupFormatter{Formatter: base, window: window, now: time.Now, entries: map[string]*dedupState{}}
+}
+
+// Format implements logrus.Formatter; suppressed entries format to nothing
+func (f *DedupFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	// Fatal and panic end the process and lower levels are for diagnosis
+	if f.window <= 0 || (entry.Level != logrus.WarnLevel && entry.Level != logrus.ErrorLevel) {
+		return f.Formatter.Format(entry)
+	}
+	key := fmt.Sprint(entry.Level, entry.Message, dedupFields(entry.Data))
+	now := f.now()
+
+	f.mu.Lock()
+	state, seen := f.entries[key]
+	if seen && now.Sub(state.first) < f.window {
+		state.suppressed++
+		f.mu.Unlock()
+		return nil, nil
+	}
+	suppressed, since := 0, time.Duration(0)
+	if seen {
+		suppressed, since = state.suppressed, now.Sub(state.first)
+	} else if len(f.entries) >= maxDedupEntries {
+		f.prune(now)
+	}
+	f.entries[key] = &dedupState{first: now}
+	f.mu.Unlock()
+
+	if suppressed > 0 {
+		summary := *entry
+		summary.Message = fmt.Sprintf("%s (repeated %d more times in the last %s)",
+			entry.Message, suppressed, since.Round(time.Second))
+		return f.Formatter.Format(&summary)
+	}
+	return f.Formatter.Format(entry)
+}
+
+// dedupFields returns the fields identifying a message, without the
+// volatile log context (see LogContextHook)
+func dedupFields(data logrus.Fields) logrus.Fields {
+	fields := make(logrus.Fields, len(data))
+	for key, value := range data {
+		if !containsString(volatileLogKeys, key) {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// prune drops messages whose window has ended, or every message when all
+// are still active; the caller holds f.mu
+func (f *DedupFormatter) prune(now time.Time) {
+	for key, state := range f.entries {
+		if now.Sub(state.first) >= f.window {
+			delete(f.entries, key)
+		}
+	}
+	if len(f.entries) >= maxDedupEntries {
+		clear(f.entries)
+	}
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDedupFormatter(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	formatter := NewDedupFormatter(&logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}, time.Hour)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	formatter.now = func() time.Time { return now }
+	logger.SetFormatter(formatter)
+
+	for i := 0; i < 5; i++ {
+		logger.Error("Error sending data: connection refused")
+		logger.Info("Starting data collection")
+		now = now.Add(time.Minute)
+	}
+	logger.WithField("collector", "sensors").Error("Error sending data: connection refused")
+	if got := strings.Count(out.String(), "connection refused"); got != 2 {
+		t.Errorf("expected the error once plus the one with other fields, got %d:\n%s", got, out.String())
+	}
+	if got := strings.Count(out.String(), "Starting data collection"); got != 5 {
+		t.Errorf("info lines must not be suppressed, got %d", got)
+	}
+
+	out.Reset()
+	now = now.Add(time.Hour)
+	logger.Error("Error sending data: connection refused")
+	if want := "connection refused (repeated 4 more times in the last 1h5m0s)"; !strings.Contains(out.String(), want) {
+		t.Errorf("summary line = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	logger.Error("Error sending data: connection refused")
+	if out.Len() != 0 {
+		t.Errorf("expected a new window after the summary, got %q", out.String())
+	}
+}
@@ -0,0 +1,66 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// eventLogSource is the Windows Event Log source of the agent entries
+const eventLogSource = "TatuScan"
+
+// Event IDs of the agent entries, by severity
+const (
+	eventIDError   = 1
+	eventIDWarning = 2
+)
+
+// eventLogWriter is the part of *eventlog.Log used by the hook
+type eventLogWriter interface {
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+}
+
+// eventLogHook copies warnings and errors to the Windows Event Log, where
+// a service has no console. It keeps its own repeated log suppression, as
+// the logger output keeps formatting every entry.
+type eventLogHook struct {
+	w         eventLogWriter
+	formatter logrus.Formatter
+}
+
+// Levels implements logrus.Hook
+func (h *eventLogHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}
+}
+
+// Fire implements logrus.Hook
+func (h *eventLogHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil || len(line) == 0 {
+		return err
+	}
+	msg := strings.TrimRight(string(line), "\n")
+	if entry.Level == logrus.WarnLevel {
+		return h.w.Warning(eventIDWarning, msg)
+	}
+	return h.w.Error(eventIDError, msg)
+}
+
+// UseEventLog adds the Windows Event Log to the logger outputs for
+// warnings and errors, under the TatuScan source of the Application log
+// (registered on first use). It fails on other systems.
+func UseEventLog(logger *logrus.Logger, format string) error {
+	w, err := openEventLog()
+	if err != nil {
+		return err
+	}
+	formatter := NewLogFormatter(format, false)
+	if Cfg.LogDedup {
+		formatter = NewDedupFormatter(formatter, Cfg.LogDedupWindow)
+	}
+	logger.AddHook(&eventLogHook{w: w, formatter: formatter})
+	return nil
+}
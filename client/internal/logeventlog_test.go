@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeEventLog records the entries with their event ID
+type fakeEventLog struct {
+	entries []string
+}
+
+func (f *fakeEventLog) Warning(eid uint32, msg string) error {
+	f.entries = append(f.entries, fmt.Sprintf("warning %d %s", eid, msg))
+	return nil
+}
+
+func (f *fakeEventLog) Error(eid uint32, msg string) error {
+	f.entries = append(f.entries, fmt.Sprintf("error %d %s", eid, msg))
+	return nil
+}
+
+func TestEventLogHook(t *testing.T) {
+	w := &fakeEventLog{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.DebugLevel)
+	base := &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}
+	logger.AddHook(&eventLogHook{w: w, formatter: NewDedupFormatter(base, time.Hour)})
+
+	logger.Info("sent")
+	logger.Warn("server down")
+	logger.Warn("server down") // suppressed
+	logger.Error("failed")
+
+	want := []string{`warning 2 level=warning msg="server down"`, `error 1 level=error msg=failed`}
+	if fmt.Sprint(w.entries) != fmt.Sprint(want) {
+		t.Errorf("event log entries = %q, want %q", w.entries, want)
+	}
+}
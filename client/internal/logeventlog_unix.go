@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package internal
+
+import "errors"
+
+// openEventLog fails: the Event Log only exists on Windows
+func openEventLog() (eventLogWriter, error) {
+	return nil, errors.New("the Event Log is only available on Windows")
+}
+
+// RemoveEventLogSource does nothing outside Windows
+func RemoveEventLogSource() error {
+	return nil
+}
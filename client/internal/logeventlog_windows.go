@@ -0,0 +1,30 @@
+//go:build windows
+
+package internal
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// openEventLog registers the TatuScan source when missing (the service
+// runs as LocalSystem) and opens it
+func openEventLog() (eventLogWriter, error) {
+	err := eventlog.InstallAsEventCreate(eventLogSource, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil && !strings.Contains(err.Error(), "already exists") {
+		return nil, err
+	}
+	return eventlog.Open(eventLogSource)
+}
+
+// RemoveEventLogSource unregisters the TatuScan source, on uninstall
+func RemoveEventLogSource() error {
+	err := eventlog.Remove(eventLogSource)
+	if errors.Is(err, syscall.ERROR_FILE_NOT_FOUND) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the log file rotation and retention
+const (
+	defaultLogFileMaxSizeMB  = 10
+	defaultLogFileMaxAge     = 7 * 24 * time.Hour
+	defaultLogFileMaxBackups = 5
+)
+
+// logBackupLayout stamps rotated log files, sortable and valid on Windows
+const logBackupLayout = "2006-01-02T15-04-05.000"
+
+// LogFile is a log file rotated when it reaches a size: the full file is
+// renamed with a timestamp (tatuscan-2026-01-02T15-04-05.000.log) and
+// backups beyond the count or age limits are removed, so long-running
+// installs need no external logrotate setup
+type LogFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	now        func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenLogFile opens path for appending with the TATUSCAN_LOG_FILE_* limits,
+// creating its directory if needed
+func OpenLogFile(path string) (*LogFile, error) {
+	f := &LogFile{
+		path:       path,
+		maxSize:    int64(Cfg.LogFileMaxSizeMB) << 20,
+		maxAge:     Cfg.LogFileMaxAge,
+		maxBackups: Cfg.LogFileMaxBackups,
+		now:        time.Now,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.prune()
+	return f, nil
+}
+
+// Write implements io.Writer, rotating the file first when p would take it
+// over the size limit
+func (f *LogFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (f *LogFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file for appending; the caller holds f.mu or owns f
+func (f *LogFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate renames the current file to a timestamped backup and starts a new
+// one; the caller holds f.mu
+func (f *LogFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	ext := filepath.Ext(f.path)
+	backup := strings.TrimSuffix(f.path, ext) + "-" + f.now().Format(logBackupLayout) + ext
+	if err := os.Rename(f.path, backup); err != nil {
+		// Keep logging to the full file rather than losing entries
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// backups returns the rotated files of the log, oldest first
+func (f *LogFile) backups() []string {
+	ext := filepath.Ext(f.path)
+	prefix := filepath.Base(strings.TrimSuffix(f.path, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, ok := strings.CutPrefix(name, prefix)
+		if !ok || entry.IsDir() || !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		if _, err := time.Parse(logBackupLayout, strings.TrimSuffix(stamp, ext)); err == nil {
+			backups = append(backups, filepath.Join(filepath.Dir(f.path), name))
+		}
+	}
+	// The timestamp layout sorts chronologically
+	sort.Strings(backups)
+	return backups
+}
+
+// prune removes the backups beyond the count limit and those older than
+// the age limit
+func (f *LogFile) prune() {
+	backups := f.backups()
+	for i, backup := range backups {
+		expired := f.maxBackups > 0 && len(backups)-i > f.maxBackups
+		if !expired && f.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && f.now().Sub(info.ModTime()) > f.maxAge {
+				expired = true
+			}
+		}
+		if expired {
+			if err := os.Remove(backup); err != nil && Log != nil {
+				Log.Debugf("Error to remove old log file %s: %v", backup, err)
+			}
+		}
+	}
+}
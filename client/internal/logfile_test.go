@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogFileRotation(t *testing.T) {
+	setupTestAgent(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "tatuscan.log")
+	Cfg.LogFileMaxSizeMB, Cfg.LogFileMaxAge, Cfg.LogFileMaxBackups = 1, time.Hour, 2
+
+	// A stale backup past the age limit and an unrelated file
+	os.MkdirAll(filepath.Dir(path), 0o750)
+	stale := filepath.Join(dir, "logs", "tatuscan-2020-01-01T00-00-00.000.log")
+	os.WriteFile(stale, []byte("old\n"), 0o640)
+	os.Chtimes(stale, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour))
+	other := filepath.Join(dir, "logs", "tatuscan-notes.log")
+	os.WriteFile(other, []byte("keep\n"), 0o640)
+
+	f, err := OpenLogFile(path)
+	if err != nil {
+		t.Fatalf("OpenLogFile: %v", err)
+	}
+	defer f.Close()
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("expected the stale backup to be removed on open")
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time { return now }
+	line := []byte(strings.Repeat("x", 599*1024) + "\n")
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write(line); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	backups := f.backups()
+	if len(backups) != 2 || !strings.HasSuffix(backups[1], "tatuscan-2026-01-01T00-00-04.000.log") {
+		t.Errorf("expected the 2 newest backups, got %v", backups)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != int64(len(line)) {
+		t.Errorf("expected the current file to hold the last line, got %v %v", info, err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("unrelated files must be kept: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log formats selected by -log-format and TATUSCAN_LOG_FORMAT
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// volatileLogKeys are the context fields ignored by the repeated log
+// suppression, as they change between otherwise identical messages
+var volatileLogKeys = []string{"machine_id", "cycle"}
+
+// logContext is the state of the running collection, read by the log
+// context hook without the locks of the collection (which may be held while
+// logging)
+var logContext struct {
+	machineID atomic.Value // string
+	collector atomic.Value // string
+	cycle     atomic.Int64
+}
+
+// ParseLogFormat normalizes a log format, reporting whether it is valid
+func ParseLogFormat(value string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", LogFormatText:
+		return LogFormatText, true
+	case LogFormatJSON:
+		return LogFormatJSON, true
+	}
+	return LogFormatText, false
+}
+
+// parseLogFormat reads TATUSCAN_LOG_FORMAT, falling back to text
+func parseLogFormat(value string) string {
+	format, ok := ParseLogFormat(value)
+	if !ok && Log != nil {
+		Log.Warnf("Invalid log format %q, using %s", value, LogFormatText)
+	}
+	return format
+}
+
+// NewLogFormatter returns the formatter of format; outputs stamping the
+// entries themselves (syslog, Event Log) leave timestamps out
+func NewLogFormatter(format string, timestamps bool) logrus.Formatter {
+	if format == LogFormatJSON {
+		return &logrus.JSONFormatter{DisableTimestamp: !timestamps}
+	}
+	return &logrus.TextFormatter{DisableColors: true, DisableTimestamp: !timestamps}
+}
+
+// LogContextHook adds the machine_id, cycle and collector of the running
+// collection to the entries, so structured logs shipped to ELK or Loki can
+// be filtered by them. Fields set by the caller are kept.
+type LogContextHook struct{}
+
+// Levels implements logrus.Hook
+func (LogContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (LogContextHook) Fire(entry *logrus.Entry) error {
+	setField := func(key string, value any) {
+		if _, ok := entry.Data[key]; !ok {
+			entry.Data[key] = value
+		}
+	}
+	if id, _ := logContext.machineID.Load().(string); id != "" {
+		setField("machine_id", id)
+	}
+	if cycle := logContext.cycle.Load(); cycle > 0 {
+		setField("cycle", cycle)
+	}
+	if collector, _ := logContext.collector.Load().(string); collector != "" {
+		setField("collector", collector)
+	}
+	return nil
+}
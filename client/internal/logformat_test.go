@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogContextHook(t *testing.T) {
+	t.Cleanup(func() {
+		logContext.machineID.Store("")
+		logContext.collector.Store("")
+		logContext.cycle.Store(0)
+	})
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(NewDedupFormatter(NewLogFormatter(LogFormatJSON, false), time.Hour))
+	logger.AddHook(LogContextHook{})
+
+	logContext.machineID.Store("abc123")
+	logContext.cycle.Store(4)
+	logContext.collector.Store("disks")
+	logger.Warn("disk almost full")
+	logContext.cycle.Store(5)
+	logger.Warn("disk almost full") // suppressed, despite the new cycle
+	logContext.collector.Store("")
+	logger.WithField("collector", "custom").Error("failed")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", lines)
+	}
+	var first, second map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", lines[0], err)
+	}
+	json.Unmarshal([]byte(lines[1]), &second)
+	if first["machine_id"] != "abc123" || first["cycle"] != float64(4) || first["collector"] != "disks" || first["msg"] != "disk almost full" {
+		t.Errorf("unexpected first entry: %v", first)
+	}
+	if second["collector"] != "custom" || second["cycle"] != float64(5) {
+		t.Errorf("unexpected second entry: %v", second)
+	}
+}
+
+func TestParseLogFormat(t *testing.T) {
+	for value, want := range map[string]string{"": LogFormatText, "TEXT": LogFormatText, " json ": LogFormatJSON} {
+		if got, ok := ParseLogFormat(value); !ok || got != want {
+			t.Errorf("ParseLogFormat(%q) = %q, %v", value, got, ok)
+		}
+	}
+	if _, ok := ParseLogFormat("xml"); ok {
+		t.Error("expected xml to be rejected")
+	}
+}
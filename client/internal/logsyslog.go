@@ -0,0 +1,105 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Log outputs selected by TATUSCAN_LOG_OUTPUT
+const (
+	LogOutputStdout = "stdout"
+	LogOutputSyslog = "syslog"
+)
+
+// syslogTag identifies the agent lines in syslog and journald
+const syslogTag = "tatuscan"
+
+// syslogWriter is the part of *syslog.Writer used by the hook, one method
+// per severity
+type syslogWriter interface {
+	Debug(m string) error
+	Info(m string) error
+	Warning(m string) error
+	Err(m string) error
+	Crit(m string) error
+}
+
+// syslogHook writes every entry to syslog with the severity of its level.
+// It formats entries itself (the logger output is discarded), so the
+// repeated log suppression sees each entry once.
+type syslogHook struct {
+	w         syslogWriter
+	formatter logrus.Formatter
+}
+
+// Levels implements logrus.Hook
+func (h *syslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (h *syslogHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil || len(line) == 0 {
+		return err
+	}
+	msg := strings.TrimRight(string(line), "\n")
+	switch entry.Level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return h.w.Crit(msg)
+	case logrus.ErrorLevel:
+		return h.w.Err(msg)
+	case logrus.WarnLevel:
+		return h.w.Warning(msg)
+	case logrus.InfoLevel:
+		return h.w.Info(msg)
+	default:
+		return h.w.Debug(msg)
+	}
+}
+
+// discardFormatter formats nothing, for loggers whose output is replaced
+// by a hook
+type discardFormatter struct{}
+
+// Format implements logrus.Formatter
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// UseSyslog sends the logger entries to the local syslog (journald on
+// systemd hosts) instead of its output, keeping the repeated log
+// suppression; syslog adds the timestamp. On Windows it fails and the
+// output is left unchanged.
+func UseSyslog(logger *logrus.Logger, format string) error {
+	w, err := dialSyslog()
+	if err != nil {
+		return err
+	}
+	formatter := NewLogFormatter(format, false)
+	if Cfg.LogDedup {
+		formatter = NewDedupFormatter(formatter, Cfg.LogDedupWindow)
+	}
+	logger.AddHook(&syslogHook{w: w, formatter: formatter})
+	logger.SetFormatter(discardFormatter{})
+	logger.SetOutput(io.Discard)
+	return nil
+}
+
+// parseLogOutput normalizes TATUSCAN_LOG_OUTPUT, falling back to stdout
+func parseLogOutput(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", LogOutputStdout:
+		return LogOutputStdout
+	case LogOutputSyslog, "journald":
+		return LogOutputSyslog
+	}
+	if Log != nil {
+		Log.Warnf("Invalid log output %q, using %s", value, LogOutputStdout)
+	}
+	return LogOutputStdout
+}
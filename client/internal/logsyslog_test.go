@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeSyslog records the messages by severity
+type fakeSyslog struct {
+	lines []string
+}
+
+func (f *fakeSyslog) add(severity, m string) error {
+	f.lines = append(f.lines, severity+" "+m)
+	return nil
+}
+
+func (f *fakeSyslog) Debug(m string) error   { return f.add("debug", m) }
+func (f *fakeSyslog) Info(m string) error    { return f.add("info", m) }
+func (f *fakeSyslog) Warning(m string) error { return f.add("warning", m) }
+func (f *fakeSyslog) Err(m string) error     { return f.add("err", m) }
+func (f *fakeSyslog) Crit(m string) error    { return f.add("crit", m) }
+
+func TestSyslogHook(t *testing.T) {
+	w := &fakeSyslog{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.SetOutput(io.Discard)
+	logger.SetFormatter(discardFormatter{})
+	base := &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}
+	logger.AddHook(&syslogHook{w: w, formatter: NewDedupFormatter(base, time.Hour)})
+
+	logger.Debug("probing")
+	logger.Info("sent")
+	logger.Warn("server down")
+	logger.Warn("server down") // suppressed by the dedup formatter
+	logger.Error("failed")
+
+	want := []string{
+		`debug level=debug msg=probing`,
+		`info level=info msg=sent`,
+		`warning level=warning msg="server down"`,
+		`err level=error msg=failed`,
+	}
+	if fmt.Sprint(w.lines) != fmt.Sprint(want) {
+		t.Errorf("syslog lines = %q, want %q", w.lines, want)
+	}
+}
+
+func TestParseLogOutput(t *testing.T) {
+	setupTestAgent(t)
+	for value, want := range map[string]string{"": LogOutputStdout, "SYSLOG": LogOutputSyslog, "journald": LogOutputSyslog, "file": LogOutputStdout} {
+		if got := parseLogOutput(value); got != want {
+			t.Errorf("parseLogOutput(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
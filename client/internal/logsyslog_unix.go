@@ -0,0 +1,10 @@
+//go:build linux || darwin
+
+package internal
+
+import "log/syslog"
+
+// dialSyslog connects to the local syslog daemon with the daemon facility
+func dialSyslog() (syslogWriter, error) {
+	return syslog.New(syslog.LOG_DAEMON|syslog.LOG_INFO, syslogTag)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package internal
+
+import "errors"
+
+// dialSyslog fails: Windows has no syslog, the service logs go to stdout
+func dialSyslog() (syslogWriter, error) {
+	return nil, errors.New("syslog output is not available on Windows")
+}
@@ -0,0 +1,94 @@
+//go:build linux
+
+package internal
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readSELinux returns the SELinux state from selinuxfs and the configured
+// mode and policy from /etc/selinux/config; nil when SELinux is not installed
+func readSELinux() *LSMStatus {
+	status := &LSMStatus{Name: "selinux", Mode: LSMDisabled}
+	enforce := readSysfsValue(sysfsRoot, "fs", "selinux", "enforce")
+	switch enforce {
+	case "1":
+		status.Mode = LSMEnforcing
+	case "0":
+		status.Mode = LSMPermissive
+	}
+
+	f, err := os.Open(filepath.Join(etcRoot, "selinux", "config"))
+	if err != nil {
+		if enforce == "" {
+			return nil
+		}
+		return status
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(key) {
+		case "SELINUX":
+			status.ConfiguredMode = strings.ToLower(value)
+		case "SELINUXTYPE":
+			status.Policy = value
+		}
+	}
+	return status
+}
+
+// readAppArmor returns the AppArmor state from the kernel module parameters
+// and its profile modes from securityfs (readable by root); nil when the
+// kernel has no AppArmor
+func readAppArmor() *LSMStatus {
+	enabled := readSysfsValue(sysfsRoot, "module", "apparmor", "parameters", "enabled")
+	if enabled == "" {
+		return nil
+	}
+	status := &LSMStatus{Name: "apparmor", Mode: LSMDisabled}
+	if enabled != "Y" {
+		return status
+	}
+	status.Mode = LSMEnforcing
+	f, err := os.Open(filepath.Join(sysfsRoot, "kernel", "security", "apparmor", "profiles"))
+	if err != nil {
+		return status
+	}
+	defer f.Close()
+	enforced, complain := 0, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// one "name (mode)" line per loaded profile
+		switch line := scanner.Text(); {
+		case strings.HasSuffix(line, "(enforce)"):
+			enforced++
+		case strings.HasSuffix(line, "(complain)"):
+			complain++
+		}
+	}
+	status.Enforced, status.Complain = &enforced, &complain
+	if enforced == 0 && complain > 0 {
+		status.Mode = LSMPermissive
+	}
+	return status
+}
+
+// platformLSM reports SELinux and AppArmor, the ones present
+func platformLSM() []LSMStatus {
+	var modules []LSMStatus
+	for _, status := range []*LSMStatus{readSELinux(), readAppArmor()} {
+		if status != nil {
+			modules = append(modules, *status)
+		}
+	}
+	return modules
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSELinuxDisabled(t *testing.T) {
+	origSysfs, origEtc := sysfsRoot, etcRoot
+	sysfsRoot, etcRoot = t.TempDir(), t.TempDir()
+	t.Cleanup(func() { sysfsRoot, etcRoot = origSysfs, origEtc })
+
+	if status := readSELinux(); status != nil {
+		t.Fatalf("SELinux reported without selinuxfs nor config: %+v", status)
+	}
+
+	// installed, disabled at boot: no selinuxfs, mode from the config
+	os.MkdirAll(filepath.Join(etcRoot, "selinux"), 0o755)
+	os.WriteFile(filepath.Join(etcRoot, "selinux", "config"), []byte("SELINUX=permissive\nSELINUXTYPE=targeted\n"), 0o644)
+	status := readSELinux()
+	if status == nil || status.Mode != LSMDisabled || status.ConfiguredMode != LSMPermissive {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestReadAppArmorDisabled(t *testing.T) {
+	origSysfs := sysfsRoot
+	sysfsRoot = t.TempDir()
+	t.Cleanup(func() { sysfsRoot = origSysfs })
+
+	if status := readAppArmor(); status != nil {
+		t.Fatalf("AppArmor reported without the kernel module: %+v", status)
+	}
+	dir := filepath.Join(sysfsRoot, "module", "apparmor", "parameters")
+	os.MkdirAll(dir, 0o755)
+	os.WriteFile(filepath.Join(dir, "enabled"), []byte("N\n"), 0o644)
+	if status := readAppArmor(); status == nil || status.Mode != LSMDisabled || status.Enforced != nil {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows || darwin
+
+package internal
+
+// platformLSM reports nothing: SELinux and AppArmor are Linux security
+// modules
+func platformLSM() []LSMStatus {
+	return nil
+}
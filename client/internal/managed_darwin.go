@@ -0,0 +1,36 @@
+//go:build darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// managedConfigDomain is the preference domain of configuration profiles:
+// MDM delivers its payload to /Library/Managed Preferences/<domain>.plist
+const managedConfigDomain = "com.github.carlosrabelo.tatuscan"
+
+// managedConfig reads the settings of an installed configuration profile
+func managedConfig() map[string]string {
+	path := filepath.Join(rootDir, "Library", "Managed Preferences", managedConfigDomain+".plist")
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	output, err := runCommand("plutil", "-convert", "json", "-o", "-", path)
+	if err != nil {
+		if Log != nil {
+			Log.Warnf("Error to read managed preferences %s: %v", path, err)
+		}
+		return nil
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(output, &doc); err != nil {
+		if Log != nil {
+			Log.Warnf("Error to parse managed preferences %s: %v", path, err)
+		}
+		return nil
+	}
+	return managedValues(doc)
+}
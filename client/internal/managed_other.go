@@ -0,0 +1,8 @@
+//go:build linux || windows
+
+package internal
+
+// managedConfig returns nil: configuration profiles only exist on macOS
+func managedConfig() map[string]string {
+	return nil
+}
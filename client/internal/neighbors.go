@@ -0,0 +1,203 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/binary"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// neighborSweepPrefix caps the sweep at a /24: larger subnets are only
+	// swept around the agent address
+	neighborSweepPrefix = 24
+	// neighborSweepWait lets the ARP replies arrive before the table is read
+	neighborSweepWait = 2 * time.Second
+	// neighborSweepPort is the discard port: the datagrams only exist to
+	// make the OS resolve the addresses
+	neighborSweepPort = 9
+)
+
+// Neighbor is a device seen in the ARP table of the agent, which the server
+// can match against the MACs of enrolled machines to find unmanaged devices
+type Neighbor struct {
+	IP        string `json:"ip"`
+	MAC       string `json:"mac"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// neighborTable reads the OS ARP table; replaced in tests
+var neighborTable = platformNeighbors
+
+// getNeighbors returns the IPv4 neighbors of the primary subnet when
+// TATUSCAN_NEIGHBORS is on, after a sweep when TATUSCAN_NEIGHBORS_SWEEP is
+// on. Only one agent per subnet needs it enabled.
+func getNeighbors(primaryIP string) ([]Neighbor, error) {
+	if !Cfg.Neighbors {
+		return nil, nil
+	}
+	subnet, ownMACs := localSubnet(net.ParseIP(primaryIP))
+	if subnet == nil {
+		return nil, nil
+	}
+	if Cfg.NeighborsSweep {
+		sweepSubnet(subnet, net.ParseIP(primaryIP))
+	}
+	entries, err := neighborTable()
+	if err != nil {
+		return nil, err
+	}
+	return filterNeighbors(entries, subnet, ownMACs), nil
+}
+
+// filterNeighbors keeps the unicast entries of subnet, dropping the agent
+// own MACs and duplicates, sorted by address
+func filterNeighbors(entries []Neighbor, subnet *net.IPNet, ownMACs map[string]bool) []Neighbor {
+	var neighbors []Neighbor
+	seen := map[string]bool{}
+	for _, n := range entries {
+		ip := net.ParseIP(n.IP).To4()
+		mac, err := net.ParseMAC(n.MAC)
+		if ip == nil || err != nil || !subnet.Contains(ip) || len(mac) != 6 {
+			continue
+		}
+		n.MAC = strings.ToUpper(mac.String())
+		if mac[0]&1 == 1 || n.MAC == "00:00:00:00:00:00" || ownMACs[n.MAC] || seen[n.IP+n.MAC] {
+			continue // broadcast, multicast, incomplete or ours
+		}
+		seen[n.IP+n.MAC] = true
+		n.IP = ip.String()
+		neighbors = append(neighbors, n)
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		return binary.BigEndian.Uint32(net.ParseIP(neighbors[i].IP).To4()) < binary.BigEndian.Uint32(net.ParseIP(neighbors[j].IP).To4())
+	})
+	return neighbors
+}
+
+// localSubnet returns the IPv4 subnet holding ip and the MACs of the local
+// interfaces
+func localSubnet(ip net.IP) (*net.IPNet, map[string]bool) {
+	ip = ip.To4()
+	if ip == nil {
+		return nil, nil
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, nil
+	}
+	var subnet *net.IPNet
+	ownMACs := map[string]bool{}
+	for _, iface := range ifaces {
+		if len(iface.HardwareAddr) > 0 {
+			ownMACs[strings.ToUpper(iface.HardwareAddr.String())] = true
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+				subnet = &net.IPNet{IP: ip.Mask(ipnet.Mask), Mask: ipnet.Mask}
+			}
+		}
+	}
+	return subnet, ownMACs
+}
+
+// sweepSubnet sends one UDP datagram to every address of the subnet (capped
+// at the /24 around own), so the OS resolves them and fills its ARP table.
+// Unlike ICMP it needs no privileges; hosts only see an ARP request and a
+// datagram to the discard port.
+func sweepSubnet(subnet *net.IPNet, own net.IP) {
+	if ones, _ := subnet.Mask.Size(); ones < neighborSweepPrefix {
+		mask := net.CIDRMask(neighborSweepPrefix, 32)
+		subnet = &net.IPNet{IP: own.To4().Mask(mask), Mask: mask}
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: own})
+	if err != nil {
+		Log.Debugf("Error to open the sweep socket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	hosts := sweepHosts(subnet)
+	Log.Debugf("Sweeping %d addresses of %s", len(hosts), subnet)
+	for _, host := range hosts {
+		if !host.Equal(own) {
+			_, _ = conn.WriteToUDP([]byte{0}, &net.UDPAddr{IP: host, Port: neighborSweepPort})
+		}
+	}
+	time.Sleep(neighborSweepWait)
+}
+
+// sweepHosts lists the host addresses of subnet, without the network and
+// broadcast addresses
+func sweepHosts(subnet *net.IPNet) []net.IP {
+	ones, bits := subnet.Mask.Size()
+	if bits != 32 || ones > 30 {
+		return nil
+	}
+	base := binary.BigEndian.Uint32(subnet.IP.To4())
+	size := uint32(1) << (32 - ones)
+	hosts := make([]net.IP, 0, size-2)
+	for i := uint32(1); i < size-1; i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, base+i)
+		hosts = append(hosts, ip)
+	}
+	return hosts
+}
+
+// parseProcARP parses /proc/net/arp (Linux), skipping incomplete entries
+func parseProcARP(data string) []Neighbor {
+	var neighbors []Neighbor
+	for _, line := range strings.Split(data, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[2] == "0x0" {
+			continue
+		}
+		neighbors = append(neighbors, Neighbor{IP: fields[0], MAC: fields[3], Interface: fields[5]})
+	}
+	return neighbors
+}
+
+// parseWindowsARP parses `arp -a` (Windows): entries follow an
+// "Interface: <ip> --- 0x<index>" header per interface
+func parseWindowsARP(output string) []Neighbor {
+	var neighbors []Neighbor
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && net.ParseIP(fields[0]) != nil && strings.Count(fields[1], "-") == 5 {
+			neighbors = append(neighbors, Neighbor{IP: fields[0], MAC: fields[1]})
+		}
+	}
+	return neighbors
+}
+
+// parseDarwinARP parses `arp -an` (macOS), whose MACs drop leading zeros:
+// "? (192.168.1.1) at 0:11:22:33:44:55 on en0 ifscope [ethernet]"
+func parseDarwinARP(output string) []Neighbor {
+	var neighbors []Neighbor
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[2] != "at" || fields[4] != "on" {
+			continue
+		}
+		octets := strings.Split(fields[3], ":")
+		if len(octets) != 6 {
+			continue // (incomplete)
+		}
+		for i, o := range octets {
+			if len(o) == 1 {
+				octets[i] = "0" + o
+			}
+		}
+		neighbors = append(neighbors, Neighbor{
+			IP:        strings.Trim(fields[1], "()"),
+			MAC:       strings.Join(octets, ":"),
+			Interface: fields[5],
+		})
+	}
+	return neighbors
+}
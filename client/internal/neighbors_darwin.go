@@ -0,0 +1,12 @@
+//go:build darwin
+
+package internal
+
+// platformNeighbors reads the ARP table with arp(8), numeric only
+func platformNeighbors() ([]Neighbor, error) {
+	output, err := runCommand("arp", "-an")
+	if err != nil {
+		return nil, err
+	}
+	return parseDarwinARP(string(output)), nil
+}
@@ -0,0 +1,14 @@
+//go:build linux
+
+package internal
+
+import "os"
+
+// platformNeighbors reads the kernel ARP table
+func platformNeighbors() ([]Neighbor, error) {
+	data, err := os.ReadFile("/proc/net/arp")
+	if err != nil {
+		return nil, err
+	}
+	return parseProcARP(string(data)), nil
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseARPTables(t *testing.T) {
+	proc := `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         00:11:22:33:44:55     *        eth0
+192.168.1.9      0x1         0x0         00:00:00:00:00:00     *        eth0
+`
+	if got, want := parseProcARP(proc), []Neighbor{{IP: "192.168.1.1", MAC: "00:11:22:33:44:55", Interface: "eth0"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProcARP = %+v, want %+v", got, want)
+	}
+
+	windows := "\r\nInterface: 192.168.1.10 --- 0xb\r\n  Internet Address      Physical Address      Type\r\n  192.168.1.1           00-11-22-33-44-55     dynamic   \r\n  192.168.1.255         ff-ff-ff-ff-ff-ff     static    \r\n"
+	if got := parseWindowsARP(windows); len(got) != 2 || got[0].MAC != "00-11-22-33-44-55" {
+		t.Errorf("parseWindowsARP = %+v", got)
+	}
+
+	darwin := "? (192.168.1.1) at 0:11:22:3:44:55 on en0 ifscope [ethernet]\n? (192.168.1.7) at (incomplete) on en0 ifscope [ethernet]\n"
+	if got, want := parseDarwinARP(darwin), []Neighbor{{IP: "192.168.1.1", MAC: "00:11:22:03:44:55", Interface: "en0"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDarwinARP = %+v, want %+v", got, want)
+	}
+}
+
+func TestFilterNeighbors(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("192.168.1.0/24")
+	entries := []Neighbor{
+		{IP: "192.168.1.20", MAC: "aa-bb-cc-00-00-02"},
+		{IP: "192.168.1.3", MAC: "aa:bb:cc:00:00:01"},
+		{IP: "192.168.1.3", MAC: "AA:BB:CC:00:00:01"},   // duplicate
+		{IP: "192.168.1.255", MAC: "ff:ff:ff:ff:ff:ff"}, // broadcast
+		{IP: "224.0.0.251", MAC: "01:00:5e:00:00:fb"},   // multicast, off subnet
+		{IP: "10.0.0.1", MAC: "aa:bb:cc:00:00:03"},      // off subnet
+		{IP: "192.168.1.10", MAC: "aa:bb:cc:00:00:10"},  // the agent itself
+	}
+	got := filterNeighbors(entries, subnet, map[string]bool{"AA:BB:CC:00:00:10": true})
+	want := []Neighbor{{IP: "192.168.1.3", MAC: "AA:BB:CC:00:00:01"}, {IP: "192.168.1.20", MAC: "AA:BB:CC:00:00:02"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterNeighbors = %+v, want %+v", got, want)
+	}
+}
+
+func TestSweepHosts(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.1.2.0/29")
+	hosts := sweepHosts(subnet)
+	if len(hosts) != 6 || hosts[0].String() != "10.1.2.1" || hosts[5].String() != "10.1.2.6" {
+		t.Errorf("sweepHosts = %v", hosts)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package internal
+
+// platformNeighbors reads the ARP table with arp.exe
+func platformNeighbors() ([]Neighbor, error) {
+	output, err := runCommand("arp", "-a")
+	if err != nil {
+		return nil, err
+	}
+	return parseWindowsARP(string(output)), nil
+}
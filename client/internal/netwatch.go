@@ -0,0 +1,62 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// networkChangeDebounce lets a burst of address events (DHCP renewal, Wi-Fi
+// roaming, VPN reconnect) settle before the network is re-evaluated
+const networkChangeDebounce = 5 * time.Second
+
+// WatchNetworkChanges returns a channel signalled after the host addresses
+// change, so the agent can report a move without waiting for the next cycle.
+// It returns nil (never ready) when TATUSCAN_NETWORK_WATCH is off or the
+// platform notifications are unavailable.
+func WatchNetworkChanges(ctx context.Context) <-chan struct{} {
+	if !Cfg.NetworkWatch {
+		return nil
+	}
+	events, err := platformNetworkEvents(ctx)
+	if err != nil {
+		Log.Warnf("Network change notifications unavailable: %v", err)
+		return nil
+	}
+	return debounceEvents(ctx, events, networkChangeDebounce)
+}
+
+// debounceEvents signals once events have been quiet for wait
+func debounceEvents(ctx context.Context, events <-chan struct{}, wait time.Duration) <-chan struct{} {
+	settled := make(chan struct{}, 1)
+	go func() {
+		var fire <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+				fire = time.After(wait)
+			case <-fire:
+				fire = nil
+				select {
+				case settled <- struct{}{}:
+				default: // a signal is already pending
+				}
+			}
+		}
+	}()
+	return settled
+}
+
+// SameNetwork reports whether two collections saw the same primary IP and
+// interface addresses, so a network event that changed nothing the server
+// sees (a container veth, a link flap) does not trigger a send
+func SameNetwork(a, b MachineInfo) bool {
+	return a.IP == b.IP && a.PublicIP == b.PublicIP && reflect.DeepEqual(a.Addresses, b.Addresses)
+}
@@ -0,0 +1,30 @@
+//go:build darwin
+
+package internal
+
+import "golang.org/x/sys/unix"
+
+// openRouteSocket opens a PF_ROUTE socket, which receives the same kernel
+// address events configd publishes through SCDynamicStore without cgo
+func openRouteSocket() (int, error) {
+	fd, err := unix.Socket(unix.AF_ROUTE, unix.SOCK_RAW, unix.AF_UNSPEC)
+	if err != nil {
+		return -1, err
+	}
+	unix.CloseOnExec(fd)
+	return fd, nil
+}
+
+// isAddressEvent keeps address and interface messages, skipping the route
+// and ARP updates that PF_ROUTE also delivers (byte 3 of rt_msghdr is the
+// message type)
+func isAddressEvent(msg []byte) bool {
+	if len(msg) < 4 {
+		return false
+	}
+	switch msg[3] {
+	case unix.RTM_NEWADDR, unix.RTM_DELADDR, unix.RTM_IFINFO:
+		return true
+	}
+	return false
+}
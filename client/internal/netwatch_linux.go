@@ -0,0 +1,28 @@
+//go:build linux
+
+package internal
+
+import "golang.org/x/sys/unix"
+
+// openRouteSocket subscribes a netlink socket to link and address changes
+func openRouteSocket() (int, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return -1, err
+	}
+	addr := &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: unix.RTMGRP_LINK | unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV6_IFADDR,
+	}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return -1, err
+	}
+	return fd, nil
+}
+
+// isAddressEvent accepts every message: the multicast groups above only
+// deliver link and address changes
+func isAddressEvent(msg []byte) bool {
+	return len(msg) > 0
+}
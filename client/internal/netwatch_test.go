@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDebounceEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan struct{})
+	settled := debounceEvents(ctx, events, 50*time.Millisecond)
+
+	// A burst of events settles into a single signal
+	for i := 0; i < 5; i++ {
+		events <- struct{}{}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-settled:
+	case <-time.After(time.Second):
+		t.Fatal("no signal after the burst settled")
+	}
+	select {
+	case <-settled:
+		t.Error("expected a single signal per burst")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestSameNetwork(t *testing.T) {
+	home := MachineInfo{IP: "192.168.1.20", Addresses: []InterfaceAddress{{Interface: "en0", IP: "192.168.1.20"}}}
+	moved := MachineInfo{IP: "10.0.5.7", Addresses: []InterfaceAddress{{Interface: "en0", IP: "10.0.5.7"}}}
+	again := home
+	again.CPUPercent = 42
+
+	if !SameNetwork(home, again) {
+		t.Error("metrics changes must not count as a move")
+	}
+	if SameNetwork(home, moved) {
+		t.Error("a new primary IP must count as a move")
+	}
+}
+
+func TestWatchNetworkChangesDisabled(t *testing.T) {
+	setupTestAgent(t)
+	if WatchNetworkChanges(context.Background()) != nil {
+		t.Error("expected a nil channel when TATUSCAN_NETWORK_WATCH is off")
+	}
+}
@@ -0,0 +1,53 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"context"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// platformNetworkEvents reads address and link messages from a kernel
+// routing socket (netlink on Linux, PF_ROUTE on macOS)
+func platformNetworkEvents(ctx context.Context) (<-chan struct{}, error) {
+	fd, err := openRouteSocket()
+	if err != nil {
+		return nil, err
+	}
+	// A non-blocking descriptor goes through the runtime poller, so closing
+	// the file unblocks the pending read
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	sock := os.NewFile(uintptr(fd), "route")
+
+	events := make(chan struct{}, 1)
+	go func() {
+		<-ctx.Done()
+		sock.Close()
+	}()
+	go func() {
+		defer close(events)
+		buf := make([]byte, os.Getpagesize())
+		for {
+			n, err := sock.Read(buf)
+			if err != nil {
+				if ctx.Err() == nil {
+					Log.Warnf("Network change notifications stopped: %v", err)
+				}
+				return
+			}
+			if !isAddressEvent(buf[:n]) {
+				continue
+			}
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// The callback is created once (Windows callbacks are never freed) and
+// signals a package-level channel, as Go pointers cannot be handed to the
+// API as the caller context
+var (
+	addressCallbackOnce sync.Once
+	addressCallback     uintptr
+	addressEvents       = make(chan struct{}, 1)
+)
+
+// platformNetworkEvents registers for unicast address changes with
+// NotifyUnicastIpAddressChange, the IP Helper successor of NotifyAddrChange
+// that also covers IPv6
+func platformNetworkEvents(ctx context.Context) (<-chan struct{}, error) {
+	addressCallbackOnce.Do(func() {
+		addressCallback = windows.NewCallback(func(callerContext, row, notificationType uintptr) uintptr {
+			select {
+			case addressEvents <- struct{}{}:
+			default:
+			}
+			return 0
+		})
+	})
+	var handle windows.Handle
+	if err := windows.NotifyUnicastIpAddressChange(windows.AF_UNSPEC, addressCallback, nil, false, &handle); err != nil {
+		return nil, err
+	}
+	go func() {
+		<-ctx.Done()
+		windows.CancelMibChangeNotify2(handle)
+	}()
+	return addressEvents, nil
+}
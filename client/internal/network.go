@@ -0,0 +1,171 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// NetInterface abstracts a network interface so IP/MAC selection can be
+// exercised with fake interfaces instead of the host NICs
+type NetInterface interface {
+	Name() string
+	Flags() net.Flags
+	HardwareAddr() net.HardwareAddr
+	MTU() int
+	Addrs() ([]net.Addr, error)
+}
+
+// systemInterface adapts net.Interface to NetInterface
+type systemInterface struct {
+	iface net.Interface
+}
+
+func (s systemInterface) Name() string                   { return s.iface.Name }
+func (s systemInterface) Flags() net.Flags               { return s.iface.Flags }
+func (s systemInterface) HardwareAddr() net.HardwareAddr { return s.iface.HardwareAddr }
+func (s systemInterface) MTU() int                       { return s.iface.MTU }
+func (s systemInterface) Addrs() ([]net.Addr, error)     { return s.iface.Addrs() }
+
+// listSystemInterfaces returns the host interfaces ordered by index
+func listSystemInterfaces() ([]NetInterface, error) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].Index < interfaces[j].Index
+	})
+	result := make([]NetInterface, len(interfaces))
+	for i, iface := range interfaces {
+		result[i] = systemInterface{iface: iface}
+	}
+	return result, nil
+}
+
+// interfaceLister enumerates network interfaces; replaced in tests
+var interfaceLister = listSystemInterfaces
+
+// defaultVirtualInterfacePatterns lists substrings of virtual network interface
+// names (matched case-insensitively) shared by all platforms
+var defaultVirtualInterfacePatterns = []string{
+	// Linux
+	"docker", "veth", "br-", "tun", "tap", "vmnet", "macvlan", "ipvlan", "wg", "wireguard", "dummy",
+	// Windows
+	"virtual", "vpn", "hyper-v", "vmware", "virtualbox", "teredo",
+	// Overlay networks
+	"tailscale", "zerotier",
+}
+
+// virtualInterfacePatterns returns the effective pattern list: the configured
+// override (TATUSCAN_VIRTUAL_PATTERNS) or the defaults, plus configured extras
+func virtualInterfacePatterns() []string {
+	patterns := defaultVirtualInterfacePatterns
+	if len(Cfg.VirtualPatterns) > 0 {
+		patterns = Cfg.VirtualPatterns
+	}
+	if len(Cfg.VirtualPatternsExtra) == 0 {
+		return patterns
+	}
+	combined := make([]string, 0, len(patterns)+len(Cfg.VirtualPatternsExtra))
+	combined = append(combined, patterns...)
+	return append(combined, Cfg.VirtualPatternsExtra...)
+}
+
+// isVirtualInterface checks if an interface is virtual based on its name
+func isVirtualInterface(name string) bool {
+	nameLower := strings.ToLower(name)
+	for _, pattern := range virtualInterfacePatterns() {
+		if pattern != "" && strings.Contains(nameLower, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocallyAdministeredMAC returns true if the MAC has the "locally administered" bit set
+func isLocallyAdministeredMAC(hw net.HardwareAddr) bool {
+	if len(hw) == 0 {
+		return false
+	}
+	// Bit 1 (0x02) from first octet indicates "locally administered"
+	return (hw[0] & 0x02) == 0x02
+}
+
+// LinkTopology is a bond, bridge or VLAN carrying an address, with the
+// physical interfaces under it, whose MACs identify the machine
+type LinkTopology struct {
+	Interface string   `json:"interface"`
+	Kind      string   `json:"kind"` // bond, bridge or vlan
+	Members   []string `json:"members"`
+}
+
+// linkMember is a physical interface under a bond, bridge or VLAN
+type linkMember struct {
+	Name string
+	MAC  string // permanent MAC for bond slaves, which share the bond MAC
+}
+
+// InterfaceAddress is an IP address assigned to a physical interface
+type InterfaceAddress struct {
+	Interface string `json:"interface"`
+	IP        string `json:"ip"`
+}
+
+// interfaceAddresses lists the non-loopback addresses of an interface;
+// IPv6 link-local addresses are skipped as they are not reachable off-link
+func interfaceAddresses(name string, addrs []net.Addr) []InterfaceAddress {
+	var result []InterfaceAddress
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() {
+			continue
+		}
+		if ipnet.IP.To4() == nil && ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		result = append(result, InterfaceAddress{Interface: name, IP: ipnet.IP.String()})
+	}
+	return result
+}
+
+// InterfaceDetail describes a physical network interface for troubleshooting
+type InterfaceDetail struct {
+	Name      string `json:"name"`
+	MAC       string `json:"mac,omitempty"`
+	Up        bool   `json:"up"`
+	MTU       int    `json:"mtu,omitempty"`
+	SpeedMbps int64  `json:"speed_mbps,omitempty"`
+	Duplex    string `json:"duplex,omitempty"`
+	Driver    string `json:"driver,omitempty"`
+	Vendor    string `json:"vendor,omitempty"`
+}
+
+// baseInterfaceDetails lists non-loopback interfaces with a MAC that are not
+// virtual by name, filling the fields available from net.Interfaces()
+func baseInterfaceDetails() []InterfaceDetail {
+	interfaces, err := interfaceLister()
+	if err != nil {
+		Log.Warnf("Error to collect network interfaces: %v", err)
+		return nil
+	}
+	var details []InterfaceDetail
+	for _, iface := range interfaces {
+		name, hw, flags := iface.Name(), iface.HardwareAddr(), iface.Flags()
+		if name == "" || len(hw) == 0 || (flags&net.FlagLoopback) != 0 {
+			continue
+		}
+		if isVirtualInterface(name) {
+			continue
+		}
+		details = append(details, InterfaceDetail{
+			Name: name,
+			MAC:  hw.String(),
+			Up:   (flags & net.FlagUp) != 0,
+			MTU:  iface.MTU(),
+		})
+	}
+	return details
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestVirtualInterfacePatternsConfig(t *testing.T) {
+	defer SetConfig(Config{})
+
+	SetConfig(Config{})
+	if !isVirtualInterface("Tailscale") || !isVirtualInterface("ZeroTier One [8056c2e21c]") {
+		t.Error("overlay adapters should be virtual by default")
+	}
+	if isVirtualInterface("zt3jnkd7bd") {
+		t.Error("zt prefix is not a default pattern")
+	}
+
+	SetConfig(Config{VirtualPatternsExtra: []string{"zt"}})
+	if !isVirtualInterface("zt3jnkd7bd") {
+		t.Error("extra pattern should extend the defaults")
+	}
+	if !isVirtualInterface("docker0") {
+		t.Error("defaults should be kept when extending")
+	}
+
+	SetConfig(Config{VirtualPatterns: []string{"LAB-"}})
+	if isVirtualInterface("docker0") {
+		t.Error("override should replace the defaults")
+	}
+	if !isVirtualInterface("lab-bridge") {
+		t.Error("override pattern should match case-insensitively")
+	}
+}
+
+func TestInterfaceAddresses(t *testing.T) {
+	addrs := []net.Addr{
+		createMockIPv4Addr("192.168.1.10"),
+		createMockIPv4Addr("10.0.0.5"),
+		createMockIPv6Addr("2001:db8::10"),
+		createMockIPv6Addr("fe80::1"),
+		createMockIPv4Addr("127.0.0.1"),
+	}
+	got := interfaceAddresses("eth0", addrs)
+	want := []InterfaceAddress{
+		{Interface: "eth0", IP: "192.168.1.10"},
+		{Interface: "eth0", IP: "10.0.0.5"},
+		{Interface: "eth0", IP: "2001:db8::10"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("interfaceAddresses = %+v, want %+v", got, want)
+	}
+}
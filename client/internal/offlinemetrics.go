@@ -0,0 +1,111 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// offlineMetricsFileName keeps the buffered samples across restarts
+const offlineMetricsFileName = "offline-metrics.json"
+
+// defaultOfflineSamples holds a day of samples at the default interval
+const defaultOfflineSamples = 1440
+
+// MetricSample is the metrics of a cycle whose report was not delivered,
+// replayed with its original timestamp in metric_history
+type MetricSample struct {
+	Timestamp     string  `json:"timestamp"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryTotalMB uint64  `json:"memory_total_mb"`
+	MemoryUsedMB  uint64  `json:"memory_used_mb"`
+}
+
+// offlineMetrics is a ring buffer of the samples not delivered yet, oldest
+// first, bounded by TATUSCAN_OFFLINE_SAMPLES and mirrored to the state
+// directory
+var offlineMetrics struct {
+	sync.Mutex
+	samples []MetricSample
+	loaded  bool
+}
+
+// pendingMetrics returns the buffered samples to replay in the next report
+func pendingMetrics() []MetricSample {
+	if Cfg.OfflineSamples <= 0 {
+		return nil
+	}
+	offlineMetrics.Lock()
+	defer offlineMetrics.Unlock()
+	loadOfflineMetrics()
+	return append([]MetricSample(nil), offlineMetrics.samples...)
+}
+
+// bufferMetrics keeps the metrics of a report that could not be delivered,
+// dropping the oldest samples beyond TATUSCAN_OFFLINE_SAMPLES
+func bufferMetrics(info MachineInfo) {
+	if Cfg.OfflineSamples <= 0 || info.Timestamp == "" {
+		return
+	}
+	offlineMetrics.Lock()
+	defer offlineMetrics.Unlock()
+	loadOfflineMetrics()
+	samples := append(offlineMetrics.samples, MetricSample{
+		Timestamp:     info.Timestamp,
+		CPUPercent:    info.CPUPercent,
+		MemoryTotalMB: info.MemoryTotalMB,
+		MemoryUsedMB:  info.MemoryUsedMB,
+	})
+	if over := len(samples) - Cfg.OfflineSamples; over > 0 {
+		samples = samples[over:]
+	}
+	offlineMetrics.samples = samples
+	saveOfflineMetrics()
+}
+
+// ackMetrics drops the replayed samples once their report is delivered
+func ackMetrics(replayed int) {
+	if replayed == 0 {
+		return
+	}
+	offlineMetrics.Lock()
+	defer offlineMetrics.Unlock()
+	offlineMetrics.samples = offlineMetrics.samples[min(replayed, len(offlineMetrics.samples)):]
+	saveOfflineMetrics()
+}
+
+// loadOfflineMetrics reads the samples buffered before a restart; the
+// caller holds offlineMetrics
+func loadOfflineMetrics() {
+	if offlineMetrics.loaded {
+		return
+	}
+	offlineMetrics.loaded = true
+	data, err := os.ReadFile(statePath(offlineMetricsFileName))
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, &offlineMetrics.samples); err != nil {
+		Log.Debugf("Ignoring invalid %s: %v", offlineMetricsFileName, err)
+		offlineMetrics.samples = nil
+	}
+}
+
+// saveOfflineMetrics mirrors the buffer to the state directory, removing
+// the file once it is empty; the caller holds offlineMetrics
+func saveOfflineMetrics() {
+	path := statePath(offlineMetricsFileName)
+	if len(offlineMetrics.samples) == 0 {
+		os.Remove(path)
+		return
+	}
+	data, err := json.Marshal(offlineMetrics.samples)
+	if err == nil {
+		err = writeFileAtomic(path, data, 0o644)
+	}
+	if err != nil {
+		Log.Warnf("Error to persist the offline metrics, keeping them in memory: %v", err)
+	}
+}
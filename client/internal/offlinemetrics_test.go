@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// resetOfflineMetrics empties the buffer and forgets it was loaded
+func resetOfflineMetrics(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		offlineMetrics.Lock()
+		offlineMetrics.samples, offlineMetrics.loaded = nil, false
+		offlineMetrics.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestOfflineMetricsReplay(t *testing.T) {
+	setupTestAgent(t)
+	resetOfflineMetrics(t)
+	Cfg.OfflineSamples = 2
+
+	var received MachineInfo
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = MachineInfo{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+
+	// Three undelivered cycles keep the last two samples, on disk too
+	for i, ts := range []string{"2026-10-16T10:00:00Z", "2026-10-16T10:01:00Z", "2026-10-16T10:02:00Z"} {
+		info := MachineInfo{Timestamp: ts, CPUPercent: float64(10 * (i + 1)), MemoryUsedMB: 2048}
+		if err := sender.Send(context.Background(), info); err == nil {
+			t.Fatal("expected an error for status 503")
+		}
+	}
+	if _, err := os.Stat(statePath(offlineMetricsFileName)); err != nil {
+		t.Fatalf("buffer not persisted: %v", err)
+	}
+
+	// After a restart the samples are replayed with their timestamps
+	offlineMetrics.samples, offlineMetrics.loaded = nil, false
+	status = http.StatusCreated
+	if err := sender.Send(context.Background(), MachineInfo{Timestamp: "2026-10-16T10:03:00Z", CPUPercent: 40}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	history := received.MetricHistory
+	if len(history) != 2 || history[0].Timestamp != "2026-10-16T10:01:00Z" || history[0].CPUPercent != 20 ||
+		history[1].Timestamp != "2026-10-16T10:02:00Z" || history[1].MemoryUsedMB != 2048 {
+		t.Errorf("unexpected history: %+v", history)
+	}
+
+	// Delivered samples are not sent again
+	if err := sender.Send(context.Background(), MachineInfo{Timestamp: "2026-10-16T10:04:00Z"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(received.MetricHistory) != 0 {
+		t.Errorf("history replayed twice: %+v", received.MetricHistory)
+	}
+	if _, err := os.Stat(statePath(offlineMetricsFileName)); !os.IsNotExist(err) {
+		t.Errorf("buffer file left after the replay: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// osqueryVariablePrefix starts the per-query variables
+// (TATUSCAN_OSQUERY_USB=SELECT vendor, model FROM usb_devices)
+const osqueryVariablePrefix = "TATUSCAN_OSQUERY_"
+
+// osqueryRowLimit caps the rows reported per query
+const osqueryRowLimit = 500
+
+// OsqueryResults holds the rows of each osquery query by query name
+type OsqueryResults map[string][]map[string]string
+
+// getOsquery runs the configured osquery SQL queries with osqueryi and
+// returns their rows by query name, values as osquery prints them. Failed
+// queries are left out and reported together in the error.
+func getOsquery(ctx context.Context) (OsqueryResults, error) {
+	if len(Cfg.OsqueryQueries) == 0 {
+		return nil, nil
+	}
+	path := findOsqueryi()
+	if path == "" {
+		return nil, errors.New("osquery queries configured but osqueryi was not found; set TATUSCAN_OSQUERYI")
+	}
+
+	names := make([]string, 0, len(Cfg.OsqueryQueries))
+	for name := range Cfg.OsqueryQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := OsqueryResults{}
+	var errs []error
+	for _, name := range names {
+		output, err := runCommandContext(ctx, path, "--json", Cfg.OsqueryQueries[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("osquery %s: %w", name, err))
+			continue
+		}
+		rows, err := parseOsqueryRows(output)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("osquery %s: %w", name, err))
+			continue
+		}
+		if len(rows) > osqueryRowLimit {
+			Log.Warnf("osquery %s returned %d rows, reporting the first %d", name, len(rows), osqueryRowLimit)
+			rows = rows[:osqueryRowLimit]
+		}
+		results[name] = rows
+	}
+	if len(results) == 0 {
+		results = nil
+	}
+	return results, errors.Join(errs...)
+}
+
+// parseOsqueryRows decodes the output of osqueryi --json, an array of
+// objects whose values are strings
+func parseOsqueryRows(output []byte) ([]map[string]string, error) {
+	rows := []map[string]string{}
+	if err := json.Unmarshal(output, &rows); err != nil {
+		return nil, fmt.Errorf("unexpected osqueryi output: %w", err)
+	}
+	return rows, nil
+}
+
+// findOsqueryi returns TATUSCAN_OSQUERYI, or osqueryi from PATH or the
+// install locations of the osquery packages
+func findOsqueryi() string {
+	if Cfg.Osqueryi != "" {
+		return Cfg.Osqueryi
+	}
+	if path, err := exec.LookPath("osqueryi"); err == nil {
+		return path
+	}
+	for _, path := range osqueryiPaths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// parseOsqueryQueries reads the TATUSCAN_OSQUERY_<NAME> variables, keyed by
+// the lowercased name
+func parseOsqueryQueries(env map[string]string) map[string]string {
+	queries := map[string]string{}
+	for variable, query := range env {
+		name, ok := strings.CutPrefix(variable, osqueryVariablePrefix)
+		if !ok || strings.TrimSpace(query) == "" {
+			continue
+		}
+		name = strings.ToLower(name)
+		if !validTagKey(name) {
+			if Log != nil {
+				Log.Warnf("Invalid osquery query name %q ignored", name)
+			}
+			continue
+		}
+		queries[name] = strings.TrimSpace(query)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+	return queries
+}
@@ -0,0 +1,6 @@
+//go:build darwin
+
+package internal
+
+// osqueryiPaths are the osqueryi locations of the osquery packages
+var osqueryiPaths = []string{"/usr/local/bin/osqueryi"}
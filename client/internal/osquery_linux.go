@@ -0,0 +1,6 @@
+//go:build linux
+
+package internal
+
+// osqueryiPaths are the osqueryi locations of the osquery packages
+var osqueryiPaths = []string{"/usr/bin/osqueryi", "/opt/osquery/bin/osqueryi"}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseOsqueryQueries(t *testing.T) {
+	setupTestAgent(t)
+	queries := parseOsqueryQueries(map[string]string{
+		"TATUSCAN_OSQUERY_USB":        " SELECT vendor, model FROM usb_devices ",
+		"TATUSCAN_OSQUERY_BAD NAME":   "SELECT 1",
+		"TATUSCAN_OSQUERY_EMPTY":      "",
+		"TATUSCAN_OSQUERYI":           "/opt/osquery/bin/osqueryi",
+		"TATUSCAN_OSQUERY_Chrome_Ext": "SELECT name FROM chrome_extensions",
+	})
+	if len(queries) != 2 || queries["usb"] != "SELECT vendor, model FROM usb_devices" || queries["chrome_ext"] == "" {
+		t.Errorf("unexpected queries: %v", queries)
+	}
+}
+
+func TestGetOsquery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	setupTestAgent(t)
+	// A fake osqueryi answering one query and failing the other
+	osqueryi := filepath.Join(t.TempDir(), "osqueryi")
+	script := `#!/bin/sh
+case "$2" in
+*usb_devices*) echo '[{"vendor":"Logitech","model":"USB Receiver"}]' ;;
+*) echo "Error: no such table: nope" >&2; exit 1 ;;
+esac
+`
+	if err := os.WriteFile(osqueryi, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	Cfg.Osqueryi = osqueryi
+	Cfg.OsqueryQueries = map[string]string{
+		"usb":    "SELECT vendor, model FROM usb_devices",
+		"broken": "SELECT * FROM nope",
+	}
+
+	results, err := getOsquery(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "osquery broken") || !strings.Contains(err.Error(), "no such table") {
+		t.Errorf("expected the failed query in the error, got %v", err)
+	}
+	rows := results["usb"]
+	if len(results) != 1 || len(rows) != 1 || rows[0]["vendor"] != "Logitech" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	Cfg.OsqueryQueries = nil
+	if results, err := getOsquery(context.Background()); results != nil || err != nil {
+		t.Errorf("no queries configured: %v, %v", results, err)
+	}
+}
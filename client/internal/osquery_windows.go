@@ -0,0 +1,6 @@
+//go:build windows
+
+package internal
+
+// osqueryiPaths are the osqueryi locations of the osquery packages
+var osqueryiPaths = []string{`C:\Program Files\osquery\osqueryi.exe`}
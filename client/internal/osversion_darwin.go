@@ -5,7 +5,6 @@ package internal
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 )
 
@@ -114,8 +113,7 @@ func getOSVersionLinux() string {
 
 	// 4. Fallback para uname -r
 	Log.Debug("No distribution file found, using fallback uname -r")
-	cmd := exec.Command("uname", "-r")
-	output, err := cmd.Output()
+	output, err := runCommand("uname", "-r")
 	if err != nil {
 		Log.Warnf("Error executing uname: %v", err)
 		return "Linux Unknown"
@@ -5,7 +5,7 @@ package internal
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -23,7 +23,7 @@ func getOSVersionLinux() string {
 	}
 
 	// 1. Try to read /etc/os-release (most modern method)
-	osReleaseData, err := readFile("/etc/os-release")
+	osReleaseData, err := readFile(filepath.Join(etcRoot, "os-release"))
 	if err == nil {
 		Log.Debug("File /etc/os-release found, processing...")
 		osRelease := make(map[string]string)
@@ -71,7 +71,7 @@ func getOSVersionLinux() string {
 	}
 
 	// 2. Try to read /etc/lsb-release (for Debian/Ubuntu based distributions)
-	lsbReleaseData, err := readFile("/etc/lsb-release")
+	lsbReleaseData, err := readFile(filepath.Join(etcRoot, "lsb-release"))
 	if err == nil {
 		Log.Debug("File /etc/lsb-release found, processing...")
 		lsbRelease := make(map[string]string)
@@ -104,7 +104,7 @@ func getOSVersionLinux() string {
 	}
 
 	// 3. Try to read /etc/redhat-release (for Red Hat based distributions)
-	redhatReleaseData, err := readFile("/etc/redhat-release")
+	redhatReleaseData, err := readFile(filepath.Join(etcRoot, "redhat-release"))
 	if err == nil {
 		Log.Debugf("File /etc/redhat-release found: %s", strings.TrimSpace(redhatReleaseData))
 		return strings.TrimSpace(redhatReleaseData)
@@ -114,8 +114,7 @@ func getOSVersionLinux() string {
 
 	// 4. Fallback to uname -r
 	Log.Debug("No distribution file found, using fallback uname -r")
-	cmd := exec.Command("uname", "-r")
-	output, err := cmd.Output()
+	output, err := runCommand("uname", "-r")
 	if err != nil {
 		Log.Warnf("Error to execute uname: %v", err)
 		return "Linux Unknown"
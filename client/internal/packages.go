@@ -0,0 +1,73 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultPackagesInterval spaces package inventories; the installed set
+// rarely changes between collections
+const defaultPackagesInterval = time.Hour
+
+// Package is an installed software package
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch,omitempty"`
+	Manager string `json:"manager"` // dpkg, rpm, pacman, snap, flatpak, brew, windows
+}
+
+// packagesCache keeps the last inventory between collections
+var packagesCache struct {
+	sync.Mutex
+	packages []Package
+	err      error
+	checked  time.Time
+}
+
+// getPackages returns the installed packages when TATUSCAN_PACKAGES is set,
+// listing them at most once per TATUSCAN_PACKAGES_INTERVAL
+func getPackages() ([]Package, error) {
+	if !Cfg.Packages {
+		return nil, nil
+	}
+	packagesCache.Lock()
+	defer packagesCache.Unlock()
+	if !packagesCache.checked.IsZero() && time.Since(packagesCache.checked) < Cfg.PackagesInterval {
+		return packagesCache.packages, packagesCache.err
+	}
+
+	Log.Debug("Listing installed packages")
+	packages, err := platformPackages()
+	packagesCache.packages, packagesCache.err, packagesCache.checked = sortPackages(packages), err, time.Now()
+	return packagesCache.packages, err
+}
+
+// sortPackages orders packages by name, then manager and architecture,
+// dropping exact duplicates
+func sortPackages(packages []Package) []Package {
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Manager != b.Manager {
+			return a.Manager < b.Manager
+		}
+		if a.Arch != b.Arch {
+			return a.Arch < b.Arch
+		}
+		return a.Version < b.Version
+	})
+	out := packages[:0]
+	for i, p := range packages {
+		if i > 0 && p == out[len(out)-1] {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+//go:build darwin
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// brewPrefixes are the Homebrew prefixes of Apple silicon and Intel Macs
+var brewPrefixes = []string{"/opt/homebrew", "/usr/local"}
+
+// listBrewDir lists the packages of a Cellar or Caskroom directory, laid
+// out as <name>/<version>; the newest version sorts last
+func listBrewDir(dir string) []Package {
+	names, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var packages []Package
+	for _, name := range names {
+		if !name.IsDir() || name.Name()[0] == '.' {
+			continue
+		}
+		versions, err := os.ReadDir(filepath.Join(dir, name.Name()))
+		if err != nil {
+			continue
+		}
+		version := ""
+		for _, v := range versions {
+			if v.IsDir() && v.Name()[0] != '.' {
+				version = v.Name()
+			}
+		}
+		if version != "" {
+			packages = append(packages, Package{Name: name.Name(), Version: version, Manager: "brew"})
+		}
+	}
+	return packages
+}
+
+// platformPackages lists the Homebrew formulae and casks from the Cellar and
+// Caskroom directories, as brew refuses to run as root
+func platformPackages() ([]Package, error) {
+	var packages []Package
+	for _, prefix := range brewPrefixes {
+		packages = append(packages, listBrewDir(filepath.Join(prefix, "Cellar"))...)
+		packages = append(packages, listBrewDir(filepath.Join(prefix, "Caskroom"))...)
+	}
+	return packages, nil
+}
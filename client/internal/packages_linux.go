@@ -0,0 +1,146 @@
+//go:build linux
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dpkgQueryFormat prints the status, name, version and architecture of
+// each package known to dpkg
+const dpkgQueryFormat = "${db:Status-Abbrev}\t${Package}\t${Version}\t${Architecture}\n"
+
+// rpmQueryFormat prints the name, [epoch:]version-release and architecture
+// of each installed package
+const rpmQueryFormat = "%{NAME}\t%|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE}\t%{ARCH}\n"
+
+// parseDpkgQuery parses dpkgQueryFormat output, keeping the installed
+// packages ("ii" status) only
+func parseDpkgQuery(output string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 || strings.TrimSpace(fields[0]) != "ii" {
+			continue
+		}
+		packages = append(packages, Package{Name: fields[1], Version: fields[2], Arch: fields[3], Manager: "dpkg"})
+	}
+	return packages
+}
+
+// parseRpmQuery parses rpmQueryFormat output; gpg-pubkey entries are keys
+// imported into the database, not packages
+func parseRpmQuery(output string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "gpg-pubkey" {
+			continue
+		}
+		arch := fields[2]
+		if arch == "(none)" {
+			arch = ""
+		}
+		packages = append(packages, Package{Name: fields[0], Version: fields[1], Arch: arch, Manager: "rpm"})
+	}
+	return packages
+}
+
+// parsePacmanQuery parses `pacman -Q` output (name version)
+func parsePacmanQuery(output string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		packages = append(packages, Package{Name: fields[0], Version: fields[1], Manager: "pacman"})
+	}
+	return packages
+}
+
+// parseSnapList parses `snap list` output (Name Version Rev Tracking
+// Publisher Notes), skipping the header
+func parseSnapList(output string) []Package {
+	var packages []Package
+	for i, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 2 {
+			continue
+		}
+		packages = append(packages, Package{Name: fields[0], Version: fields[1], Manager: "snap"})
+	}
+	return packages
+}
+
+// flatpakListColumns are the columns of `flatpak list`, tab separated
+const flatpakListColumns = "application,version,arch"
+
+// parseFlatpakList parses `flatpak list --app --columns=flatpakListColumns`
+// output; apps without a version number report an empty one
+func parseFlatpakList(output string) []Package {
+	var packages []Package
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[0] == "" || fields[0] == "Application ID" {
+			continue
+		}
+		packages = append(packages, Package{Name: fields[0], Version: fields[1], Arch: fields[2], Manager: "flatpak"})
+	}
+	return packages
+}
+
+// platformPackages lists the packages of the system package database, dpkg
+// (apt), rpm (dnf, yum, zypper) or pacman, whichever is found first, and the
+// snap and flatpak applications installed beside them
+func platformPackages() ([]Package, error) {
+	var packages []Package
+	found := false
+	databases := []struct {
+		name  string
+		args  []string
+		parse func(string) []Package
+	}{
+		{"dpkg-query", []string{"-W", "-f", dpkgQueryFormat}, parseDpkgQuery},
+		{"rpm", []string{"-qa", "--qf", rpmQueryFormat}, parseRpmQuery},
+		{"pacman", []string{"-Q"}, parsePacmanQuery},
+	}
+	for _, m := range databases {
+		if _, err := lookPackageManager(m.name); err != nil {
+			continue
+		}
+		output, err := runPackageManager(m.name, m.args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.name, err)
+		}
+		packages, found = m.parse(string(output)), true
+		break
+	}
+
+	// Application stores are optional: a failing one is logged and skipped
+	stores := []struct {
+		name  string
+		args  []string
+		parse func(string) []Package
+	}{
+		{"snap", []string{"list"}, parseSnapList},
+		{"flatpak", []string{"list", "--system", "--app", "--columns=" + flatpakListColumns}, parseFlatpakList},
+	}
+	for _, m := range stores {
+		if _, err := lookPackageManager(m.name); err != nil {
+			continue
+		}
+		found = true
+		output, err := runPackageManager(m.name, m.args...)
+		if err != nil {
+			Log.Warnf("Error to list %s applications: %v", m.name, err)
+			continue
+		}
+		packages = append(packages, m.parse(string(output))...)
+	}
+	if !found {
+		return nil, fmt.Errorf("no supported package database (dpkg-query, rpm, pacman, snap, flatpak) found")
+	}
+	return packages, nil
+}
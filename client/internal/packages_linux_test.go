@@ -0,0 +1,125 @@
+//go:build linux
+
+package internal
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const dpkgQuery = "ii \topenssl\t3.0.2-0ubuntu1.15\tamd64\n" +
+	"rc \told-kernel\t5.15.0-1\tamd64\n" +
+	"ii \tlibc6\t2.35-0ubuntu3.6\tamd64\n" +
+	"ii \tlibc6\t2.35-0ubuntu3.6\ti386\n"
+
+const rpmQuery = "openssh-server\t8.7p1-38.el9\tx86_64\n" +
+	"gpg-pubkey\t3228467c-613798eb\t(none)\n" +
+	"shim-x64\t15.8-4.el9\tx86_64\n" +
+	"grub2-common\t1:2.06-80.el9\tnoarch\n"
+
+func TestParseDpkgQuery(t *testing.T) {
+	want := []Package{
+		{Name: "openssl", Version: "3.0.2-0ubuntu1.15", Arch: "amd64", Manager: "dpkg"},
+		{Name: "libc6", Version: "2.35-0ubuntu3.6", Arch: "amd64", Manager: "dpkg"},
+		{Name: "libc6", Version: "2.35-0ubuntu3.6", Arch: "i386", Manager: "dpkg"},
+	}
+	if got := parseDpkgQuery(dpkgQuery); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDpkgQuery = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseRpmQuery(t *testing.T) {
+	got := parseRpmQuery(rpmQuery)
+	if len(got) != 3 || got[2].Version != "1:2.06-80.el9" || got[2].Arch != "noarch" {
+		t.Errorf("unexpected packages: %+v", got)
+	}
+}
+
+func TestParsePacmanQuery(t *testing.T) {
+	want := []Package{{Name: "linux", Version: "6.8.9.arch1-1", Manager: "pacman"}}
+	if got := parsePacmanQuery("linux 6.8.9.arch1-1\n\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePacmanQuery = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetPackagesCachesResult(t *testing.T) {
+	setupTestAgent(t)
+	calls := stubPackageManager(t, "rpm", rpmQuery, nil)
+	Cfg = Config{Packages: true, PackagesInterval: time.Hour}
+
+	for i := 0; i < 2; i++ {
+		packages, err := getPackages()
+		if err != nil {
+			t.Fatalf("getPackages: %v", err)
+		}
+		if len(packages) != 3 || packages[0].Name != "grub2-common" {
+			t.Errorf("unexpected packages: %+v", packages)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("rpm ran %d times, want 1", *calls)
+	}
+
+	Cfg.Packages = false
+	if packages, _ := getPackages(); packages != nil {
+		t.Errorf("expected no packages when disabled, got %+v", packages)
+	}
+}
+
+const snapList = `Name      Version          Rev    Tracking         Publisher   Notes
+core22    20240111         1122   latest/stable    canonical✓  base
+firefox   125.0.2-1        4173   latest/stable/…  mozilla✓    -
+`
+
+const flatpakList = "org.gimp.GIMP\t2.10.38\tx86_64\n" +
+	"org.mozilla.Thunderbird\t\tx86_64\n"
+
+func TestParseSnapList(t *testing.T) {
+	want := []Package{
+		{Name: "core22", Version: "20240111", Manager: "snap"},
+		{Name: "firefox", Version: "125.0.2-1", Manager: "snap"},
+	}
+	if got := parseSnapList(snapList); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSnapList = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFlatpakList(t *testing.T) {
+	want := []Package{
+		{Name: "org.gimp.GIMP", Version: "2.10.38", Arch: "x86_64", Manager: "flatpak"},
+		{Name: "org.mozilla.Thunderbird", Arch: "x86_64", Manager: "flatpak"},
+	}
+	if got := parseFlatpakList(flatpakList); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFlatpakList = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlatformPackagesAddsAppStores(t *testing.T) {
+	setupTestAgent(t)
+	origLook, origRun := lookPackageManager, runPackageManager
+	t.Cleanup(func() { lookPackageManager, runPackageManager = origLook, origRun })
+	outputs := map[string]string{"dpkg-query": dpkgQuery, "snap": snapList, "flatpak": ""}
+	lookPackageManager = func(file string) (string, error) {
+		if _, ok := outputs[file]; ok {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	runPackageManager = func(name string, _ ...string) ([]byte, error) {
+		if name == "flatpak" {
+			return nil, errors.New("flatpak: system installation not found")
+		}
+		return []byte(outputs[name]), nil
+	}
+
+	packages, err := platformPackages()
+	if err != nil {
+		t.Fatalf("platformPackages: %v", err)
+	}
+	if len(packages) != 5 || packages[3].Manager != "snap" {
+		t.Errorf("unexpected packages: %+v", packages)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// uninstallRegistryKey lists the programs shown in Apps & features, which is
+// also what winget reports for software it did not install itself
+const uninstallRegistryKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+
+// listUninstallKey lists the programs of one registry view of the
+// Uninstall key, skipping system components and updates
+func listUninstallKey(view uint32) ([]Package, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, uninstallRegistryKey, registry.ENUMERATE_SUB_KEYS|view)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Close()
+	names, err := k.ReadSubKeyNames(0)
+	if err != nil {
+		return nil, err
+	}
+	var packages []Package
+	for _, name := range names {
+		sub, err := registry.OpenKey(k, name, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		displayName, _, _ := sub.GetStringValue("DisplayName")
+		version, _, _ := sub.GetStringValue("DisplayVersion")
+		system, _, _ := sub.GetIntegerValue("SystemComponent")
+		parent, _, _ := sub.GetStringValue("ParentKeyName")
+		sub.Close()
+		if displayName = strings.TrimSpace(displayName); displayName == "" || system == 1 || parent != "" {
+			continue
+		}
+		packages = append(packages, Package{Name: displayName, Version: strings.TrimSpace(version), Manager: "windows"})
+	}
+	return packages, nil
+}
+
+// platformPackages lists the installed programs of the 64-bit and 32-bit
+// registry views; on 32-bit Windows both are the same key, whose duplicates
+// sortPackages drops. winget is not used: it is not available to services
+// running as LocalSystem.
+func platformPackages() ([]Package, error) {
+	packages, err := listUninstallKey(registry.WOW64_64KEY)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", uninstallRegistryKey, err)
+	}
+	if wow, err := listUninstallKey(registry.WOW64_32KEY); err == nil {
+		packages = append(packages, wow...)
+	}
+	return packages, nil
+}
@@ -0,0 +1,12 @@
+//go:build windows || linux || darwin
+
+package internal
+
+// Filesystem roots read by the collectors; tests point them to fixture trees
+// under testdata so payloads can be reproduced without the real hardware
+var (
+	rootDir   = "/"
+	sysfsRoot = "/sys"
+	procRoot  = "/proc"
+	etcRoot   = "/etc"
+)
@@ -0,0 +1,93 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PCIDevice is a PCI or PCIe device: network cards, GPUs, storage and USB
+// controllers and the like. Bridges are left out.
+type PCIDevice struct {
+	Slot     string `json:"slot,omitempty"` // bus address on Linux (0000:00:1f.6)
+	Class    string `json:"class"`
+	VendorID string `json:"vendor_id"` // 4 lowercase hex digits
+	DeviceID string `json:"device_id"`
+	Vendor   string `json:"vendor,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Driver   string `json:"driver,omitempty"`
+}
+
+// pciClasses names the PCI base class codes
+var pciClasses = map[string]string{
+	"00": "unclassified", "01": "storage", "02": "network", "03": "display",
+	"04": "multimedia", "05": "memory", "06": "bridge", "07": "communication",
+	"08": "system", "09": "input", "0a": "docking", "0b": "processor",
+	"0c": "serial_bus", "0d": "wireless", "0e": "intelligent_io",
+	"0f": "satellite", "10": "encryption", "11": "signal_processing",
+	"12": "accelerator", "13": "instrumentation",
+}
+
+// pciClassName returns the name of the base class of a class code (0x020000)
+func pciClassName(code string) string {
+	code = strings.TrimPrefix(strings.ToLower(code), "0x")
+	if len(code) < 2 {
+		return ""
+	}
+	if name, ok := pciClasses[code[:2]]; ok {
+		return name
+	}
+	return "other"
+}
+
+// pciIDs holds vendor and device names from a pci.ids database, keyed by
+// vendor ID and by vendor ID + device ID
+type pciIDs map[string]string
+
+// parsePCIIDs reads the vendor and device names of the pci.ids format,
+// keeping only the vendors in wanted; subsystems and the class section
+// are skipped
+func parsePCIIDs(r io.Reader, wanted map[string]bool) pciIDs {
+	ids := make(pciIDs)
+	vendor := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "C ") {
+			break // device classes follow the vendors
+		}
+		if line[0] != '\t' {
+			id, name, _ := strings.Cut(line, "  ")
+			vendor = ""
+			if wanted[id] {
+				vendor = id
+				ids[id] = strings.TrimSpace(name)
+			}
+			continue
+		}
+		if vendor == "" || strings.HasPrefix(line, "\t\t") {
+			continue
+		}
+		id, name, _ := strings.Cut(strings.TrimPrefix(line, "\t"), "  ")
+		ids[vendor+":"+id] = strings.TrimSpace(name)
+	}
+	return ids
+}
+
+// sortPCIDevices orders devices by slot, then IDs, for stable payloads
+func sortPCIDevices(devices []PCIDevice) []PCIDevice {
+	sort.Slice(devices, func(i, j int) bool {
+		a, b := devices[i], devices[j]
+		if a.Slot != b.Slot {
+			return a.Slot < b.Slot
+		}
+		return a.VendorID+a.DeviceID+a.Name < b.VendorID+b.DeviceID+b.Name
+	})
+	return devices
+}
@@ -0,0 +1,11 @@
+//go:build darwin
+
+package internal
+
+import "context"
+
+// getPCIDevices is not implemented on macOS, where PCI devices are few and
+// fixed by the Mac model
+func getPCIDevices(_ context.Context) []PCIDevice {
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pciIDsPaths are the pci.ids databases of the hwdata and pciutils packages
+var pciIDsPaths = []string{"usr/share/hwdata/pci.ids", "usr/share/misc/pci.ids", "usr/share/pci.ids"}
+
+// getPCIDevices lists /sys/bus/pci/devices, named from the pci.ids
+// database when one is installed
+func getPCIDevices(_ context.Context) []PCIDevice {
+	root := filepath.Join(sysfsRoot, "bus", "pci", "devices")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		Log.Debugf("Error to list PCI devices: %v", err)
+		return nil
+	}
+	var devices []PCIDevice
+	vendors := make(map[string]bool)
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		device := PCIDevice{
+			Slot:     entry.Name(),
+			Class:    pciClassName(readSysfsValue(dir, "class")),
+			VendorID: strings.TrimPrefix(readSysfsValue(dir, "vendor"), "0x"),
+			DeviceID: strings.TrimPrefix(readSysfsValue(dir, "device"), "0x"),
+		}
+		if device.VendorID == "" || device.Class == "bridge" {
+			continue
+		}
+		if target, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+			device.Driver = filepath.Base(target)
+		}
+		vendors[device.VendorID] = true
+		devices = append(devices, device)
+	}
+
+	for _, path := range pciIDsPaths {
+		f, err := os.Open(filepath.Join(rootDir, path))
+		if err != nil {
+			continue
+		}
+		ids := parsePCIIDs(f, vendors)
+		f.Close()
+		for i, d := range devices {
+			devices[i].Vendor = ids[d.VendorID]
+			devices[i].Name = ids[d.VendorID+":"+d.DeviceID]
+		}
+		break
+	}
+	return sortPCIDevices(devices)
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPCIClassName(t *testing.T) {
+	cases := map[string]string{
+		"0x020000": "network",
+		"0x030000": "display",
+		"0x0c0330": "serial_bus",
+		"0x060400": "bridge",
+		"0xff0000": "other",
+		"":         "",
+	}
+	for code, want := range cases {
+		if got := pciClassName(code); got != want {
+			t.Errorf("pciClassName(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestParsePCIIDs(t *testing.T) {
+	const db = `# comment
+10de  NVIDIA Corporation
+	1c82  GP107 [GeForce GTX 1050 Ti]
+8086  Intel Corporation
+	15be  Ethernet Connection (6) I219-V
+		8086 0084  Subsystem name
+	2723  Wi-Fi 6 AX200
+
+C 02  Network controller
+	00  Ethernet controller
+`
+	ids := parsePCIIDs(strings.NewReader(db), map[string]bool{"8086": true})
+	want := pciIDs{
+		"8086":      "Intel Corporation",
+		"8086:15be": "Ethernet Connection (6) I219-V",
+		"8086:2723": "Wi-Fi 6 AX200",
+	}
+	if len(ids) != len(want) {
+		t.Fatalf("parsePCIIDs = %v, want %v", ids, want)
+	}
+	for key, name := range want {
+		if ids[key] != name {
+			t.Errorf("ids[%q] = %q, want %q", key, ids[key], name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/StackExchange/wmi"
+)
+
+// pciDeviceIDRe extracts the IDs of a PnP device ID such as
+// PCI\VEN_8086&DEV_15BC&SUBSYS_...
+var pciDeviceIDRe = regexp.MustCompile(`(?i)^PCI\\VEN_([0-9A-F]{4})&DEV_([0-9A-F]{4})`)
+
+// pnpClasses maps the PnP device classes to PCI class names
+var pnpClasses = map[string]string{
+	"net":         "network",
+	"display":     "display",
+	"scsiadapter": "storage",
+	"hdc":         "storage",
+	"media":       "multimedia",
+	"usb":         "serial_bus",
+	"system":      "system",
+	"bluetooth":   "wireless",
+	"processor":   "processor",
+}
+
+// getPCIDevices lists the PCI devices known to Plug and Play, with the
+// names Windows drivers give them
+func getPCIDevices(ctx context.Context) []PCIDevice {
+	type pnpEntity struct {
+		DeviceID     string
+		Name         *string
+		Manufacturer *string
+		PNPClass     *string
+		Service      *string
+	}
+	var entities []pnpEntity
+	q := wmi.CreateQuery(&entities, `WHERE DeviceID LIKE 'PCI\\%'`, "Win32_PnPEntity")
+	if err := wmiQuery(q, &entities, ""); errors.Is(err, errWMIDisabled) {
+		Log.Debug("WMI disabled; PCI devices not collected")
+		return nil
+	} else if err != nil {
+		Log.Warnf("Error to query Win32_PnPEntity: %v", err)
+		addWarning(ctx, WarnWMIUnavailable, "Win32_PnPEntity query failed: %v", err)
+		return nil
+	}
+	var devices []PCIDevice
+	for _, e := range entities {
+		m := pciDeviceIDRe.FindStringSubmatch(e.DeviceID)
+		if m == nil {
+			continue
+		}
+		device := PCIDevice{VendorID: strings.ToLower(m[1]), DeviceID: strings.ToLower(m[2])}
+		if e.PNPClass != nil {
+			class := strings.ToLower(*e.PNPClass)
+			device.Class = class
+			if name, ok := pnpClasses[class]; ok {
+				device.Class = name
+			}
+		}
+		if e.Name != nil {
+			device.Name = *e.Name
+		}
+		if e.Manufacturer != nil {
+			device.Vendor = *e.Manufacturer
+		}
+		if e.Service != nil {
+			device.Driver = *e.Service
+		}
+		devices = append(devices, device)
+	}
+	return sortPCIDevices(devices)
+}
@@ -0,0 +1,175 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// collectorResult is the outcome of a collector run over its own copy of
+// the report
+type collectorResult struct {
+	info MachineInfo
+	err  error
+}
+
+// errCollectorTimeout marks a collector abandoned at its own deadline
+var errCollectorTimeout = errors.New("collector timed out")
+
+// nextWave splits pending into the collectors whose needs are all done,
+// which can run together, and the rest; a dependency cycle falls back to
+// running the first collector alone
+func nextWave(pending []collector) (wave, rest []collector) {
+	waiting := collectorNames(pending)
+	for _, c := range pending {
+		ready := true
+		for _, need := range c.needs {
+			if slices.Contains(waiting, need) {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			wave = append(wave, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	if len(wave) == 0 {
+		return pending[:1], pending[1:]
+	}
+	return wave, rest
+}
+
+// runWave runs the collectors of a wave concurrently over copies of info,
+// then merges their results in pipeline order; a collector whose
+// TATUSCAN_COLLECTOR_INTERVALS interval has not elapsed contributes its last
+// result instead. It returns the error of a failed required collector.
+func runWave(ctx context.Context, wave []collector, info *MachineInfo, started time.Time) error {
+	// The collector of the log context is only known without concurrency
+	if len(wave) == 1 {
+		logContext.collector.Store(wave[0].name)
+	} else {
+		logContext.collector.Store("")
+	}
+	defer logContext.collector.Store("")
+
+	base := *info
+	results := make([]collectorResult, len(wave))
+	var wg sync.WaitGroup
+	fresh := make([]bool, len(wave))
+	for i, c := range wave {
+		if r, ok := scheduledResult(c, base, started); ok {
+			results[i] = r
+			continue
+		}
+		fresh[i] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Log.Debugf("Running collector %s", c.name)
+			results[i] = runCollector(ctx, c, base)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range wave {
+		r := results[i]
+		cctx := withCollector(ctx, c.name)
+		switch {
+		case r.err == nil:
+			if fresh[i] {
+				recordScheduled(c, &base, &r.info, started)
+			}
+			mergeCollected(info, &base, &r.info)
+			if info.MachineID != "" {
+				logContext.machineID.Store(info.MachineID)
+			}
+			continue
+		case errors.Is(r.err, errCollectorTimeout) && ctx.Err() != nil:
+			Log.Warnf("Collection interrupted in collector %s: %v", c.name, ctx.Err())
+			addWarning(cctx, WarnCollectionTimeout, "collection interrupted after %s: %v", time.Since(started).Round(time.Second), ctx.Err())
+		case errors.Is(r.err, errCollectorTimeout):
+			Log.Warnf("Collector %s abandoned after %s", c.name, Cfg.CollectorTimeout)
+			addWarning(cctx, WarnCollectorTimeout, "collector abandoned after %s", Cfg.CollectorTimeout)
+		case c.required:
+			Log.Errorf("Collector %s failed: %v", c.name, r.err)
+		default:
+			Log.Warnf("Collector %s failed: %v", c.name, r.err)
+			addWarning(cctx, WarnCollectorFailed, "%v", r.err)
+		}
+		if c.required {
+			return r.err
+		}
+	}
+	return nil
+}
+
+// runCollector runs c over a copy of base within TATUSCAN_COLLECTOR_TIMEOUT;
+// an abandoned collector goroutine can finish (or hang) without touching
+// the report
+func runCollector(ctx context.Context, c collector, base MachineInfo) collectorResult {
+	ctx = withCollector(ctx, c.name)
+	if Cfg.CollectorTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Cfg.CollectorTimeout)
+		defer cancel()
+	}
+	done := make(chan collectorResult, 1)
+	go func() {
+		work := base
+		err := c.collect(ctx, &work)
+		done <- collectorResult{info: work, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r
+	case <-ctx.Done():
+		return collectorResult{err: fmt.Errorf("%w: %w", errCollectorTimeout, ctx.Err())}
+	}
+}
+
+// mergeCollected copies into dst the fields a collector changed in work
+// from base
+func mergeCollected(dst, base, work *MachineInfo) {
+	d, b, w := reflect.ValueOf(dst).Elem(), reflect.ValueOf(base).Elem(), reflect.ValueOf(work).Elem()
+	for i := 0; i < w.NumField(); i++ {
+		if !reflect.DeepEqual(w.Field(i).Interface(), b.Field(i).Interface()) {
+			d.Field(i).Set(w.Field(i))
+		}
+	}
+}
+
+// collectorNames returns the names of cs
+func collectorNames(cs []collector) []string {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = c.name
+	}
+	return names
+}
+
+// sortWarnings orders warnings by pipeline position, agent warnings first,
+// so concurrent collectors still give a stable report
+func sortWarnings(warnings []Warning) []Warning {
+	position := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		if i := slices.IndexFunc(collectors, func(c collector) bool { return c.name == name }); i >= 0 {
+			return i
+		}
+		return len(collectors)
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return position(warnings[i].Collector) < position(warnings[j].Collector)
+	})
+	return warnings
+}
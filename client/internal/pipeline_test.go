@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// withCollectors replaces the pipeline for the duration of a test
+func withCollectors(t *testing.T, cs ...collector) {
+	t.Helper()
+	orig := collectors
+	collectors = cs
+	t.Cleanup(func() { collectors = orig })
+}
+
+func TestCollectDataTimeout(t *testing.T) {
+	setupTestAgent(t)
+	release := make(chan struct{})
+	defer close(release)
+	var mu sync.Mutex
+	var ran []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, name)
+	}
+	withCollectors(t,
+		collector{name: "host", collect: func(_ context.Context, info *MachineInfo) error {
+			info.Hostname = "lab-01"
+			return nil
+		}},
+		// Stuck like a WMI query: ignores the context
+		collector{name: "wmi", collect: func(_ context.Context, info *MachineInfo) error {
+			<-release
+			info.Hostname = "overwritten"
+			return nil
+		}},
+		collector{name: "metrics", collect: func(_ context.Context, info *MachineInfo) error {
+			record("metrics")
+			info.CPUPercent = 12.5
+			return nil
+		}},
+		collector{name: "late", needs: []string{"wmi"}, collect: func(_ context.Context, info *MachineInfo) error {
+			record("late")
+			return nil
+		}},
+	)
+
+	// The stuck collector is abandoned at its own deadline and the others
+	// are kept, including the ones waiting for it
+	Cfg.CollectorTimeout = 50 * time.Millisecond
+	info, err := CollectData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectData: %v", err)
+	}
+	if info.Hostname != "lab-01" || info.CPUPercent != 12.5 || strings.Join(ran, ",") != "metrics,late" {
+		t.Errorf("unexpected report: hostname %q, cpu %v, collectors %v", info.Hostname, info.CPUPercent, ran)
+	}
+	if len(info.Warnings) != 1 || info.Warnings[0].Code != WarnCollectorTimeout || info.Warnings[0].Collector != "wmi" {
+		t.Errorf("expected a collector_timeout warning, got %+v", info.Warnings)
+	}
+
+	// At the cycle deadline the collectors waiting are skipped
+	ran = nil
+	Cfg.CollectorTimeout, Cfg.CollectTimeout = 0, 50*time.Millisecond
+	started := time.Now()
+	info, err = CollectData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectData: %v", err)
+	}
+	if time.Since(started) > 5*time.Second {
+		t.Error("the stuck collector blocked the collection")
+	}
+	if info.Hostname != "lab-01" || strings.Join(ran, ",") != "metrics" {
+		t.Errorf("unexpected report: hostname %q, collectors %v", info.Hostname, ran)
+	}
+	if len(info.Warnings) != 1 || info.Warnings[0].Code != WarnCollectionTimeout || info.Warnings[0].Collector != "wmi" {
+		t.Errorf("expected a collection_timeout warning, got %+v", info.Warnings)
+	}
+
+	// A required collector left out fails the collection
+	collectors = append(collectors, collector{name: "network", required: true, needs: []string{"wmi"}, collect: collectors[0].collect})
+	if _, err := CollectData(context.Background()); err == nil || !strings.Contains(err.Error(), "before collector network") {
+		t.Errorf("expected the required collector error, got %v", err)
+	}
+}
+
+func TestCollectDataConcurrent(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.CollectTimeout = 5 * time.Second
+	// Each collector waits for the other: they only finish together
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	meet := func(ctx context.Context) error {
+		barrier.Done()
+		done := make(chan struct{})
+		go func() { barrier.Wait(); close(done) }()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	withCollectors(t,
+		collector{name: "host", collect: func(ctx context.Context, info *MachineInfo) error {
+			info.Hostname = "lab-01"
+			addWarning(ctx, WarnClockSkew, "from host")
+			return meet(ctx)
+		}},
+		collector{name: "metrics", collect: func(ctx context.Context, info *MachineInfo) error {
+			info.MemoryTotalMB = 8192
+			addWarning(ctx, WarnNoIPv4, "from metrics")
+			return meet(ctx)
+		}},
+		collector{name: "health", needs: []string{"host", "metrics"}, collect: func(_ context.Context, info *MachineInfo) error {
+			if info.Hostname == "" || info.MemoryTotalMB == 0 {
+				t.Error("health ran before the collectors it needs")
+			}
+			return nil
+		}},
+	)
+
+	info, err := CollectData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectData: %v", err)
+	}
+	if info.Hostname != "lab-01" || info.MemoryTotalMB != 8192 {
+		t.Errorf("results not merged: %+v", info)
+	}
+	if len(info.Warnings) != 2 || info.Warnings[0].Collector != "host" || info.Warnings[1].Collector != "metrics" {
+		t.Errorf("expected warnings tagged and ordered by collector, got %+v", info.Warnings)
+	}
+}
+
+func TestDisabledCollectors(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.DisabledCollectors = parseDisabledCollectors("Watchlist, network, bogus, sensors")
+	if strings.Join(Cfg.DisabledCollectors, ",") != "watchlist,sensors" {
+		t.Fatalf("unexpected disabled collectors: %v", Cfg.DisabledCollectors)
+	}
+	Cfg.Privacy = PrivacyStrict
+	noop := func(context.Context, *MachineInfo) error { return nil }
+	withCollectors(t,
+		collector{name: "host", collect: noop},
+		collector{name: "watchlist", collect: noop},
+		collector{name: "neighbors", personal: true, collect: noop},
+		collector{name: "sensors", collect: noop},
+		collector{name: "network", required: true, collect: noop},
+	)
+	info, err := CollectData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectData: %v", err)
+	}
+	if strings.Join(info.Collectors, ",") != "host,network" {
+		t.Errorf("unexpected enabled collectors: %v", info.Collectors)
+	}
+}
+
+func TestCollectorIntervals(t *testing.T) {
+	setupTestAgent(t)
+	t.Cleanup(func() { collectorSchedule.results = map[string]scheduledCollection{} })
+	intervals := parseCollectorIntervals("Packages=24h, network=1h, bogus=1h, pci=soon, metrics=1m")
+	if len(intervals) != 2 || intervals["packages"] != 24*time.Hour || intervals["metrics"] != time.Minute {
+		t.Fatalf("unexpected intervals: %v", intervals)
+	}
+	Cfg.CollectorIntervals = intervals
+	runs := 0
+	withCollectors(t,
+		collector{name: "network", required: true, collect: func(_ context.Context, info *MachineInfo) error {
+			info.IP = "192.0.2.10"
+			return nil
+		}},
+		collector{name: "packages", collect: func(_ context.Context, info *MachineInfo) error {
+			runs++
+			info.Packages = []Package{{Name: "openssl", Version: "3.0." + strconv.Itoa(runs)}}
+			return nil
+		}},
+	)
+
+	// The second cycle reuses the packages of the first
+	for range 2 {
+		info, err := CollectData(context.Background())
+		if err != nil {
+			t.Fatalf("CollectData: %v", err)
+		}
+		if runs != 1 || info.IP != "192.0.2.10" || len(info.Packages) != 1 || info.Packages[0].Version != "3.0.1" {
+			t.Fatalf("unexpected report after %d runs: %+v", runs, info.Packages)
+		}
+	}
+
+	// Once the interval elapses the collector runs again
+	last := collectorSchedule.results["packages"]
+	last.at = last.at.Add(-25 * time.Hour)
+	collectorSchedule.results["packages"] = last
+	info, err := CollectData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectData: %v", err)
+	}
+	if runs != 2 || info.Packages[0].Version != "3.0.2" {
+		t.Errorf("collector not run again: %d runs, %+v", runs, info.Packages)
+	}
+}
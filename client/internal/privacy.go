@@ -0,0 +1,170 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PrivacyStrict is the TATUSCAN_PRIVACY preset that skips collectors marked
+// personal and pseudonymizes what remains. Still sent: MachineID, the
+// hostname as a keyed pseudonym, IP addresses and interfaces (without
+// MACs), OS, SMBIOS identity, virtualization and container, image markers,
+// services (without accounts), pending updates, sensors, batteries, metrics
+// and warnings.
+const PrivacyStrict = "strict"
+
+// PrivacyPseudonymous is the TATUSCAN_PRIVACY preset for research datasets:
+// hostname, serial numbers, product UUID, user names, IP and MAC addresses
+// and the Wi-Fi network are replaced by HMAC-SHA256 pseudonyms under a key
+// that never leaves the machine, which also keys the MachineID, so payloads
+// stay joinable across cycles (and across machines sharing the key file)
+// without revealing the original values. Warranty data and the osquery and
+// custom script results, free-form and possibly naming the assigned user,
+// are dropped.
+const PrivacyPseudonymous = "pseudonymous"
+
+// pseudonymKeyFile is the generated key in the state directory, used when
+// TATUSCAN_PSEUDONYM_KEY_FILE is not set
+const pseudonymKeyFile = "pseudonym.key"
+
+// minPseudonymKeySize rejects keys too short to resist brute force of
+// low-entropy values such as hostnames
+const minPseudonymKeySize = 16
+
+// parsePrivacy parses TATUSCAN_PRIVACY: empty or "off" disables the preset,
+// "pseudonymous" selects pseudonyms and any other value selects strict so
+// that a typo never sends more data
+func parsePrivacy(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "off", "none":
+		return ""
+	case PrivacyPseudonymous:
+		return PrivacyPseudonymous
+	}
+	return PrivacyStrict
+}
+
+// collectorEnabled reports whether c runs: not turned off by
+// TATUSCAN_DISABLE_COLLECTORS nor, when personal, by the strict preset
+func collectorEnabled(c collector) bool {
+	if containsString(Cfg.DisabledCollectors, c.name) {
+		return false
+	}
+	return !(c.personal && Cfg.Privacy == PrivacyStrict)
+}
+
+// applyPrivacy drops the MACs unless TATUSCAN_MAC_ADDRESSES is enabled,
+// pseudonymizes the collected data under the privacy presets, then applies
+// the TATUSCAN_REDACT rules
+func applyPrivacy(ctx context.Context, info *MachineInfo) {
+	if !Cfg.MACAddresses {
+		redactMACs(info, func(string) string { return "" })
+	}
+	var pseudonym func(string) string
+	pseudonyms := func() func(string) string {
+		if pseudonym == nil {
+			pseudonym = newPseudonymizer(ctx)
+		}
+		return pseudonym
+	}
+	switch Cfg.Privacy {
+	case PrivacyStrict:
+		// An unkeyed hash of a hostname is reversed with a dictionary of
+		// the naming convention
+		info.Hostname = pseudonyms()(strings.ToLower(info.Hostname))
+		info.FQDN = pseudonyms()(strings.ToLower(info.FQDN))
+		info.MACAddresses = nil
+		redactMACs(info, func(string) string { return "" })
+		for i := range info.Services {
+			info.Services[i].Account = ""
+		}
+	case PrivacyPseudonymous:
+		applyPseudonyms(info, pseudonyms())
+	}
+	applyRedaction(info, Cfg.Redact, pseudonyms)
+}
+
+// newPseudonymizer returns the keyed pseudonym function. Without a usable
+// key it returns empty values instead, so nothing is sent in clear.
+func newPseudonymizer(ctx context.Context) func(string) string {
+	key, err := pseudonymKey()
+	if err != nil {
+		Log.Warnf("Pseudonym key unavailable, identifying fields removed: %v", err)
+		addWarning(ctx, WarnPseudonymKeyUnavailable, "identifying fields removed: %v", err)
+	}
+	return func(value string) string {
+		if value == "" || key == nil {
+			return ""
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// applyPseudonyms replaces identifying values by keyed pseudonyms
+func applyPseudonyms(info *MachineInfo, pseudonym func(string) string) {
+	info.Hostname = pseudonym(strings.ToLower(info.Hostname))
+	info.FQDN = pseudonym(info.FQDN)
+	info.SerialNumber = pseudonym(info.SerialNumber)
+	info.ProductUUID = pseudonym(strings.ToLower(info.ProductUUID))
+	info.Warranty = nil
+	info.MACAddresses = nil
+	info.Osquery, info.Custom = nil, nil
+	redactIPs(info, pseudonym)
+	redactMACs(info, pseudonym)
+	if info.WiFi != nil {
+		info.WiFi.SSID = pseudonym(info.WiFi.SSID)
+	}
+	for i := range info.Storage {
+		info.Storage[i].Serial = pseudonym(info.Storage[i].Serial)
+	}
+	for i := range info.Services {
+		info.Services[i].Account = pseudonym(info.Services[i].Account)
+	}
+	for i := range info.Watchlist {
+		info.Watchlist[i].Username = pseudonym(info.Watchlist[i].Username)
+	}
+	for i := range info.Startup {
+		info.Startup[i].User = pseudonym(info.Startup[i].User)
+	}
+	redactCertificates(info, pseudonym)
+}
+
+// pseudonymKey reads the HMAC key from TATUSCAN_PSEUDONYM_KEY_FILE, or from
+// the state directory, where a random key is generated on first use
+func pseudonymKey() ([]byte, error) {
+	path := Cfg.PseudonymKeyFile
+	if path == "" {
+		path = statePath(pseudonymKeyFile)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && Cfg.PseudonymKeyFile == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		data = []byte(hex.EncodeToString(random) + "\n")
+		if err := writeFileAtomic(path, data, 0o600); err != nil {
+			return nil, fmt.Errorf("create %s: %w", path, err)
+		}
+		Log.Infof("Generated pseudonym key %s", path)
+	} else if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) < minPseudonymKeySize {
+		return nil, fmt.Errorf("%s holds less than %d bytes", path, minPseudonymKeySize)
+	}
+	return key, nil
+}
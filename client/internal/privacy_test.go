@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParsePrivacy(t *testing.T) {
+	for value, want := range map[string]string{"": "", "off": "", "Strict": PrivacyStrict, "stirct": PrivacyStrict, "Pseudonymous": PrivacyPseudonymous} {
+		if got := parsePrivacy(value); got != want {
+			t.Errorf("parsePrivacy(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestStrictPrivacySkipsPersonalCollectors(t *testing.T) {
+	setupTestAgent(t)
+	origCollectors, origCfg := collectors, Cfg
+	defer func() { collectors, Cfg = origCollectors, origCfg }()
+
+	var ran []string
+	record := func(name string) func(context.Context, *MachineInfo) error {
+		return func(_ context.Context, info *MachineInfo) error {
+			ran = append(ran, name)
+			info.Hostname = "Lab-PC01"
+			info.Services = []Service{{Name: "backup", State: "running", Account: `CORP\jdoe`}}
+			info.MACAddresses = []string{"00:1b:21:12:34:56"}
+			info.Interfaces = []InterfaceDetail{{Name: "eth0", MAC: "00:1b:21:12:34:56"}}
+			return nil
+		}
+	}
+	collectors = []collector{
+		{name: "host", collect: record("host")},
+		{name: "watchlist", personal: true, needs: []string{"host"}, collect: record("watchlist")},
+	}
+
+	Cfg = Config{Privacy: PrivacyStrict, StateDir: t.TempDir(), MACAddresses: true}
+	info, err := CollectData(context.Background())
+	if err != nil {
+		t.Fatalf("CollectData: %v", err)
+	}
+	if len(ran) != 1 || ran[0] != "host" {
+		t.Errorf("collectors run: %v", ran)
+	}
+	if info.Hostname != newPseudonymizer(context.Background())("lab-pc01") || len(info.Hostname) != 64 {
+		t.Errorf("hostname not pseudonymized: %q", info.Hostname)
+	}
+	if info.Interfaces[0].MAC != "" {
+		t.Errorf("interface MAC kept: %+v", info.Interfaces[0])
+	}
+	if info.Services[0].Account != "" {
+		t.Errorf("service account kept: %+v", info.Services[0])
+	}
+	if info.MACAddresses != nil {
+		t.Errorf("MAC addresses kept: %v", info.MACAddresses)
+	}
+
+	ran = nil
+	Cfg = Config{}
+	info, _ = CollectData(context.Background())
+	if len(ran) != 2 || info.Hostname != "Lab-PC01" {
+		t.Errorf("without preset: collectors %v, hostname %q", ran, info.Hostname)
+	}
+}
+
+func TestPseudonymousPrivacy(t *testing.T) {
+	// Own state dir, and so own key file, and no warnings left by earlier tests
+	origCfg := Cfg
+	t.Cleanup(func() { Cfg = origCfg; takeWarnings() })
+	setupTestAgent(t)
+	takeWarnings()
+	Cfg.Privacy, Cfg.MACAddresses = PrivacyPseudonymous, true
+	collect := func() MachineInfo {
+		info := MachineInfo{
+			Hostname:     "Lab-PC01",
+			SerialNumber: "5CG1234XYZ",
+			Warranty:     map[string]any{"owner": "jdoe"},
+			MACAddresses: []string{"00:1b:21:12:34:56"},
+			Services:     []Service{{Name: "backup", Account: `CORP\jdoe`}},
+			Watchlist:    []WatchedProcess{{Name: "steam", Username: `CORP\jdoe`}},
+			IP:           "10.0.0.5",
+			Interfaces:   []InterfaceDetail{{Name: "eth0", MAC: "00:1b:21:12:34:56"}},
+			Neighbors:    []Neighbor{{IP: "10.0.0.1", MAC: "00:e0:4c:00:00:01"}},
+			WiFi:         &WiFiInfo{Interface: "wlan0", SSID: "LabNet", BSSID: "00:e0:4c:00:00:02"},
+			Osquery:      OsqueryResults{"users": {{"username": "jdoe"}}},
+			Custom:       CustomResults{"owner": {"user": "jdoe"}},
+		}
+		applyPrivacy(context.Background(), &info)
+		return info
+	}
+
+	first, second := collect(), collect()
+	if first.Hostname == "" || first.Hostname == "lab-pc01" || first.SerialNumber == "5CG1234XYZ" {
+		t.Errorf("values not pseudonymized: %+v", first)
+	}
+	if first.Hostname != second.Hostname || first.SerialNumber != second.SerialNumber {
+		t.Error("pseudonyms must be stable across cycles")
+	}
+	if first.Services[0].Account != first.Watchlist[0].Username || first.Warranty != nil || first.MACAddresses != nil {
+		t.Errorf("user names must share pseudonyms and warranty and MACs be dropped: %+v", first)
+	}
+	if first.IP == "10.0.0.5" || first.IP != second.IP || first.Neighbors[0].IP == "10.0.0.1" {
+		t.Errorf("addresses not pseudonymized: %+v", first)
+	}
+	if len(first.Interfaces[0].MAC) != 64 || len(first.Neighbors[0].MAC) != 64 || len(first.WiFi.BSSID) != 64 || first.WiFi.SSID == "LabNet" {
+		t.Errorf("network identifiers not pseudonymized: %+v %+v %+v", first.Interfaces, first.Neighbors, first.WiFi)
+	}
+	if first.Osquery != nil || first.Custom != nil {
+		t.Errorf("free-form results kept: %+v %+v", first.Osquery, first.Custom)
+	}
+	if _, err := os.Stat(statePath(pseudonymKeyFile)); err != nil {
+		t.Errorf("key not persisted: %v", err)
+	}
+
+	// The MachineID is keyed, so it cannot be recomputed from the MACs
+	macs := []string{"00:1b:21:12:34:56"}
+	keyed := computeMachineID(macs)
+	Cfg.Privacy = ""
+	if plain := computeMachineID(macs); keyed == plain || !isValidMachineID(keyed) {
+		t.Errorf("MachineID not keyed by the pseudonym key: %s", keyed)
+	}
+	Cfg.Privacy = PrivacyPseudonymous
+
+	// A configured key file that is missing removes the values
+	Cfg.PseudonymKeyFile = filepath.Join(t.TempDir(), "missing.key")
+	info := collect()
+	if info.Hostname != "" || info.SerialNumber != "" {
+		t.Errorf("values kept without a key: %+v", info)
+	}
+	if warnings := takeWarnings(); len(warnings) != 1 || warnings[0].Code != WarnPseudonymKeyUnavailable {
+		t.Errorf("expected pseudonym_key_unavailable, got %+v", warnings)
+	}
+}
+
+func TestStrictPresetCollectors(t *testing.T) {
+	// The collectors the strict preset skips, as listed in the README
+	personal := []string{"warranty", "public_ip", "wifi", "watchlist", "listeners", "neighbors", "osquery", "custom", "packages", "certificates", "startup"}
+	var got []string
+	for _, c := range collectors {
+		if c.personal {
+			got = append(got, c.name)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(personal, ",") {
+		t.Errorf("personal collectors %v, want %v", got, personal)
+	}
+}
+
+func TestPseudonymousCertificates(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Privacy = PrivacyPseudonymous
+	info := MachineInfo{Certificates: []ExpiringCert{{Subject: "CN=lab-pc01.corp.example", Issuer: "CN=R11", Store: "/etc/letsencrypt/live/lab-pc01.corp.example/cert.pem"}}}
+	applyPrivacy(context.Background(), &info)
+	cert := info.Certificates[0]
+	if len(cert.Subject) != 64 || len(cert.Store) != 64 || strings.Contains(cert.Subject+cert.Store, "lab-pc01") || cert.Issuer != "CN=R11" {
+		t.Errorf("certificate names the machine: %+v", cert)
+	}
+}
+
+func TestMACAddressesGate(t *testing.T) {
+	setupTestAgent(t)
+	macPattern := regexp.MustCompile(`(?i)([0-9a-f]{2}[:-]){5}[0-9a-f]{2}`)
+	collect := func() string {
+		info := MachineInfo{
+			Interfaces: []InterfaceDetail{{Name: "eth0", MAC: "00:1b:21:12:34:56"}},
+			Neighbors:  []Neighbor{{IP: "10.0.0.1", MAC: "00:E0:4C:00:00:01"}},
+			WiFi:       &WiFiInfo{Interface: "wlan0", BSSID: "00:e0:4c:00:00:02"},
+		}
+		applyPrivacy(context.Background(), &info)
+		data, err := json.Marshal(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if payload := collect(); macPattern.MatchString(payload) {
+		t.Errorf("MAC sent with TATUSCAN_MAC_ADDRESSES off: %s", payload)
+	}
+	Cfg.MACAddresses = true
+	if payload := collect(); len(macPattern.FindAllString(payload, -1)) != 3 {
+		t.Errorf("MACs dropped with TATUSCAN_MAC_ADDRESSES on: %s", payload)
+	}
+}
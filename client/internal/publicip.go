@@ -0,0 +1,223 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPublicIPInterval spaces probes: the egress address only changes
+	// when a laptop roams, and public services rate-limit frequent callers
+	defaultPublicIPInterval = 30 * time.Minute
+	// publicIPTimeout bounds a single probe
+	publicIPTimeout = 10 * time.Second
+)
+
+// STUN (RFC 5389) binding request constants
+const (
+	stunBindingRequest    = 0x0001
+	stunBindingSuccess    = 0x0101
+	stunMagicCookie       = 0x2112A442
+	stunMappedAddress     = 0x0001
+	stunXorMappedAddress  = 0x0020
+	stunHeaderSize        = 20
+	stunDefaultPort       = "3478"
+	maxPublicIPResponse   = 256
+	maxSTUNResponseLength = 1500
+)
+
+// publicIPProbe queries the configured endpoint; replaced in tests
+var publicIPProbe = probePublicIP
+
+// publicIPCache keeps the last probe between collections
+var publicIPCache struct {
+	sync.Mutex
+	endpoint string
+	ip       string
+	err      error
+	checked  time.Time
+}
+
+// getPublicIP returns the egress address seen from the internet when
+// TATUSCAN_PUBLIC_IP is set, probing at most once per
+// TATUSCAN_PUBLIC_IP_INTERVAL
+func getPublicIP(ctx context.Context) (string, error) {
+	endpoint := Cfg.PublicIPEndpoint
+	if endpoint == "" {
+		return "", nil
+	}
+	publicIPCache.Lock()
+	defer publicIPCache.Unlock()
+	if publicIPCache.endpoint == endpoint && !publicIPCache.checked.IsZero() &&
+		time.Since(publicIPCache.checked) < Cfg.PublicIPInterval {
+		return publicIPCache.ip, publicIPCache.err
+	}
+
+	Log.Debugf("Probing public IP via %s", endpoint)
+	ctx, cancel := context.WithTimeout(ctx, publicIPTimeout)
+	defer cancel()
+	ip, err := publicIPProbe(ctx, endpoint)
+	if err != nil {
+		err = fmt.Errorf("public IP probe %s: %w", endpoint, err)
+	}
+	publicIPCache.endpoint, publicIPCache.ip, publicIPCache.err = endpoint, ip, err
+	publicIPCache.checked = time.Now()
+	return ip, err
+}
+
+// probePublicIP dispatches on the endpoint: "stun:host[:port]" sends a STUN
+// binding request, http(s) URLs must answer with the address as plain text
+func probePublicIP(ctx context.Context, endpoint string) (string, error) {
+	if host, ok := strings.CutPrefix(endpoint, "stun:"); ok {
+		return stunPublicIP(ctx, host)
+	}
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return httpPublicIP(ctx, endpoint)
+	}
+	return "", errors.New("endpoint must be an http(s) URL or stun:host[:port]")
+}
+
+// httpPublicIP fetches an endpoint such as https://api.ipify.org
+func httpPublicIP(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPublicIPResponse))
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return "", fmt.Errorf("response is not an IP address: %q", body)
+	}
+	return ip.String(), nil
+}
+
+// stunPublicIP sends a STUN binding request over UDP and returns the mapped
+// address of the reply
+func stunPublicIP(ctx context.Context, server string) (string, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, stunDefaultPort)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	request := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(request[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint32(request[4:8], stunMagicCookie)
+	if _, err := rand.Read(request[8:20]); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write(request); err != nil {
+		return "", err
+	}
+	response := make([]byte, maxSTUNResponseLength)
+	n, err := conn.Read(response)
+	if err != nil {
+		return "", err
+	}
+	ip, err := parseSTUNResponse(response[:n], request[8:20])
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+// parseSTUNResponse extracts the address of a binding success response,
+// preferring XOR-MAPPED-ADDRESS over the legacy MAPPED-ADDRESS
+func parseSTUNResponse(msg, transactionID []byte) (net.IP, error) {
+	if len(msg) < stunHeaderSize {
+		return nil, errors.New("short STUN response")
+	}
+	if binary.BigEndian.Uint16(msg[0:2]) != stunBindingSuccess {
+		return nil, fmt.Errorf("unexpected STUN message type %#04x", binary.BigEndian.Uint16(msg[0:2]))
+	}
+	if binary.BigEndian.Uint32(msg[4:8]) != stunMagicCookie || string(msg[8:20]) != string(transactionID) {
+		return nil, errors.New("STUN response does not match the request")
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:4]))
+	if stunHeaderSize+length > len(msg) {
+		return nil, errors.New("truncated STUN response")
+	}
+
+	var mapped net.IP
+	attrs := msg[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+		switch attrType {
+		case stunXorMappedAddress:
+			if ip := stunAddress(value, msg[4:20]); ip != nil {
+				return ip, nil
+			}
+		case stunMappedAddress:
+			mapped = stunAddress(value, nil)
+		}
+		// Attributes are padded to a multiple of 4 bytes
+		attrs = attrs[min(len(attrs), 4+(attrLen+3)&^3):]
+	}
+	if mapped == nil {
+		return nil, errors.New("STUN response without a mapped address")
+	}
+	return mapped, nil
+}
+
+// stunAddress decodes a (XOR-)MAPPED-ADDRESS value; key is the magic cookie
+// followed by the transaction ID for XOR-MAPPED-ADDRESS, nil otherwise
+func stunAddress(value, key []byte) net.IP {
+	if len(value) < 4 {
+		return nil
+	}
+	var size int
+	switch value[1] {
+	case 0x01:
+		size = net.IPv4len
+	case 0x02:
+		size = net.IPv6len
+	default:
+		return nil
+	}
+	if len(value) < 4+size {
+		return nil
+	}
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if key != nil {
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+	return ip
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stunReply builds a binding success response carrying XOR-MAPPED-ADDRESS
+func stunReply(request []byte, ip net.IP, port uint16) []byte {
+	ip4 := ip.To4()
+	attr := make([]byte, 12)
+	binary.BigEndian.PutUint16(attr[0:2], stunXorMappedAddress)
+	binary.BigEndian.PutUint16(attr[2:4], 8)
+	attr[5] = 0x01
+	binary.BigEndian.PutUint16(attr[6:8], port^uint16(stunMagicCookie>>16))
+	for i := range ip4 {
+		attr[8+i] = ip4[i] ^ request[4+i]
+	}
+	msg := make([]byte, stunHeaderSize, stunHeaderSize+len(attr))
+	binary.BigEndian.PutUint16(msg[0:2], stunBindingSuccess)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(attr)))
+	copy(msg[4:20], request[4:20])
+	return append(msg, attr...)
+}
+
+func TestProbePublicIPSTUN(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 1500)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil || n != stunHeaderSize {
+			return
+		}
+		conn.WriteTo(stunReply(buf[:n], net.ParseIP("203.0.113.7"), 40000), addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ip, err := probePublicIP(ctx, "stun:"+conn.LocalAddr().String())
+	if err != nil || ip != "203.0.113.7" {
+		t.Errorf("probePublicIP = %q, %v", ip, err)
+	}
+}
+
+func TestParseSTUNResponseRejectsMismatch(t *testing.T) {
+	request := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint32(request[4:8], stunMagicCookie)
+	copy(request[8:], "transaction1")
+	reply := stunReply(request, net.ParseIP("203.0.113.7"), 1)
+
+	if _, err := parseSTUNResponse(reply, []byte("transaction2")); err == nil {
+		t.Error("expected an error for a foreign transaction ID")
+	}
+	if _, err := parseSTUNResponse(reply[:stunHeaderSize+4], []byte("transaction1")); err == nil {
+		t.Error("expected an error for a truncated response")
+	}
+}
+
+func TestGetPublicIPHTTPAndCache(t *testing.T) {
+	setupTestAgent(t)
+	calls := 0
+	body := "198.51.100.20\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { publicIPCache.checked = time.Time{} })
+
+	if ip, err := getPublicIP(context.Background()); ip != "" || err != nil || calls != 0 {
+		t.Fatalf("disabled probe = %q, %v (%d calls)", ip, err, calls)
+	}
+
+	Cfg.PublicIPEndpoint, Cfg.PublicIPInterval = srv.URL, time.Hour
+	for i := 0; i < 2; i++ {
+		if ip, err := getPublicIP(context.Background()); ip != "198.51.100.20" || err != nil {
+			t.Fatalf("getPublicIP = %q, %v", ip, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("endpoint called %d times, want 1 (cached)", calls)
+	}
+
+	body = "<html>blocked</html>"
+	Cfg.PublicIPEndpoint = srv.URL + "/other"
+	if _, err := getPublicIP(context.Background()); err == nil || !strings.Contains(err.Error(), "not an IP address") {
+		t.Errorf("expected an invalid response error, got %v", err)
+	}
+}
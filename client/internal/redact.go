@@ -0,0 +1,141 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import "strings"
+
+// Redaction actions of TATUSCAN_REDACT
+const (
+	RedactHash = "hash" // keyed pseudonym, as in the pseudonymous preset
+	RedactOmit = "omit"
+)
+
+// redactFields are the field classes TATUSCAN_REDACT accepts
+var redactFields = []string{"hostname", "usernames", "ips", "macs", "serials"}
+
+// parseRedact parses TATUSCAN_REDACT, comma-separated field classes each
+// with an optional action: "hostname:hash,usernames,ips:omit". The action
+// defaults to hash; an unknown action omits the field so that a typo never
+// sends more data. Unknown classes are ignored.
+func parseRedact(value string) map[string]string {
+	rules := map[string]string{}
+	for _, item := range splitList(value) {
+		field, action, found := strings.Cut(strings.ToLower(item), ":")
+		field, action = strings.TrimSpace(field), strings.TrimSpace(action)
+		if !containsString(redactFields, field) {
+			if Log != nil {
+				Log.Warnf("Unknown redaction field %q ignored", field)
+			}
+			continue
+		}
+		if !found {
+			action = RedactHash
+		} else if action != RedactHash {
+			action = RedactOmit
+		}
+		rules[field] = action
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return rules
+}
+
+// applyRedaction hashes or omits the field classes chosen in
+// TATUSCAN_REDACT, for jurisdictions with strict personal-data rules.
+// pseudonyms returns the keyed pseudonym function, only read when a class
+// is hashed.
+func applyRedaction(info *MachineInfo, rules map[string]string, pseudonyms func() func(string) string) {
+	for field, action := range rules {
+		redact := func(string) string { return "" }
+		if action == RedactHash {
+			redact = pseudonyms()
+		}
+		switch field {
+		case "hostname":
+			info.Hostname = redact(strings.ToLower(info.Hostname))
+			info.FQDN = redact(strings.ToLower(info.FQDN))
+			redactCertificates(info, redact)
+		case "usernames":
+			for i := range info.Services {
+				info.Services[i].Account = redact(info.Services[i].Account)
+			}
+			for i := range info.Watchlist {
+				info.Watchlist[i].Username = redact(info.Watchlist[i].Username)
+			}
+			for i := range info.Startup {
+				info.Startup[i].User = redact(info.Startup[i].User)
+			}
+		case "ips":
+			redactIPs(info, redact)
+		case "macs":
+			info.MACAddresses = redactAll(info.MACAddresses, redact)
+			redactMACs(info, redact)
+		case "serials":
+			info.SerialNumber = redact(info.SerialNumber)
+			info.ProductUUID = redact(strings.ToLower(info.ProductUUID))
+			for i := range info.Storage {
+				info.Storage[i].Serial = redact(info.Storage[i].Serial)
+			}
+		}
+	}
+}
+
+// redactIPs redacts the addresses of the machine, its neighbors, listeners
+// and connections
+func redactIPs(info *MachineInfo, redact func(string) string) {
+	info.IP = redact(info.IP)
+	info.PublicIP = redact(info.PublicIP)
+	for i := range info.Addresses {
+		info.Addresses[i].IP = redact(info.Addresses[i].IP)
+	}
+	for i := range info.Neighbors {
+		info.Neighbors[i].IP = redact(info.Neighbors[i].IP)
+	}
+	for i := range info.Listeners {
+		info.Listeners[i].Address = redact(info.Listeners[i].Address)
+	}
+	for i := range info.Watchlist {
+		for j := range info.Watchlist[i].Connections {
+			conn := &info.Watchlist[i].Connections[j]
+			conn.Local, conn.Remote = redact(conn.Local), redact(conn.Remote)
+		}
+	}
+	if info.Addressing != nil {
+		info.Addressing.DHCPServer = redact(info.Addressing.DHCPServer)
+	}
+}
+
+// redactMACs redacts the MACs of the interfaces, the neighbors and the
+// access point
+func redactMACs(info *MachineInfo, redact func(string) string) {
+	for i := range info.Interfaces {
+		info.Interfaces[i].MAC = redact(info.Interfaces[i].MAC)
+	}
+	for i := range info.Neighbors {
+		info.Neighbors[i].MAC = redact(info.Neighbors[i].MAC)
+	}
+	if info.WiFi != nil {
+		info.WiFi.BSSID = redact(info.WiFi.BSSID)
+	}
+}
+
+// redactCertificates redacts the certificate subjects and stores, which
+// name the machine (CN=<fqdn>, letsencrypt/live/<fqdn>/cert.pem)
+func redactCertificates(info *MachineInfo, redact func(string) string) {
+	for i := range info.Certificates {
+		cert := &info.Certificates[i]
+		cert.Subject, cert.Store = redact(cert.Subject), redact(cert.Store)
+	}
+}
+
+// redactAll redacts every value of a list, dropping the list when omitted
+func redactAll(values []string, redact func(string) string) []string {
+	var result []string
+	for _, value := range values {
+		if value = redact(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseRedact(t *testing.T) {
+	got := parseRedact("Hostname, usernames:omit, ips:hsh, phone:omit")
+	want := map[string]string{"hostname": RedactHash, "usernames": RedactOmit, "ips": RedactOmit}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRedact = %v, want %v", got, want)
+	}
+	if got := parseRedact(""); got != nil {
+		t.Errorf("empty value = %v, want nil", got)
+	}
+}
+
+func TestApplyRedaction(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Redact = parseRedact("hostname,usernames,ips:omit")
+	collect := func() MachineInfo {
+		info := MachineInfo{
+			Hostname:  "Lab-PC01",
+			FQDN:      "lab-pc01.corp.example",
+			IP:        "10.0.0.5",
+			Addresses: []InterfaceAddress{{Interface: "eth0", IP: "10.0.0.5"}},
+			Services:  []Service{{Name: "backup", Account: `CORP\jdoe`}},
+			Watchlist: []WatchedProcess{{Name: "steam", Username: `CORP\jdoe`, Connections: []ProcessConnection{{Protocol: "tcp", Local: "10.0.0.5:50000", Remote: "203.0.113.9:443"}}}},
+			Startup:   []StartupItem{{Name: "sync", User: "jdoe"}},
+			Certificates: []ExpiringCert{{Subject: "CN=lab-pc01.corp.example", Issuer: "CN=R11,O=Let's Encrypt",
+				Store: "/etc/letsencrypt/live/lab-pc01.corp.example/cert.pem"}},
+		}
+		applyPrivacy(context.Background(), &info)
+		return info
+	}
+
+	first, second := collect(), collect()
+	if len(first.Hostname) != 64 || first.Hostname != second.Hostname || first.FQDN == "lab-pc01.corp.example" {
+		t.Errorf("hostname not hashed stably: %q %q", first.Hostname, second.Hostname)
+	}
+	if first.Services[0].Account != first.Watchlist[0].Username || first.Services[0].Account == `CORP\jdoe` || first.Startup[0].User == "jdoe" {
+		t.Errorf("usernames not hashed: %+v", first)
+	}
+	conn := first.Watchlist[0].Connections[0]
+	if first.IP != "" || first.Addresses[0].IP != "" || conn.Local != "" || conn.Remote != "" {
+		t.Errorf("addresses not omitted: %+v", first)
+	}
+	cert := first.Certificates[0]
+	if len(cert.Subject) != 64 || len(cert.Store) != 64 || cert.Issuer != "CN=R11,O=Let's Encrypt" {
+		t.Errorf("certificate subject and store not hashed: %+v", cert)
+	}
+	if first.Addresses[0].Interface != "eth0" {
+		t.Errorf("interface name dropped: %+v", first.Addresses)
+	}
+}
@@ -0,0 +1,239 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRemoteConfigInterval spaces the refreshes of the site overlay
+const defaultRemoteConfigInterval = time.Hour
+
+// maxOverlaySize bounds the site overlay and its signature
+const maxOverlaySize = 64 << 10
+
+// overlayCacheName is the last verified overlay, kept in the cache
+// directory (with a .sig file) for startups without network
+const overlayCacheName = "site-config.env"
+
+// protectedOverlayKeys cannot be set by the site overlay or server
+// rollouts: they choose where the overlay and payloads come from and go, the
+// server credentials, the local privacy decision, where files are written,
+// which programs and remote tasks the agent executes, and the probes it
+// sends to the network and third parties
+var protectedOverlayKeys = []string{
+	"TATUSCAN_URL",
+	"TATUSCAN_TOKEN",
+	"TATUSCAN_HMAC_SECRET",
+	"TATUSCAN_PRIVACY",
+	"TATUSCAN_REDACT",
+	"TATUSCAN_MAC_ADDRESSES",
+	"TATUSCAN_MACHINE_ID_SALT",
+	"TATUSCAN_PSEUDONYM_KEY_FILE",
+	"TATUSCAN_CONFIG_URL",
+	"TATUSCAN_CONFIG_KEY",
+	"TATUSCAN_STATE_DIR",
+	"TATUSCAN_CONFIG_DIR",
+	"TATUSCAN_CACHE_DIR",
+	"TATUSCAN_LOG_FILE",
+	"TATUSCAN_WARRANTY_HOOK",
+	"TATUSCAN_OSQUERYI",
+	"TATUSCAN_TASKS",
+	"TATUSCAN_TASKS_KEY",
+	"TATUSCAN_NEIGHBORS",
+	"TATUSCAN_NEIGHBORS_SWEEP",
+	"TATUSCAN_PUBLIC_IP",
+}
+
+// protectedOverlayPrefixes protect whole families of keys, such as the
+// custom scripts the agent executes, the osquery SQL it runs and the headers
+// sent with its credentials
+var protectedOverlayPrefixes = []string{scriptVariablePrefix, osqueryVariablePrefix, headerVariablePrefix}
+
+// protectedOverlayKey reports whether key is out of reach of the site
+// overlay and server rollouts
+func protectedOverlayKey(key string) bool {
+	for _, prefix := range protectedOverlayPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return containsString(protectedOverlayKeys, key)
+}
+
+// ReloadConfig loads the local configuration and merges the site overlay
+// from TATUSCAN_CONFIG_URL over it. When the overlay cannot be fetched or
+// verified, the last verified copy is used, then the local settings alone.
+// The server configuration document and the settings of server rollouts
+// targeting this machine stay on top, in that order.
+func ReloadConfig(ctx context.Context) {
+	site := loadSiteEnv(ctx, configEnv())
+	configLayers.Lock()
+	defer configLayers.Unlock()
+	configLayers.site = site
+	if configLayers.server == nil {
+		configLayers.server = loadServerConfig()
+	}
+	rebuildConfig()
+}
+
+// configLayers keeps the settings sources that change at run time
+var configLayers struct {
+	sync.Mutex
+	site    map[string]string // local settings plus the site overlay
+	server  map[string]string // server configuration document
+	rollout map[string]string // settings of the server rollouts
+}
+
+// rebuildConfig sets Cfg from the layers; the caller holds configLayers
+func rebuildConfig() {
+	site := configLayers.site
+	if site == nil {
+		site = configEnv()
+	}
+	SetConfig(loadConfig(mergeEnv(mergeEnv(site, configLayers.server), configLayers.rollout)))
+}
+
+// loadSiteConfig builds the configuration from env plus the site overlay
+func loadSiteConfig(ctx context.Context, env map[string]string) Config {
+	return loadConfig(loadSiteEnv(ctx, env))
+}
+
+// loadSiteEnv returns env with the site overlay merged over it
+func loadSiteEnv(ctx context.Context, env map[string]string) map[string]string {
+	local := loadConfig(env)
+	if local.RemoteConfigURL == "" {
+		return env
+	}
+	overlay, err := fetchOverlay(ctx, local)
+	if err != nil {
+		Log.Warnf("Error to fetch site configuration: %v", err)
+		if overlay, err = loadCachedOverlay(local); err != nil {
+			Log.Warnf("Site configuration not applied: %v", err)
+			return env
+		}
+		Log.Info("Using the cached site configuration")
+	}
+	return mergeEnv(env, overlay)
+}
+
+// parseOverlay parses the overlay as a settings file, keeping only
+// overridable TATUSCAN_* keys
+func parseOverlay(data []byte) (map[string]string, error) {
+	values, err := parseEnvFile(data)
+	if err != nil {
+		return nil, err
+	}
+	overlay := make(map[string]string, len(values))
+	for key, value := range values {
+		if !strings.HasPrefix(key, "TATUSCAN_") || protectedOverlayKey(key) {
+			Log.Warnf("Site configuration cannot set %s, ignored", key)
+			continue
+		}
+		overlay[key] = value
+	}
+	return overlay, nil
+}
+
+// verifyOverlay checks the base64 Ed25519 signature of data against the
+// base64 public key in TATUSCAN_CONFIG_KEY and parses it
+func verifyOverlay(data, signature []byte, publicKey string) (map[string]string, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("TATUSCAN_CONFIG_KEY is not a base64 Ed25519 public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return nil, fmt.Errorf("signature is not base64: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, sig) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return parseOverlay(data)
+}
+
+// fetchOverlay downloads the overlay and its detached signature (same URL
+// with a .sig suffix), verifies it and caches the verified copy
+func fetchOverlay(ctx context.Context, cfg Config) (map[string]string, error) {
+	if cfg.RemoteConfigKey == "" {
+		return nil, fmt.Errorf("TATUSCAN_CONFIG_KEY is required to verify %s", cfg.RemoteConfigURL)
+	}
+	target, err := url.Parse(cfg.RemoteConfigURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, fmt.Errorf("TATUSCAN_CONFIG_URL %q is not an http(s) URL", cfg.RemoteConfigURL)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	data, err := fetchOverlayFile(ctx, client, target.String())
+	if err != nil {
+		return nil, err
+	}
+	sigURL := *target
+	sigURL.Path += ".sig"
+	signature, err := fetchOverlayFile(ctx, client, sigURL.String())
+	if err != nil {
+		return nil, err
+	}
+	overlay, err := verifyOverlay(data, signature, cfg.RemoteConfigKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := filepath.Join(cfg.CacheDir, overlayCacheName)
+	if err := writeFileAtomic(cache, data, 0o640); err != nil {
+		Log.Warnf("Error to cache site configuration: %v", err)
+	} else if err := writeFileAtomic(cache+".sig", signature, 0o640); err != nil {
+		Log.Warnf("Error to cache site configuration signature: %v", err)
+	}
+	Log.Debugf("Site configuration applied from %s (%d settings)", cfg.RemoteConfigURL, len(overlay))
+	return overlay, nil
+}
+
+// fetchOverlayFile GETs a small file, failing on non-200 responses
+func fetchOverlayFile(ctx context.Context, client *http.Client, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	setAgentHeaders(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status: %d", target, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOverlaySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxOverlaySize {
+		return nil, fmt.Errorf("GET %s: larger than %d bytes", target, maxOverlaySize)
+	}
+	return data, nil
+}
+
+// loadCachedOverlay reads and re-verifies the last fetched overlay
+func loadCachedOverlay(cfg Config) (map[string]string, error) {
+	cache := filepath.Join(cfg.CacheDir, overlayCacheName)
+	data, err := os.ReadFile(cache)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(cache + ".sig")
+	if err != nil {
+		return nil, err
+	}
+	return verifyOverlay(data, signature, cfg.RemoteConfigKey)
+}
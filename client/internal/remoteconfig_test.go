@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serveOverlay serves doc at /site.env signed with a new key, returning the
+// server and the base64 public key
+func serveOverlay(t *testing.T, doc string) (*httptest.Server, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(doc)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/site.env", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(doc)) })
+	mux.HandleFunc("/site.env.sig", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(sig)) })
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, base64.StdEncoding.EncodeToString(pub)
+}
+
+func TestLoadSiteConfigMergesSignedOverlay(t *testing.T) {
+	setupTestAgent(t)
+	srv, key := serveOverlay(t, "# site defaults\nTATUSCAN_SENSORS=true\nTATUSCAN_SERVICES=\"sshd,veeam*\"\nTATUSCAN_WARRANTY_HOOK=/tmp/evil\n")
+	env := map[string]string{
+		"TATUSCAN_CONFIG_URL": srv.URL + "/site.env",
+		"TATUSCAN_CONFIG_KEY": key,
+		"TATUSCAN_CACHE_DIR":  t.TempDir(),
+		"TATUSCAN_WATCHLIST":  "xmrig",
+	}
+
+	cfg := loadSiteConfig(context.Background(), env)
+	if !cfg.Sensors || len(cfg.Services) != 2 || cfg.Services[1] != "veeam*" {
+		t.Errorf("overlay not applied: %+v", cfg)
+	}
+	if cfg.WarrantyHook != "" {
+		t.Errorf("protected key applied: %q", cfg.WarrantyHook)
+	}
+	if len(cfg.Watchlist) != 1 {
+		t.Errorf("local setting lost: %v", cfg.Watchlist)
+	}
+
+	// Server down: the cached verified copy still applies
+	srv.Close()
+	if cfg := loadSiteConfig(context.Background(), env); !cfg.Sensors {
+		t.Error("cached overlay not applied")
+	}
+}
+
+func TestLoadSiteConfigRejectsBadSignature(t *testing.T) {
+	setupTestAgent(t)
+	srv, _ := serveOverlay(t, "TATUSCAN_SENSORS=true\n")
+	_, otherKey := serveOverlay(t, "")
+	env := map[string]string{
+		"TATUSCAN_CONFIG_URL": srv.URL + "/site.env",
+		"TATUSCAN_CONFIG_KEY": otherKey,
+		"TATUSCAN_CACHE_DIR":  t.TempDir(),
+	}
+	if cfg := loadSiteConfig(context.Background(), env); cfg.Sensors {
+		t.Error("overlay with an invalid signature applied")
+	}
+
+	delete(env, "TATUSCAN_CONFIG_KEY")
+	if cfg := loadSiteConfig(context.Background(), env); cfg.Sensors {
+		t.Error("unsigned overlay applied")
+	}
+}
+
+func TestParseOverlayRejectsMalformedLines(t *testing.T) {
+	if _, err := parseOverlay([]byte("TATUSCAN_SENSORS\n")); err == nil {
+		t.Error("expected error for a line without =")
+	}
+}
+
+func TestOverlayCannotSetOsqueryQueries(t *testing.T) {
+	setupTestAgent(t)
+	overlay, err := parseOverlay([]byte("TATUSCAN_OSQUERY_USERS=SELECT * FROM shadow\nTATUSCAN_SENSORS=true\n"))
+	if err != nil {
+		t.Fatalf("parseOverlay: %v", err)
+	}
+	if len(overlay) != 1 || overlay["TATUSCAN_SENSORS"] != "true" {
+		t.Errorf("overlay set an osquery query: %v", overlay)
+	}
+	rollouts := []Rollout{{ID: "wave", Percent: 100, Config: map[string]string{"TATUSCAN_OSQUERY_USERS": "SELECT * FROM shadow"}}}
+	if got := rolloutSettings(rollouts, 0); len(got) != 0 {
+		t.Errorf("rollout set an osquery query: %v", got)
+	}
+}
+
+func TestOverlayCannotSendMACAddresses(t *testing.T) {
+	setupTestAgent(t)
+	overlay, err := parseOverlay([]byte("TATUSCAN_MAC_ADDRESSES=true\nTATUSCAN_SENSORS=true\n"))
+	if err != nil {
+		t.Fatalf("parseOverlay: %v", err)
+	}
+	if len(overlay) != 1 || overlay["TATUSCAN_SENSORS"] != "true" {
+		t.Errorf("overlay enabled mac_addresses: %v", overlay)
+	}
+	rollouts := []Rollout{{ID: "wave", Percent: 100, Config: map[string]string{"TATUSCAN_MAC_ADDRESSES": "true"}}}
+	if got := rolloutSettings(rollouts, 0); len(got) != 0 {
+		t.Errorf("rollout enabled mac_addresses: %v", got)
+	}
+}
+
+func TestOverlayCannotSetHeaders(t *testing.T) {
+	setupTestAgent(t)
+	overlay, err := parseOverlay([]byte("TATUSCAN_HEADER_AUTHORIZATION=Bearer evil\nTATUSCAN_SENSORS=true\n"))
+	if err != nil {
+		t.Fatalf("parseOverlay: %v", err)
+	}
+	if len(overlay) != 1 || overlay["TATUSCAN_SENSORS"] != "true" {
+		t.Errorf("overlay set a header: %v", overlay)
+	}
+	rollouts := []Rollout{{ID: "wave", Percent: 100, Config: map[string]string{"TATUSCAN_HEADER_X_ORG_ID": "42"}}}
+	if got := rolloutSettings(rollouts, 0); len(got) != 0 {
+		t.Errorf("rollout set a header: %v", got)
+	}
+}
+
+func TestOverlayCannotEnableNetworkProbes(t *testing.T) {
+	setupTestAgent(t)
+	overlay, err := parseOverlay([]byte("TATUSCAN_NEIGHBORS=true\nTATUSCAN_NEIGHBORS_SWEEP=true\nTATUSCAN_PUBLIC_IP=https://ip.example\nTATUSCAN_SENSORS=true\n"))
+	if err != nil {
+		t.Fatalf("parseOverlay: %v", err)
+	}
+	if len(overlay) != 1 || overlay["TATUSCAN_SENSORS"] != "true" {
+		t.Errorf("overlay enabled a network probe: %v", overlay)
+	}
+	rollouts := []Rollout{{ID: "wave", Percent: 100, Config: map[string]string{"TATUSCAN_NEIGHBORS_SWEEP": "true"}}}
+	if got := rolloutSettings(rollouts, 0); len(got) != 0 {
+		t.Errorf("rollout enabled a subnet sweep: %v", got)
+	}
+}
@@ -0,0 +1,95 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Rollout targets settings at the machines whose cohort is below Percent,
+// so a change can reach 1%, then 10%, then the whole fleet
+type Rollout struct {
+	ID      string            `json:"id"`
+	Percent int               `json:"percent"`
+	Config  map[string]string `json:"config"`
+}
+
+// checkinResponse is the optional JSON reply of the server to a payload.
+// Its fields are pointers: a reply without the key leaves the current value
+// unchanged, an empty list or document withdraws it.
+type checkinResponse struct {
+	Rollouts *[]Rollout     `json:"rollouts"`
+	Config   *ServerConfig  `json:"config"`
+	Tasks    []Task         `json:"tasks"`
+	Backoff  *ServerBackoff `json:"backoff"`
+}
+
+// machineCohort maps a MachineID to a stable cohort in [0, 100)
+func machineCohort(machineID string) int {
+	hash := sha256.Sum256([]byte(machineID))
+	return int(binary.BigEndian.Uint64(hash[:8]) % 100)
+}
+
+// rolloutSettings merges the settings of the rollouts targeting cohort, in
+// order (later rollouts win), skipping protected and non-TATUSCAN_ keys
+func rolloutSettings(rollouts []Rollout, cohort int) map[string]string {
+	settings := make(map[string]string)
+	for _, r := range rollouts {
+		if cohort >= r.Percent {
+			continue
+		}
+		for key, value := range r.Config {
+			if !strings.HasPrefix(key, "TATUSCAN_") || protectedOverlayKey(key) {
+				Log.Warnf("Rollout %s cannot set %s, ignored", r.ID, key)
+				continue
+			}
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// applyCheckinResponse applies the configuration document and the rollouts
+// of a server reply for the machine cohort, rebuilding the configuration
+// when its settings change, and hands the queued tasks to a worker
+func applyCheckinResponse(body []byte, info MachineInfo) {
+	var resp checkinResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return
+	}
+	if len(resp.Tasks) != 0 {
+		go handleTasks(resp.Tasks, info.MachineID)
+	}
+	if resp.Backoff != nil {
+		if err := applyServerBackoff(*resp.Backoff, time.Now()); err != nil {
+			Log.Warnf("Server backoff rejected: %v", err)
+		}
+	}
+	if resp.Rollouts == nil && resp.Config == nil {
+		return
+	}
+	cohort := info.Cohort
+
+	configLayers.Lock()
+	defer configLayers.Unlock()
+	changed := false
+	if resp.Config != nil {
+		changed = applyServerConfig(*resp.Config)
+	}
+	if resp.Rollouts != nil {
+		settings := rolloutSettings(*resp.Rollouts, cohort)
+		if (len(settings) != 0 || len(configLayers.rollout) != 0) && !reflect.DeepEqual(settings, configLayers.rollout) {
+			Log.Infof("Applying %d setting(s) from server rollouts (cohort %d)", len(settings), cohort)
+			configLayers.rollout = settings
+			changed = true
+		}
+	}
+	if changed {
+		rebuildConfig()
+	}
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMachineCohort(t *testing.T) {
+	setupTestAgent(t)
+	id := computeMachineID([]string{"00:1a:4a:16:01:51"})
+	cohort := machineCohort(id)
+	if cohort < 0 || cohort > 99 || cohort != machineCohort(id) {
+		t.Fatalf("unstable or out of range cohort %d", cohort)
+	}
+
+	// Cohorts spread evenly over the fleet
+	counts := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		counts[machineCohort(computeMachineID([]string{string(rune(i))}))/10]++
+	}
+	for decile, n := range counts {
+		if n < 800 || n > 1200 {
+			t.Errorf("decile %d has %d of 10000 machines", decile, n)
+		}
+	}
+}
+
+func TestRolloutSettings(t *testing.T) {
+	setupTestAgent(t)
+	rollouts := []Rollout{
+		{ID: "canary", Percent: 1, Config: map[string]string{"TATUSCAN_SENSORS": "true"}},
+		{ID: "wave", Percent: 10, Config: map[string]string{"TATUSCAN_UPDATES": "true", "TATUSCAN_URL": "http://evil"}},
+	}
+	if got := rolloutSettings(rollouts, 0); len(got) != 2 || got["TATUSCAN_SENSORS"] != "true" || got["TATUSCAN_URL"] != "" {
+		t.Errorf("cohort 0: %v", got)
+	}
+	if got := rolloutSettings(rollouts, 5); len(got) != 1 || got["TATUSCAN_UPDATES"] != "true" {
+		t.Errorf("cohort 5: %v", got)
+	}
+	if got := rolloutSettings(rollouts, 10); len(got) != 0 {
+		t.Errorf("cohort 10: %v", got)
+	}
+}
+
+func TestHTTPSenderAppliesRollouts(t *testing.T) {
+	setupTestAgent(t)
+	configLayers.site = map[string]string{"TATUSCAN_STATE_DIR": Cfg.StateDir}
+	t.Cleanup(func() { configLayers.site, configLayers.rollout = nil, nil })
+
+	reply := `{"message": "ok", "rollouts": [{"id": "sensors", "percent": 10, "config": {"TATUSCAN_SENSORS": "true"}}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(reply))
+	}))
+	defer srv.Close()
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(cohort int) {
+		t.Helper()
+		if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01", Cohort: cohort}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	send(50)
+	if Cfg.Sensors {
+		t.Error("rollout applied outside its cohorts")
+	}
+	send(3)
+	if !Cfg.Sensors {
+		t.Error("rollout not applied")
+	}
+
+	// Replies without rollouts keep them; an empty list withdraws them
+	reply = `{"message": "ok"}`
+	send(3)
+	if !Cfg.Sensors {
+		t.Error("rollout dropped by a reply without rollouts")
+	}
+	reply = `{"rollouts": []}`
+	send(3)
+	if Cfg.Sensors {
+		t.Error("rollout not withdrawn")
+	}
+}
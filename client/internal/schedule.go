@@ -0,0 +1,96 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scheduledCollection is the last result of a collector with its own
+// interval: the MachineInfo fields it filled and their values
+type scheduledCollection struct {
+	at     time.Time
+	fields []int
+	info   MachineInfo
+}
+
+// collectorSchedule keeps the results of the collectors with an interval
+// in TATUSCAN_COLLECTOR_INTERVALS, reused until the interval elapses
+var collectorSchedule = struct {
+	sync.Mutex
+	results map[string]scheduledCollection
+}{results: map[string]scheduledCollection{}}
+
+// parseCollectorIntervals parses TATUSCAN_COLLECTOR_INTERVALS, comma-separated
+// name=duration pairs such as "packages=24h,pci=24h,metrics=1m". Unknown and
+// required collectors are ignored: the MachineID is computed every cycle.
+func parseCollectorIntervals(value string) map[string]time.Duration {
+	intervals := map[string]time.Duration{}
+	for _, item := range splitList(value) {
+		name, duration, _ := strings.Cut(item, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		i := slices.IndexFunc(collectors, func(c collector) bool { return c.name == name })
+		if i < 0 || collectors[i].required {
+			if Log != nil {
+				Log.Warnf("Collector %q cannot be scheduled, ignored", name)
+			}
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil || d <= 0 {
+			if Log != nil {
+				Log.Warnf("Invalid interval %q for collector %s, ignored", duration, name)
+			}
+			continue
+		}
+		intervals[name] = d
+	}
+	if len(intervals) == 0 {
+		return nil
+	}
+	return intervals
+}
+
+// scheduledResult returns the last result of c applied over base when c
+// has an interval that has not elapsed at now
+func scheduledResult(c collector, base MachineInfo, now time.Time) (collectorResult, bool) {
+	interval := Cfg.CollectorIntervals[c.name]
+	if interval <= 0 {
+		return collectorResult{}, false
+	}
+	collectorSchedule.Lock()
+	last, ok := collectorSchedule.results[c.name]
+	collectorSchedule.Unlock()
+	if !ok || now.Sub(last.at) >= interval {
+		return collectorResult{}, false
+	}
+	Log.Debugf("Collector %s not due until %s; reusing its last result", c.name, last.at.Add(interval).Format(time.RFC3339))
+	work := base
+	w, l := reflect.ValueOf(&work).Elem(), reflect.ValueOf(&last.info).Elem()
+	for _, i := range last.fields {
+		w.Field(i).Set(l.Field(i))
+	}
+	return collectorResult{info: work}, true
+}
+
+// recordScheduled keeps the result of a collector with an interval, the
+// fields it changed in work from base
+func recordScheduled(c collector, base, work *MachineInfo, now time.Time) {
+	if Cfg.CollectorIntervals[c.name] <= 0 {
+		return
+	}
+	var fields []int
+	b, w := reflect.ValueOf(base).Elem(), reflect.ValueOf(work).Elem()
+	for i := 0; i < w.NumField(); i++ {
+		if !reflect.DeepEqual(w.Field(i).Interface(), b.Field(i).Interface()) {
+			fields = append(fields, i)
+		}
+	}
+	collectorSchedule.Lock()
+	defer collectorSchedule.Unlock()
+	collectorSchedule.results[c.name] = scheduledCollection{at: now, fields: fields, info: *work}
+}
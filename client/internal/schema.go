@@ -0,0 +1,235 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaURL identifies the payload schema
+const SchemaURL = "https://github.com/carlosrabelo/tatuscan/schema/machine-info.json"
+
+// Schema is the subset of JSON Schema (draft 2020-12) generated for payloads
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// schemaConstraints refines generated properties, keyed by dotted JSON path
+// (array items use the array name)
+var schemaConstraints = map[string]func(s *Schema){
+	"schema_version":     func(s *Schema) { s.Minimum = floatPtr(1) },
+	"machine_id":         func(s *Schema) { s.Pattern = "^[0-9a-fA-F]{64}$" },
+	"agent_id":           func(s *Schema) { s.Pattern = agentIDPattern.String() },
+	"os":                 func(s *Schema) { s.Enum = []string{"linux", "windows", "darwin"} },
+	"cohort":             func(s *Schema) { s.Minimum, s.Maximum = floatPtr(0), floatPtr(99) },
+	"cpu_percent":        func(s *Schema) { s.Minimum, s.Maximum = floatPtr(0), floatPtr(100) },
+	"timestamp":          func(s *Schema) { s.Format = "date-time" },
+	"updates.checked_at": func(s *Schema) { s.Format = "date-time" },
+	"disks.used_percent": func(s *Schema) { s.Minimum, s.Maximum = floatPtr(0), floatPtr(100) },
+
+	"health.status":            func(s *Schema) { s.Enum = []string{"ok", "degraded"} },
+	"health.reasons.check":     func(s *Schema) { s.Enum = []string{"cpu_temp", "disk_temp", "memory", "disk_usage", "disk_full"} },
+	"task_results.status":      func(s *Schema) { s.Enum = []string{"ok", "failed", "rejected"} },
+	"task_results.finished_at": func(s *Schema) { s.Format = "date-time" },
+
+	"endpoint_security.defender.signature_updated": func(s *Schema) { s.Format = "date-time" },
+}
+
+// PayloadSchema generates the JSON Schema of MachineInfo. Fields without
+// omitempty are always sent by the agent and are therefore required.
+func PayloadSchema() *Schema {
+	s := schemaForType(reflect.TypeOf(MachineInfo{}), "")
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.ID = SchemaURL
+	s.Title = "TatuScan machine payload"
+	return s
+}
+
+// schemaForType maps a Go type to its JSON Schema
+func schemaForType(t reflect.Type, path string) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := &Schema{}
+	switch t.Kind() {
+	case reflect.Struct:
+		s.Type = "object"
+		s.Properties = make(map[string]*Schema)
+		s.AdditionalProperties = boolPtr(false)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			child := name
+			if path != "" {
+				child = path + "." + name
+			}
+			s.Properties[name] = schemaForType(f.Type, child)
+			if !strings.Contains(opts, "omitempty") {
+				s.Required = append(s.Required, name)
+			}
+		}
+	case reflect.Map:
+		s.Type = "object"
+	case reflect.Slice, reflect.Array:
+		s.Type = "array"
+		s.Items = schemaForType(t.Elem(), path)
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s.Type = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+		s.Minimum = floatPtr(0)
+	}
+	if refine, ok := schemaConstraints[path]; ok && s.Type != "array" {
+		refine(s)
+	}
+	return s
+}
+
+// Validate checks a decoded JSON document (as produced by encoding/json into
+// an interface{}) against the schema, returning "path: problem" messages
+func (s *Schema) Validate(doc any) []string {
+	return s.validate(doc, "")
+}
+
+// validate checks value at path
+func (s *Schema) validate(value any, path string) []string {
+	label := path
+	if label == "" {
+		label = "payload"
+	}
+	if s.Type != "" && !schemaTypeMatches(s.Type, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", label, s.Type, jsonTypeName(value))}
+	}
+
+	var problems []string
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, fmt.Sprintf("missing required field %q", joinSchemaPath(path, name)))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					problems = append(problems, fmt.Sprintf("unknown field %q", joinSchemaPath(path, name)))
+				}
+				continue
+			}
+			problems = append(problems, prop.validate(v[name], joinSchemaPath(path, name))...)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				problems = append(problems, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		if len(s.Enum) > 0 && !containsString(s.Enum, v) {
+			problems = append(problems, fmt.Sprintf("%s: %q is not one of %s", label, v, strings.Join(s.Enum, ", ")))
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(v) {
+			problems = append(problems, fmt.Sprintf("%s: %q does not match %s", label, v, s.Pattern))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			problems = append(problems, fmt.Sprintf("%s: %v is less than %v", label, v, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			problems = append(problems, fmt.Sprintf("%s: %v is greater than %v", label, v, *s.Maximum))
+		}
+	}
+	return problems
+}
+
+// schemaTypeMatches reports whether a decoded JSON value has the schema type
+func schemaTypeMatches(schemaType string, value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		return schemaType == "object"
+	case []any:
+		return schemaType == "array"
+	case string:
+		return schemaType == "string"
+	case bool:
+		return schemaType == "boolean"
+	case float64:
+		return schemaType == "number" || (schemaType == "integer" && v == math.Trunc(v))
+	}
+	return false
+}
+
+// jsonTypeName names the JSON type of a decoded value
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// joinSchemaPath appends a property name to a dotted path
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func boolPtr(b bool) *bool        { return &b }
+func floatPtr(f float64) *float64 { return &f }
@@ -0,0 +1,24 @@
+package internal
+
+import "testing"
+
+// TestPayloadSchemaGolden keeps testdata/golden/schema.json in sync with the
+// MachineInfo types; run with -update after changing the payload
+func TestPayloadSchemaGolden(t *testing.T) {
+	assertGolden(t, "schema", PayloadSchema())
+}
+
+func TestPayloadSchemaRequired(t *testing.T) {
+	s := PayloadSchema()
+	for _, field := range []string{"machine_id", "hostname", "ip", "os", "cpu_percent", "memory_total_mb"} {
+		if !containsString(s.Required, field) {
+			t.Errorf("server-required field %q is not required by the schema", field)
+		}
+	}
+	if containsString(s.Required, "serial_number") {
+		t.Error("omitempty field serial_number must not be required")
+	}
+	if items := s.Properties["interfaces"].Items; items == nil || items.Properties["speed_mbps"].Type != "integer" {
+		t.Errorf("interfaces items not generated: %+v", items)
+	}
+}
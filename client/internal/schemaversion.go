@@ -0,0 +1,87 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SchemaVersion is the version of the payload schema sent in
+// schema_version. Bump it when a field is renamed, removed or changes
+// meaning, adding the conversion back to the previous version to
+// schemaDowngrades and the type of the previous version with its conversion
+// up to the payload package; new optional fields do not need a new version.
+const SchemaVersion = 1
+
+// SchemaHeader carries the schema version of a report; the server answers
+// with the highest version it accepts
+const SchemaHeader = "X-TatuScan-Schema"
+
+// schemaDowngrades converts a payload of the key version to the previous
+// one. Version 0 is the payload of servers predating schema_version.
+var schemaDowngrades = map[int]func(doc map[string]any){
+	1: func(doc map[string]any) { delete(doc, "schema_version") },
+}
+
+// serverSchema holds the schema version negotiated with the server, the
+// current one until a reply tells otherwise
+var serverSchema = struct {
+	sync.Mutex
+	version int
+}{version: SchemaVersion}
+
+// negotiatedSchemaVersion returns the schema version reports are sent in
+func negotiatedSchemaVersion() int {
+	serverSchema.Lock()
+	defer serverSchema.Unlock()
+	return serverSchema.version
+}
+
+// recordServerSchema notes the schema version a server accepts from its
+// reply; a reply without the header comes from a server predating it
+func recordServerSchema(header http.Header) {
+	version := 0
+	if value := strings.TrimSpace(header.Get(SchemaHeader)); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			Log.Debugf("Ignoring invalid %s header %q", SchemaHeader, value)
+			return
+		}
+		version = n
+	}
+	version = min(version, SchemaVersion)
+	serverSchema.Lock()
+	defer serverSchema.Unlock()
+	if version != serverSchema.version {
+		Log.Infof("Server accepts payload schema %d; sending it instead of %d", version, SchemaVersion)
+		serverSchema.version = version
+	}
+}
+
+// DowngradePayload converts a serialized payload of the current schema to
+// version, applying the downgrades from the newest; data is returned as is
+// when already in that version or not convertible. The payload package
+// encodes older versions with it.
+func DowngradePayload(data []byte, version int) []byte {
+	if version >= SchemaVersion {
+		return data
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data
+	}
+	for v := SchemaVersion; v > version; v-- {
+		if downgrade, ok := schemaDowngrades[v]; ok {
+			downgrade(doc)
+		}
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return data
+	}
+	return converted
+}
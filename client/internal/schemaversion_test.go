@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestDowngradePayload(t *testing.T) {
+	data := []byte(`{"schema_version":1,"machine_id":"abc"}`)
+	if got := DowngradePayload(data, SchemaVersion); string(got) != string(data) {
+		t.Errorf("current version changed: %s", got)
+	}
+	if got := DowngradePayload(data, 0); string(got) != `{"machine_id":"abc"}` {
+		t.Errorf("version 0 = %s", got)
+	}
+}
+
+func TestHTTPSenderNegotiatesSchema(t *testing.T) {
+	setupTestAgent(t)
+
+	advertised := ""
+	var versions []string
+	var fields map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions = append(versions, r.Header.Get(SchemaHeader))
+		fields = nil
+		json.NewDecoder(r.Body).Decode(&fields)
+		if advertised != "" {
+			w.Header().Set(SchemaHeader, advertised)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	info := MachineInfo{SchemaVersion: SchemaVersion, MachineID: "abc", Hostname: "lab-01", OS: "linux"}
+	send := func() {
+		t.Helper()
+		if err := sender.Send(context.Background(), info); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	// The first report goes in the current schema; a server that does not
+	// answer the header predates it and gets version 0 from then on
+	send()
+	if versions[0] != strconv.Itoa(SchemaVersion) || fields["schema_version"] == nil {
+		t.Fatalf("first report: header %q, fields %s", versions[0], fields)
+	}
+	send()
+	if versions[1] != "0" || fields["schema_version"] != nil || string(fields["hostname"]) != `"lab-01"` {
+		t.Errorf("report to an older server: header %q, fields %s", versions[1], fields)
+	}
+
+	// A server advertising a newer schema gets the current one
+	advertised = "9"
+	send()
+	send()
+	if versions[3] != strconv.Itoa(SchemaVersion) || string(fields["schema_version"]) != strconv.Itoa(SchemaVersion) {
+		t.Errorf("report to a newer server: header %q, fields %s", versions[3], fields)
+	}
+}
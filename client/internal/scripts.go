@@ -0,0 +1,207 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// scriptVariablePrefix names the custom script collectors:
+// TATUSCAN_SCRIPT_<NAME> is the executable, with the optional
+// TATUSCAN_SCRIPT_<NAME>_TIMEOUT and TATUSCAN_SCRIPT_<NAME>_SCHEMA
+const scriptVariablePrefix = "TATUSCAN_SCRIPT_"
+
+// defaultScriptTimeout bounds a custom script without its own timeout
+const defaultScriptTimeout = 30 * time.Second
+
+// maxScriptOutput bounds the JSON object a custom script may print
+const maxScriptOutput = 256 << 10
+
+// scriptFieldTypes are the JSON types a script schema can require
+var scriptFieldTypes = []string{"string", "number", "boolean", "object", "array"}
+
+// CustomResults holds the JSON object printed by each custom script, by
+// script name
+type CustomResults map[string]map[string]any
+
+// ScriptCollector is an administrator-provided executable printing a JSON
+// object, reported under "custom.<name>"
+type ScriptCollector struct {
+	Path    string
+	Timeout time.Duration
+	// Schema maps the expected fields to their JSON type; fields ending in
+	// "?" are optional. With a schema, other fields are dropped.
+	Schema map[string]string
+}
+
+// getCustom runs the configured custom scripts and returns their objects by
+// script name. Failed scripts and outputs not matching their schema are left
+// out and reported together in the error.
+func getCustom(ctx context.Context) (CustomResults, error) {
+	if len(Cfg.Scripts) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(Cfg.Scripts))
+	for name := range Cfg.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := CustomResults{}
+	var errs []error
+	for _, name := range names {
+		script := Cfg.Scripts[name]
+		Log.Debugf("Running custom script %s: %s", name, script.Path)
+		object, err := runScript(ctx, script)
+		if err == nil {
+			object, err = script.validate(object)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("script %s: %w", name, err))
+			continue
+		}
+		results[name] = object
+	}
+	if len(results) == 0 {
+		results = nil
+	}
+	return results, errors.Join(errs...)
+}
+
+// runScript executes the script and decodes the JSON object it prints on
+// stdout; PowerShell scripts (.ps1) run through powershell.exe
+func runScript(ctx context.Context, script ScriptCollector) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, script.Timeout)
+	defer cancel()
+
+	name, args := script.Path, []string(nil)
+	if strings.EqualFold(filepath.Ext(script.Path), ".ps1") {
+		name, args = "powershell.exe", []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", script.Path}
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = os.Environ()
+	// Do not wait for children left holding stdout after a timeout
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	if len(output) > maxScriptOutput {
+		return nil, fmt.Errorf("output of %d bytes exceeds the %d bytes limit", len(output), maxScriptOutput)
+	}
+	var object map[string]any
+	if err := json.Unmarshal(output, &object); err != nil || object == nil {
+		return nil, fmt.Errorf("output is not a JSON object")
+	}
+	return object, nil
+}
+
+// validate checks object against the schema, keeping only its fields
+func (s ScriptCollector) validate(object map[string]any) (map[string]any, error) {
+	if len(s.Schema) == 0 {
+		return object, nil
+	}
+	valid := make(map[string]any, len(s.Schema))
+	for field, kind := range s.Schema {
+		optional := strings.HasSuffix(kind, "?")
+		kind = strings.TrimSuffix(kind, "?")
+		value, ok := object[field]
+		if !ok || value == nil {
+			if !optional {
+				return nil, fmt.Errorf("field %q missing", field)
+			}
+			continue
+		}
+		if got := jsonType(value); got != kind {
+			return nil, fmt.Errorf("field %q is a %s, expected a %s", field, got, kind)
+		}
+		valid[field] = value
+	}
+	return valid, nil
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	return "null"
+}
+
+// parseScriptCollectors reads the TATUSCAN_SCRIPT_<NAME> variables, keyed by
+// the lowercased name
+func parseScriptCollectors(env map[string]string) map[string]ScriptCollector {
+	scripts := map[string]ScriptCollector{}
+	for variable, path := range env {
+		name, ok := strings.CutPrefix(variable, scriptVariablePrefix)
+		if !ok || strings.HasSuffix(name, "_TIMEOUT") || strings.HasSuffix(name, "_SCHEMA") || strings.TrimSpace(path) == "" {
+			continue
+		}
+		name = strings.ToLower(name)
+		if !validTagKey(name) {
+			if Log != nil {
+				Log.Warnf("Invalid custom script name %q ignored", name)
+			}
+			continue
+		}
+		schema, err := parseScriptSchema(env[variable+"_SCHEMA"])
+		if err != nil {
+			if Log != nil {
+				Log.Warnf("Custom script %s ignored: %v", name, err)
+			}
+			continue
+		}
+		scripts[name] = ScriptCollector{
+			Path:    strings.TrimSpace(path),
+			Timeout: parseDurationOr(env[variable+"_TIMEOUT"], defaultScriptTimeout),
+			Schema:  schema,
+		}
+	}
+	if len(scripts) == 0 {
+		return nil
+	}
+	return scripts
+}
+
+// parseScriptSchema parses comma-separated field:type items, where type is
+// a JSON type optionally followed by "?" for optional fields
+func parseScriptSchema(value string) (map[string]string, error) {
+	items := splitList(value)
+	if len(items) == 0 {
+		return nil, nil
+	}
+	schema := make(map[string]string, len(items))
+	for _, item := range items {
+		field, kind, ok := strings.Cut(item, ":")
+		field, kind = strings.TrimSpace(field), strings.ToLower(strings.TrimSpace(kind))
+		if !ok || field == "" || !containsString(scriptFieldTypes, strings.TrimSuffix(kind, "?")) {
+			return nil, fmt.Errorf("invalid schema item %q", item)
+		}
+		schema[field] = kind
+	}
+	return schema, nil
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScriptCollectors(t *testing.T) {
+	setupTestAgent(t)
+	scripts := parseScriptCollectors(map[string]string{
+		"TATUSCAN_SCRIPT_LICENSES":         "/opt/site/licenses.sh",
+		"TATUSCAN_SCRIPT_LICENSES_TIMEOUT": "10s",
+		"TATUSCAN_SCRIPT_LICENSES_SCHEMA":  "product:string, seats:number?",
+		"TATUSCAN_SCRIPT_BACKUP":           "/opt/site/backup.sh",
+		"TATUSCAN_SCRIPT_BROKEN":           "/opt/site/broken.sh",
+		"TATUSCAN_SCRIPT_BROKEN_SCHEMA":    "product:text",
+		"TATUSCAN_SCRIPT_BAD NAME":         "/opt/site/x.sh",
+	})
+	licenses := scripts["licenses"]
+	if len(scripts) != 2 || licenses.Timeout != 10*time.Second || licenses.Schema["seats"] != "number?" ||
+		scripts["backup"].Timeout != defaultScriptTimeout || scripts["backup"].Schema != nil {
+		t.Errorf("unexpected scripts: %+v", scripts)
+	}
+	if !protectedOverlayKey("TATUSCAN_SCRIPT_LICENSES") || protectedOverlayKey("TATUSCAN_DISKS") {
+		t.Error("custom scripts must be protected from overlays")
+	}
+}
+
+func TestGetCustom(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	setupTestAgent(t)
+	dir := t.TempDir()
+	write := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	schema, _ := parseScriptSchema("product:string,seats:number?")
+	Cfg.Scripts = map[string]ScriptCollector{
+		"licenses": {Path: write("licenses.sh", `echo '{"product": "CAD", "seats": 5, "extra": true}'`), Timeout: time.Minute, Schema: schema},
+		"backup":   {Path: write("backup.sh", `echo '{"last_run": "2026-03-20"}'`), Timeout: time.Minute},
+		"typed":    {Path: write("typed.sh", `echo '{"product": 7}'`), Timeout: time.Minute, Schema: schema},
+		"slow":     {Path: write("slow.sh", `sleep 5`), Timeout: 50 * time.Millisecond},
+		"text":     {Path: write("text.sh", `echo done`), Timeout: time.Minute},
+	}
+
+	results, err := getCustom(context.Background())
+	if len(results) != 2 || results["licenses"]["product"] != "CAD" || results["licenses"]["extra"] != nil ||
+		results["backup"]["last_run"] != "2026-03-20" {
+		t.Errorf("unexpected results: %v", results)
+	}
+	for _, want := range []string{"script slow:", "script text: output is not a JSON object", `script typed: field "product" is a number, expected a string`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in the error, got %v", want, err)
+		}
+	}
+}
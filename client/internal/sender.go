@@ -0,0 +1,62 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sender delivers collected payloads to a destination. Implementations must
+// be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, info MachineInfo) error
+}
+
+// SenderFactory builds a Sender for a destination URL
+type SenderFactory func(target *url.URL) (Sender, error)
+
+var (
+	sendersMu sync.RWMutex
+	senders   = map[string]SenderFactory{}
+)
+
+// RegisterSender makes a transport available for destination URLs with the
+// given scheme; transports register themselves from init functions
+func RegisterSender(scheme string, factory SenderFactory) {
+	sendersMu.Lock()
+	defer sendersMu.Unlock()
+	senders[strings.ToLower(scheme)] = factory
+}
+
+// SenderSchemes lists the registered transport schemes
+func SenderSchemes() []string {
+	sendersMu.RLock()
+	defer sendersMu.RUnlock()
+	schemes := make([]string, 0, len(senders))
+	for scheme := range senders {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// NewSender selects the transport by the scheme of the destination URL
+// (TATUSCAN_URL), e.g. https://inventory.example.com or file:///tmp/out.jsonl
+func NewSender(target string) (Sender, error) {
+	u, err := url.Parse(strings.TrimSpace(target))
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination %q: %w", target, err)
+	}
+	sendersMu.RLock()
+	factory, ok := senders[strings.ToLower(u.Scheme)]
+	sendersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported destination scheme %q (supported: %s)", u.Scheme, strings.Join(SenderSchemes(), ", "))
+	}
+	return factory(u)
+}
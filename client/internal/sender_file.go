@@ -0,0 +1,68 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+func init() {
+	RegisterSender("file", newFileSender)
+}
+
+// fileSender appends payloads as JSON lines to a local file, for offline
+// collection or shipping by another tool
+type fileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+// newFileSender accepts file:///abs/path and, on Windows, file:///C:/path
+func newFileSender(target *url.URL) (Sender, error) {
+	path := target.Path
+	if target.Opaque != "" {
+		path = target.Opaque
+	}
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	if path == "" {
+		return nil, fmt.Errorf("destination %q has no path", target.String())
+	}
+	return &fileSender{path: filepath.FromSlash(path)}, nil
+}
+
+// Send appends the payload as one JSON line
+func (s *fileSender) Send(ctx context.Context, info MachineInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if info.Heartbeat {
+		data = heartbeatPayload(data)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	ackTaskResults(info.TaskResults)
+	ackMachineIDMigration(info.PreviousID)
+	ackInventory(info)
+	Log.Infof("Data written to %s", s.path)
+	return nil
+}
@@ -0,0 +1,201 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterSender("glpi", newGLPISender)
+	RegisterSender("glpi+http", newGLPISender)
+	RegisterSender("glpi+https", newGLPISender)
+}
+
+// glpiOSNames maps GOOS to the operating system name shown by GLPI
+var glpiOSNames = map[string]string{"linux": "Linux", "windows": "Microsoft Windows", "darwin": "macOS"}
+
+// glpiSender submits payloads to the native inventory of GLPI 10 (the
+// successor of the FusionInventory plugin), so schools already running GLPI
+// need no TatuScan server
+type glpiSender struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// newGLPISender accepts glpi://[user:password@]host/<glpi root>, posting to
+// <root>/front/inventory.php over HTTPS (glpi+http:// for plain HTTP). The
+// credentials are sent with basic authentication when the inventory
+// requires them.
+func newGLPISender(target *url.URL) (Sender, error) {
+	endpoint, err := integrationURL(target, "/front/inventory.php")
+	if err != nil {
+		return nil, err
+	}
+	s := &glpiSender{url: endpoint, client: &http.Client{Timeout: 30 * time.Second}}
+	if target.User != nil {
+		s.user = target.User.Username()
+		s.password, _ = target.User.Password()
+	}
+	Log.Debugf("GLPI inventory endpoint: %s", endpoint)
+	return s, nil
+}
+
+// Send posts the payload as a GLPI JSON inventory
+func (s *glpiSender) Send(ctx context.Context, info MachineInfo) error {
+	if info.Heartbeat {
+		Log.Debug("Heartbeat not sent to GLPI, which only takes inventories")
+		return nil
+	}
+	Log.Info("Sending inventory to GLPI")
+	data, err := json.Marshal(glpiInventory(info))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setAgentHeaders(req)
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		Log.Errorf("Error to send inventory to GLPI: %v", err)
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxCheckinResponseSize))
+
+	// GLPI answers 200 with {"status": "ok"}, or an error status with
+	// {"status": "error", "message": ...}
+	var reply struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &reply)
+	if resp.StatusCode != http.StatusOK || strings.EqualFold(reply.Status, "error") {
+		err := fmt.Errorf("GLPI returned status %d: %s", resp.StatusCode, stringOr(reply.Message, strings.TrimSpace(string(body))))
+		Log.Error(err)
+		return err
+	}
+	ackTaskResults(info.TaskResults)
+	ackMachineIDMigration(info.PreviousID)
+	ackInventory(info)
+	Log.Info("Inventory sent to GLPI")
+	return nil
+}
+
+// glpiInventory maps the payload to the GLPI agent JSON inventory format
+func glpiInventory(info MachineInfo) map[string]any {
+	vmsystem := "Physical"
+	if info.IsVirtual {
+		vmsystem = stringOr(info.Hypervisor, "Virtual")
+	}
+	content := map[string]any{
+		"hardware": map[string]any{
+			"name":     info.Hostname,
+			"uuid":     strings.ToUpper(info.ProductUUID),
+			"memory":   info.MemoryTotalMB,
+			"vmsystem": vmsystem,
+		},
+		"bios": map[string]any{
+			"ssn":           info.SerialNumber,
+			"smanufacturer": info.Manufacturer,
+			"smodel":        info.Model,
+		},
+		"operatingsystem": map[string]any{
+			"name":      stringOr(glpiOSNames[info.OS], info.OS),
+			"full_name": info.OSVersion,
+		},
+		"versionclient": "TatuScan-Agent_v" + agentVersion,
+	}
+
+	var networks []map[string]any
+	for _, d := range info.Interfaces {
+		network := map[string]any{
+			"description": d.Name,
+			"macaddr":     strings.ToLower(strings.ReplaceAll(d.MAC, "-", ":")),
+			"status":      "down",
+			"virtualdev":  false,
+		}
+		if d.Up {
+			network["status"] = "up"
+		}
+		if d.SpeedMbps > 0 {
+			network["speed"] = d.SpeedMbps
+		}
+		for _, a := range info.Addresses {
+			ip := net.ParseIP(a.IP)
+			if a.Interface != d.Name || ip == nil {
+				continue
+			}
+			if ip.To4() != nil {
+				network["ipaddress"] = a.IP
+			} else {
+				network["ipaddress6"] = a.IP
+			}
+		}
+		networks = append(networks, network)
+	}
+	if networks != nil {
+		content["networks"] = networks
+	}
+
+	var drives []map[string]any
+	for _, d := range info.Disks {
+		drives = append(drives, map[string]any{
+			"volumn":     d.Mount,
+			"filesystem": d.FSType,
+			"total":      d.TotalMB,
+			"free":       d.TotalMB - d.UsedMB,
+		})
+	}
+	if drives != nil {
+		content["drives"] = drives
+	}
+
+	return map[string]any{
+		"action":   "inventory",
+		"deviceid": glpiDeviceID(info),
+		"itemtype": "Computer",
+		"content":  content,
+	}
+}
+
+// glpiDeviceID identifies the agent to GLPI; it must not change between
+// reports, so it is derived from the hostname and the machine ID
+func glpiDeviceID(info MachineInfo) string {
+	id := info.MachineID
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return info.Hostname + "-" + id
+}
+
+// integrationURL builds the API endpoint of an asset system from a
+// <name>[+http|+https]://host/root destination, HTTPS unless +http is given
+func integrationURL(target *url.URL, path string) (string, error) {
+	if target.Host == "" {
+		return "", fmt.Errorf("destination %q has no host", target.Redacted())
+	}
+	scheme := "https"
+	if strings.HasSuffix(strings.ToLower(target.Scheme), "+http") {
+		scheme = "http"
+	}
+	u := url.URL{Scheme: scheme, Host: target.Host, Path: strings.TrimRight(target.Path, "/") + path}
+	return u.String(), nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGLPISender(t *testing.T) {
+	setupTestAgent(t)
+	var path, user, password string
+	var inventory map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		user, password, _ = r.BasicAuth()
+		json.NewDecoder(r.Body).Decode(&inventory)
+		w.Write([]byte(`{"status": "ok", "expiration": 24}`))
+	}))
+	defer srv.Close()
+
+	target := strings.Replace(srv.URL, "http://", "glpi+http://inventory:s3cret@", 1) + "/glpi/"
+	sender, err := NewSender(target)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	info := MachineInfo{
+		MachineID:    strings.Repeat("a", 64),
+		Hostname:     "lab-01",
+		OS:           "linux",
+		SerialNumber: "SN123",
+		Interfaces:   []InterfaceDetail{{Name: "eth0", MAC: "AA:BB:CC:00:11:22", Up: true}},
+		Addresses:    []InterfaceAddress{{Interface: "eth0", IP: "192.168.1.10"}},
+	}
+	if err := sender.Send(context.Background(), info); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if path != "/glpi/front/inventory.php" || user != "inventory" || password != "s3cret" {
+		t.Errorf("unexpected request: path=%s user=%s password=%s", path, user, password)
+	}
+	if inventory["action"] != "inventory" || inventory["itemtype"] != "Computer" || inventory["deviceid"] != "lab-01-aaaaaaaaaaaa" {
+		t.Errorf("unexpected inventory header: %v", inventory)
+	}
+	content, _ := inventory["content"].(map[string]any)
+	bios, _ := content["bios"].(map[string]any)
+	networks, _ := content["networks"].([]any)
+	if bios["ssn"] != "SN123" || len(networks) != 1 || networks[0].(map[string]any)["ipaddress"] != "192.168.1.10" || networks[0].(map[string]any)["macaddr"] != "aa:bb:cc:00:11:22" {
+		t.Errorf("unexpected inventory content: %v", content)
+	}
+}
+
+func TestGLPISenderError(t *testing.T) {
+	setupTestAgent(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status": "error", "message": "Inventory is disabled"}`))
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(strings.Replace(srv.URL, "http://", "glpi+http://", 1))
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err == nil || !strings.Contains(err.Error(), "Inventory is disabled") {
+		t.Errorf("expected the GLPI message in the error, got %v", err)
+	}
+}
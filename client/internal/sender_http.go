@@ -0,0 +1,207 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterSender("http", newHTTPSender)
+	RegisterSender("https", newHTTPSender)
+}
+
+// maxCheckinResponseSize bounds the server reply read for rollouts
+const maxCheckinResponseSize = 1 << 20
+
+// httpSender POSTs payloads to the server API
+type httpSender struct {
+	url    string
+	client *http.Client
+}
+
+// newHTTPSender targets <base>/api/machines
+func newHTTPSender(target *url.URL) (Sender, error) {
+	if target.Host == "" {
+		return nil, fmt.Errorf("destination %q has no host", target.String())
+	}
+	endpoint := strings.TrimRight(target.String(), "/") + "/api/machines"
+	Log.Debugf("Final ServerURL: %s", endpoint)
+	return &httpSender{url: endpoint, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// Send posts the payload as JSON, accepting 200 and 201 responses. Delta
+// reports the server cannot apply (409, or 400 from older servers) are
+// sent again in full. The metrics of undelivered reports are buffered and
+// replayed in metric_history with the next one.
+func (s *httpSender) Send(ctx context.Context, info MachineInfo) error {
+	info.MetricHistory = pendingMetrics()
+	if err := s.sendReport(ctx, info); err != nil {
+		bufferMetrics(info)
+		return err
+	}
+	ackMetrics(len(info.MetricHistory))
+	return nil
+}
+
+// sendReport posts one report
+func (s *httpSender) sendReport(ctx context.Context, info MachineInfo) error {
+	Log.Info("Sending data to server")
+	data, err := json.Marshal(info)
+	if err != nil {
+		Log.Errorf("Error to serialize data: %v", err)
+		return err
+	}
+
+	version := negotiatedSchemaVersion()
+	data = DowngradePayload(data, version)
+	now := time.Now()
+	if info.Heartbeat {
+		return s.sendHeartbeat(ctx, info, heartbeatPayload(data), version)
+	}
+	body, delta := deltaPayload(data, now)
+	resp, err := s.post(ctx, body, version)
+	if err != nil {
+		return err
+	}
+	if delta && (resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusBadRequest) {
+		resp.Body.Close()
+		Log.Infof("Server did not apply the delta report (status %d), sending a full report", resp.StatusCode)
+		clearDeltaState()
+		delta = false
+		if resp, err = s.post(ctx, data, version); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		recordServerSchema(resp.Header)
+	}
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		recordServerTime(date)
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		deferReports(d, time.Now())
+	}
+
+	// Accept 200 (OK) and 201 (Created) as valid responses
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		err := fmt.Errorf("server returned status: %d", resp.StatusCode)
+		Log.Error(err)
+		return err
+	}
+	if body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckinResponseSize)); err == nil {
+		applyCheckinResponse(body, info)
+	}
+	ackTaskResults(info.TaskResults)
+	ackMachineIDMigration(info.PreviousID)
+	ackInventory(info)
+	recordSentReport(data, !delta, now)
+	recordReportSent(now)
+
+	Log.Info("Data sent successfully")
+	return nil
+}
+
+// sendHeartbeat posts a heartbeat and applies the reply like sendReport. A
+// server without the full inventory of the machine answers 409, and the next
+// cycle sends it.
+func (s *httpSender) sendHeartbeat(ctx context.Context, info MachineInfo, body []byte, version int) error {
+	resp, err := s.post(ctx, body, version)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusBadRequest {
+		forgetInventory()
+		return fmt.Errorf("server did not apply the heartbeat (status %d); sending the full inventory next cycle", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		err := fmt.Errorf("server returned status: %d", resp.StatusCode)
+		Log.Error(err)
+		return err
+	}
+	recordServerSchema(resp.Header)
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		recordServerTime(date)
+	}
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		deferReports(d, time.Now())
+	}
+	// Heartbeat replies carry tasks, config and backoff like full ones
+	if body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckinResponseSize)); err == nil {
+		applyCheckinResponse(body, info)
+	}
+	ackTaskResults(info.TaskResults)
+	recordReportSent(time.Now())
+	Log.Info("Heartbeat sent successfully")
+	return nil
+}
+
+// post sends a JSON body of the given schema version, signed with
+// TATUSCAN_HMAC_SECRET and gzip-compressed from TATUSCAN_COMPRESS_THRESHOLD
+// bytes
+func (s *httpSender) post(ctx context.Context, data []byte, version int) (*http.Response, error) {
+	var signature string
+	if Cfg.HMACSecret != "" {
+		signature = PayloadSignature(data, Cfg.HMACSecret)
+	}
+	compressed := Cfg.CompressThreshold > 0 && len(data) >= Cfg.CompressThreshold
+	if compressed {
+		size := len(data)
+		var err error
+		if data, err = gzipBytes(data); err != nil {
+			Log.Errorf("Error to compress data: %v", err)
+			return nil, err
+		}
+		Log.Debugf("Payload compressed from %d to %d bytes", size, len(data))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewBuffer(data))
+	if err != nil {
+		Log.Errorf("Error to create HTTP request: %v", err)
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SchemaHeader, strconv.Itoa(version))
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+	setAgentHeaders(req)
+	if Cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+Cfg.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		Log.Errorf("Error to send data: %v", err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// gzipBytes compresses data with gzip
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
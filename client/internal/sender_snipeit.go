@@ -0,0 +1,230 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterSender("snipeit", newSnipeITSender)
+	RegisterSender("snipeit+http", newSnipeITSender)
+	RegisterSender("snipeit+https", newSnipeITSender)
+}
+
+// snipeITFieldValues are the payload values TATUSCAN_SNIPEIT_FIELDS can map
+// to Snipe-IT custom fields
+var snipeITFieldValues = map[string]func(info MachineInfo) any{
+	"machine_id": func(info MachineInfo) any { return info.MachineID },
+	"agent_id":   func(info MachineInfo) any { return info.AgentID },
+	"ip":         func(info MachineInfo) any { return info.IP },
+	"mac":        primaryMAC,
+	"os":         func(info MachineInfo) any { return info.OS },
+	"os_version": func(info MachineInfo) any { return info.OSVersion },
+	"memory_mb":  func(info MachineInfo) any { return info.MemoryTotalMB },
+	"timestamp":  func(info MachineInfo) any { return info.Timestamp },
+}
+
+// snipeITSender creates or updates the hardware asset of the machine
+// through the Snipe-IT REST API, matched by serial number (or by asset tag
+// when there is none)
+type snipeITSender struct {
+	api    string
+	client *http.Client
+}
+
+// newSnipeITSender accepts snipeit://host/<root>, calling <root>/api/v1 over
+// HTTPS (snipeit+http:// for plain HTTP) with TATUSCAN_TOKEN as the API
+// key. New assets need TATUSCAN_SNIPEIT_MODEL_ID and _STATUS_ID.
+func newSnipeITSender(target *url.URL) (Sender, error) {
+	api, err := integrationURL(target, "/api/v1")
+	if err != nil {
+		return nil, err
+	}
+	if Cfg.Token == "" {
+		return nil, errors.New("TATUSCAN_TOKEN must hold a Snipe-IT API key")
+	}
+	Log.Debugf("Snipe-IT API: %s", api)
+	return &snipeITSender{api: api, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// snipeITReply is the part of the Snipe-IT answers the sender reads:
+// byserial lists rows, bytag returns the asset itself, and errors come back
+// with HTTP 200 and "status": "error"
+type snipeITReply struct {
+	Status   string          `json:"status"`
+	Messages json.RawMessage `json:"messages"`
+	ID       int             `json:"id"`
+	Rows     []struct {
+		ID int `json:"id"`
+	} `json:"rows"`
+}
+
+// assetID returns the ID of the asset found by a lookup, 0 when none
+func (r snipeITReply) assetID() int {
+	if r.Status == "error" {
+		return 0
+	}
+	if len(r.Rows) > 0 {
+		return r.Rows[0].ID
+	}
+	return r.ID
+}
+
+// Send updates the asset when it exists, or creates it
+func (s *snipeITSender) Send(ctx context.Context, info MachineInfo) error {
+	if info.Heartbeat {
+		Log.Debug("Heartbeat not sent to Snipe-IT, which only takes inventories")
+		return nil
+	}
+	Log.Info("Sending inventory to Snipe-IT")
+	tag := snipeITAssetTag(info)
+	lookup := "/hardware/bytag/" + url.PathEscape(tag)
+	if info.SerialNumber != "" {
+		lookup = "/hardware/byserial/" + url.PathEscape(info.SerialNumber)
+	}
+	found, err := s.call(ctx, http.MethodGet, lookup, nil)
+	if err != nil {
+		Log.Errorf("Error to look the asset up in Snipe-IT: %v", err)
+		return err
+	}
+
+	asset := snipeITAsset(info)
+	var reply snipeITReply
+	if id := found.assetID(); id > 0 {
+		reply, err = s.call(ctx, http.MethodPatch, fmt.Sprintf("/hardware/%d", id), asset)
+	} else {
+		if Cfg.SnipeITModelID == 0 || Cfg.SnipeITStatusID == 0 {
+			return fmt.Errorf("asset %s not found in Snipe-IT; set TATUSCAN_SNIPEIT_MODEL_ID and TATUSCAN_SNIPEIT_STATUS_ID to create it", tag)
+		}
+		asset["asset_tag"] = tag
+		asset["model_id"] = Cfg.SnipeITModelID
+		asset["status_id"] = Cfg.SnipeITStatusID
+		reply, err = s.call(ctx, http.MethodPost, "/hardware", asset)
+	}
+	if err == nil && reply.Status == "error" {
+		err = fmt.Errorf("Snipe-IT rejected the asset: %s", reply.Messages)
+	}
+	if err != nil {
+		Log.Errorf("Error to send inventory to Snipe-IT: %v", err)
+		return err
+	}
+	ackTaskResults(info.TaskResults)
+	ackMachineIDMigration(info.PreviousID)
+	ackInventory(info)
+	Log.Info("Inventory sent to Snipe-IT")
+	return nil
+}
+
+// call sends a request to the API, failing on transport errors and non-200
+// answers; the reply status is left to the caller
+func (s *snipeITSender) call(ctx context.Context, method, path string, body any) (snipeITReply, error) {
+	var reply snipeITReply
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return reply, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.api+path, reader)
+	if err != nil {
+		return reply, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	setAgentHeaders(req)
+	req.Header.Set("Authorization", "Bearer "+Cfg.Token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return reply, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxCheckinResponseSize))
+	json.Unmarshal(data, &reply)
+	if resp.StatusCode != http.StatusOK {
+		return reply, fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+	}
+	return reply, nil
+}
+
+// snipeITAsset maps the payload to the asset fields updated on every report
+func snipeITAsset(info MachineInfo) map[string]any {
+	asset := map[string]any{"name": info.Hostname}
+	if info.SerialNumber != "" {
+		asset["serial"] = info.SerialNumber
+	}
+	for key, column := range Cfg.SnipeITFields {
+		if value, ok := snipeITFieldValues[key]; ok {
+			asset[column] = value(info)
+		}
+	}
+	return asset
+}
+
+// snipeITAssetTag is the tag of created assets: the serial number, or the
+// hostname of machines without one
+func snipeITAssetTag(info MachineInfo) string {
+	return stringOr(info.SerialNumber, info.Hostname)
+}
+
+// primaryMAC returns the MAC of the interface holding the primary IP
+func primaryMAC(info MachineInfo) any {
+	for _, a := range info.Addresses {
+		if a.IP != info.IP {
+			continue
+		}
+		for _, d := range info.Interfaces {
+			if d.Name == a.Interface {
+				return strings.ToUpper(strings.ReplaceAll(d.MAC, "-", ":"))
+			}
+		}
+	}
+	return ""
+}
+
+// parseSnipeITFields parses TATUSCAN_SNIPEIT_FIELDS, comma-separated
+// value=column items mapping payload values to custom field columns
+func parseSnipeITFields(value string) map[string]string {
+	fields := map[string]string{}
+	for _, item := range splitList(value) {
+		key, column, ok := strings.Cut(item, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, known := snipeITFieldValues[key]; !ok || !known || strings.TrimSpace(column) == "" {
+			if Log != nil {
+				Log.Warnf("Invalid Snipe-IT field mapping %q ignored", item)
+			}
+			continue
+		}
+		fields[key] = strings.TrimSpace(column)
+	}
+	return fields
+}
+
+// parseID parses a positive numeric ID, returning 0 when empty or invalid
+func parseID(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		if Log != nil {
+			Log.Warnf("Invalid ID %q ignored", value)
+		}
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSnipeITSender(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Token = "api-key"
+	Cfg.SnipeITFields = parseSnipeITFields("ip=_snipeit_ip_address_2, mac=_snipeit_mac_address_1, bogus=x")
+
+	assets := map[string]int{"SN-OLD": 7}
+	var requests []string
+	var written map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v1/hardware/byserial/"):
+			if id, ok := assets[strings.TrimPrefix(r.URL.Path, "/api/v1/hardware/byserial/")]; ok {
+				json.NewEncoder(w).Encode(map[string]any{"total": 1, "rows": []map[string]any{{"id": id}}})
+			} else {
+				w.Write([]byte(`{"total": 0, "rows": []}`))
+			}
+		case r.Method == http.MethodPatch || r.Method == http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&written)
+			w.Write([]byte(`{"status": "success", "messages": "ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(strings.Replace(srv.URL, "http://", "snipeit+http://", 1))
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	info := MachineInfo{
+		Hostname:     "lab-01",
+		IP:           "192.168.1.10",
+		SerialNumber: "SN-OLD",
+		Interfaces:   []InterfaceDetail{{Name: "eth0", MAC: "aa-bb-cc-00-11-22"}},
+		Addresses:    []InterfaceAddress{{Interface: "eth0", IP: "192.168.1.10"}},
+	}
+	if err := sender.Send(context.Background(), info); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if requests[len(requests)-1] != "PATCH /api/v1/hardware/7" || written["name"] != "lab-01" ||
+		written["_snipeit_ip_address_2"] != "192.168.1.10" || written["_snipeit_mac_address_1"] != "AA:BB:CC:00:11:22" || written["x"] != nil {
+		t.Errorf("unexpected update: %v %v", requests, written)
+	}
+
+	// Unknown assets are only created with a model and a status
+	info.SerialNumber = "SN-NEW"
+	if err := sender.Send(context.Background(), info); err == nil || !strings.Contains(err.Error(), "TATUSCAN_SNIPEIT_MODEL_ID") {
+		t.Errorf("expected a missing model error, got %v", err)
+	}
+	Cfg.SnipeITModelID, Cfg.SnipeITStatusID = 3, 2
+	if err := sender.Send(context.Background(), info); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if requests[len(requests)-1] != "POST /api/v1/hardware" || written["asset_tag"] != "SN-NEW" || written["model_id"] != float64(3) || written["status_id"] != float64(2) {
+		t.Errorf("unexpected creation: %v %v", requests, written)
+	}
+}
+
+func TestSnipeITSenderNeedsToken(t *testing.T) {
+	setupTestAgent(t)
+	if _, err := NewSender("snipeit://assets.example.com"); err == nil {
+		t.Error("expected an error without TATUSCAN_TOKEN")
+	}
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewSenderUnsupportedScheme(t *testing.T) {
+	setupTestAgent(t)
+	_, err := NewSender("ftp://example.com")
+	if err == nil || !strings.Contains(err.Error(), "unsupported destination scheme") {
+		t.Fatalf("expected unsupported scheme error, got %v", err)
+	}
+}
+
+func TestRegisterSender(t *testing.T) {
+	setupTestAgent(t)
+	var got MachineInfo
+	RegisterSender("test", func(target *url.URL) (Sender, error) {
+		return senderFunc(func(ctx context.Context, info MachineInfo) error {
+			got = info
+			return nil
+		}), nil
+	})
+	t.Cleanup(func() {
+		sendersMu.Lock()
+		delete(senders, "test")
+		sendersMu.Unlock()
+	})
+
+	sender, err := NewSender("TEST://anything")
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil || got.Hostname != "lab-01" {
+		t.Errorf("custom sender not used: %v, %+v", err, got)
+	}
+}
+
+// senderFunc adapts a function to the Sender interface
+type senderFunc func(ctx context.Context, info MachineInfo) error
+
+func (f senderFunc) Send(ctx context.Context, info MachineInfo) error { return f(ctx, info) }
+
+func TestHTTPSender(t *testing.T) {
+	setupTestAgent(t)
+	SetBuildInfo(BuildInfo{Version: "1.2.3"})
+	t.Cleanup(func() { SetBuildInfo(BuildInfo{}) })
+
+	var received MachineInfo
+	var path, agent, auth string
+	status := http.StatusCreated
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, agent, auth = r.URL.Path, r.UserAgent(), r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if path != "/api/machines" || received.Hostname != "lab-01" || !strings.HasPrefix(agent, "TatuScan/1.2.3 (") || auth != "" {
+		t.Errorf("unexpected request: path=%s agent=%s auth=%s payload=%+v", path, agent, auth, received)
+	}
+
+	Cfg.Token = "s3cret"
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if auth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+
+	status = http.StatusBadRequest
+	if err := sender.Send(context.Background(), MachineInfo{}); err == nil {
+		t.Error("expected an error for status 400")
+	}
+}
+
+func TestHTTPSenderCompression(t *testing.T) {
+	setupTestAgent(t)
+	var received MachineInfo
+	var encoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		body := r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		json.NewDecoder(body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	Cfg.CompressThreshold = 1 << 20
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil || encoding != "" {
+		t.Errorf("small payload: err=%v Content-Encoding=%q", err, encoding)
+	}
+	Cfg.CompressThreshold = 10
+	received = MachineInfo{}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-02"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if encoding != "gzip" || received.Hostname != "lab-02" {
+		t.Errorf("Content-Encoding=%q payload=%+v", encoding, received)
+	}
+}
+
+func TestHTTPSenderSignature(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.HMACSecret = "shared"
+	Cfg.CompressThreshold = 10
+	var valid bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		valid = VerifyPayloadSignature(body, r.Header.Get(SignatureHeader), "shared")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	sender, err := NewSender(srv.URL)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), MachineInfo{Hostname: "lab-01"}); err != nil || !valid {
+		t.Errorf("signature not verified over the uncompressed body: err=%v", err)
+	}
+	if VerifyPayloadSignature([]byte(`{"hostname":"spoofed"}`), PayloadSignature([]byte(`{"hostname":"lab-01"}`), "shared"), "shared") {
+		t.Error("signature of another body accepted")
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	setupTestAgent(t)
+	path := filepath.Join(t.TempDir(), "payloads.jsonl")
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed // file:///C:/... on Windows
+	}
+	sender, err := NewSender("file://" + slashed)
+	if err != nil {
+		t.Fatalf("NewSender: %v", err)
+	}
+	for _, host := range []string{"lab-01", "lab-02"} {
+		if err := sender.Send(context.Background(), MachineInfo{Hostname: host}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var hosts []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var info MachineInfo
+		if err := json.Unmarshal(scanner.Bytes(), &info); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		hosts = append(hosts, info.Hostname)
+	}
+	if strings.Join(hosts, ",") != "lab-01,lab-02" {
+		t.Errorf("hosts = %v", hosts)
+	}
+}
+
+func TestUserAgentBuild(t *testing.T) {
+	SetBuildInfo(BuildInfo{Version: "1.4.0", Commit: "3f2a9c1d0b7e55aa", BuildDate: "2026-10-01T12:00:00Z"})
+	t.Cleanup(func() { SetBuildInfo(BuildInfo{}) })
+	if got := userAgent(); got != "TatuScan/1.4.0 ("+runtime.GOOS+"; 3f2a9c1d0b7e)" {
+		t.Errorf("userAgent() = %q", got)
+	}
+	if AgentBuild().BuildDate != "2026-10-01T12:00:00Z" {
+		t.Errorf("build date lost: %+v", AgentBuild())
+	}
+}
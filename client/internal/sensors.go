@@ -0,0 +1,75 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"math"
+	"sort"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// SensorInfo holds hardware temperature and fan readings
+type SensorInfo struct {
+	Temperatures []TemperatureReading `json:"temperatures,omitempty"`
+	Fans         []FanReading         `json:"fans,omitempty"`
+}
+
+// TemperatureReading is a temperature sensor in degrees Celsius
+type TemperatureReading struct {
+	Sensor   string  `json:"sensor"`
+	Celsius  float64 `json:"celsius"`
+	High     float64 `json:"high,omitempty"`
+	Critical float64 `json:"critical,omitempty"`
+}
+
+// FanReading is a fan speed in RPM
+type FanReading struct {
+	Sensor string `json:"sensor"`
+	RPM    int64  `json:"rpm"`
+}
+
+// sensorTemperatures is replaceable in tests
+var sensorTemperatures = host.SensorsTemperatures
+
+// temperatureReadings converts gopsutil readings, dropping empty sensors
+func temperatureReadings(stats []host.TemperatureStat) []TemperatureReading {
+	var readings []TemperatureReading
+	for _, s := range stats {
+		if s.SensorKey == "" || s.Temperature <= 0 {
+			continue
+		}
+		readings = append(readings, TemperatureReading{
+			Sensor:   s.SensorKey,
+			Celsius:  math.Round(s.Temperature*10) / 10,
+			High:     s.High,
+			Critical: s.Critical,
+		})
+	}
+	sort.Slice(readings, func(i, j int) bool { return readings[i].Sensor < readings[j].Sensor })
+	return readings
+}
+
+// getSensorInfo returns temperatures and fan speeds when TATUSCAN_SENSORS is
+// enabled, or nil when disabled or nothing could be read
+func getSensorInfo() *SensorInfo {
+	if !Cfg.Sensors {
+		return nil
+	}
+	Log.Debug("Collecting hardware sensors")
+	info := &SensorInfo{}
+
+	// gopsutil may return partial readings together with an error
+	stats, err := sensorTemperatures()
+	if err != nil {
+		Log.Debugf("Error to collect temperatures: %v", err)
+	}
+	info.Temperatures = temperatureReadings(stats)
+	info.Fans = platformFans()
+
+	if len(info.Temperatures) == 0 && len(info.Fans) == 0 {
+		Log.Debug("No hardware sensor available")
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+func TestTemperatureReadings(t *testing.T) {
+	readings := temperatureReadings([]host.TemperatureStat{
+		{SensorKey: "nvme_composite", Temperature: 41.85, High: 84.85, Critical: 89.85},
+		{SensorKey: "coretemp_package_id_0", Temperature: 55},
+		{SensorKey: "acpitz", Temperature: 0},
+		{SensorKey: "", Temperature: 30},
+	})
+	if len(readings) != 2 {
+		t.Fatalf("expected 2 readings, got %+v", readings)
+	}
+	if readings[0].Sensor != "coretemp_package_id_0" || readings[1].Sensor != "nvme_composite" {
+		t.Errorf("unexpected order: %+v", readings)
+	}
+	if readings[1].Celsius != 41.9 || readings[1].Critical != 89.85 {
+		t.Errorf("unexpected reading: %+v", readings[1])
+	}
+}
+
+func TestGetSensorInfoDisabled(t *testing.T) {
+	setupTestAgent(t)
+	if info := getSensorInfo(); info != nil {
+		t.Errorf("expected nil when disabled, got %+v", info)
+	}
+}
+
+func TestGetSensorInfo(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.Sensors = true
+	original := sensorTemperatures
+	t.Cleanup(func() { sensorTemperatures = original })
+
+	// Partial readings are kept even when gopsutil reports an error
+	sensorTemperatures = func() ([]host.TemperatureStat, error) {
+		return []host.TemperatureStat{{SensorKey: "k10temp_tctl", Temperature: 62.5}}, errors.New("partial")
+	}
+	info := getSensorInfo()
+	if info == nil || len(info.Temperatures) != 1 || info.Temperatures[0].Celsius != 62.5 {
+		t.Fatalf("unexpected sensors: %+v", info)
+	}
+
+	sensorTemperatures = func() ([]host.TemperatureStat, error) { return nil, errors.New("not implemented") }
+	if info := getSensorInfo(); info != nil {
+		t.Errorf("expected nil without readings, got %+v", info)
+	}
+}
+
+func TestPlatformFansHwmon(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("hwmon is Linux only")
+	}
+	setupTestAgent(t)
+	write := func(rel, value string) {
+		path := filepath.Join(sysfsRoot, "class", "hwmon", rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("hwmon2/name", "thinkpad")
+	write("hwmon2/fan1_input", "2400")
+	write("hwmon3/name", "nct6775")
+	write("hwmon3/fan2_input", "870")
+	write("hwmon3/fan2_label", "CPU_FAN")
+	write("hwmon3/fan3_input", "garbage")
+
+	fans := platformFans()
+	want := []FanReading{{Sensor: "nct6775_CPU_FAN", RPM: 870}, {Sensor: "thinkpad_fan1", RPM: 2400}}
+	if len(fans) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, fans)
+	}
+	for i := range want {
+		if fans[i] != want[i] {
+			t.Errorf("fan %d: expected %+v, got %+v", i, want[i], fans[i])
+		}
+	}
+}
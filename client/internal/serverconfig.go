@@ -0,0 +1,127 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverConfigFile keeps the last applied server document in the state
+// directory, so it still applies after a restart without the server
+const serverConfigFile = "server-config.json"
+
+// minServerInterval keeps a server mistake from turning the fleet into a
+// load test
+const minServerInterval = 10 * time.Second
+
+// ServerConfig is the configuration document a server may return in its
+// reply, centrally managing the agents without rerunning installers
+type ServerConfig struct {
+	Interval   string          `json:"interval,omitempty"`   // Go duration, e.g. "5m"
+	Collectors map[string]bool `json:"collectors,omitempty"` // optional collectors by name
+	Tags       []string        `json:"tags,omitempty"`       // replace TATUSCAN_TAGS
+}
+
+// serverCollectorSettings maps the collectors a server may toggle to their
+// settings; collectors that need local parameters (paths, endpoints) are
+// left to the local configuration
+var serverCollectorSettings = map[string]string{
+	"disks":             "TATUSCAN_DISKS",
+	"endpoint_security": "TATUSCAN_ENDPOINT_SECURITY",
+	"listeners":         "TATUSCAN_LISTENERS",
+	"packages":          "TATUSCAN_PACKAGES",
+	"sensors":           "TATUSCAN_SENSORS",
+	"updates":           "TATUSCAN_UPDATES",
+}
+
+// settings validates the document and converts it to TATUSCAN_* settings;
+// any invalid entry rejects the whole document
+func (c ServerConfig) settings() (map[string]string, error) {
+	settings := make(map[string]string)
+	if c.Interval != "" {
+		d, err := time.ParseDuration(c.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q", c.Interval)
+		}
+		if d < minServerInterval {
+			return nil, fmt.Errorf("interval %s below the %s minimum", d, minServerInterval)
+		}
+		settings["TATUSCAN_INTERVAL"] = d.String()
+	}
+	names := make([]string, 0, len(c.Collectors))
+	for name := range c.Collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key, ok := serverCollectorSettings[name]
+		if !ok {
+			return nil, fmt.Errorf("collector %q cannot be managed by the server", name)
+		}
+		settings[key] = strconv.FormatBool(c.Collectors[name])
+	}
+	if c.Tags != nil {
+		for _, tag := range c.Tags {
+			if strings.ContainsAny(tag, ",\n") {
+				return nil, fmt.Errorf("invalid tag %q", tag)
+			}
+		}
+		settings["TATUSCAN_TAGS"] = strings.Join(c.Tags, ",")
+	}
+	return settings, nil
+}
+
+// applyServerConfig validates, applies and persists a server document,
+// keeping the current settings when it is invalid; the caller holds
+// configLayers
+func applyServerConfig(doc ServerConfig) bool {
+	settings, err := doc.settings()
+	if err != nil {
+		Log.Warnf("Server configuration rejected: %v", err)
+		return false
+	}
+	if configLayers.server != nil && maps.Equal(settings, configLayers.server) {
+		return false
+	}
+	Log.Infof("Applying %d setting(s) from the server configuration", len(settings))
+	configLayers.server = settings
+	data, err := json.Marshal(doc)
+	if err == nil {
+		err = writeFileAtomic(statePath(serverConfigFile), data, 0o644)
+	}
+	if err != nil {
+		Log.Warnf("Error to persist the server configuration: %v", err)
+	}
+	return true
+}
+
+// loadServerConfig returns the settings of the persisted server document,
+// empty when there is none or it no longer validates
+func loadServerConfig() map[string]string {
+	data, err := os.ReadFile(statePath(serverConfigFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			Log.Warnf("Error to read the server configuration: %v", err)
+		}
+		return map[string]string{}
+	}
+	var doc ServerConfig
+	if err := json.Unmarshal(data, &doc); err != nil {
+		Log.Warnf("Ignoring invalid server configuration %s", statePath(serverConfigFile))
+		return map[string]string{}
+	}
+	settings, err := doc.settings()
+	if err != nil {
+		Log.Warnf("Ignoring persisted server configuration: %v", err)
+		return map[string]string{}
+	}
+	return settings
+}
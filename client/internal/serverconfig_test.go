@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestServerConfigSettings(t *testing.T) {
+	doc := ServerConfig{
+		Interval:   "5m",
+		Collectors: map[string]bool{"sensors": true, "updates": false},
+		Tags:       []string{"lab", "site=lab3"},
+	}
+	got, err := doc.settings()
+	want := map[string]string{
+		"TATUSCAN_INTERVAL": "5m0s",
+		"TATUSCAN_SENSORS":  "true",
+		"TATUSCAN_UPDATES":  "false",
+		"TATUSCAN_TAGS":     "lab,site=lab3",
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("settings = %v, %v; want %v", got, err, want)
+	}
+
+	for _, bad := range []ServerConfig{
+		{Interval: "soon"},
+		{Interval: "1s"},
+		{Collectors: map[string]bool{"warranty": true}},
+		{Tags: []string{"a,b"}},
+	} {
+		if _, err := bad.settings(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestApplyCheckinResponseConfig(t *testing.T) {
+	setupTestAgent(t)
+	stateDir := Cfg.StateDir
+	configLayers.site = map[string]string{"TATUSCAN_STATE_DIR": stateDir}
+	t.Cleanup(func() { configLayers.site, configLayers.server, configLayers.rollout = nil, nil, nil })
+
+	applyCheckinResponse([]byte(`{"config": {"interval": "2m", "collectors": {"sensors": true}, "tags": ["lab"]}}`), MachineInfo{})
+	if Cfg.Interval != 2*time.Minute || !Cfg.Sensors || !reflect.DeepEqual(Cfg.Tags, []string{"lab"}) {
+		t.Fatalf("server configuration not applied: interval=%s sensors=%v tags=%v", Cfg.Interval, Cfg.Sensors, Cfg.Tags)
+	}
+
+	// An invalid document keeps the current settings
+	applyCheckinResponse([]byte(`{"config": {"interval": "1ms"}}`), MachineInfo{})
+	if Cfg.Interval != 2*time.Minute {
+		t.Errorf("invalid document applied, interval = %s", Cfg.Interval)
+	}
+
+	// The document survives a restart
+	configLayers.server = nil
+	if got := loadServerConfig(); got["TATUSCAN_SENSORS"] != "true" || got["TATUSCAN_INTERVAL"] != "2m0s" {
+		t.Errorf("persisted settings = %v", got)
+	}
+
+	// An empty document withdraws the settings
+	applyCheckinResponse([]byte(`{"config": {}}`), MachineInfo{})
+	if Cfg.Sensors || Cfg.Interval != 0 {
+		t.Errorf("settings not withdrawn: interval=%s sensors=%v", Cfg.Interval, Cfg.Sensors)
+	}
+}
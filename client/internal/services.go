@@ -0,0 +1,51 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Service is a Windows service or a systemd service unit
+type Service struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	State       string `json:"state"`
+	StartType   string `json:"start_type,omitempty"`
+	Account     string `json:"account,omitempty"`
+}
+
+// serviceSelected reports whether a service name matches the configured
+// names or glob patterns ("*" selects every service), case-insensitively
+func serviceSelected(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if ok, err := path.Match(strings.ToLower(pattern), name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getServices returns the services selected by TATUSCAN_SERVICES plus, where
+// the platform supports it, every failed service, sorted by name
+func getServices() []Service {
+	if len(Cfg.Services) == 0 && !reportsFailedServices {
+		return nil
+	}
+	all, err := platformServices()
+	if err != nil {
+		Log.Warnf("Error to collect services: %v", err)
+		return nil
+	}
+	var selected []Service
+	for _, s := range all {
+		if s.State == "failed" || serviceSelected(s.Name, Cfg.Services) {
+			selected = append(selected, s)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+	return selected
+}
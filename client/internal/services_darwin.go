@@ -0,0 +1,13 @@
+//go:build darwin
+
+package internal
+
+import "errors"
+
+// reportsFailedServices is false: launchd jobs are not inventoried
+const reportsFailedServices = false
+
+// platformServices is not implemented for launchd
+func platformServices() ([]Service, error) {
+	return nil, errors.New("service inventory is not available on macOS")
+}
@@ -0,0 +1,69 @@
+//go:build linux
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reportsFailedServices is true: failed systemd units are always reported
+const reportsFailedServices = true
+
+// runSystemctl runs systemctl; tests replace it to replay recorded output
+var runSystemctl = func(args ...string) ([]byte, error) {
+	return runCommand("systemctl", args...)
+}
+
+// parseSystemctlUnits parses `systemctl list-units --plain --no-legend`
+// lines (UNIT LOAD ACTIVE SUB DESCRIPTION) into services keyed by unit
+func parseSystemctlUnits(output string) []Service {
+	var services []Service
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasSuffix(fields[0], ".service") {
+			continue
+		}
+		services = append(services, Service{
+			Name:        strings.TrimSuffix(fields[0], ".service"),
+			DisplayName: strings.Join(fields[4:], " "),
+			State:       fields[2],
+		})
+	}
+	return services
+}
+
+// parseSystemctlUnitFiles parses `systemctl list-unit-files --no-legend`
+// lines (UNIT STATE [PRESET]) into start types keyed by service name
+func parseSystemctlUnitFiles(output string) map[string]string {
+	startTypes := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasSuffix(fields[0], ".service") {
+			continue
+		}
+		startTypes[strings.TrimSuffix(fields[0], ".service")] = fields[1]
+	}
+	return startTypes
+}
+
+// platformServices lists systemd service units through systemctl
+func platformServices() ([]Service, error) {
+	Log.Debug("Listing systemd units via systemctl")
+	units, err := runSystemctl("list-units", "--type=service", "--all", "--plain", "--no-legend", "--no-pager")
+	if err != nil {
+		return nil, fmt.Errorf("systemctl list-units: %w", err)
+	}
+	services := parseSystemctlUnits(string(units))
+
+	files, err := runSystemctl("list-unit-files", "--type=service", "--no-legend", "--no-pager")
+	if err != nil {
+		Log.Debugf("Error to list systemd unit files: %v", err)
+		return services, nil
+	}
+	startTypes := parseSystemctlUnitFiles(string(files))
+	for i := range services {
+		services[i].StartType = startTypes[services[i].Name]
+	}
+	return services, nil
+}
@@ -0,0 +1,25 @@
+//go:build linux
+
+package internal
+
+import "testing"
+
+func TestParseSystemctlUnits(t *testing.T) {
+	units := parseSystemctlUnits("ssh.service loaded active running OpenBSD Secure Shell server\n" +
+		"backup.service loaded failed failed Nightly backup\n" +
+		"dev-sda1.device loaded active plugged /dev/sda1\n")
+	if len(units) != 2 {
+		t.Fatalf("expected 2 services, got %+v", units)
+	}
+	if units[0] != (Service{Name: "ssh", DisplayName: "OpenBSD Secure Shell server", State: "active"}) {
+		t.Errorf("unexpected unit: %+v", units[0])
+	}
+	if units[1].Name != "backup" || units[1].State != "failed" {
+		t.Errorf("unexpected unit: %+v", units[1])
+	}
+
+	startTypes := parseSystemctlUnitFiles("ssh.service enabled enabled\nbackup.service disabled enabled\n")
+	if startTypes["ssh"] != "enabled" || startTypes["backup"] != "disabled" {
+		t.Errorf("unexpected start types: %v", startTypes)
+	}
+}
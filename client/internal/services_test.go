@@ -0,0 +1,28 @@
+package internal
+
+import "testing"
+
+func TestServiceSelected(t *testing.T) {
+	patterns := []string{"WinDefend", "Veeam*", "sense"}
+	tests := map[string]bool{
+		"windefend":              true,
+		"VeeamEndpointBackupSvc": true,
+		"Sense":                  true,
+		"Spooler":                false,
+	}
+	for name, want := range tests {
+		if got := serviceSelected(name, patterns); got != want {
+			t.Errorf("serviceSelected(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !serviceSelected("Spooler", []string{"*"}) {
+		t.Error(`"*" must select every service`)
+	}
+}
+
+func TestGetServicesDisabled(t *testing.T) {
+	setupTestAgent(t)
+	if services := getServices(); services != nil {
+		t.Errorf("expected nil when TATUSCAN_SERVICES is empty, got %+v", services)
+	}
+}
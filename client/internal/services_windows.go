@@ -0,0 +1,46 @@
+//go:build windows
+
+package internal
+
+import (
+	"strings"
+
+	"github.com/StackExchange/wmi"
+)
+
+// reportsFailedServices is false: Windows services have no failed state
+const reportsFailedServices = false
+
+// platformServices lists services through Win32_Service
+func platformServices() ([]Service, error) {
+	type win32Service struct {
+		Name        string
+		DisplayName *string
+		State       *string
+		StartMode   *string
+		StartName   *string
+	}
+	Log.Debug("Querying Win32_Service via WMI")
+	var dst []win32Service
+	if err := wmiQuery(wmi.CreateQuery(&dst, "", "Win32_Service"), &dst, ""); err != nil {
+		return nil, err
+	}
+	services := make([]Service, 0, len(dst))
+	for _, s := range dst {
+		service := Service{Name: s.Name}
+		if s.DisplayName != nil {
+			service.DisplayName = *s.DisplayName
+		}
+		if s.State != nil {
+			service.State = strings.ToLower(*s.State)
+		}
+		if s.StartMode != nil {
+			service.StartType = strings.ToLower(*s.StartMode)
+		}
+		if s.StartName != nil {
+			service.Account = *s.StartName
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
@@ -0,0 +1,32 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// defaultShutdownTimeout bounds the wait for an in-flight cycle and the
+// final flush on stop, below the stop timeouts of the service managers
+const defaultShutdownTimeout = 15 * time.Second
+
+// DrainContext returns the context of the work done by the agent loop. It
+// outlives stop by Cfg.ShutdownTimeout, so a collection or upload in flight
+// when the agent is asked to stop completes instead of being cut short;
+// cancel releases it.
+func DrainContext(stop context.Context) (context.Context, context.CancelFunc) {
+	work, cancel := context.WithCancel(context.WithoutCancel(stop))
+	release := context.AfterFunc(stop, func() {
+		if Cfg.ShutdownTimeout <= 0 {
+			cancel()
+			return
+		}
+		timer := time.AfterFunc(Cfg.ShutdownTimeout, cancel)
+		context.AfterFunc(work, func() { timer.Stop() })
+	})
+	return work, func() {
+		release()
+		cancel()
+	}
+}
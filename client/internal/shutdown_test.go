@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDrainContext(t *testing.T) {
+	setupTestAgent(t)
+	Cfg.ShutdownTimeout = 50 * time.Millisecond
+
+	stop, cancel := context.WithCancel(context.Background())
+	work, release := DrainContext(stop)
+	defer release()
+
+	cancel()
+	select {
+	case <-work.Done():
+		t.Fatal("work cancelled with the stop, not after the grace period")
+	case <-time.After(20 * time.Millisecond):
+	}
+	select {
+	case <-work.Done():
+	case <-time.After(time.Second):
+		t.Fatal("work not cancelled after the grace period")
+	}
+
+	// Released before any stop
+	work, release = DrainContext(context.Background())
+	release()
+	if work.Err() == nil {
+		t.Error("release must cancel the work context")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the payload, as sha256=<hex>
+const SignatureHeader = "X-TatuScan-Signature"
+
+// PayloadSignature returns the SignatureHeader value of body under secret;
+// the body is signed before compression, as the server reads it
+func PayloadSignature(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPayloadSignature checks a SignatureHeader value in constant time
+func VerifyPayloadSignature(body []byte, signature, secret string) bool {
+	return hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(PayloadSignature(body, secret)))
+}
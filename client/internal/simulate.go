@@ -0,0 +1,143 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// simulatedProfile is a hardware/OS combination used by synthetic machines
+type simulatedProfile struct {
+	OS            string
+	OSVersion     string
+	Manufacturer  string
+	Model         string
+	Interface     string
+	Driver        string
+	MemoryTotalMB uint64
+}
+
+// simulatedProfiles mimics a typical lab/office fleet
+var simulatedProfiles = []simulatedProfile{
+	{"windows", "Windows 11 Pro 23H2 (Build 22631)", "Dell Inc.", "OptiPlex 7010", "Ethernet", "Intel(R) Ethernet Connection (17) I219-LM", 16384},
+	{"windows", "Windows 10 Pro 22H2 (Build 19045)", "HP", "HP ProDesk 400 G7 Microtower PC", "Ethernet", "Realtek PCIe GbE Family Controller", 8192},
+	{"windows", "Windows 11 Education 23H2 (Build 22631)", "LENOVO", "ThinkCentre M70q", "Ethernet", "Intel(R) Ethernet Connection (14) I219-V", 16384},
+	{"linux", "Ubuntu 22.04.4 LTS", "Dell Inc.", "OptiPlex 7090", "enp0s31f6", "e1000e", 16384},
+	{"linux", "Debian GNU/Linux 12 (bookworm)", "LENOVO", "ThinkCentre M720q", "eno1", "e1000e", 8192},
+	{"linux", "Rocky Linux 9.3 (Blue Onyx)", "Supermicro", "SYS-5019C-M", "eno1", "igb", 32768},
+	{"darwin", "macOS 14.4 (23E214)", "Apple Inc.", "Mac14,3", "en0", "", 16384},
+}
+
+// SimulatedMachine generates synthetic payloads for one fake machine. Its
+// identity (MACs, MachineID, hostname, serial) is stable for a given seed
+// and index; metrics drift between calls to Next.
+type SimulatedMachine struct {
+	mu      sync.Mutex
+	rng     *rand.Rand
+	profile simulatedProfile
+	base    MachineInfo
+	cpu     float64
+	memUsed float64
+}
+
+// NewSimulatedFleet creates n synthetic machines derived from seed
+func NewSimulatedFleet(n int, seed int64) []*SimulatedMachine {
+	fleet := make([]*SimulatedMachine, n)
+	for i := range fleet {
+		fleet[i] = newSimulatedMachine(seed, i)
+	}
+	return fleet
+}
+
+// newSimulatedMachine builds the stable identity of machine index
+func newSimulatedMachine(seed int64, index int) *SimulatedMachine {
+	rng := rand.New(rand.NewSource(seed*1_000_003 + int64(index)))
+	profile := simulatedProfiles[rng.Intn(len(simulatedProfiles))]
+
+	// Globally administered unicast MAC: clear the two low bits of octet 0
+	hw := make(net.HardwareAddr, 6)
+	rng.Read(hw)
+	hw[0] &^= 0x03
+	mac := hw.String()
+	if profile.OS == "windows" {
+		mac = strings.ToUpper(mac)
+	}
+
+	lab := index / 250
+	ip := fmt.Sprintf("10.%d.%d.%d", 200+lab/250, lab%250, index%250+2)
+	hostname := fmt.Sprintf("sim-lab%03d-%03d", lab, index%250)
+	uuid := make([]byte, 16)
+	rng.Read(uuid)
+
+	machineID := computeMachineID([]string{mac})
+	m := &SimulatedMachine{
+		rng:     rng,
+		profile: profile,
+		cpu:     5 + rng.Float64()*20,
+		memUsed: float64(profile.MemoryTotalMB) * (0.3 + rng.Float64()*0.3),
+		base: MachineInfo{
+			SchemaVersion: SchemaVersion,
+			MachineID:     machineID,
+			Cohort:        machineCohort(machineID),
+			Hostname:      hostname,
+			IP:            ip,
+			Addresses:     []InterfaceAddress{{Interface: profile.Interface, IP: ip}},
+			Interfaces: []InterfaceDetail{{
+				Name:      profile.Interface,
+				MAC:       strings.ToLower(mac),
+				Up:        true,
+				MTU:       1500,
+				SpeedMbps: 1000,
+				Duplex:    "full",
+				Driver:    profile.Driver,
+			}},
+			OS:            profile.OS,
+			OSVersion:     profile.OSVersion,
+			SerialNumber:  fmt.Sprintf("SIM%09X", rng.Int63n(1<<36)),
+			Manufacturer:  profile.Manufacturer,
+			Model:         profile.Model,
+			ProductUUID:   fmt.Sprintf("%X-%X-%X-%X-%X", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16]),
+			MemoryTotalMB: profile.MemoryTotalMB,
+		},
+	}
+	return m
+}
+
+// MachineID returns the stable identity of the machine
+func (m *SimulatedMachine) MachineID() string {
+	return m.base.MachineID
+}
+
+// Next returns a payload with drifted CPU/memory usage and the current time
+func (m *SimulatedMachine) Next() MachineInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Random walk bounded to plausible values, with occasional spikes
+	m.cpu = clamp(m.cpu+m.rng.NormFloat64()*5, 1, 100)
+	cpu := m.cpu
+	if m.rng.Float64() < 0.02 {
+		cpu = 90 + m.rng.Float64()*10
+	}
+	total := float64(m.profile.MemoryTotalMB)
+	m.memUsed = clamp(m.memUsed+m.rng.NormFloat64()*total*0.02, total*0.15, total*0.95)
+
+	info := m.base
+	info.Addresses = append([]InterfaceAddress(nil), m.base.Addresses...)
+	info.Interfaces = append([]InterfaceDetail(nil), m.base.Interfaces...)
+	info.CPUPercent = math.Round(cpu*10) / 10
+	info.MemoryUsedMB = uint64(m.memUsed)
+	info.Timestamp = time.Now().Format(time.RFC3339)
+	return info
+}
+
+// clamp bounds v to [lo, hi]
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
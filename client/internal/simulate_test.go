@@ -0,0 +1,43 @@
+package internal
+
+import "testing"
+
+func TestSimulatedFleetStableIdentities(t *testing.T) {
+	setupTestAgent(t)
+	first := NewSimulatedFleet(300, 42)
+	second := NewSimulatedFleet(300, 42)
+
+	seen := make(map[string]bool)
+	for i := range first {
+		a, b := first[i].Next(), second[i].Next()
+		if a.MachineID != b.MachineID || a.Hostname != b.Hostname || a.SerialNumber != b.SerialNumber || a.IP != b.IP {
+			t.Fatalf("machine %d is not stable: %+v vs %+v", i, a, b)
+		}
+		if seen[a.MachineID] {
+			t.Fatalf("duplicate MachineID %s at machine %d", a.MachineID, i)
+		}
+		seen[a.MachineID] = true
+	}
+
+	other := NewSimulatedFleet(1, 7)
+	if other[0].MachineID() == first[0].MachineID() {
+		t.Error("different seeds produced the same identity")
+	}
+}
+
+func TestSimulatedMachineMetricsBounds(t *testing.T) {
+	setupTestAgent(t)
+	m := NewSimulatedFleet(1, 1)[0]
+	for i := 0; i < 500; i++ {
+		info := m.Next()
+		if info.CPUPercent < 1 || info.CPUPercent > 100 {
+			t.Fatalf("cpu_percent out of range: %v", info.CPUPercent)
+		}
+		if info.MemoryUsedMB == 0 || info.MemoryUsedMB > info.MemoryTotalMB {
+			t.Fatalf("memory_used_mb out of range: %d/%d", info.MemoryUsedMB, info.MemoryTotalMB)
+		}
+		if !isValidMachineID(info.MachineID) {
+			t.Fatalf("invalid MachineID %q", info.MachineID)
+		}
+	}
+}
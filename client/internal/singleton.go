@@ -0,0 +1,8 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import "errors"
+
+// errAlreadyRunning is returned when another agent holds the instance lock
+var errAlreadyRunning = errors.New("another TatuScan agent is running")
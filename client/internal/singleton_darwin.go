@@ -2,8 +2,7 @@
 
 package internal
 
-// ensureSingleInstance is a no-op on macOS
-func EnsureSingleInstance() {
-	Log.Debug("Single instance check is no-op on macOS; launchd manages the unique instance")
-	// No-op: launchd manages the unique instance on macOS
-}
+// runLockFile is the instance lock shared by every agent on the machine, the
+// LaunchDaemon and interactive runs alike; unprivileged runs open it
+// read-only, which is enough for flock
+const runLockFile = "/var/run/tatuscan.lock"
@@ -2,8 +2,7 @@
 
 package internal
 
-// ensureSingleInstance is a no-op on Linux
-func EnsureSingleInstance() {
-	Log.Debug("Single instance check is no-op on Linux; systemd manages the unique instance")
-	// No-op: systemd manages the unique instance on Linux
-}
+// runLockFile is the instance lock shared by every agent on the machine, the
+// systemd service and manual runs alike; unprivileged runs open it
+// read-only, which is enough for flock
+const runLockFile = "/run/tatuscan.lock"
@@ -0,0 +1,101 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// stateLockFile is the lock in the state directory, used when /run is not
+// usable (unprivileged run before the service ever started)
+const stateLockFile = "tatuscan.lock"
+
+// instanceLock is held open for the life of the process: the kernel
+// releases the flock when the process exits, even when it crashes
+var instanceLock *os.File
+
+// instanceLockPath is the instance lock taken first, runLockFile unless
+// replaced by SetInstanceLock
+var instanceLockPath = runLockFile
+
+// SetInstanceLock replaces the instance lock file, so tests do not contend
+// with an agent running on the host; empty restores the default
+func SetInstanceLock(path string) {
+	if path == "" {
+		path = runLockFile
+	}
+	instanceLockPath = path
+}
+
+// ReleaseSingleInstance releases the instance lock taken by
+// EnsureSingleInstance
+func ReleaseSingleInstance() {
+	if instanceLock != nil {
+		instanceLock.Close()
+		instanceLock = nil
+	}
+}
+
+// EnsureSingleInstance exits when another agent holds the instance lock, so
+// an agent run by hand next to the service does not report twice
+func EnsureSingleInstance() {
+	lock, err := lockInstance(instanceLockPath)
+	if err != nil && !errors.Is(err, errAlreadyRunning) {
+		Log.Debugf("Instance lock %s unavailable, using the state directory: %v", instanceLockPath, err)
+		if err = ensurePrivateDir(Cfg.StateDir); err == nil {
+			lock, err = lockInstance(statePath(stateLockFile))
+		}
+	}
+	switch {
+	case errors.Is(err, errAlreadyRunning):
+		Log.Warnf("%v; exiting", err)
+		os.Exit(0)
+	case err != nil:
+		Log.Warnf("Single instance check unavailable: %v", err)
+	default:
+		instanceLock = lock
+	}
+}
+
+// lockInstance takes an exclusive flock on path without blocking and, when
+// the file is writable, records the PID of this process in it
+func lockInstance(path string) (*os.File, error) {
+	writable := true
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if errors.Is(err, os.ErrPermission) {
+		writable = false
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		defer f.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			if pid := readLockPID(f); pid > 0 {
+				return nil, fmt.Errorf("%w (pid %d)", errAlreadyRunning, pid)
+			}
+			return nil, errAlreadyRunning
+		}
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+	if writable {
+		f.Truncate(0)
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return f, nil
+}
+
+// readLockPID returns the PID recorded in a lock file, 0 when unknown
+func readLockPID(f *os.File) int {
+	data := make([]byte, 32)
+	n, _ := f.ReadAt(data, 0)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data[:n])))
+	return pid
+}
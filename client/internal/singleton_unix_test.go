@@ -0,0 +1,33 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateLockFile)
+	lock, err := lockInstance(path)
+	if err != nil {
+		t.Fatalf("lockInstance: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != fmt.Sprintf("%d\n", os.Getpid()) {
+		t.Errorf("lock file holds %q", data)
+	}
+
+	// flock conflicts between open files, even in the same process
+	if _, err := lockInstance(path); !errors.Is(err, errAlreadyRunning) {
+		t.Fatalf("second lock: got %v, want errAlreadyRunning", err)
+	}
+	lock.Close()
+	lock, err = lockInstance(path)
+	if err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+	lock.Close()
+}
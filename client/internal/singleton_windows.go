@@ -2,8 +2,92 @@
 
 package internal
 
-// ensureSingleInstance checks if another instance is running
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// instanceMutex is the named mutex held by the running agent. The Global
+// namespace spans sessions, so the service (session 0) and an agent run
+// from a desktop see each other.
+const instanceMutex = `Global\TatuScanAgent`
+
+// instanceHandle keeps the mutex open for the life of the process; Windows
+// releases it when the process exits, even when it crashes
+var instanceHandle windows.Handle
+
+// instanceMutexName is the mutex taken, instanceMutex unless replaced by
+// SetInstanceLock
+var instanceMutexName = instanceMutex
+
+// SetInstanceLock replaces the instance mutex name, so tests do not contend
+// with an agent running on the host; empty restores the default
+func SetInstanceLock(name string) {
+	if name == "" {
+		name = instanceMutex
+	}
+	instanceMutexName = name
+}
+
+// ReleaseSingleInstance releases the instance mutex taken by
+// EnsureSingleInstance
+func ReleaseSingleInstance() {
+	if instanceHandle != 0 {
+		windows.CloseHandle(instanceHandle)
+		instanceHandle = 0
+	}
+}
+
+// EnsureSingleInstance exits when another agent holds the instance mutex,
+// so an agent run by hand next to the service does not report twice
 func EnsureSingleInstance() {
-	Log.Debug("Single instance check temporarily disabled on Windows")
-	// No-op: Temporarily disabled for debugging on Windows 7
+	handle, err := lockInstance(instanceMutexName)
+	switch {
+	case errors.Is(err, errAlreadyRunning):
+		Log.Errorf("%v (mutex %s); stop it or wait for it to exit", err, instanceMutexName)
+		os.Exit(0)
+	case err != nil:
+		Log.Warnf("Single instance check unavailable: %v", err)
+	default:
+		instanceHandle = handle
+	}
+}
+
+// lockInstance creates the named mutex, failing with errAlreadyRunning when
+// another process has it open
+func lockInstance(name string) (windows.Handle, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	handle, err := windows.CreateMutex(nil, false, namePtr)
+	switch {
+	case err == nil:
+		return handle, nil
+	case errors.Is(err, windows.ERROR_ALREADY_EXISTS):
+		windows.CloseHandle(handle)
+		return 0, errAlreadyRunning
+	case !errors.Is(err, windows.ERROR_ACCESS_DENIED):
+		return 0, fmt.Errorf("create mutex %s: %w", name, err)
+	}
+
+	// Access is denied both when the service created the mutex (its DACL
+	// excludes users) and when a standard user may not create global
+	// objects; only the first means another agent is running
+	existing, err := windows.OpenMutex(windows.SYNCHRONIZE, false, namePtr)
+	if err == nil {
+		windows.CloseHandle(existing)
+		return 0, errAlreadyRunning
+	}
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return 0, errAlreadyRunning
+	}
+	if local, ok := strings.CutPrefix(name, `Global\`); ok {
+		return lockInstance(`Local\` + local)
+	}
+	return 0, fmt.Errorf("create mutex %s: %w", name, err)
 }
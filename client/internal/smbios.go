@@ -0,0 +1,56 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"regexp"
+	"strings"
+)
+
+// smbiosPlaceholders lists filler values left by OEMs in SMBIOS fields
+var smbiosPlaceholders = []string{
+	"to be filled by o.e.m.",
+	"default string",
+	"system serial number",
+	"system product name",
+	"system manufacturer",
+	"chassis serial number",
+	"not specified",
+	"not applicable",
+	"none",
+	"n/a",
+	"0",
+	"0123456789",
+	"00000000-0000-0000-0000-000000000000",
+	"ffffffff-ffff-ffff-ffff-ffffffffffff",
+}
+
+// cleanSMBIOSValue trims a SMBIOS value and discards known placeholders
+func cleanSMBIOSValue(value string) string {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(value)
+	for _, placeholder := range smbiosPlaceholders {
+		if lower == placeholder {
+			return ""
+		}
+	}
+	return value
+}
+
+// ioregPropertyRe matches lines like `"key" = "value"` or `"key" = <"value">`
+var ioregPropertyRe = regexp.MustCompile(`^\s*"([^"]+)"\s*=\s*<?"?([^">]*)"?>?\s*$`)
+
+// parseIORegProperties extracts string properties from `ioreg -l` style output
+func parseIORegProperties(output string) map[string]string {
+	props := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		m := ioregPropertyRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, exists := props[m[1]]; !exists {
+			props[m[1]] = strings.TrimSpace(m[2])
+		}
+	}
+	return props
+}
@@ -0,0 +1,28 @@
+//go:build darwin
+
+package internal
+
+import (
+	"context"
+	"strings"
+)
+
+// getSMBIOSInfo reads serial number, vendor, model and platform UUID from IOKit
+func getSMBIOSInfo(ctx context.Context) SMBIOSInfo {
+	Log.Debug("Querying IOPlatformExpertDevice via ioreg")
+	output, err := runCommand("ioreg", "-rd1", "-c", "IOPlatformExpertDevice")
+	if err != nil {
+		Log.Warnf("Error to execute ioreg: %v", err)
+		return SMBIOSInfo{}
+	}
+
+	props := parseIORegProperties(string(output))
+	info := SMBIOSInfo{
+		SerialNumber: cleanSMBIOSValue(props["IOPlatformSerialNumber"]),
+		Manufacturer: cleanSMBIOSValue(props["manufacturer"]),
+		Model:        cleanSMBIOSValue(props["model"]),
+		ProductUUID:  strings.ToUpper(cleanSMBIOSValue(props["IOPlatformUUID"])),
+	}
+	Log.Debugf("SMBIOS detected: %+v", info)
+	return info
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readDMIField reads a single DMI attribute; serial and UUID require root
+func readDMIField(name string) string {
+	data, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "dmi", "id", name))
+	if err != nil {
+		Log.Debugf("DMI field %s not available: %v", name, err)
+		return ""
+	}
+	return cleanSMBIOSValue(strings.TrimSpace(string(data)))
+}
+
+// getSMBIOSInfo reads serial number, vendor, model and product UUID from
+// sysfs, falling back to the device tree on boards without DMI
+func getSMBIOSInfo(ctx context.Context) SMBIOSInfo {
+	Log.Debug("Collecting SMBIOS information from sysfs")
+	info := SMBIOSInfo{
+		SerialNumber: readDMIField("product_serial"),
+		Manufacturer: readDMIField("sys_vendor"),
+		Model:        readDMIField("product_name"),
+		ProductUUID:  strings.ToUpper(readDMIField("product_uuid")),
+	}
+	if info.SerialNumber == "" {
+		info.SerialNumber = readDMIField("chassis_serial")
+	}
+	if info.Model == "" {
+		applyDeviceTree(&info)
+	}
+	Log.Debugf("SMBIOS detected: %+v", info)
+	return info
+}
@@ -0,0 +1,51 @@
+package internal
+
+import "testing"
+
+func TestCleanSMBIOSValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"Real serial", "  PF2ABCDE ", "PF2ABCDE"},
+		{"OEM filler", "To Be Filled By O.E.M.", ""},
+		{"Default string", "Default String", ""},
+		{"Zero UUID", "00000000-0000-0000-0000-000000000000", ""},
+		{"Empty", "", ""},
+		{"Vendor", "LENOVO", "LENOVO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := cleanSMBIOSValue(tt.value); result != tt.expected {
+				t.Errorf("cleanSMBIOSValue(%q) = %q, want %q", tt.value, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseIORegProperties(t *testing.T) {
+	output := `+-o J293AP  <class IOPlatformExpertDevice, id 0x100000110, registered>
+    {
+      "IOPlatformUUID" = "1B2C3D4E-0000-1111-2222-333344445555"
+      "manufacturer" = <"Apple Inc.">
+      "model" = <"MacBookPro17,1">
+      "IOPlatformSerialNumber" = "FVFXXXXXQ05D"
+      "CycleCount" = 187
+    }`
+
+	props := parseIORegProperties(output)
+	expected := map[string]string{
+		"IOPlatformUUID":         "1B2C3D4E-0000-1111-2222-333344445555",
+		"manufacturer":           "Apple Inc.",
+		"model":                  "MacBookPro17,1",
+		"IOPlatformSerialNumber": "FVFXXXXXQ05D",
+		"CycleCount":             "187",
+	}
+	for key, want := range expected {
+		if got := props[key]; got != want {
+			t.Errorf("props[%q] = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/StackExchange/wmi"
+)
+
+// getSMBIOSInfo reads serial number, vendor, model and product UUID via WMI
+func getSMBIOSInfo(ctx context.Context) SMBIOSInfo {
+	Log.Debug("Querying Win32_ComputerSystemProduct via WMI")
+	var info SMBIOSInfo
+
+	type computerSystemProduct struct {
+		IdentifyingNumber *string
+		Name              *string
+		Vendor            *string
+		UUID              *string
+	}
+	var products []computerSystemProduct
+	if err := wmiQuery(wmi.CreateQuery(&products, "", "Win32_ComputerSystemProduct"), &products, ""); errors.Is(err, errWMIDisabled) {
+		Log.Debug("WMI disabled; SMBIOS identity not collected")
+	} else if err != nil {
+		Log.Warnf("Error to query Win32_ComputerSystemProduct: %v", err)
+		addWarning(ctx, WarnWMIUnavailable, "Win32_ComputerSystemProduct query failed: %v", err)
+	} else if len(products) > 0 {
+		p := products[0]
+		if p.IdentifyingNumber != nil {
+			info.SerialNumber = cleanSMBIOSValue(*p.IdentifyingNumber)
+		}
+		if p.Vendor != nil {
+			info.Manufacturer = cleanSMBIOSValue(*p.Vendor)
+		}
+		if p.Name != nil {
+			info.Model = cleanSMBIOSValue(*p.Name)
+		}
+		if p.UUID != nil {
+			info.ProductUUID = strings.ToUpper(cleanSMBIOSValue(*p.UUID))
+		}
+	}
+
+	// Fallback: chassis serial from Win32_SystemEnclosure
+	if info.SerialNumber == "" {
+		type systemEnclosure struct {
+			SerialNumber *string
+		}
+		var enclosures []systemEnclosure
+		if err := wmiQuery(wmi.CreateQuery(&enclosures, "", "Win32_SystemEnclosure"), &enclosures, ""); err != nil {
+			Log.Debugf("Error to query Win32_SystemEnclosure: %v", err)
+		} else if len(enclosures) > 0 && enclosures[0].SerialNumber != nil {
+			info.SerialNumber = cleanSMBIOSValue(*enclosures[0].SerialNumber)
+		}
+	}
+
+	Log.Debugf("SMBIOS detected: %+v", info)
+	return info
+}
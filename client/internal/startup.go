@@ -0,0 +1,83 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"strings"
+)
+
+// StartupItem is a program started at boot or logon outside of the system
+// services: Run keys and Startup folders, XDG autostart entries, systemd
+// user units and LaunchAgents
+type StartupItem struct {
+	Name     string `json:"name"`
+	Command  string `json:"command,omitempty"`
+	Location string `json:"location"`       // registry key, folder or unit directory; ~ for per-user ones
+	User     string `json:"user,omitempty"` // owner of a per-user entry; empty for all users
+}
+
+// sortStartupItems orders startup items by location, user and name
+func sortStartupItems(items []StartupItem) []StartupItem {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.Name < b.Name
+	})
+	return items
+}
+
+// parseLaunchdPlist returns the Label and the program of an XML launchd
+// property list; binary plists return nothing
+func parseLaunchdPlist(data []byte) (label, program string) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var key string
+	var args []string
+	inArgs := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			if end, ok := token.(xml.EndElement); ok && end.Name.Local == "array" {
+				inArgs = false
+			}
+			continue
+		}
+		var text string
+		switch start.Name.Local {
+		case "key", "string":
+			if decoder.DecodeElement(&text, &start) != nil {
+				return label, program
+			}
+		case "array":
+			inArgs = key == "ProgramArguments"
+			continue
+		default:
+			continue
+		}
+		switch {
+		case start.Name.Local == "key":
+			key = text
+		case inArgs:
+			args = append(args, text)
+		case key == "Label":
+			label = text
+		case key == "Program":
+			program = text
+		}
+	}
+	if program == "" {
+		program = strings.Join(args, " ")
+	}
+	return label, program
+}
@@ -0,0 +1,43 @@
+//go:build darwin
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// launchAgentsDir holds the LaunchAgents of all users
+const launchAgentsDir = "/Library/LaunchAgents"
+
+// launchAgents lists the LaunchAgents of dir
+func launchAgents(dir, location, user string) []StartupItem {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.plist"))
+	var items []StartupItem
+	for _, file := range files {
+		item := StartupItem{Name: strings.TrimSuffix(filepath.Base(file), ".plist"), Location: location, User: user}
+		if data, err := os.ReadFile(file); err == nil {
+			label, program := parseLaunchdPlist(data)
+			if label != "" {
+				item.Name = label
+			}
+			item.Command = program
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// getStartupItems lists the LaunchAgents of all users and of each home
+// directory under /Users
+func getStartupItems() []StartupItem {
+	items := launchAgents(launchAgentsDir, launchAgentsDir, "")
+	homes, _ := filepath.Glob("/Users/*")
+	for _, home := range homes {
+		if user := filepath.Base(home); user != "Shared" {
+			items = append(items, launchAgents(filepath.Join(home, "Library", "LaunchAgents"), "~/Library/LaunchAgents", user)...)
+		}
+	}
+	return sortStartupItems(items)
+}
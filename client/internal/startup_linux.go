@@ -0,0 +1,99 @@
+//go:build linux
+
+package internal
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xdgAutostartDir holds the desktop autostart entries of all users
+const xdgAutostartDir = "etc/xdg/autostart"
+
+// systemdUserDir holds the systemd user units enabled for all users
+const systemdUserDir = "etc/systemd/user"
+
+// parseDesktopEntry returns the Name and Exec of a .desktop file, and
+// whether it is enabled (not Hidden nor disabled for GNOME)
+func parseDesktopEntry(path string) (name, command string, enabled bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", false
+	}
+	defer f.Close()
+	enabled = true
+	inEntry := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inEntry || !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Name":
+			name = strings.TrimSpace(value)
+		case "Exec":
+			command = strings.TrimSpace(value)
+		case "Hidden":
+			enabled = enabled && strings.TrimSpace(value) != "true"
+		case "X-GNOME-Autostart-enabled":
+			enabled = enabled && strings.TrimSpace(value) != "false"
+		}
+	}
+	return name, command, enabled
+}
+
+// desktopAutostart lists the enabled .desktop entries of dir
+func desktopAutostart(dir, location, user string) []StartupItem {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.desktop"))
+	var items []StartupItem
+	for _, file := range files {
+		name, command, enabled := parseDesktopEntry(file)
+		if !enabled {
+			continue
+		}
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(file), ".desktop")
+		}
+		items = append(items, StartupItem{Name: name, Command: command, Location: location, User: user})
+	}
+	return items
+}
+
+// systemdUserUnits lists the units enabled in the *.wants directories of a
+// systemd user unit directory
+func systemdUserUnits(dir, location, user string) []StartupItem {
+	links, _ := filepath.Glob(filepath.Join(dir, "*.wants", "*"))
+	var items []StartupItem
+	for _, link := range links {
+		item := StartupItem{Name: filepath.Base(link), Location: location, User: user}
+		if target, err := os.Readlink(link); err == nil {
+			item.Command = target
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// getStartupItems lists the XDG autostart entries and the systemd user units
+// of all users and of each home directory under /home and /root
+func getStartupItems() []StartupItem {
+	items := desktopAutostart(filepath.Join(rootDir, xdgAutostartDir), "/"+xdgAutostartDir, "")
+	items = append(items, systemdUserUnits(filepath.Join(rootDir, systemdUserDir), "/"+systemdUserDir, "")...)
+
+	homes, _ := filepath.Glob(filepath.Join(rootDir, "home", "*"))
+	homes = append(homes, filepath.Join(rootDir, "root"))
+	for _, home := range homes {
+		user := filepath.Base(home)
+		items = append(items, desktopAutostart(filepath.Join(home, ".config", "autostart"), "~/.config/autostart", user)...)
+		items = append(items, systemdUserUnits(filepath.Join(home, ".config", "systemd", "user"), "~/.config/systemd/user", user)...)
+	}
+	return sortStartupItems(items)
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetStartupItems(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("etc/xdg/autostart/nm-applet.desktop", "[Desktop Entry]\nName=Network\nExec=nm-applet\n[Desktop Action New]\nName=Other\n")
+	write("etc/xdg/autostart/tracker.desktop", "[Desktop Entry]\nName=Tracker\nExec=tracker\nX-GNOME-Autostart-enabled=false\n")
+	write("home/alice/.config/autostart/steam.desktop", "[Desktop Entry]\nExec=steam -silent\nHidden=false\n")
+	wants := filepath.Join(root, "home/alice/.config/systemd/user/default.target.wants")
+	if err := os.MkdirAll(wants, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/usr/lib/systemd/user/syncthing.service", filepath.Join(wants, "syncthing.service")); err != nil {
+		t.Fatal(err)
+	}
+
+	origRoot := rootDir
+	rootDir = root
+	t.Cleanup(func() { rootDir = origRoot })
+
+	want := []StartupItem{
+		{Name: "Network", Command: "nm-applet", Location: "/etc/xdg/autostart"},
+		{Name: "steam", Command: "steam -silent", Location: "~/.config/autostart", User: "alice"},
+		{Name: "syncthing.service", Command: "/usr/lib/systemd/user/syncthing.service", Location: "~/.config/systemd/user", User: "alice"},
+	}
+	if got := getStartupItems(); !reflect.DeepEqual(got, want) {
+		t.Errorf("getStartupItems = %+v, want %+v", got, want)
+	}
+}
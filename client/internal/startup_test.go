@@ -0,0 +1,26 @@
+package internal
+
+import "testing"
+
+func TestParseLaunchdPlist(t *testing.T) {
+	job := LaunchdJob{Program: "/usr/local/bin/tatuscan", Args: []string{"-interval", "5m"}}
+	label, program := parseLaunchdPlist(job.Plist())
+	if label != LaunchdLabel || program != "/usr/local/bin/tatuscan -interval 5m" {
+		t.Errorf("parseLaunchdPlist = %q, %q", label, program)
+	}
+
+	const withProgram = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0"><dict>
+	<key>Label</key><string>com.example.updater</string>
+	<key>Program</key><string>/Applications/Updater.app/Contents/MacOS/updater</string>
+	<key>ProgramArguments</key><array><string>updater</string><string>--background</string></array>
+</dict></plist>`
+	label, program = parseLaunchdPlist([]byte(withProgram))
+	if label != "com.example.updater" || program != "/Applications/Updater.app/Contents/MacOS/updater" {
+		t.Errorf("parseLaunchdPlist = %q, %q", label, program)
+	}
+
+	if label, program := parseLaunchdPlist([]byte("bplist00\x00\x01")); label != "" || program != "" {
+		t.Errorf("binary plist parsed as %q, %q", label, program)
+	}
+}
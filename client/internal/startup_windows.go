@@ -0,0 +1,113 @@
+//go:build windows
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// runKeys are the Run keys of HKLM and of each user hive
+var runKeys = []string{
+	`SOFTWARE\Microsoft\Windows\CurrentVersion\Run`,
+	`SOFTWARE\Microsoft\Windows\CurrentVersion\RunOnce`,
+}
+
+// startupFolder is the Startup folder under ProgramData and under the
+// roaming AppData of each profile
+const startupFolder = `Microsoft\Windows\Start Menu\Programs\Startup`
+
+// readRunKey lists the values of a Run key
+func readRunKey(root registry.Key, path string, view uint32, location, user string) []StartupItem {
+	k, err := registry.OpenKey(root, path, registry.QUERY_VALUE|view)
+	if err != nil {
+		return nil
+	}
+	defer k.Close()
+	names, _ := k.ReadValueNames(0)
+	var items []StartupItem
+	for _, name := range names {
+		command, _, err := k.GetStringValue(name)
+		if err != nil {
+			continue
+		}
+		items = append(items, StartupItem{Name: name, Command: command, Location: location, User: user})
+	}
+	return items
+}
+
+// startupFolderItems lists the shortcuts and programs of a Startup folder
+func startupFolderItems(dir, location, user string) []StartupItem {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var items []StartupItem
+	for _, entry := range entries {
+		if entry.IsDir() || strings.EqualFold(entry.Name(), "desktop.ini") {
+			continue
+		}
+		items = append(items, StartupItem{Name: entry.Name(), Command: filepath.Join(dir, entry.Name()), Location: location, User: user})
+	}
+	return items
+}
+
+// sidAccount returns the account name of a SID string, or the SID itself
+func sidAccount(sidString string) string {
+	sid, err := windows.StringToSid(sidString)
+	if err != nil {
+		return sidString
+	}
+	account, _, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sidString
+	}
+	return account
+}
+
+// getStartupItems lists the HKLM Run keys (both registry views), the Run
+// keys of the user hives loaded in HKEY_USERS (users logged on or running
+// services), the common Startup folder and the Startup folder of each profile
+func getStartupItems() []StartupItem {
+	var items []StartupItem
+	for _, path := range runKeys {
+		items = append(items, readRunKey(registry.LOCAL_MACHINE, path, registry.WOW64_64KEY, `HKLM\`+path, "")...)
+		wowPath := strings.Replace(path, `SOFTWARE\`, `SOFTWARE\WOW6432Node\`, 1)
+		items = append(items, readRunKey(registry.LOCAL_MACHINE, path, registry.WOW64_32KEY, `HKLM\`+wowPath, "")...)
+	}
+
+	if users, err := registry.OpenKey(registry.USERS, "", registry.ENUMERATE_SUB_KEYS); err == nil {
+		sids, _ := users.ReadSubKeyNames(0)
+		users.Close()
+		for _, sid := range sids {
+			// S-1-5-21-*: domain and local accounts, without the _Classes hives
+			if !strings.HasPrefix(sid, "S-1-5-21-") || strings.HasSuffix(sid, "_Classes") {
+				continue
+			}
+			user := sidAccount(sid)
+			for _, path := range runKeys {
+				items = append(items, readRunKey(registry.USERS, sid+`\`+path, 0, `HKCU\`+path, user)...)
+			}
+		}
+	}
+
+	programData := os.Getenv("ProgramData")
+	if programData == "" {
+		programData = `C:\ProgramData`
+	}
+	items = append(items, startupFolderItems(filepath.Join(programData, startupFolder), `%ProgramData%\`+startupFolder, "")...)
+	systemDrive := os.Getenv("SystemDrive")
+	if systemDrive == "" {
+		systemDrive = "C:"
+	}
+	profiles, _ := filepath.Glob(filepath.Join(systemDrive+`\`, "Users", "*"))
+	for _, profile := range profiles {
+		dir := filepath.Join(profile, "AppData", "Roaming", startupFolder)
+		items = append(items, startupFolderItems(dir, `%APPDATA%\`+startupFolder, filepath.Base(profile))...)
+	}
+	return sortStartupItems(items)
+}
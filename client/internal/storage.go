@@ -0,0 +1,100 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// StorageDevice is a physical disk, independent of the filesystems on it
+type StorageDevice struct {
+	Name   string `json:"name"`
+	Model  string `json:"model,omitempty"`
+	Serial string `json:"serial,omitempty"`
+	SizeMB uint64 `json:"size_mb"`
+	Bus    string `json:"bus,omitempty"`   // nvme, sata, sas, scsi, usb, mmc, virtio, ...
+	Media  string `json:"media,omitempty"` // ssd or hdd
+}
+
+// Media types reported in StorageDevice.Media
+const (
+	MediaSSD = "ssd"
+	MediaHDD = "hdd"
+)
+
+// sortStorageDevices orders devices by name for stable payloads
+func sortStorageDevices(devices []StorageDevice) []StorageDevice {
+	sort.Slice(devices, func(i, j int) bool { return devices[i].Name < devices[j].Name })
+	return devices
+}
+
+// msftBusTypes maps the BusType of MSFT_PhysicalDisk to bus names
+var msftBusTypes = map[uint16]string{
+	1: "scsi", 2: "atapi", 3: "ata", 4: "ieee1394", 5: "ssa", 6: "fibre_channel",
+	7: "usb", 8: "raid", 9: "iscsi", 10: "sas", 11: "sata", 12: "sd", 13: "mmc",
+	15: "file_backed", 16: "storage_spaces", 17: "nvme",
+}
+
+// msftMediaType maps the MediaType of MSFT_PhysicalDisk (3 HDD, 4 SSD,
+// 5 SCM, 0 unspecified)
+func msftMediaType(value uint16) string {
+	switch value {
+	case 3:
+		return MediaHDD
+	case 4, 5:
+		return MediaSSD
+	}
+	return ""
+}
+
+// systemProfilerDisk is a device of the SPNVMeDataType and
+// SPSerialATADataType reports of system_profiler -json
+type systemProfilerDisk struct {
+	Name       string `json:"_name"`
+	Model      string `json:"device_model"`
+	Serial     string `json:"device_serial"`
+	Size       uint64 `json:"size_in_bytes"`
+	BSDName    string `json:"bsd_name"`
+	MediumType string `json:"spsata_medium_type"`
+}
+
+// parseSystemProfilerDisks reads the disks of
+// `system_profiler -json SPNVMeDataType SPSerialATADataType`, grouped by
+// controller under each data type
+func parseSystemProfilerDisks(output []byte) ([]StorageDevice, error) {
+	var report map[string][]struct {
+		Items []systemProfilerDisk `json:"_items"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, err
+	}
+	var devices []StorageDevice
+	for dataType, controllers := range report {
+		for _, controller := range controllers {
+			for _, d := range controller.Items {
+				device := StorageDevice{
+					Name:   stringOr(d.BSDName, d.Name),
+					Model:  strings.TrimSpace(stringOr(d.Model, d.Name)),
+					Serial: strings.TrimSpace(d.Serial),
+					SizeMB: d.Size / (1024 * 1024),
+				}
+				switch dataType {
+				case "SPNVMeDataType":
+					device.Bus, device.Media = "nvme", MediaSSD
+				case "SPSerialATADataType":
+					device.Bus = "sata"
+					switch d.MediumType {
+					case "Solid State":
+						device.Media = MediaSSD
+					case "Rotational":
+						device.Media = MediaHDD
+					}
+				}
+				devices = append(devices, device)
+			}
+		}
+	}
+	return sortStorageDevices(devices), nil
+}
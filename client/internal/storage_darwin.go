@@ -0,0 +1,20 @@
+//go:build darwin
+
+package internal
+
+import "context"
+
+// getStorageDevices lists the NVMe and SATA disks reported by
+// system_profiler
+func getStorageDevices(ctx context.Context) []StorageDevice {
+	output, err := runCommandContext(ctx, "system_profiler", "-json", "SPNVMeDataType", "SPSerialATADataType")
+	if err != nil {
+		Log.Debugf("Error to execute system_profiler: %v", err)
+		return nil
+	}
+	devices, err := parseSystemProfilerDisks(output)
+	if err != nil {
+		Log.Warnf("Error to parse the system_profiler storage report: %v", err)
+	}
+	return devices
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// virtualBlockPrefixes are block devices without a disk behind them
+var virtualBlockPrefixes = []string{"loop", "ram", "zram", "dm-", "md", "sr", "nbd"}
+
+// getStorageDevices lists the disks of /sys/block, with the serial and bus
+// from the udev database when sysfs lacks them
+func getStorageDevices(_ context.Context) []StorageDevice {
+	entries, err := os.ReadDir(filepath.Join(sysfsRoot, "block"))
+	if err != nil {
+		Log.Debugf("Error to list block devices: %v", err)
+		return nil
+	}
+	var devices []StorageDevice
+	for _, entry := range entries {
+		name := entry.Name()
+		if hasAnyPrefix(name, virtualBlockPrefixes) {
+			continue
+		}
+		dir := filepath.Join(sysfsRoot, "block", name)
+		if _, err := os.Stat(filepath.Join(dir, "device")); err != nil {
+			continue
+		}
+		sectors, _ := strconv.ParseUint(readSysfsValue(dir, "size"), 10, 64)
+		if sectors == 0 {
+			continue
+		}
+		udev := readUdevData(readSysfsValue(dir, "dev"))
+		device := StorageDevice{
+			Name:   name,
+			Model:  stringOr(readSysfsValue(dir, "device", "model"), strings.ReplaceAll(udev["ID_MODEL"], "_", " ")),
+			Serial: stringOr(readSysfsValue(dir, "device", "serial"), udev["ID_SERIAL_SHORT"]),
+			SizeMB: sectors * 512 / (1024 * 1024), // size is in 512-byte sectors
+			Bus:    linuxBlockBus(name, dir, udev["ID_BUS"]),
+		}
+		switch readSysfsValue(dir, "queue", "rotational") {
+		case "0":
+			device.Media = MediaSSD
+		case "1":
+			device.Media = MediaHDD
+		}
+		devices = append(devices, device)
+	}
+	return sortStorageDevices(devices)
+}
+
+// linuxBlockBus names the bus of a block device from its name, its sysfs
+// path and the udev ID_BUS property
+func linuxBlockBus(name, dir, udevBus string) string {
+	switch {
+	case strings.HasPrefix(name, "nvme"):
+		return "nvme"
+	case strings.HasPrefix(name, "mmcblk"):
+		return "mmc"
+	case strings.HasPrefix(name, "vd"):
+		return "virtio"
+	}
+	if target, err := filepath.EvalSymlinks(dir); err == nil && strings.Contains(target, "/usb") {
+		return "usb"
+	}
+	if udevBus == "ata" {
+		return "sata"
+	}
+	return udevBus
+}
+
+// readSysfsValue reads a trimmed sysfs attribute, "" when missing
+func readSysfsValue(elem ...string) string {
+	data, err := os.ReadFile(filepath.Join(elem...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readUdevData reads the E: properties udev recorded for the block device
+// major:minor
+func readUdevData(dev string) map[string]string {
+	props := make(map[string]string)
+	if dev == "" {
+		return props
+	}
+	data, err := os.ReadFile(filepath.Join(rootDir, "run", "udev", "data", "b"+dev))
+	if err != nil {
+		return props
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimPrefix(line, "E:"), "="); ok && strings.HasPrefix(line, "E:") {
+			props[key] = value
+		}
+	}
+	return props
+}
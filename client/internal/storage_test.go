@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSystemProfilerDisks(t *testing.T) {
+	output := []byte(`{
+  "SPNVMeDataType": [{"_name": "Apple SSD Controller", "_items": [
+    {"_name": "APPLE SSD AP0512Q", "bsd_name": "disk0", "device_model": "APPLE SSD AP0512Q", "device_serial": "0ba0123456789abc", "size_in_bytes": 500277790720}
+  ]}],
+  "SPSerialATADataType": [{"_name": "Intel 8 Series Chipset", "_items": [
+    {"_name": "ST1000LM024", "bsd_name": "disk1", "device_model": "ST1000LM024 HN-M101MBB", "device_serial": "  S318J9AF123456", "size_in_bytes": 1000204886016, "spsata_medium_type": "Rotational"}
+  ]}]
+}`)
+	devices, err := parseSystemProfilerDisks(output)
+	if err != nil {
+		t.Fatalf("parseSystemProfilerDisks: %v", err)
+	}
+	want := []StorageDevice{
+		{Name: "disk0", Model: "APPLE SSD AP0512Q", Serial: "0ba0123456789abc", SizeMB: 477102, Bus: "nvme", Media: MediaSSD},
+		{Name: "disk1", Model: "ST1000LM024 HN-M101MBB", Serial: "S318J9AF123456", SizeMB: 953869, Bus: "sata", Media: MediaHDD},
+	}
+	if !reflect.DeepEqual(devices, want) {
+		t.Errorf("got %+v\nwant %+v", devices, want)
+	}
+}
+
+func TestMSFTPhysicalDiskTypes(t *testing.T) {
+	if msftBusTypes[17] != "nvme" || msftBusTypes[11] != "sata" || msftBusTypes[7] != "usb" {
+		t.Errorf("unexpected bus names: %v", msftBusTypes)
+	}
+	if msftMediaType(4) != MediaSSD || msftMediaType(3) != MediaHDD || msftMediaType(0) != "" {
+		t.Error("unexpected media types")
+	}
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/StackExchange/wmi"
+)
+
+// getStorageDevices lists the disks of MSFT_PhysicalDisk (Windows 8 and
+// later), which knows the bus and the SSD/HDD media type
+func getStorageDevices(ctx context.Context) []StorageDevice {
+	type physicalDisk struct {
+		DeviceId     string
+		FriendlyName string
+		SerialNumber string
+		Size         uint64
+		BusType      uint16
+		MediaType    uint16
+	}
+	var disks []physicalDisk
+	q := wmi.CreateQuery(&disks, "", "MSFT_PhysicalDisk")
+	if err := wmiQuery(q, &disks, `root\Microsoft\Windows\Storage`); errors.Is(err, errWMIDisabled) {
+		Log.Debug("WMI disabled; storage devices not collected")
+		return nil
+	} else if err != nil {
+		Log.Warnf("Error to query MSFT_PhysicalDisk: %v", err)
+		addWarning(ctx, WarnWMIUnavailable, "MSFT_PhysicalDisk query failed: %v", err)
+		return nil
+	}
+	devices := make([]StorageDevice, 0, len(disks))
+	for _, d := range disks {
+		devices = append(devices, StorageDevice{
+			Name:   "PhysicalDrive" + d.DeviceId,
+			Model:  strings.TrimSpace(d.FriendlyName),
+			Serial: cleanSMBIOSValue(d.SerialNumber),
+			SizeMB: d.Size / (1024 * 1024),
+			Bus:    msftBusTypes[d.BusType],
+			Media:  msftMediaType(d.MediaType),
+		})
+	}
+	return sortStorageDevices(devices)
+}
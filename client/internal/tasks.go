@@ -0,0 +1,236 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// taskMaxAge rejects tasks issued too long ago, so a captured reply
+	// cannot be replayed later
+	taskMaxAge = time.Hour
+	// taskClockTolerance accepts tasks issued slightly in the future
+	taskClockTolerance = 5 * time.Minute
+	// tasksFile keeps the IDs of the tasks already handled in the state
+	// directory, so a replayed task is not run again after a restart
+	tasksFile = "tasks.json"
+	// maxTaskOutput bounds the command output reported in the payload
+	maxTaskOutput = 16 * 1024
+	// maxPendingResults bounds the results waiting for a successful send
+	maxPendingResults = 50
+	// agentServiceName is the service name given to the OS service manager
+	agentServiceName = "TatuScanAgent"
+)
+
+// Task is a command queued by the server in its reply. Commands are names
+// of built-in actions, never programs or arguments, and only run when
+// allowed by TATUSCAN_TASKS and signed with the TATUSCAN_TASKS_KEY key.
+type Task struct {
+	ID        string `json:"id"`
+	MachineID string `json:"machine_id"`
+	Command   string `json:"command"`
+	IssuedAt  string `json:"issued_at"` // RFC 3339
+	Signature string `json:"signature"` // base64 Ed25519 over taskMessage
+}
+
+// TaskResult reports the outcome of a task in the next payloads, until one
+// of them is delivered
+type TaskResult struct {
+	ID         string `json:"id"`
+	Command    string `json:"command"`
+	Status     string `json:"status"` // ok, failed or rejected
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// taskActions are the built-in commands a server may queue
+var taskActions = map[string]func() ([]byte, error){
+	"collect_now":  requestCollection,
+	"flush_dns":    platformFlushDNS,
+	"collect_logs": platformAgentLogs,
+}
+
+var (
+	taskMu           sync.Mutex
+	taskResults      []TaskResult
+	collectNow       = make(chan struct{}, 1)
+	errTasksDisabled = errors.New("remote tasks are disabled")
+)
+
+// CollectRequests delivers the collect_now tasks to the agent loop
+func CollectRequests() <-chan struct{} {
+	return collectNow
+}
+
+// requestCollection asks the agent loop for an immediate cycle
+func requestCollection() ([]byte, error) {
+	select {
+	case collectNow <- struct{}{}:
+	default: // one is already pending
+	}
+	return []byte("collection scheduled"), nil
+}
+
+// taskMessage is the signed content of a task: the signature binds the
+// command to one machine and one moment
+func taskMessage(t Task) []byte {
+	return []byte(strings.Join([]string{t.ID, t.MachineID, t.Command, t.IssuedAt}, "\n"))
+}
+
+// verifyTask checks a task against the local policy, the machine, its age
+// and its signature
+func verifyTask(t Task, machineID string, now time.Time) error {
+	if len(Cfg.Tasks) == 0 || Cfg.TasksKey == "" {
+		return errTasksDisabled
+	}
+	if _, ok := taskActions[t.Command]; !ok {
+		return fmt.Errorf("unknown command %q", t.Command)
+	}
+	if !containsString(Cfg.Tasks, t.Command) {
+		return fmt.Errorf("command %q is not allowed by TATUSCAN_TASKS", t.Command)
+	}
+	if t.MachineID != machineID {
+		return fmt.Errorf("task targets another machine")
+	}
+	issued, err := time.Parse(time.RFC3339, t.IssuedAt)
+	if err != nil {
+		return fmt.Errorf("invalid issued_at %q", t.IssuedAt)
+	}
+	if now.Sub(issued) > taskMaxAge || issued.Sub(now) > taskClockTolerance {
+		return fmt.Errorf("task issued at %s has expired", t.IssuedAt)
+	}
+	key, err := base64.StdEncoding.DecodeString(Cfg.TasksKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("TATUSCAN_TASKS_KEY is not a base64 Ed25519 public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(t.Signature)
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), taskMessage(t), sig) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// handleTasks runs the tasks of a server reply in order, once per ID. Only
+// accepted tasks use up their ID, so a forged task cannot block the genuine
+// one; a rejection is reported once while pending. The caller runs it off
+// the send path, as commands may take a while.
+func handleTasks(tasks []Task, machineID string) {
+	taskMu.Lock()
+	defer taskMu.Unlock()
+	seen := loadTaskIDs()
+	now := time.Now()
+	for _, t := range tasks {
+		if t.ID == "" || seen[t.ID] != 0 {
+			continue
+		}
+		result := TaskResult{ID: t.ID, Command: t.Command}
+		if err := verifyTask(t, machineID, now); err != nil {
+			Log.Warnf("Task %s (%s) rejected: %v", t.ID, t.Command, err)
+			if containsTaskResult(taskResults, t.ID) {
+				continue
+			}
+			result.Status, result.Error = "rejected", err.Error()
+		} else {
+			seen[t.ID] = now.Unix()
+			Log.Infof("Running task %s (%s)", t.ID, t.Command)
+			output, err := taskActions[t.Command]()
+			result.Status, result.Output = "ok", truncateOutput(output)
+			if err != nil {
+				Log.Warnf("Task %s (%s) failed: %v", t.ID, t.Command, err)
+				result.Status, result.Error = "failed", err.Error()
+			}
+		}
+		result.FinishedAt = time.Now().Format(time.RFC3339)
+		taskResults = append(taskResults, result)
+	}
+	if n := len(taskResults); n > maxPendingResults {
+		taskResults = taskResults[n-maxPendingResults:]
+	}
+	saveTaskIDs(seen, now)
+}
+
+// truncateOutput keeps the end of the output, where errors usually are
+func truncateOutput(output []byte) string {
+	if len(output) > maxTaskOutput {
+		output = output[len(output)-maxTaskOutput:]
+	}
+	return strings.TrimSpace(strings.ToValidUTF8(string(output), ""))
+}
+
+// pendingTaskResults returns the results not yet delivered
+func pendingTaskResults() []TaskResult {
+	taskMu.Lock()
+	defer taskMu.Unlock()
+	return append([]TaskResult(nil), taskResults...)
+}
+
+// ackTaskResults drops the results delivered in a payload
+func ackTaskResults(delivered []TaskResult) {
+	if len(delivered) == 0 {
+		return
+	}
+	taskMu.Lock()
+	defer taskMu.Unlock()
+	kept := taskResults[:0]
+	for _, r := range taskResults {
+		if !containsTaskResult(delivered, r.ID) {
+			kept = append(kept, r)
+		}
+	}
+	taskResults = kept
+}
+
+// containsTaskResult reports whether id is among results
+func containsTaskResult(results []TaskResult, id string) bool {
+	for _, r := range results {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTaskIDs reads the handled task IDs with the Unix time they were seen
+func loadTaskIDs() map[string]int64 {
+	seen := map[string]int64{}
+	data, err := os.ReadFile(statePath(tasksFile))
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			Log.Warnf("Error to read handled tasks: %v", err)
+		}
+		return seen
+	}
+	if err := json.Unmarshal(data, &seen); err != nil {
+		Log.Warnf("Ignoring invalid task list %s", statePath(tasksFile))
+		return map[string]int64{}
+	}
+	return seen
+}
+
+// saveTaskIDs persists the handled task IDs, forgetting those old enough to
+// be rejected as expired anyway
+func saveTaskIDs(seen map[string]int64, now time.Time) {
+	cutoff := now.Add(-2 * taskMaxAge).Unix()
+	for id, at := range seen {
+		if at < cutoff {
+			delete(seen, id)
+		}
+	}
+	data, err := json.Marshal(seen)
+	if err == nil {
+		err = writeFileAtomic(statePath(tasksFile), data, 0o600)
+	}
+	if err != nil {
+		Log.Warnf("Error to persist handled tasks in %s: %v", Cfg.StateDir, err)
+	}
+}
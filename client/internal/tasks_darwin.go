@@ -0,0 +1,19 @@
+//go:build darwin
+
+package internal
+
+// platformFlushDNS clears the directory service cache and makes
+// mDNSResponder drop its own
+func platformFlushDNS() ([]byte, error) {
+	output, err := runCommand("dscacheutil", "-flushcache")
+	if err != nil {
+		return output, err
+	}
+	return runCommand("killall", "-HUP", "mDNSResponder")
+}
+
+// platformAgentLogs returns the agent entries of the unified log from the
+// last hour
+func platformAgentLogs() ([]byte, error) {
+	return runCommand("log", "show", "--last", "1h", "--style", "compact", "--predicate", `process == "tatuscan"`)
+}
@@ -0,0 +1,31 @@
+//go:build linux
+
+package internal
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// platformFlushDNS flushes the systemd-resolved cache, the resolver of the
+// mainstream distributions; systems without it have no cache to flush
+func platformFlushDNS() ([]byte, error) {
+	if _, err := exec.LookPath("resolvectl"); err == nil {
+		return runCommand("resolvectl", "flush-caches")
+	}
+	if _, err := exec.LookPath("systemd-resolve"); err == nil {
+		return runCommand("systemd-resolve", "--flush-caches")
+	}
+	return nil, errors.New("no DNS cache service found (systemd-resolved)")
+}
+
+// packagedServiceName is the unit installed by the deb and rpm packages
+// (packaging/linux/tatuscan-agent.service), next to the agentServiceName
+// unit of `tatuscan install`
+const packagedServiceName = "tatuscan-agent"
+
+// platformAgentLogs returns the last journal entries of the agent unit,
+// whichever way it was installed
+func platformAgentLogs() ([]byte, error) {
+	return runCommand("journalctl", "-u", agentServiceName, "-u", packagedServiceName, "-n", "200", "--no-pager", "-o", "short-iso")
+}
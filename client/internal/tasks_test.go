@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestHandleTasks(t *testing.T) {
+	setupTestAgent(t)
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Cfg.Tasks = []string{"collect_now"}
+	Cfg.TasksKey = base64.StdEncoding.EncodeToString(public)
+	t.Cleanup(func() { taskResults = nil })
+
+	machineID := "m1"
+	now := time.Now().UTC().Format(time.RFC3339)
+	sign := func(task Task) Task {
+		task.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(private, taskMessage(task)))
+		return task
+	}
+	forged := sign(Task{ID: "t2", MachineID: machineID, Command: "collect_now", IssuedAt: now})
+	forged.Command = "flush_dns"
+	handleTasks([]Task{
+		sign(Task{ID: "t1", MachineID: machineID, Command: "collect_now", IssuedAt: now}),
+		forged,
+		sign(Task{ID: "t3", MachineID: machineID, Command: "flush_dns", IssuedAt: now}),
+		sign(Task{ID: "t4", MachineID: "other", Command: "collect_now", IssuedAt: now}),
+		sign(Task{ID: "t5", MachineID: machineID, Command: "collect_now", IssuedAt: "2020-01-01T00:00:00Z"}),
+		sign(Task{ID: "t6", MachineID: machineID, Command: "rm -rf /", IssuedAt: now}),
+	}, machineID)
+
+	results := pendingTaskResults()
+	want := []string{"ok", "rejected", "rejected", "rejected", "rejected", "rejected"}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v", results)
+	}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("task %s: status %s (%s), want %s", r.ID, r.Status, r.Error, want[i])
+		}
+	}
+	select {
+	case <-CollectRequests():
+	default:
+		t.Error("collect_now did not request a collection")
+	}
+
+	// A replayed task is not run again, even after a restart
+	ackTaskResults(results[:1])
+	handleTasks([]Task{sign(Task{ID: "t1", MachineID: machineID, Command: "collect_now", IssuedAt: now})}, machineID)
+	if results := pendingTaskResults(); len(results) != 5 || results[0].ID != "t2" {
+		t.Errorf("after ack and replay, results = %+v", results)
+	}
+
+	// A forged task does not use up the ID of the genuine one
+	ackTaskResults(pendingTaskResults())
+	forged = sign(Task{ID: "t8", MachineID: machineID, Command: "collect_now", IssuedAt: now})
+	forged.Signature = base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+	handleTasks([]Task{forged, forged}, machineID)
+	if results := pendingTaskResults(); len(results) != 1 || results[0].Status != "rejected" {
+		t.Fatalf("forged task results = %+v", results)
+	}
+	ackTaskResults(pendingTaskResults())
+	handleTasks([]Task{sign(Task{ID: "t8", MachineID: machineID, Command: "collect_now", IssuedAt: now})}, machineID)
+	if results := pendingTaskResults(); len(results) != 1 || results[0].Status != "ok" {
+		t.Errorf("genuine task after a forged one, results = %+v", results)
+	}
+	<-CollectRequests()
+
+	// Without a key every task is rejected
+	Cfg.TasksKey = ""
+	if err := verifyTask(sign(Task{ID: "t7", MachineID: machineID, Command: "collect_now", IssuedAt: now}), machineID, time.Now()); err != errTasksDisabled {
+		t.Errorf("verifyTask without key = %v", err)
+	}
+}
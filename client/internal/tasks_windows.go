@@ -0,0 +1,15 @@
+//go:build windows
+
+package internal
+
+// platformFlushDNS clears the DNS client resolver cache
+func platformFlushDNS() ([]byte, error) {
+	return runCommand("ipconfig", "/flushdns")
+}
+
+// platformAgentLogs returns the last Application log events the agent
+// wrote under its Event Log source, newest first
+func platformAgentLogs() ([]byte, error) {
+	query := "*[System[Provider[@Name='" + eventLogSource + "']]]"
+	return runCommand("wevtutil", "qe", "Application", "/q:"+query, "/c:200", "/rd:true", "/f:text")
+}
@@ -4,15 +4,69 @@ package internal
 
 // MachineInfo represents the collected machine data
 type MachineInfo struct {
-	MachineID     string  `json:"machine_id"`
-	Hostname      string  `json:"hostname"`
-	IP            string  `json:"ip"`
-	OS            string  `json:"os"`
-	OSVersion     string  `json:"os_version"`
-	CPUPercent    float64 `json:"cpu_percent"`
-	MemoryTotalMB uint64  `json:"memory_total_mb"`
-	MemoryUsedMB  uint64  `json:"memory_used_mb"`
-	Timestamp     string  `json:"timestamp"`
+	SchemaVersion int                    `json:"schema_version"`
+	MachineID     string                 `json:"machine_id"`
+	PreviousID    string                 `json:"previous_machine_id,omitempty"`
+	MACAddresses  []string               `json:"mac_addresses,omitempty"`
+	AgentID       string                 `json:"agent_id,omitempty"`
+	Cohort        int                    `json:"cohort"`
+	Hostname      string                 `json:"hostname"`
+	FQDN          string                 `json:"fqdn,omitempty"`
+	DNSSuffixes   []string               `json:"dns_suffixes,omitempty"`
+	IP            string                 `json:"ip"`
+	PublicIP      string                 `json:"public_ip,omitempty"`
+	Addresses     []InterfaceAddress     `json:"addresses,omitempty"`
+	Interfaces    []InterfaceDetail      `json:"interfaces,omitempty"`
+	Links         []LinkTopology         `json:"link_topology,omitempty"`
+	WiFi          *WiFiInfo              `json:"wifi,omitempty"`
+	Addressing    *AddressingInfo        `json:"addressing,omitempty"`
+	OS            string                 `json:"os"`
+	OSVersion     string                 `json:"os_version"`
+	SerialNumber  string                 `json:"serial_number,omitempty"`
+	Manufacturer  string                 `json:"manufacturer,omitempty"`
+	Model         string                 `json:"model,omitempty"`
+	HardwareRev   string                 `json:"hardware_revision,omitempty"`
+	ProductUUID   string                 `json:"product_uuid,omitempty"`
+	Firmware      *FirmwareInfo          `json:"firmware,omitempty"`
+	IsVirtual     bool                   `json:"is_virtual"`
+	Hypervisor    string                 `json:"hypervisor,omitempty"`
+	Container     string                 `json:"container,omitempty"`
+	Image         *ImageInfo             `json:"image,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	Labels        map[string]string      `json:"labels,omitempty"`
+	Warranty      map[string]any         `json:"warranty,omitempty"`
+	Watchlist     []WatchedProcess       `json:"watchlist,omitempty"`
+	Services      []Service              `json:"services,omitempty"`
+	Endpoint      *EndpointSecurity      `json:"endpoint_security,omitempty"`
+	Listeners     []ListeningPort        `json:"listeners,omitempty"`
+	Neighbors     []Neighbor             `json:"neighbors,omitempty"`
+	Osquery       OsqueryResults         `json:"osquery,omitempty"`
+	Custom        CustomResults          `json:"custom,omitempty"`
+	Updates       *UpdateInfo            `json:"updates,omitempty"`
+	Packages      []Package              `json:"packages,omitempty"`
+	Hotfixes      []Hotfix               `json:"hotfixes,omitempty"`
+	WindowsUpdate *WindowsUpdateSettings `json:"windows_update,omitempty"`
+	Certificates  []ExpiringCert         `json:"expiring_certificates,omitempty"`
+	Startup       []StartupItem          `json:"startup,omitempty"`
+	Sensors       *SensorInfo            `json:"sensors,omitempty"`
+	Batteries     []BatteryInfo          `json:"batteries,omitempty"`
+	Disks         []Disk                 `json:"disks,omitempty"`
+	Storage       []StorageDevice        `json:"storage_devices,omitempty"`
+	PCI           []PCIDevice            `json:"pci_devices,omitempty"`
+	Health        *Health                `json:"health,omitempty"`
+	TaskResults   []TaskResult           `json:"task_results,omitempty"`
+	CPU           *CPUInfo               `json:"cpu,omitempty"`
+	CPUPercent    float64                `json:"cpu_percent"`
+	MemoryTotalMB uint64                 `json:"memory_total_mb"`
+	MemoryUsedMB  uint64                 `json:"memory_used_mb"`
+	Summary       *MetricsSummary        `json:"metrics_summary,omitempty"`
+	MetricHistory []MetricSample         `json:"metric_history,omitempty"`
+	Timestamp     string                 `json:"timestamp"`
+	Heartbeat     bool                   `json:"heartbeat,omitempty"`
+	Agent         *AgentStats            `json:"agent,omitempty"`
+	Build         *BuildInfo             `json:"build,omitempty"`
+	Collectors    []string               `json:"collectors,omitempty"`
+	Warnings      []Warning              `json:"warnings,omitempty"`
 }
 
 // MachineMetrics holds common machine metrics
@@ -21,3 +75,13 @@ type MachineMetrics struct {
 	MemoryTotalMB uint64
 	MemoryUsedMB  uint64
 }
+
+// SMBIOSInfo holds the system identity exposed by SMBIOS/DMI
+type SMBIOSInfo struct {
+	SerialNumber string
+	Manufacturer string
+	Model        string
+	ProductUUID  string
+	// HardwareRevision is the board revision code of ARM boards
+	HardwareRevision string
+}
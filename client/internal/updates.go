@@ -0,0 +1,92 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultUpdatesInterval spaces update checks, which may query remote
+// repositories and take minutes (softwareupdate, Windows Update)
+const defaultUpdatesInterval = 6 * time.Hour
+
+// UpdateInfo reports the OS updates available but not installed
+type UpdateInfo struct {
+	Source    string   `json:"source"` // windows-update, apt, dnf, softwareupdate
+	Pending   int      `json:"pending"`
+	Security  int      `json:"security,omitempty"`
+	Names     []string `json:"names,omitempty"`
+	CheckedAt string   `json:"checked_at"`
+}
+
+// WindowsUpdateSettings reports how the Windows Update client is configured,
+// so machines not updating or pointed at the wrong WSUS stand out
+type WindowsUpdateSettings struct {
+	Mode        string `json:"mode"`    // from AUOptions: disabled, notify, download, scheduled, ...
+	Managed     bool   `json:"managed"` // configured by Group Policy
+	Source      string `json:"source"`  // windows-update or wsus
+	WSUSServer  string `json:"wsus_server,omitempty"`
+	TargetGroup string `json:"target_group,omitempty"`
+	LastSearch  string `json:"last_search,omitempty"`  // RFC3339
+	LastInstall string `json:"last_install,omitempty"` // RFC3339
+}
+
+// auOptionModes names the AUOptions policy values
+var auOptionModes = map[uint64]string{
+	2: "notify",         // notify before download
+	3: "download",       // download, notify before install
+	4: "scheduled",      // download and install on schedule
+	5: "local",          // local administrator chooses
+	7: "notify-restart", // download, notify before install and restart
+}
+
+// windowsUpdateMode returns the automatic updates mode of the NoAutoUpdate and
+// AUOptions policies; "automatic" when no policy sets them
+func windowsUpdateMode(noAutoUpdate, auOptions uint64) string {
+	if noAutoUpdate == 1 {
+		return "disabled"
+	}
+	if auOptions == 0 {
+		return "automatic"
+	}
+	if mode, ok := auOptionModes[auOptions]; ok {
+		return mode
+	}
+	return "unknown"
+}
+
+// updatesCache keeps the last check between collections
+var updatesCache struct {
+	sync.Mutex
+	info    *UpdateInfo
+	err     error
+	checked time.Time
+}
+
+// getUpdates returns the pending OS updates when TATUSCAN_UPDATES is set,
+// running the platform check at most once per TATUSCAN_UPDATES_INTERVAL
+func getUpdates() (*UpdateInfo, error) {
+	if !Cfg.Updates {
+		return nil, nil
+	}
+	updatesCache.Lock()
+	defer updatesCache.Unlock()
+	if !updatesCache.checked.IsZero() && time.Since(updatesCache.checked) < Cfg.UpdatesInterval {
+		return updatesCache.info, updatesCache.err
+	}
+
+	Log.Debug("Checking pending OS updates")
+	now := time.Now()
+	info, err := platformUpdates()
+	if err == nil {
+		info.CheckedAt = now.Format(time.RFC3339)
+		sort.Strings(info.Names)
+		if !Cfg.UpdatesNames {
+			info.Names = nil
+		}
+	}
+	updatesCache.info, updatesCache.err, updatesCache.checked = info, err, now
+	return info, err
+}
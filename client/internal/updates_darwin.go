@@ -0,0 +1,35 @@
+//go:build darwin
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseSoftwareUpdateList parses `softwareupdate -l` output, where each
+// update is a "* Label: <label>" line followed by a "Title: <title>, ..." line
+func parseSoftwareUpdateList(output string) *UpdateInfo {
+	info := &UpdateInfo{Source: "softwareupdate"}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "* Label:"):
+			info.Pending++
+			info.Names = append(info.Names, strings.TrimSpace(strings.TrimPrefix(line, "* Label:")))
+		case strings.HasPrefix(line, "Title:") && len(info.Names) > 0:
+			title, _, _ := strings.Cut(strings.TrimPrefix(line, "Title:"), ",")
+			info.Names[len(info.Names)-1] = strings.TrimSpace(title)
+		}
+	}
+	return info
+}
+
+// platformUpdates lists the updates offered by Software Update
+func platformUpdates() (*UpdateInfo, error) {
+	output, err := runCommand("softwareupdate", "-l")
+	if err != nil {
+		return nil, fmt.Errorf("softwareupdate: %w", err)
+	}
+	return parseSoftwareUpdateList(string(output)), nil
+}
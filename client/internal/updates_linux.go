@@ -0,0 +1,86 @@
+//go:build linux
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runPackageManager runs a package manager; tests replace it to replay
+// recorded output
+var runPackageManager = func(name string, args ...string) ([]byte, error) {
+	return runCommand(name, args...)
+}
+
+// lookPackageManager finds a package manager in PATH
+var lookPackageManager = exec.LookPath
+
+// parseAptSimulation parses `apt-get -s dist-upgrade` output, where each
+// upgrade is an "Inst name [old] (new origin [arch])" line
+func parseAptSimulation(output string) *UpdateInfo {
+	info := &UpdateInfo{Source: "apt"}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "Inst" {
+			continue
+		}
+		info.Pending++
+		info.Names = append(info.Names, fields[1])
+		if strings.Contains(line, "-security") {
+			info.Security++
+		}
+	}
+	return info
+}
+
+// parseDnfCheckUpdate parses `dnf -q check-update` output (name.arch
+// version repo), which ends with an "Obsoleting Packages" section
+func parseDnfCheckUpdate(output string) *UpdateInfo {
+	info := &UpdateInfo{Source: "dnf"}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		name := fields[0]
+		if dot := strings.LastIndex(name, "."); dot > 0 {
+			name = name[:dot]
+		}
+		info.Pending++
+		info.Names = append(info.Names, name)
+	}
+	return info
+}
+
+// platformUpdates simulates an upgrade with apt or lists updates with dnf
+// (yum on older releases); package lists are not refreshed by the agent
+func platformUpdates() (*UpdateInfo, error) {
+	if _, err := lookPackageManager("apt-get"); err == nil {
+		output, err := runPackageManager("apt-get", "-s", "-o", "Debug::NoLocking=1", "dist-upgrade")
+		if err != nil {
+			return nil, fmt.Errorf("apt-get: %w", err)
+		}
+		return parseAptSimulation(string(output)), nil
+	}
+	for _, name := range []string{"dnf", "yum"} {
+		if _, err := lookPackageManager(name); err != nil {
+			continue
+		}
+		// check-update exits with 100 when updates are available
+		output, err := runPackageManager(name, "-q", "-C", "check-update")
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 100) {
+			return nil, fmt.Errorf("%s check-update: %w", name, err)
+		}
+		info := parseDnfCheckUpdate(string(output))
+		info.Source = name
+		return info, nil
+	}
+	return nil, fmt.Errorf("no supported package manager (apt-get, dnf, yum) found")
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+const aptSimulation = `Reading package lists...
+Building dependency tree...
+The following packages will be upgraded:
+  libssl3 openssl tzdata
+Inst libssl3 [3.0.2-0ubuntu1.14] (3.0.2-0ubuntu1.15 Ubuntu:22.04/jammy-security [amd64])
+Inst openssl [3.0.2-0ubuntu1.14] (3.0.2-0ubuntu1.15 Ubuntu:22.04/jammy-security [amd64])
+Inst tzdata [2024a-0ubuntu0.22.04] (2024b-0ubuntu0.22.04 Ubuntu:22.04/jammy-updates [all])
+Conf libssl3 (3.0.2-0ubuntu1.15 Ubuntu:22.04/jammy-security [amd64])
+`
+
+const dnfCheckUpdate = `
+kernel.x86_64                 5.14.0-427.el9          baseos
+openssh-server.x86_64         8.7p1-38.el9            baseos
+Obsoleting Packages
+grub2-tools.x86_64            1:2.06-80.el9           baseos
+    grub2-tools.x86_64        1:2.06-77.el9           @baseos
+`
+
+func TestParseAptSimulation(t *testing.T) {
+	info := parseAptSimulation(aptSimulation)
+	if info.Pending != 3 || info.Security != 2 {
+		t.Errorf("pending/security = %d/%d, want 3/2", info.Pending, info.Security)
+	}
+	if fmt.Sprint(info.Names) != "[libssl3 openssl tzdata]" {
+		t.Errorf("unexpected names: %v", info.Names)
+	}
+}
+
+func TestParseDnfCheckUpdate(t *testing.T) {
+	info := parseDnfCheckUpdate(dnfCheckUpdate)
+	if info.Pending != 2 || fmt.Sprint(info.Names) != "[kernel openssh-server]" {
+		t.Errorf("unexpected updates: %+v", info)
+	}
+}
+
+// stubPackageManager makes only name available, replying with output and err
+func stubPackageManager(t *testing.T, name, output string, err error) *int {
+	t.Helper()
+	calls := 0
+	origLook, origRun, origCfg := lookPackageManager, runPackageManager, Cfg
+	t.Cleanup(func() {
+		lookPackageManager, runPackageManager, Cfg = origLook, origRun, origCfg
+		updatesCache.info, updatesCache.err, updatesCache.checked = nil, nil, time.Time{}
+		packagesCache.packages, packagesCache.err, packagesCache.checked = nil, nil, time.Time{}
+	})
+	lookPackageManager = func(file string) (string, error) {
+		if file == name {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	runPackageManager = func(string, ...string) ([]byte, error) {
+		calls++
+		return []byte(output), err
+	}
+	updatesCache.checked, packagesCache.checked = time.Time{}, time.Time{}
+	return &calls
+}
+
+func TestGetUpdatesDnfExitCode(t *testing.T) {
+	setupTestAgent(t)
+	exitErr := exec.Command("sh", "-c", "exit 100").Run()
+	stubPackageManager(t, "dnf", dnfCheckUpdate, exitErr)
+	Cfg = Config{Updates: true, UpdatesInterval: time.Hour}
+
+	info, err := getUpdates()
+	if err != nil {
+		t.Fatalf("getUpdates: %v", err)
+	}
+	if info.Source != "dnf" || info.Pending != 2 || info.Names != nil {
+		t.Errorf("unexpected updates: %+v", info)
+	}
+	if _, err := time.Parse(time.RFC3339, info.CheckedAt); err != nil {
+		t.Errorf("checked_at %q: %v", info.CheckedAt, err)
+	}
+}
+
+func TestGetUpdatesCachesResult(t *testing.T) {
+	setupTestAgent(t)
+	calls := stubPackageManager(t, "apt-get", aptSimulation, nil)
+	Cfg = Config{Updates: true, UpdatesNames: true, UpdatesInterval: time.Hour}
+
+	for i := 0; i < 2; i++ {
+		info, err := getUpdates()
+		if err != nil {
+			t.Fatalf("getUpdates: %v", err)
+		}
+		if info.Source != "apt" || len(info.Names) != 3 {
+			t.Errorf("unexpected updates: %+v", info)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("apt-get ran %d times, want 1", *calls)
+	}
+
+	Cfg.Updates = false
+	if info, _ := getUpdates(); info != nil {
+		t.Errorf("expected no updates when disabled, got %+v", info)
+	}
+}
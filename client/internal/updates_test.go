@@ -0,0 +1,22 @@
+package internal
+
+import "testing"
+
+func TestWindowsUpdateMode(t *testing.T) {
+	cases := []struct {
+		noAutoUpdate, auOptions uint64
+		want                    string
+	}{
+		{0, 0, "automatic"},
+		{1, 4, "disabled"},
+		{0, 2, "notify"},
+		{0, 4, "scheduled"},
+		{0, 7, "notify-restart"},
+		{0, 9, "unknown"},
+	}
+	for _, c := range cases {
+		if got := windowsUpdateMode(c.noAutoUpdate, c.auOptions); got != c.want {
+			t.Errorf("windowsUpdateMode(%d, %d) = %q, want %q", c.noAutoUpdate, c.auOptions, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+//go:build windows
+
+package internal
+
+import (
+	"fmt"
+
+	ole "github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+// pendingUpdatesCriteria selects software updates offered but not installed
+const pendingUpdatesCriteria = "IsInstalled=0 and Type='Software' and IsHidden=0"
+
+// platformUpdates searches pending updates through the Windows Update Agent
+// COM API, against the configured source (Windows Update or WSUS)
+func platformUpdates() (*UpdateInfo, error) {
+	release, err := comInitialize()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	unknown, err := oleutil.CreateObject("Microsoft.Update.Session")
+	if err != nil {
+		return nil, fmt.Errorf("Microsoft.Update.Session: %w", err)
+	}
+	defer unknown.Release()
+	session, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Release()
+
+	searcherVar, err := oleutil.CallMethod(session, "CreateUpdateSearcher")
+	if err != nil {
+		return nil, fmt.Errorf("CreateUpdateSearcher: %w", err)
+	}
+	searcher := searcherVar.ToIDispatch()
+	defer searcher.Release()
+
+	resultVar, err := oleutil.CallMethod(searcher, "Search", pendingUpdatesCriteria)
+	if err != nil {
+		return nil, fmt.Errorf("update search: %w", err)
+	}
+	result := resultVar.ToIDispatch()
+	defer result.Release()
+
+	updatesVar, err := oleutil.GetProperty(result, "Updates")
+	if err != nil {
+		return nil, err
+	}
+	updates := updatesVar.ToIDispatch()
+	defer updates.Release()
+	countVar, err := oleutil.GetProperty(updates, "Count")
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UpdateInfo{Source: "windows-update"}
+	for i := 0; i < int(countVar.Val); i++ {
+		itemVar, err := oleutil.GetProperty(updates, "Item", i)
+		if err != nil {
+			return nil, err
+		}
+		item := itemVar.ToIDispatch()
+		info.Pending++
+		if title, err := oleutil.GetProperty(item, "Title"); err == nil {
+			info.Names = append(info.Names, title.ToString())
+		}
+		// only security bulletins carry an MSRC severity
+		if severity, err := oleutil.GetProperty(item, "MsrcSeverity"); err == nil && severity.Value() != nil && severity.ToString() != "" {
+			info.Security++
+		}
+		item.Release()
+	}
+	return info, nil
+}
@@ -0,0 +1,49 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ValidatePayload checks a JSON payload against PayloadSchema and the value
+// rules the schema cannot express, returning the decoded payload and the
+// problems found (empty when valid)
+func ValidatePayload(data []byte) (MachineInfo, []string) {
+	var info MachineInfo
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return info, []string{fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	if problems := PayloadSchema().Validate(doc); len(problems) > 0 {
+		return info, problems
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, []string{err.Error()}
+	}
+
+	var problems []string
+	if info.Hostname == "" {
+		problems = append(problems, "hostname is empty")
+	}
+	if info.IP != "" && net.ParseIP(info.IP) == nil {
+		problems = append(problems, fmt.Sprintf("ip %q is not an IP address", info.IP))
+	}
+	if info.MemoryUsedMB > info.MemoryTotalMB {
+		problems = append(problems, "memory_used_mb is greater than memory_total_mb")
+	}
+	if info.Timestamp != "" {
+		if _, err := time.Parse(time.RFC3339, info.Timestamp); err != nil {
+			problems = append(problems, fmt.Sprintf("timestamp %q is not RFC 3339", info.Timestamp))
+		}
+	}
+	for _, a := range info.Addresses {
+		if net.ParseIP(a.IP) == nil {
+			problems = append(problems, fmt.Sprintf("addresses: %q of interface %s is not an IP address", a.IP, a.Interface))
+		}
+	}
+	return info, problems
+}
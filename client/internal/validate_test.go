@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidatePayloadSimulated(t *testing.T) {
+	setupTestAgent(t)
+	for _, m := range NewSimulatedFleet(20, 3) {
+		data, err := json.Marshal(m.Next())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, problems := ValidatePayload(data); len(problems) > 0 {
+			t.Fatalf("unexpected problems: %v", problems)
+		}
+	}
+}
+
+func TestValidatePayloadProblems(t *testing.T) {
+	// payload builds a valid payload with the given fields replaced
+	payload := func(fields string) string {
+		base := `"schema_version": 1, "machine_id": "` + strings.Repeat("a", 64) + `", "cohort": 7, "hostname": "h", "ip": "10.0.0.1", "os": "linux",
+			"os_version": "", "is_virtual": false, "cpu_percent": 1, "memory_total_mb": 2, "memory_used_mb": 1, "timestamp": ""`
+		return "{" + base + fields + "}"
+	}
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"not an object", `[1, 2]`, "payload: expected object, got array"},
+		{"invalid JSON", `{"hostname": `, "invalid JSON"},
+		{"missing fields", `{"hostname": "lab-01"}`, `missing required field "machine_id"`},
+		{"unknown field", payload(`, "hostnme": "typo"`), `unknown field "hostnme"`},
+		{"unknown nested field", payload(`, "addresses": [{"interface": "eth0", "ip": "10.0.0.1", "mask": 24}]`), `unknown field "addresses[0].mask"`},
+		{"wrong type", payload(`, "cpu_percent": "high"`), "cpu_percent: expected number, got string"},
+		{"fractional integer", payload(`, "memory_total_mb": 1.5`), "memory_total_mb: expected integer"},
+		{"bad machine id", payload(`, "machine_id": "abc"`), "machine_id: \"abc\" does not match"},
+		{"bad os", payload(`, "os": "plan9"`), `os: "plan9" is not one of`},
+		{"cpu out of range", payload(`, "cpu_percent": 140`), "cpu_percent: 140 is greater than 100"},
+		{"cohort out of range", payload(`, "cohort": 100`), "cohort: 100 is greater than 99"},
+		{"bad ip", payload(`, "ip": "10.0.0"`), `ip "10.0.0"`},
+		{"memory", payload(`, "memory_used_mb": 3`), "memory_used_mb is greater"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, problems := ValidatePayload([]byte(tt.payload))
+			found := false
+			for _, p := range problems {
+				if strings.Contains(p, tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a problem containing %q, got %v", tt.want, problems)
+			}
+		})
+	}
+}
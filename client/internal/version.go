@@ -0,0 +1,63 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// BuildInfo identifies the agent build, injected at link time by the build
+// scripts (-X main.version, main.commit, main.buildDate)
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+}
+
+// agentBuild is reported to the server in the payload and User-Agent header
+var agentBuild = BuildInfo{Version: "dev"}
+
+// agentVersion is the version part of agentBuild
+var agentVersion = agentBuild.Version
+
+// SetBuildInfo sets the build reported by the internal functions. A missing
+// commit or date is taken from the VCS stamp of `go build`, when present.
+func SetBuildInfo(build BuildInfo) {
+	if build.Version == "" {
+		build.Version = "dev"
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && build.Commit == "":
+				build.Commit = s.Value
+			case s.Key == "vcs.time" && build.BuildDate == "":
+				build.BuildDate = s.Value
+			}
+		}
+	}
+	agentBuild, agentVersion = build, build.Version
+}
+
+// AgentBuild returns the build set by SetBuildInfo
+func AgentBuild() BuildInfo {
+	return agentBuild
+}
+
+// ShortCommit returns the first 12 characters of the build commit
+func (b BuildInfo) ShortCommit() string {
+	if len(b.Commit) > 12 {
+		return b.Commit[:12]
+	}
+	return b.Commit
+}
+
+// userAgent returns the User-Agent header sent by the agent
+func userAgent() string {
+	if commit := agentBuild.ShortCommit(); commit != "" {
+		return fmt.Sprintf("TatuScan/%s (%s; %s)", agentVersion, runtime.GOOS, commit)
+	}
+	return fmt.Sprintf("TatuScan/%s (%s)", agentVersion, runtime.GOOS)
+}
@@ -0,0 +1,54 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import "strings"
+
+// hypervisorSignatures maps lowercase DMI/WMI vendor and product substrings
+// to hypervisor names
+var hypervisorSignatures = []struct{ pattern, name string }{
+	{"vmware", "VMware"},
+	{"virtualbox", "VirtualBox"},
+	{"innotek", "VirtualBox"},
+	{"qemu", "KVM"},
+	{"kvm", "KVM"},
+	{"amazon ec2", "KVM"},
+	{"google compute engine", "KVM"},
+	{"xen", "Xen"},
+	{"parallels", "Parallels"},
+}
+
+// detectHypervisor identifies the hypervisor from vendor/product strings,
+// returning "" for physical hardware
+func detectHypervisor(hints ...string) string {
+	var joined []string
+	for _, h := range hints {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			joined = append(joined, h)
+		}
+	}
+	all := strings.Join(joined, "\n")
+	// Hyper-V guests report Microsoft as vendor and "Virtual Machine" as model
+	if strings.Contains(all, "microsoft corporation") && strings.Contains(all, "virtual machine") {
+		return "Hyper-V"
+	}
+	for _, sig := range hypervisorSignatures {
+		if strings.Contains(all, sig.pattern) {
+			return sig.name
+		}
+	}
+	return ""
+}
+
+// getVirtualization reports whether the machine is a guest and which
+// hypervisor runs it, from the SMBIOS identity, platform hints and the CPUID
+// hypervisor bit where available
+func getVirtualization(manufacturer, model string) (bool, string) {
+	cpuFlag, hints := platformVirtualization()
+	hypervisor := detectHypervisor(append(hints, manufacturer, model)...)
+	if hypervisor == "" && cpuFlag {
+		hypervisor = "unknown"
+	}
+	Log.Debugf("Virtualization detected: %v (%s)", hypervisor != "", hypervisor)
+	return hypervisor != "", hypervisor
+}
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package internal
+
+import (
+	"strings"
+)
+
+// platformVirtualization reads kern.hv_vmm_present, set by macOS when it
+// runs under a hypervisor
+func platformVirtualization() (bool, []string) {
+	output, err := runCommand("sysctl", "-n", "kern.hv_vmm_present")
+	if err != nil {
+		Log.Debugf("Error to read kern.hv_vmm_present: %v", err)
+		return false, nil
+	}
+	return strings.TrimSpace(string(output)) == "1", nil
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package internal
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cpuinfoHasHypervisorFlag reports whether /proc/cpuinfo lists the
+// "hypervisor" flag (CPUID leaf 1, ECX bit 31), set only inside guests
+func cpuinfoHasHypervisorFlag() bool {
+	f, err := os.Open(filepath.Join(procRoot, "cpuinfo"))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			if flag == "hypervisor" {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// platformVirtualization reads the CPUID hypervisor flag and the DMI/Xen
+// vendor strings from sysfs
+func platformVirtualization() (bool, []string) {
+	var hints []string
+	for _, rel := range []string{"hypervisor/type", "class/dmi/id/bios_vendor", "class/dmi/id/board_vendor", "class/dmi/id/product_version"} {
+		if data, err := os.ReadFile(filepath.Join(sysfsRoot, rel)); err == nil {
+			hints = append(hints, strings.TrimSpace(string(data)))
+		}
+	}
+	return cpuinfoHasHypervisorFlag(), hints
+}
@@ -0,0 +1,25 @@
+package internal
+
+import "testing"
+
+func TestDetectHypervisor(t *testing.T) {
+	tests := []struct {
+		hints []string
+		want  string
+	}{
+		{[]string{"VMware, Inc.", "VMware7,1"}, "VMware"},
+		{[]string{"innotek GmbH", "VirtualBox"}, "VirtualBox"},
+		{[]string{"Microsoft Corporation", "Virtual Machine"}, "Hyper-V"},
+		{[]string{"QEMU", "Standard PC (i440FX + PIIX, 1996)"}, "KVM"},
+		{[]string{"xen", "Xen", "HVM domU"}, "Xen"},
+		{[]string{"Amazon EC2", "t3.micro"}, "KVM"},
+		{[]string{"Parallels International GmbH.", "Parallels ARM Virtual Machine"}, "Parallels"},
+		{[]string{"Microsoft Corporation", "Surface Laptop 5"}, ""},
+		{[]string{"Dell Inc.", "OptiPlex 7010", ""}, ""},
+	}
+	for _, tt := range tests {
+		if got := detectHypervisor(tt.hints...); got != tt.want {
+			t.Errorf("detectHypervisor(%q) = %q, want %q", tt.hints, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package internal
+
+import "github.com/StackExchange/wmi"
+
+// platformVirtualization returns the BIOS vendor strings from WMI. The CPUID
+// hypervisor bit is not used: it is also set on hosts running Hyper-V or
+// virtualization-based security.
+func platformVirtualization() (bool, []string) {
+	type win32BIOS struct {
+		Manufacturer      *string
+		SMBIOSBIOSVersion *string
+		Version           *string
+	}
+	var dst []win32BIOS
+	if err := wmiQuery(wmi.CreateQuery(&dst, "", "Win32_BIOS"), &dst, ""); err != nil {
+		Log.Debugf("Error to query Win32_BIOS: %v", err)
+		return false, nil
+	}
+	var hints []string
+	for _, b := range dst {
+		for _, v := range []*string{b.Manufacturer, b.SMBIOSBIOSVersion, b.Version} {
+			if v != nil {
+				hints = append(hints, *v)
+			}
+		}
+	}
+	return false, hints
+}
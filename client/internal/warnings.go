@@ -0,0 +1,92 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Warning is a machine-readable health issue found during collection, so the
+// server can aggregate problems by code instead of parsing logs
+type Warning struct {
+	Code      string `json:"code"`
+	Collector string `json:"collector,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Warning codes reported in the payload
+const (
+	WarnCollectorFailed             = "collector_failed"
+	WarnWMIUnavailable              = "wmi_unavailable"
+	WarnOnlyLocallyAdministeredMACs = "only_locally_administered_macs"
+	WarnNoIPv4                      = "no_ipv4"
+	WarnMachineIDDrift              = "machine_id_drift"
+	WarnStateNotPersisted           = "state_not_persisted"
+	WarnRunningInContainer          = "running_in_container"
+	WarnClockSkew                   = "clock_skew"
+	WarnCycleOverrun                = "cycle_overrun"
+	WarnCollectionTimeout           = "collection_timeout"
+	WarnCollectorTimeout            = "collector_timeout"
+	WarnPseudonymKeyUnavailable     = "pseudonym_key_unavailable"
+)
+
+// clockSkewThreshold is the clock difference to the server reported as skew
+const clockSkewThreshold = 2 * time.Minute
+
+var (
+	warningsMu      sync.Mutex
+	pendingWarnings []Warning
+	lastClockSkew   time.Duration
+)
+
+// collectorKey is the context key of the running collector name
+type collectorKey struct{}
+
+// withCollector returns a context tagging warnings with a collector name;
+// collectors run concurrently, so the name travels with their context
+func withCollector(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, collectorKey{}, name)
+}
+
+// addWarning records a warning for the payload being collected, tagged with
+// the collector of ctx
+func addWarning(ctx context.Context, code, format string, args ...any) {
+	name, _ := ctx.Value(collectorKey{}).(string)
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	pendingWarnings = append(pendingWarnings, Warning{
+		Code:      code,
+		Collector: name,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// takeWarnings returns and clears the recorded warnings
+func takeWarnings() []Warning {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warnings := pendingWarnings
+	pendingWarnings = nil
+	return warnings
+}
+
+// recordServerTime keeps the difference between the local clock and a server
+// Date header, reported as clock_skew by the next collection
+func recordServerTime(server time.Time) {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	lastClockSkew = time.Since(server)
+}
+
+// checkClockSkew warns when the last measured skew exceeds the threshold
+func checkClockSkew(ctx context.Context) {
+	warningsMu.Lock()
+	skew := lastClockSkew
+	warningsMu.Unlock()
+	if skew > clockSkewThreshold || skew < -clockSkewThreshold {
+		addWarning(ctx, WarnClockSkew, "local clock differs from the server by %s", skew.Round(time.Second))
+	}
+}
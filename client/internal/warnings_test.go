@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestOnlyLocallyAdministeredMACsWarning(t *testing.T) {
+	setupTestAgent(t)
+	takeWarnings()
+	hw, _ := net.ParseMAC("02:42:ac:11:00:02")
+	scan := scanInterfaces(withCollector(context.Background(), "network"), []NetInterface{MockInterface{
+		name:         "eth0",
+		flags:        net.FlagUp | net.FlagBroadcast,
+		hardwareAddr: hw,
+		addrs:        []net.Addr{&net.IPNet{IP: net.ParseIP("172.17.0.2"), Mask: net.CIDRMask(16, 32)}},
+	}})
+	if len(scan.MACs) != 0 {
+		t.Fatalf("expected no physical MAC, got %v", scan.MACs)
+	}
+	warnings := takeWarnings()
+	if len(warnings) != 1 || warnings[0].Code != WarnOnlyLocallyAdministeredMACs || warnings[0].Collector != "network" {
+		t.Errorf("unexpected warnings: %+v", warnings)
+	}
+	if again := takeWarnings(); len(again) != 0 {
+		t.Errorf("warnings not cleared: %+v", again)
+	}
+}
+
+func TestClockSkewWarning(t *testing.T) {
+	setupTestAgent(t)
+	takeWarnings()
+	t.Cleanup(func() { recordServerTime(time.Now()) })
+
+	recordServerTime(time.Now().Add(-30 * time.Second))
+	checkClockSkew(context.Background())
+	if warnings := takeWarnings(); len(warnings) != 0 {
+		t.Errorf("30s skew must not warn: %+v", warnings)
+	}
+
+	recordServerTime(time.Now().Add(10 * time.Minute))
+	checkClockSkew(context.Background())
+	if warnings := takeWarnings(); len(warnings) != 1 || warnings[0].Code != WarnClockSkew {
+		t.Errorf("expected clock_skew, got %+v", warnings)
+	}
+}
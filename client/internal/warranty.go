@@ -0,0 +1,128 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultWarrantyHookTimeout bounds the execution of the warranty hook
+const defaultWarrantyHookTimeout = 10 * time.Second
+
+// lookupWarrantyCSV finds the row matching serial in a CSV file whose header
+// contains a "serial" column; other columns are returned by header name
+func lookupWarrantyCSV(r io.Reader, serial string) (map[string]any, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	serialCol := -1
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), "serial") {
+			serialCol = i
+			break
+		}
+	}
+	if serialCol < 0 {
+		return nil, fmt.Errorf("no \"serial\" column in header")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if serialCol >= len(record) || !strings.EqualFold(strings.TrimSpace(record[serialCol]), serial) {
+			continue
+		}
+		row := make(map[string]any, len(header))
+		for i, name := range header {
+			if i == serialCol || i >= len(record) {
+				continue
+			}
+			if value := strings.TrimSpace(record[i]); value != "" {
+				row[strings.TrimSpace(name)] = value
+			}
+		}
+		return row, nil
+	}
+}
+
+// runWarrantyHook executes the hook with the serial as argument (and in
+// TATUSCAN_SERIAL) and decodes the JSON object it prints on stdout
+func runWarrantyHook(path, serial string, timeout time.Duration) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, serial)
+	cmd.Env = append(os.Environ(), "TATUSCAN_SERIAL="+serial)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("execute %s: %w", path, err)
+	}
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return nil, nil
+	}
+	var result map[string]any
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("hook output is not a JSON object: %w", err)
+	}
+	return result, nil
+}
+
+// getWarrantyInfo attaches warranty/purchase data for the given serial from
+// the configured CSV file and/or exec hook (hook values take precedence)
+func getWarrantyInfo(serial string) map[string]any {
+	if serial == "" || (Cfg.WarrantyCSV == "" && Cfg.WarrantyHook == "") {
+		return nil
+	}
+
+	warranty := make(map[string]any)
+	if Cfg.WarrantyCSV != "" {
+		Log.Debugf("Looking up serial %s in %s", serial, Cfg.WarrantyCSV)
+		f, err := os.Open(Cfg.WarrantyCSV)
+		if err != nil {
+			Log.Warnf("Error to open warranty CSV: %v", err)
+		} else {
+			row, err := lookupWarrantyCSV(f, serial)
+			f.Close()
+			if err != nil {
+				Log.Warnf("Error to read warranty CSV: %v", err)
+			}
+			for k, v := range row {
+				warranty[k] = v
+			}
+		}
+	}
+
+	if Cfg.WarrantyHook != "" {
+		Log.Debugf("Running warranty hook %s", Cfg.WarrantyHook)
+		result, err := runWarrantyHook(Cfg.WarrantyHook, serial, Cfg.WarrantyHookTimeout)
+		if err != nil {
+			Log.Warnf("Error to run warranty hook: %v", err)
+		}
+		for k, v := range result {
+			warranty[k] = v
+		}
+	}
+
+	if len(warranty) == 0 {
+		return nil
+	}
+	return warranty
+}
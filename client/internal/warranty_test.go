@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupWarrantyCSV(t *testing.T) {
+	data := `serial,purchase_order,warranty_end
+PF2ABCDE, PO-1001, 2027-03-01
+pf9zzzzz,PO-2002,
+`
+	row, err := lookupWarrantyCSV(strings.NewReader(data), "PF2ABCDE")
+	if err != nil {
+		t.Fatalf("lookupWarrantyCSV: %v", err)
+	}
+	if row["purchase_order"] != "PO-1001" || row["warranty_end"] != "2027-03-01" {
+		t.Errorf("unexpected row: %v", row)
+	}
+
+	row, err = lookupWarrantyCSV(strings.NewReader(data), "PF9ZZZZZ")
+	if err != nil {
+		t.Fatalf("lookupWarrantyCSV: %v", err)
+	}
+	if _, ok := row["warranty_end"]; ok || row["purchase_order"] != "PO-2002" {
+		t.Errorf("unexpected row for case-insensitive match: %v", row)
+	}
+
+	row, err = lookupWarrantyCSV(strings.NewReader(data), "UNKNOWN")
+	if err != nil || row != nil {
+		t.Errorf("expected no match, got %v (err %v)", row, err)
+	}
+}
+
+func TestLookupWarrantyCSVWithoutSerialColumn(t *testing.T) {
+	if _, err := lookupWarrantyCSV(strings.NewReader("asset,po\nA,B\n"), "A"); err == nil {
+		t.Error("expected error for missing serial column")
+	}
+}
@@ -0,0 +1,118 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// WatchedProcess is a running process whose name is in the configured watchlist
+type WatchedProcess struct {
+	Name        string              `json:"name"`
+	PID         int32               `json:"pid"`
+	Exe         string              `json:"exe,omitempty"`
+	Username    string              `json:"username,omitempty"`
+	Connections []ProcessConnection `json:"connections,omitempty"`
+}
+
+// ProcessConnection is a network socket owned by a watched process
+type ProcessConnection struct {
+	Protocol string `json:"protocol"`
+	Local    string `json:"local"`
+	Remote   string `json:"remote,omitempty"`
+	Status   string `json:"status,omitempty"`
+}
+
+// normalizeProcessName lowercases a process name and strips the .exe suffix
+func normalizeProcessName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".exe")
+}
+
+// watchlistMatch reports whether a process name is in the watchlist
+func watchlistMatch(name string, watchlist []string) bool {
+	normalized := normalizeProcessName(name)
+	for _, watched := range watchlist {
+		if normalized == normalizeProcessName(watched) {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionProtocol names a gopsutil connection by family and type
+func connectionProtocol(c psnet.ConnectionStat) string {
+	proto := "tcp"
+	if c.Type == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	if c.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+// processConnections returns listening and established sockets of a process
+func processConnections(pid int32) []ProcessConnection {
+	conns, err := psnet.ConnectionsPid("inet", pid)
+	if err != nil {
+		Log.Debugf("Error to collect connections of PID %d: %v", pid, err)
+		return nil
+	}
+	var result []ProcessConnection
+	for _, c := range conns {
+		if c.Raddr.IP == "" && c.Status != "LISTEN" {
+			continue
+		}
+		pc := ProcessConnection{
+			Protocol: connectionProtocol(c),
+			Local:    fmt.Sprintf("%s:%d", c.Laddr.IP, c.Laddr.Port),
+			Status:   c.Status,
+		}
+		if c.Raddr.IP != "" {
+			pc.Remote = fmt.Sprintf("%s:%d", c.Raddr.IP, c.Raddr.Port)
+		}
+		result = append(result, pc)
+	}
+	return result
+}
+
+// getWatchlist reports running processes matching the configured watchlist
+func getWatchlist() []WatchedProcess {
+	if len(Cfg.Watchlist) == 0 {
+		return nil
+	}
+
+	Log.Debugf("Scanning processes for watchlist: %v", Cfg.Watchlist)
+	procs, err := process.Processes()
+	if err != nil {
+		Log.Warnf("Error to list processes: %v", err)
+		return nil
+	}
+
+	var watched []WatchedProcess
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil || !watchlistMatch(name, Cfg.Watchlist) {
+			continue
+		}
+		wp := WatchedProcess{Name: name, PID: p.Pid}
+		if exe, err := p.Exe(); err == nil {
+			wp.Exe = exe
+		}
+		if user, err := p.Username(); err == nil {
+			wp.Username = user
+		}
+		wp.Connections = processConnections(p.Pid)
+		Log.Debugf("Watched process found: %s (PID %d, %d connections)", name, p.Pid, len(wp.Connections))
+		watched = append(watched, wp)
+	}
+
+	sort.Slice(watched, func(i, j int) bool { return watched[i].PID < watched[j].PID })
+	return watched
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWatchlistMatch(t *testing.T) {
+	watchlist := []string{"xmrig", "AnyDesk.exe", "steam"}
+	tests := []struct {
+		name     string
+		process  string
+		expected bool
+	}{
+		{"Exact", "xmrig", true},
+		{"Windows exe", "xmrig.exe", true},
+		{"Case insensitive", "ANYDESK.EXE", true},
+		{"Configured with exe", "anydesk", true},
+		{"Prefix only", "steamwebhelper", false},
+		{"Unrelated", "bash", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watchlistMatch(tt.process, watchlist); got != tt.expected {
+				t.Errorf("watchlistMatch(%q) = %v, want %v", tt.process, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList(" a, b ,,c ")
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitList = %v, want %v", got, want)
+	}
+	if got := splitList(""); got != nil {
+		t.Errorf("splitList(\"\") = %v, want nil", got)
+	}
+}
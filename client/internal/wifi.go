@@ -0,0 +1,231 @@
+//go:build windows || linux || darwin
+
+package internal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// WiFiInfo describes the wireless link of the primary interface
+type WiFiInfo struct {
+	Interface     string  `json:"interface"`
+	SSID          string  `json:"ssid,omitempty"`
+	BSSID         string  `json:"bssid,omitempty"`
+	Band          string  `json:"band,omitempty"` // 2.4GHz, 5GHz or 6GHz
+	Channel       int     `json:"channel,omitempty"`
+	SignalDBm     int     `json:"signal_dbm,omitempty"`
+	SignalPercent int     `json:"signal_percent,omitempty"`
+	TxRateMbps    float64 `json:"tx_rate_mbps,omitempty"`
+}
+
+// wifiBandOfFrequency returns the band and channel of a frequency in MHz
+func wifiBandOfFrequency(mhz int) (string, int) {
+	switch {
+	case mhz == 2484:
+		return "2.4GHz", 14
+	case mhz >= 2400 && mhz < 2500:
+		return "2.4GHz", (mhz - 2407) / 5
+	case mhz >= 5925 && mhz <= 7125:
+		return "6GHz", (mhz - 5950) / 5
+	case mhz >= 4900 && mhz < 5925:
+		return "5GHz", (mhz - 5000) / 5
+	}
+	return "", 0
+}
+
+// wifiBandOfChannel guesses the band of a channel reported without one
+func wifiBandOfChannel(channel int) string {
+	switch {
+	case channel <= 0:
+		return ""
+	case channel <= 14:
+		return "2.4GHz"
+	}
+	return "5GHz"
+}
+
+// fillSignal completes the signal in dBm or percent from the other, with the
+// linear mapping Windows uses (-100 dBm = 0%, -50 dBm = 100%)
+func (w *WiFiInfo) fillSignal() {
+	switch {
+	case w.SignalDBm != 0 && w.SignalPercent == 0:
+		w.SignalPercent = min(max(2*(w.SignalDBm+100), 0), 100)
+	case w.SignalPercent != 0 && w.SignalDBm == 0:
+		w.SignalDBm = w.SignalPercent/2 - 100
+	}
+}
+
+// firstNumber parses the leading number of a value such as "-52 dBm"
+func firstNumber(value string) float64 {
+	fields := strings.Fields(strings.TrimSpace(value))
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseFloat(strings.TrimSuffix(fields[0], "%"), 64)
+	return n
+}
+
+// parseIwLink parses `iw dev <interface> link`; nil when not connected
+func parseIwLink(name, output string) *WiFiInfo {
+	var info *WiFiInfo
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "Connected to "); ok {
+			info = &WiFiInfo{Interface: name, BSSID: strings.ToLower(strings.Fields(rest)[0])}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if info == nil || !ok {
+			continue
+		}
+		switch key {
+		case "SSID":
+			info.SSID = strings.TrimSpace(value)
+		case "freq":
+			info.Band, info.Channel = wifiBandOfFrequency(int(firstNumber(value)))
+		case "signal":
+			info.SignalDBm = int(firstNumber(value))
+		case "tx bitrate":
+			info.TxRateMbps = firstNumber(value)
+		}
+	}
+	if info != nil {
+		info.fillSignal()
+	}
+	return info
+}
+
+// parseNetshWlan parses `netsh wlan show interfaces` for one interface;
+// nil when it is not listed or not connected
+func parseNetshWlan(name, output string) *WiFiInfo {
+	var info *WiFiInfo
+	current, connected := false, false
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "Name" {
+			current = strings.EqualFold(value, name)
+			if current {
+				info = &WiFiInfo{Interface: value}
+			}
+			continue
+		}
+		if !current {
+			continue
+		}
+		switch key {
+		case "State":
+			connected = value == "connected"
+		case "SSID":
+			info.SSID = value
+		case "BSSID", "AP BSSID":
+			info.BSSID = strings.ToLower(value)
+		case "Band":
+			info.Band = strings.ReplaceAll(value, " ", "")
+		case "Channel":
+			info.Channel = int(firstNumber(value))
+		case "Signal":
+			info.SignalPercent = int(firstNumber(value))
+		case "Transmit rate (Mbps)":
+			info.TxRateMbps = firstNumber(value)
+		}
+	}
+	if info == nil || !connected {
+		return nil
+	}
+	if info.Band == "" {
+		info.Band = wifiBandOfChannel(info.Channel)
+	}
+	info.fillSignal()
+	return info
+}
+
+// parseAirportInfo parses `airport -I` (macOS 14.3 and older); nil when
+// not associated
+func parseAirportInfo(name, output string) *WiFiInfo {
+	info := &WiFiInfo{Interface: name}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "agrCtlRSSI":
+			info.SignalDBm = int(firstNumber(value))
+		case "lastTxRate":
+			info.TxRateMbps = firstNumber(value)
+		case "BSSID":
+			// the value holds colons too
+			info.BSSID = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "BSSID:")))
+		case "SSID":
+			info.SSID = value
+		case "channel":
+			// "36,80": channel and width
+			channel, _, _ := strings.Cut(value, ",")
+			info.Channel, _ = strconv.Atoi(channel)
+			info.Band = wifiBandOfChannel(info.Channel)
+		}
+	}
+	if info.SSID == "" && info.BSSID == "" {
+		return nil
+	}
+	info.fillSignal()
+	return info
+}
+
+// parseWdutilInfo parses the WIFI section of `wdutil info` (macOS 14.4 and
+// newer); nil when not associated
+func parseWdutilInfo(name, output string) *WiFiInfo {
+	info := &WiFiInfo{Interface: name}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "None" || value == "<redacted>" {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "SSID":
+			info.SSID = value
+		case "BSSID":
+			info.BSSID = strings.ToLower(value)
+		case "RSSI":
+			info.SignalDBm = int(firstNumber(value))
+		case "Tx Rate":
+			info.TxRateMbps = firstNumber(value)
+		case "Channel":
+			// "5g36/80": band, channel and width
+			band, rest, _ := strings.Cut(value, "g")
+			channel, _, _ := strings.Cut(rest, "/")
+			info.Channel, _ = strconv.Atoi(channel)
+			switch band {
+			case "2":
+				info.Band = "2.4GHz"
+			case "5", "6":
+				info.Band = band + "GHz"
+			}
+		}
+	}
+	if info.SSID == "" && info.BSSID == "" {
+		return nil
+	}
+	info.fillSignal()
+	return info
+}
+
+// primaryInterface returns the interface holding the primary IP
+func primaryInterface(info *MachineInfo) string {
+	for _, addr := range info.Addresses {
+		if addr.IP == info.IP {
+			return addr.Interface
+		}
+	}
+	return ""
+}
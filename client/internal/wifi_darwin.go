@@ -0,0 +1,28 @@
+//go:build darwin
+
+package internal
+
+import (
+	"fmt"
+	"os"
+)
+
+// airportPath is the airport tool, removed in macOS 14.4
+const airportPath = "/System/Library/PrivateFrameworks/Apple80211.framework/Versions/Current/Resources/airport"
+
+// getWiFiInfo reads the Wi-Fi link with airport, or wdutil on the macOS
+// releases without it
+func getWiFiInfo(name string) (*WiFiInfo, error) {
+	if _, err := os.Stat(airportPath); err == nil {
+		output, err := runCommand(airportPath, "-I")
+		if err != nil {
+			return nil, fmt.Errorf("airport: %w", err)
+		}
+		return parseAirportInfo(name, string(output)), nil
+	}
+	output, err := runCommand("wdutil", "info")
+	if err != nil {
+		return nil, fmt.Errorf("wdutil: %w", err)
+	}
+	return parseWdutilInfo(name, string(output)), nil
+}
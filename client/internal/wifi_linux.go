@@ -0,0 +1,22 @@
+//go:build linux
+
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// getWiFiInfo reads the link of a wireless interface with iw, reporting
+// nothing when iw is not installed
+func getWiFiInfo(name string) (*WiFiInfo, error) {
+	if _, err := exec.LookPath("iw"); err != nil {
+		Log.Debugf("iw not found; Wi-Fi link of %s not collected", name)
+		return nil, nil
+	}
+	output, err := runCommand("iw", "dev", name, "link")
+	if err != nil {
+		return nil, fmt.Errorf("iw: %w", err)
+	}
+	return parseIwLink(name, string(output)), nil
+}
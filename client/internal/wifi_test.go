@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+const iwLink = `Connected to AA:BB:CC:00:11:22 (on wlp2s0)
+	SSID: LabNet
+	freq: 5180.0
+	RX: 1489374 bytes (9871 packets)
+	TX: 129837 bytes (1021 packets)
+	signal: -67 dBm
+	rx bitrate: 866.7 MBit/s VHT-MCS 9 80MHz short GI VHT-NSS 2
+	tx bitrate: 585.1 MBit/s VHT-MCS 7 80MHz short GI VHT-NSS 2
+
+	bss flags:	short-slot-time
+	dtim period:	1
+`
+
+const netshInterfaces = `
+There are 2 interfaces on the system:
+
+    Name                   : Wi-Fi 2
+    State                  : disconnected
+
+    Name                   : Wi-Fi
+    Description            : Intel(R) Wi-Fi 6 AX201 160MHz
+    State                  : connected
+    SSID                   : LabNet
+    AP BSSID               : aa:bb:cc:00:11:22
+    Band                   : 2.4 GHz
+    Channel                : 6
+    Radio type             : 802.11ax
+    Receive rate (Mbps)    : 286.8
+    Transmit rate (Mbps)   : 286.8
+    Signal                 : 70%
+    Profile                : LabNet
+`
+
+const airportInfo = `     agrCtlRSSI: -58
+     agrExtRSSI: 0
+          state: running
+     lastTxRate: 400
+        maxRate: 867
+          BSSID: aa:bb:cc:0:11:22
+           SSID: LabNet
+        channel: 149,80
+`
+
+const wdutilInfo = `————————————————————————————————————————————————————————————————————
+WIFI
+————————————————————————————————————————————————————————————————————
+    MAC Address          : <redacted> (hw=<redacted>)
+    Interface Name       : en0
+    SSID                 : LabNet
+    BSSID                : AA:BB:CC:00:11:22
+    RSSI                 : -48 dBm
+    Tx Rate              : 1200.0 Mbps
+    Channel              : 6g37/160
+`
+
+func TestParseIwLink(t *testing.T) {
+	want := &WiFiInfo{Interface: "wlp2s0", SSID: "LabNet", BSSID: "aa:bb:cc:00:11:22", Band: "5GHz", Channel: 36, SignalDBm: -67, SignalPercent: 66, TxRateMbps: 585.1}
+	if got := parseIwLink("wlp2s0", iwLink); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseIwLink = %+v, want %+v", got, want)
+	}
+	if got := parseIwLink("wlp2s0", "Not connected.\n"); got != nil {
+		t.Errorf("not connected: got %+v", got)
+	}
+}
+
+func TestParseNetshWlan(t *testing.T) {
+	want := &WiFiInfo{Interface: "Wi-Fi", SSID: "LabNet", BSSID: "aa:bb:cc:00:11:22", Band: "2.4GHz", Channel: 6, SignalDBm: -65, SignalPercent: 70, TxRateMbps: 286.8}
+	if got := parseNetshWlan("Wi-Fi", netshInterfaces); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNetshWlan = %+v, want %+v", got, want)
+	}
+	if got := parseNetshWlan("Wi-Fi 2", netshInterfaces); got != nil {
+		t.Errorf("disconnected interface: got %+v", got)
+	}
+}
+
+func TestParseMacWiFi(t *testing.T) {
+	want := &WiFiInfo{Interface: "en0", SSID: "LabNet", BSSID: "aa:bb:cc:0:11:22", Band: "5GHz", Channel: 149, SignalDBm: -58, SignalPercent: 84, TxRateMbps: 400}
+	if got := parseAirportInfo("en0", airportInfo); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAirportInfo = %+v, want %+v", got, want)
+	}
+	want = &WiFiInfo{Interface: "en0", SSID: "LabNet", BSSID: "aa:bb:cc:00:11:22", Band: "6GHz", Channel: 37, SignalDBm: -48, SignalPercent: 100, TxRateMbps: 1200}
+	if got := parseWdutilInfo("en0", wdutilInfo); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWdutilInfo = %+v, want %+v", got, want)
+	}
+}
+
+func TestWiFiBandOfFrequency(t *testing.T) {
+	cases := map[int]struct {
+		band    string
+		channel int
+	}{
+		2412: {"2.4GHz", 1},
+		2484: {"2.4GHz", 14},
+		5745: {"5GHz", 149},
+		5955: {"6GHz", 1},
+		900:  {"", 0},
+	}
+	for mhz, want := range cases {
+		if band, channel := wifiBandOfFrequency(mhz); band != want.band || channel != want.channel {
+			t.Errorf("wifiBandOfFrequency(%d) = %s, %d", mhz, band, channel)
+		}
+	}
+}
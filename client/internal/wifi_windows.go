@@ -0,0 +1,15 @@
+//go:build windows
+
+package internal
+
+import "fmt"
+
+// getWiFiInfo reads the link of a wireless interface with netsh, whose
+// labels are parsed in English
+func getWiFiInfo(name string) (*WiFiInfo, error) {
+	output, err := runCommand("netsh", "wlan", "show", "interfaces")
+	if err != nil {
+		return nil, fmt.Errorf("netsh wlan: %w", err)
+	}
+	return parseNetshWlan(name, string(output)), nil
+}
@@ -0,0 +1,112 @@
+//go:build windows
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	ole "github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+	"golang.org/x/sys/windows/registry"
+)
+
+// windowsUpdatePolicyKey holds the Group Policy settings of Windows Update
+const windowsUpdatePolicyKey = `SOFTWARE\Policies\Microsoft\Windows\WindowsUpdate`
+
+// getWindowsUpdateSettings reads the Windows Update policies from the
+// registry and the last search and install dates from the Windows Update Agent
+func getWindowsUpdateSettings(ctx context.Context) *WindowsUpdateSettings {
+	settings := &WindowsUpdateSettings{Source: "windows-update"}
+	var noAutoUpdate, auOptions uint64
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, windowsUpdatePolicyKey, registry.QUERY_VALUE|registry.WOW64_64KEY); err == nil {
+		settings.Managed = true
+		settings.WSUSServer, _, _ = k.GetStringValue("WUServer")
+		if enabled, _, _ := k.GetIntegerValue("TargetGroupEnabled"); enabled == 1 {
+			settings.TargetGroup, _, _ = k.GetStringValue("TargetGroup")
+		}
+		k.Close()
+	}
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, windowsUpdatePolicyKey+`\AU`, registry.QUERY_VALUE|registry.WOW64_64KEY); err == nil {
+		settings.Managed = true
+		noAutoUpdate, _, _ = k.GetIntegerValue("NoAutoUpdate")
+		auOptions, _, _ = k.GetIntegerValue("AUOptions")
+		// WUServer is only used when UseWUServer is set
+		if use, _, _ := k.GetIntegerValue("UseWUServer"); use == 1 && strings.TrimSpace(settings.WSUSServer) != "" {
+			settings.Source = "wsus"
+		}
+		k.Close()
+	}
+	if settings.Source != "wsus" {
+		settings.WSUSServer, settings.TargetGroup = "", ""
+	}
+	settings.WSUSServer = strings.TrimSpace(settings.WSUSServer)
+	settings.Mode = windowsUpdateMode(noAutoUpdate, auOptions)
+
+	lastSearch, lastInstall, err := windowsUpdateResults()
+	if err != nil {
+		Log.Warnf("Error to read Windows Update results: %v", err)
+		addWarning(ctx, WarnCollectorFailed, "Windows Update results: %v", err)
+	}
+	settings.LastSearch, settings.LastInstall = lastSearch, lastInstall
+	return settings
+}
+
+// windowsUpdateResults returns the last successful search and install dates
+// of Microsoft.Update.AutoUpdate, in RFC3339
+func windowsUpdateResults() (string, string, error) {
+	release, err := comInitialize()
+	if err != nil {
+		return "", "", err
+	}
+	defer release()
+
+	unknown, err := oleutil.CreateObject("Microsoft.Update.AutoUpdate")
+	if err != nil {
+		return "", "", fmt.Errorf("Microsoft.Update.AutoUpdate: %w", err)
+	}
+	defer unknown.Release()
+	autoUpdate, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return "", "", err
+	}
+	defer autoUpdate.Release()
+	resultsVar, err := oleutil.GetProperty(autoUpdate, "Results")
+	if err != nil {
+		return "", "", fmt.Errorf("AutoUpdate results: %w", err)
+	}
+	results := resultsVar.ToIDispatch()
+	defer results.Release()
+
+	date := func(name string) string {
+		v, err := oleutil.GetProperty(results, name)
+		if err != nil {
+			return ""
+		}
+		// never is reported as a zero OLE date (1899-12-30)
+		if t, ok := v.Value().(time.Time); ok && t.Year() > 1900 {
+			return t.UTC().Format(time.RFC3339)
+		}
+		return ""
+	}
+	return date("LastSearchSuccessDate"), date("LastInstallationSuccessDate"), nil
+}
+
+// comInitialize initializes COM on the locked calling thread, returning the
+// function undoing it
+func comInitialize() (func(), error) {
+	runtime.LockOSThread()
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		if oleErr, ok := err.(*ole.OleError); !ok || oleErr.Code() != 1 { // S_FALSE: already initialized
+			runtime.UnlockOSThread()
+			return nil, fmt.Errorf("CoInitializeEx: %w", err)
+		}
+	}
+	return func() {
+		ole.CoUninitialize()
+		runtime.UnlockOSThread()
+	}, nil
+}
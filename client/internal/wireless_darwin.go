@@ -0,0 +1,40 @@
+//go:build darwin
+
+package internal
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	wirelessDevicesOnce sync.Once
+	wirelessDevices     map[string]bool
+)
+
+// loadWirelessDevices parses `networksetup -listallhardwareports` to find the
+// BSD device names of Wi-Fi/AirPort hardware ports
+func loadWirelessDevices() {
+	wirelessDevices = make(map[string]bool)
+	output, err := runCommand("networksetup", "-listallhardwareports")
+	if err != nil {
+		Log.Debugf("Error to execute networksetup: %v", err)
+		return
+	}
+	wireless := false
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if port, ok := strings.CutPrefix(line, "Hardware Port:"); ok {
+			port = strings.ToLower(port)
+			wireless = strings.Contains(port, "wi-fi") || strings.Contains(port, "airport")
+		} else if device, ok := strings.CutPrefix(line, "Device:"); ok && wireless {
+			wirelessDevices[strings.TrimSpace(device)] = true
+		}
+	}
+}
+
+// isWirelessInterface reports whether a BSD device is a Wi-Fi hardware port
+func isWirelessInterface(name string) bool {
+	wirelessDevicesOnce.Do(loadWirelessDevices)
+	return wirelessDevices[name] || isWirelessName(name)
+}
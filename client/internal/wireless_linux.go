@@ -0,0 +1,19 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// isWirelessInterface checks sysfs for the wireless/phy80211 attributes
+func isWirelessInterface(name string) bool {
+	base := filepath.Join(sysfsRoot, "class", "net", name)
+	for _, attr := range []string{"wireless", "phy80211"} {
+		if _, err := os.Stat(filepath.Join(base, attr)); err == nil {
+			return true
+		}
+	}
+	return isWirelessName(name)
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package internal
+
+// isWirelessInterface relies on the adapter friendly name ("Wi-Fi", "Wireless ...")
+func isWirelessInterface(name string) bool {
+	return isWirelessName(name)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package internal
+
+import (
+	"errors"
+
+	"github.com/StackExchange/wmi"
+)
+
+// errWMIDisabled is returned by wmiQuery with TATUSCAN_WMI=false, for hosts
+// where WMI hangs or fails
+var errWMIDisabled = errors.New("WMI disabled by TATUSCAN_WMI")
+
+// wmiQuery runs a WQL query in the given namespace (empty for root\cimv2);
+// tests replace it to replay recorded WMI results
+var wmiQuery = func(query string, dst interface{}, namespace string) error {
+	if !Cfg.WMI {
+		return errWMIDisabled
+	}
+	if namespace == "" {
+		return wmi.Query(query, dst)
+	}
+	return wmi.QueryNamespace(query, dst, namespace)
+}
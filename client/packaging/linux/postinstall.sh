@@ -0,0 +1,16 @@
+#!/bin/sh
+# Enable the agent; it starts now if /etc/tatuscan/tatuscan.env exists,
+# otherwise as soon as configuration management writes it
+set -e
+
+if [ -d /run/systemd/system ]; then
+    systemctl daemon-reload
+    systemctl enable tatuscan-agent.service tatuscan-agent.path >/dev/null
+    systemctl start tatuscan-agent.path
+    if [ -f /etc/tatuscan/tatuscan.env ]; then
+        # Upgrades restart the running agent with the new binary
+        systemctl restart tatuscan-agent.service
+    else
+        echo "tatuscan-agent: write /etc/tatuscan/tatuscan.env (see /usr/share/doc/tatuscan-agent/tatuscan.env.example) to start the agent"
+    fi
+fi
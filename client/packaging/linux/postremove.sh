@@ -0,0 +1,7 @@
+#!/bin/sh
+# Forget the removed units; /etc/tatuscan and /var/lib/tatuscan are kept
+set -e
+
+if [ -d /run/systemd/system ]; then
+    systemctl daemon-reload || true
+fi
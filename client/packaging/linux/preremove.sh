@@ -0,0 +1,13 @@
+#!/bin/sh
+# Stop and disable the agent on removal, not on upgrade
+# (deb passes "remove", rpm passes 0 when the last version is removed)
+set -e
+
+case "$1" in
+    remove|0)
+        if [ -d /run/systemd/system ]; then
+            systemctl stop tatuscan-agent.path tatuscan-agent.service || true
+            systemctl disable tatuscan-agent.path tatuscan-agent.service >/dev/null || true
+        fi
+        ;;
+esac
@@ -0,0 +1,89 @@
+//go:build windows || linux || darwin
+
+// Package payload holds the versioned types of the agent report, for Go
+// servers and tools that ingest it. Each schema version has its type;
+// Encode writes a report in the version a server accepts, with the same
+// conversions the agent applies after negotiating it, and Decode reads a
+// report of any version up to the current one.
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/carlosrabelo/tatuscan/internal"
+)
+
+// Current is the schema version of the reports of this agent version
+const Current = internal.SchemaVersion
+
+// Header carries the schema version of a report and, in server replies, the
+// highest version the server accepts
+const Header = internal.SchemaHeader
+
+// V1 is the report of schema version 1, the first carrying schema_version
+type V1 = internal.MachineInfo
+
+// V0 is the report of servers predating schema_version: V1 without it
+type V0 struct {
+	V1
+}
+
+// MarshalJSON encodes the report without schema_version
+func (r V0) MarshalJSON() ([]byte, error) {
+	return Encode(r.V1, 0)
+}
+
+// upgrades converts a report document of the key version to the next one,
+// the reverse of the agent downgrades
+var upgrades = map[int]func(doc map[string]any){
+	0: func(doc map[string]any) { doc["schema_version"] = 1 },
+}
+
+// Encode serializes a report in version, converting it down from the
+// current one
+func Encode(report V1, version int) ([]byte, error) {
+	if version < 0 || version > Current {
+		return nil, fmt.Errorf("unknown payload schema version %d", version)
+	}
+	report.SchemaVersion = Current
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	return internal.DowngradePayload(data, version), nil
+}
+
+// Decode reads a report of any schema version up to Current, converting it
+// up to the current type; it also returns the version it was sent in
+func Decode(data []byte) (V1, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return V1{}, 0, err
+	}
+	version := 0
+	if value, ok := doc["schema_version"]; ok {
+		n, ok := value.(float64)
+		if !ok || n < 0 || n != float64(int(n)) {
+			return V1{}, 0, fmt.Errorf("invalid schema_version %v", value)
+		}
+		version = int(n)
+	}
+	if version > Current {
+		return V1{}, version, fmt.Errorf("payload schema version %d is newer than %d", version, Current)
+	}
+	for v := version; v < Current; v++ {
+		if upgrade, ok := upgrades[v]; ok {
+			upgrade(doc)
+		}
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return V1{}, version, err
+	}
+	var report V1
+	if err := json.Unmarshal(converted, &report); err != nil {
+		return V1{}, version, err
+	}
+	return report, version, nil
+}
@@ -0,0 +1,50 @@
+package payload
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEncodeOlderVersions(t *testing.T) {
+	report := V1{MachineID: "abc", Hostname: "lab-01", OS: "linux", CPUPercent: 12}
+
+	for version := 0; version <= Current; version++ {
+		data, err := Encode(report, version)
+		if err != nil {
+			t.Fatalf("Encode(%d): %v", version, err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("Encode(%d) = %s: %v", version, data, err)
+		}
+		if string(fields["machine_id"]) != `"abc"` || string(fields["cpu_percent"]) != "12" {
+			t.Errorf("version %d lost fields: %s", version, data)
+		}
+		if _, ok := fields["schema_version"]; ok != (version > 0) {
+			t.Errorf("version %d schema_version: %s", version, data)
+		}
+
+		// Every version reads back as the current type
+		decoded, sent, err := Decode(data)
+		if err != nil || sent != version || decoded.SchemaVersion != Current || decoded.Hostname != "lab-01" {
+			t.Errorf("Decode(version %d) = %+v, %d, %v", version, decoded, sent, err)
+		}
+	}
+
+	v0, err := json.Marshal(V0{report})
+	if want, _ := Encode(report, 0); err != nil || string(v0) != string(want) {
+		t.Errorf("V0 = %s, %v; want %s", v0, err, want)
+	}
+	if _, err := Encode(report, Current+1); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
+
+func TestDecodeRejectsNewerVersions(t *testing.T) {
+	if _, _, err := Decode([]byte(`{"schema_version": 99, "machine_id": "abc"}`)); err == nil {
+		t.Error("expected an error for a newer version")
+	}
+	if _, _, err := Decode([]byte(`{"schema_version": "1"}`)); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
@@ -9,6 +9,9 @@ BIN_DIR="$PROJECT_ROOT/bin"
 
 CLIENT_BINARY="${CLIENT_BINARY:-tatuscan}"
 CLIENT_VERSION="${CLIENT_VERSION:-$(git describe --tags --always --dirty 2>/dev/null || echo dev)}"
+CLIENT_COMMIT="${CLIENT_COMMIT:-$(git rev-parse HEAD 2>/dev/null || true)}"
+BUILD_DATE="${BUILD_DATE:-$(date -u +%Y-%m-%dT%H:%M:%SZ)}"
+LDFLAGS="-X main.version=$CLIENT_VERSION -X main.commit=$CLIENT_COMMIT -X main.buildDate=$BUILD_DATE"
 
 PLATFORM="${1:-linux}"
 
@@ -19,7 +22,7 @@ case "$PLATFORM" in
         echo "→ Building client for Linux..."
         mkdir -p "$BIN_DIR/linux"
         CGO_ENABLED=1 GOOS=linux GOARCH=amd64 \
-            go build -ldflags="-X main.version=$CLIENT_VERSION" \
+            go build -ldflags="$LDFLAGS" \
             -o "$BIN_DIR/linux/$CLIENT_BINARY" ./cmd/tatuscan
         echo "✓ Client built: $BIN_DIR/linux/$CLIENT_BINARY"
         ;;
@@ -27,7 +30,7 @@ case "$PLATFORM" in
         echo "→ Building client for Windows..."
         mkdir -p "$BIN_DIR/windows"
         CGO_ENABLED=1 GOOS=windows GOARCH=amd64 CC=x86_64-w64-mingw32-gcc \
-            go build -ldflags="-X main.version=$CLIENT_VERSION -H windowsgui" \
+            go build -ldflags="$LDFLAGS -H windowsgui" \
             -o "$BIN_DIR/windows/$CLIENT_BINARY.exe" ./cmd/tatuscan
         echo "✓ Client built: $BIN_DIR/windows/$CLIENT_BINARY.exe"
         ;;
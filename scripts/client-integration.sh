@@ -0,0 +1,43 @@
+#!/usr/bin/env bash
+# Run the agent/server integration tests against the server in a container
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
+SERVER_DIR="$PROJECT_ROOT/server"
+CLIENT_DIR="$PROJECT_ROOT/client"
+
+# Variables
+DOCKER="${DOCKER:-docker}"
+IMAGE="${INTEGRATION_IMAGE:-tatuscan-integration}"
+PORT="${INTEGRATION_PORT:-18040}"
+SECRET="${INTEGRATION_HMAC_SECRET:-integration-secret}"
+NAME="tatuscan-integration-$$"
+URL="http://127.0.0.1:$PORT"
+
+echo "→ Building server image: $IMAGE"
+$DOCKER build -q -t "$IMAGE" "$SERVER_DIR" >/dev/null
+
+echo "→ Starting server at $URL"
+$DOCKER run -d --rm --name "$NAME" -p "127.0.0.1:$PORT:8040" \
+  -e TATUSCAN_PORT=8040 -e TATUSCAN_DB_DIR=/tmp -e TATUSCAN_HMAC_SECRET="$SECRET" \
+  "$IMAGE" >/dev/null
+trap '$DOCKER rm -f "$NAME" >/dev/null 2>&1 || true' EXIT
+
+for attempt in $(seq 1 60); do
+  if curl -fsS "$URL/api/health" >/dev/null 2>&1; then
+    break
+  fi
+  if [ "$attempt" -eq 60 ]; then
+    echo "✗ Server did not become healthy" >&2
+    $DOCKER logs "$NAME" >&2 || true
+    exit 1
+  fi
+  sleep 1
+done
+
+echo "→ Running integration tests..."
+cd "$CLIENT_DIR"
+TATUSCAN_INTEGRATION_URL="$URL" TATUSCAN_INTEGRATION_HMAC_SECRET="$SECRET" \
+  go test -tags integration -count=1 -v ./integration/...
+echo "✓ Integration tests completed"
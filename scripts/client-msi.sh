@@ -0,0 +1,32 @@
+#!/usr/bin/env bash
+# Build the TatuScan client MSI (Windows x64)
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
+CLIENT_DIR="$PROJECT_ROOT/client"
+BIN_DIR="$PROJECT_ROOT/bin"
+
+# MSI versions must be numeric (major.minor.build)
+MSI_VERSION="${MSI_VERSION:-$(git describe --tags --abbrev=0 2>/dev/null | sed 's/^v//' || true)}"
+if [[ ! "$MSI_VERSION" =~ ^[0-9]+\.[0-9]+\.[0-9]+$ ]]; then
+    MSI_VERSION="0.0.1"
+fi
+
+"$SCRIPT_DIR/client-build.sh" windows
+
+OUTPUT="$BIN_DIR/windows/tatuscan-$MSI_VERSION.msi"
+echo "→ Building MSI $MSI_VERSION..."
+if command -v wixl >/dev/null 2>&1; then
+    wixl -a x64 -D Version="$MSI_VERSION" -D BinDir="$BIN_DIR/windows" \
+        -o "$OUTPUT" "$CLIENT_DIR/tatuscan.wxs"
+elif command -v candle >/dev/null 2>&1 && command -v light >/dev/null 2>&1; then
+    candle -arch x64 -dVersion="$MSI_VERSION" -dBinDir="$BIN_DIR/windows" \
+        -out "$BIN_DIR/windows/tatuscan.wixobj" "$CLIENT_DIR/tatuscan.wxs"
+    light -out "$OUTPUT" "$BIN_DIR/windows/tatuscan.wixobj"
+    rm -f "$BIN_DIR/windows/tatuscan.wixobj"
+else
+    echo "[ERROR] wixl (msitools) or WiX Toolset 3 (candle/light) is required"
+    exit 1
+fi
+echo "✓ MSI built: $OUTPUT"
@@ -0,0 +1,31 @@
+#!/usr/bin/env bash
+# Build the TatuScan client deb and rpm packages with nfpm
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
+CLIENT_DIR="$PROJECT_ROOT/client"
+BIN_DIR="$PROJECT_ROOT/bin"
+
+# Package versions must start with a digit
+VERSION="${VERSION:-$(git describe --tags --abbrev=0 2>/dev/null | sed 's/^v//' || true)}"
+if [[ ! "$VERSION" =~ ^[0-9] ]]; then
+    VERSION="0.0.1"
+fi
+export VERSION
+export GOARCH="${GOARCH:-amd64}"
+export MAINTAINER="${MAINTAINER:-TatuScan Maintainers}"
+
+if ! command -v nfpm >/dev/null 2>&1; then
+    echo "[ERROR] nfpm is required: go install github.com/goreleaser/nfpm/v2/cmd/nfpm@latest"
+    exit 1
+fi
+
+"$SCRIPT_DIR/client-build.sh" linux
+
+cd "$CLIENT_DIR"
+for packager in deb rpm; do
+    echo "→ Building $packager package $VERSION..."
+    nfpm package --config nfpm.yaml --packager "$packager" --target "$BIN_DIR/linux/"
+done
+echo "✓ Packages built in $BIN_DIR/linux"
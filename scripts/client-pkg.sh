@@ -0,0 +1,40 @@
+#!/usr/bin/env bash
+# Build the TatuScan client macOS installer package (run on macOS)
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
+CLIENT_DIR="$PROJECT_ROOT/client"
+BIN_DIR="$PROJECT_ROOT/bin"
+
+# Package versions must be numeric
+VERSION="${VERSION:-$(git describe --tags --abbrev=0 2>/dev/null | sed 's/^v//' || true)}"
+if [[ ! "$VERSION" =~ ^[0-9]+(\.[0-9]+)*$ ]]; then
+    VERSION="0.0.1"
+fi
+IDENTIFIER="com.github.carlosrabelo.tatuscan"
+LDFLAGS="-X main.version=$VERSION -X main.commit=$(git rev-parse HEAD 2>/dev/null || true) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+
+if ! command -v pkgbuild >/dev/null 2>&1; then
+    echo "[ERROR] pkgbuild is required (macOS Xcode command line tools)"
+    exit 1
+fi
+
+ROOT="$(mktemp -d)"
+trap 'rm -rf "$ROOT"' EXIT
+mkdir -p "$ROOT/usr/local/bin" "$BIN_DIR/darwin"
+
+echo "→ Building universal client for macOS..."
+cd "$CLIENT_DIR"
+for arch in amd64 arm64; do
+    CGO_ENABLED=0 GOOS=darwin GOARCH=$arch \
+        go build -ldflags="$LDFLAGS" -o "$ROOT/tatuscan-$arch" ./cmd/tatuscan
+done
+lipo -create -output "$ROOT/usr/local/bin/tatuscan" "$ROOT/tatuscan-amd64" "$ROOT/tatuscan-arm64"
+rm "$ROOT/tatuscan-amd64" "$ROOT/tatuscan-arm64"
+
+OUTPUT="$BIN_DIR/darwin/tatuscan-$VERSION.pkg"
+echo "→ Building package $VERSION..."
+pkgbuild --root "$ROOT" --identifier "$IDENTIFIER" --version "$VERSION" \
+    --scripts "$CLIENT_DIR/packaging/macos/scripts" --install-location / "$OUTPUT"
+echo "✓ Package built: $OUTPUT"
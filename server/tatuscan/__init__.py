@@ -5,6 +5,7 @@ from tatuscan.config import Config
 from tatuscan.extensions import db
 from tatuscan.logging import setup_logging
 from tatuscan.errors import register_error_handlers
+from tatuscan.utils.compression import GzipRequestMiddleware
 
 
 def create_app() -> Flask:
@@ -20,6 +21,9 @@ def create_app() -> Flask:
     # Configure logging
     setup_logging(app)
 
+    # Agents may gzip large payloads
+    app.wsgi_app = GzipRequestMiddleware(app.wsgi_app)
+
     # Initialize extensions
     db.init_app(app)
 
@@ -1,11 +1,13 @@
 """API routes - REST endpoints for inventory management."""
 import logging
 
-from flask import jsonify, request
+from flask import current_app, jsonify, request
 
 from tatuscan.services import InventoryService
 from tatuscan.services.exceptions import ServiceException
 from tatuscan.utils import serialize_inventory
+from tatuscan.utils.schema import SCHEMA_HEADER, SCHEMA_VERSION, upgrade_payload
+from tatuscan.utils.signing import SIGNATURE_HEADER, verify_signature
 from tatuscan.extensions import db
 
 from . import bp
@@ -32,9 +34,15 @@ def list_inventory_alias():
 @bp.route("/machines", methods=["POST"])
 def add_inventory():
     """Create or update inventory."""
+    secret = current_app.config.get("PAYLOAD_SECRET")
+    if secret and not verify_signature(request.get_data(cache=True), request.headers.get(SIGNATURE_HEADER), secret):
+        logger.warning(f"Rejected payload with invalid signature from {request.remote_addr}")
+        return jsonify({"error": "invalid payload signature"}), 401
+
     try:
         data = request.get_json(silent=True) or {}
         logger.debug(f"Received data: {data}")
+        data = upgrade_payload(data, request.headers.get(SCHEMA_HEADER))
 
         inventory, created = InventoryService.create_or_update(data)
 
@@ -42,7 +50,8 @@ def add_inventory():
         message = "Inventário adicionado com sucesso" if created else "Inventário atualizado com sucesso"
 
         logger.info(f"Inventory {'created' if created else 'updated'}: {inventory.hostname}")
-        return jsonify({"message": message, "item": serialize_inventory(inventory)}), status_code
+        response = jsonify({"message": message, "item": serialize_inventory(inventory)})
+        return response, status_code, {SCHEMA_HEADER: str(SCHEMA_VERSION)}
 
     except ServiceException as e:
         logger.error(f"Service error: {e.message}")
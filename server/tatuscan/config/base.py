@@ -35,6 +35,10 @@ class Config:
     # Flask
     SECRET_KEY = os.getenv("SECRET_KEY", "dev-secret-key-change-in-production")
 
+    # Shared secret of the agents' payload signatures (empty accepts
+    # unsigned payloads)
+    PAYLOAD_SECRET = os.getenv("TATUSCAN_HMAC_SECRET", "")
+
     # Database
     SQLALCHEMY_DATABASE_URI = _get_database_uri()
     SQLALCHEMY_TRACK_MODIFICATIONS = False
@@ -28,6 +28,13 @@ class NotFoundError(ServiceException):
         self.identifier = identifier
 
 
+class ConflictError(ServiceException):
+    """Raised when a request depends on state the server does not have."""
+
+    def __init__(self, message: str):
+        super().__init__(message, status_code=409)
+
+
 class DatabaseError(ServiceException):
     """Raised when a database operation fails."""
 
@@ -8,7 +8,7 @@ from sqlalchemy import func
 
 from tatuscan.extensions import db
 from tatuscan.models.inventory import Inventory
-from .exceptions import NotFoundError, ValidationError, DatabaseError
+from .exceptions import ConflictError, NotFoundError, ValidationError, DatabaseError
 
 
 class InventoryService:
@@ -76,8 +76,14 @@ class InventoryService:
 
         Raises:
             ValidationError: If required fields are missing
+            ConflictError: If a delta report or heartbeat arrives for an
+                unknown machine
             DatabaseError: If database operation fails
         """
+        if data.get("delta") or data.get("heartbeat"):
+            # A heartbeat only carries the metrics, merged like a delta
+            data = InventoryService._merge_delta(data)
+
         required = ["machine_id", "hostname", "ip", "os", "cpu_percent", "memory_total_mb"]
         missing = [k for k in required if k not in data]
         if missing:
@@ -139,6 +145,35 @@ class InventoryService:
             db.session.rollback()
             raise DatabaseError(f"Failed to create/update inventory: {str(e)}", original_error=e)
 
+    @staticmethod
+    def _merge_delta(data: dict[str, Any]) -> dict[str, Any]:
+        """
+        Complete a delta report, which only carries the fields changed since
+        the last report, with the stored values of the machine.
+
+        Raises:
+            ValidationError: If machine_id is missing
+            ConflictError: If the machine is unknown, so the agent must send
+                a full report
+        """
+        machine_id = data.get("machine_id")
+        if not machine_id:
+            raise ValidationError("Missing required fields: ['machine_id']", missing_fields=["machine_id"])
+        existing = db.session.get(Inventory, machine_id)
+        if not existing:
+            raise ConflictError(f"No full report stored for {machine_id}; send a full report")
+        stored = {
+            "hostname": existing.hostname,
+            "ip": existing.ip,
+            "os": existing.os,
+            "os_version": existing.os_version,
+            "cpu_percent": existing.cpu_percent,
+            "memory_total_mb": existing.memory_total_mb,
+            "memory_used_mb": existing.memory_used_mb,
+            "computer_model": existing.computer_model,
+        }
+        return {**stored, **data}
+
     @staticmethod
     def partial_update(machine_id: str, data: dict[str, Any]) -> Inventory:
         """
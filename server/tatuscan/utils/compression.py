@@ -0,0 +1,43 @@
+"""Request decompression - accept gzip-encoded agent payloads."""
+import gzip
+import io
+import zlib
+
+# Decompressed bodies above this size are refused (gzip bomb protection)
+MAX_DECOMPRESSED_SIZE = 16 * 1024 * 1024
+
+
+class GzipRequestMiddleware:
+    """WSGI middleware decoding request bodies sent with Content-Encoding: gzip."""
+
+    def __init__(self, app, max_size: int = MAX_DECOMPRESSED_SIZE):
+        self.app = app
+        self.max_size = max_size
+
+    def __call__(self, environ, start_response):
+        if environ.get("HTTP_CONTENT_ENCODING", "").strip().lower() != "gzip":
+            return self.app(environ, start_response)
+
+        try:
+            length = int(environ.get("CONTENT_LENGTH") or 0)
+        except ValueError:
+            length = 0
+        compressed = environ["wsgi.input"].read(length) if length > 0 else environ["wsgi.input"].read()
+        try:
+            with gzip.GzipFile(fileobj=io.BytesIO(compressed)) as f:
+                body = f.read(self.max_size + 1)
+        except (OSError, EOFError, zlib.error):
+            return self._error(start_response, "400 BAD REQUEST", "invalid gzip body")
+        if len(body) > self.max_size:
+            return self._error(start_response, "413 REQUEST ENTITY TOO LARGE", "payload too large")
+
+        environ["wsgi.input"] = io.BytesIO(body)
+        environ["CONTENT_LENGTH"] = str(len(body))
+        del environ["HTTP_CONTENT_ENCODING"]
+        return self.app(environ, start_response)
+
+    @staticmethod
+    def _error(start_response, status: str, message: str):
+        body = ('{"error": "%s"}' % message).encode()
+        start_response(status, [("Content-Type", "application/json"), ("Content-Length", str(len(body)))])
+        return [body]
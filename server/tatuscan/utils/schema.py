@@ -0,0 +1,44 @@
+"""Payload schema versions - accept reports of older and newer agents."""
+import logging
+from typing import Any, Callable
+
+logger = logging.getLogger(__name__)
+
+# Highest payload schema version this server understands
+SCHEMA_VERSION = 1
+
+# Header carrying the schema version of a report, and in replies the highest
+# version the server accepts so newer agents downgrade their reports
+SCHEMA_HEADER = "X-TatuScan-Schema"
+
+# UPGRADES[v] converts a payload of version v - 1 to version v. Version 0 is
+# the payload of agents predating schema_version.
+UPGRADES: dict[int, Callable[[dict[str, Any]], dict[str, Any]]] = {
+    1: lambda data: data,
+}
+
+
+def payload_version(data: dict[str, Any], header: str | None) -> int:
+    """Return the schema version of a report, from its body or header."""
+    for value in (data.get("schema_version"), header):
+        try:
+            if value is not None and str(value).strip() != "":
+                return max(int(value), 0)
+        except (TypeError, ValueError):
+            continue
+    return 0
+
+
+def upgrade_payload(data: dict[str, Any], header: str | None = None) -> dict[str, Any]:
+    """Convert a report to the current schema version.
+
+    Reports of newer agents are ingested as they are: unknown fields are
+    ignored, and the reply header makes the agent send this version next.
+    """
+    version = payload_version(data, header)
+    if version > SCHEMA_VERSION:
+        logger.info(f"Report in schema {version}, newer than {SCHEMA_VERSION}; ingesting known fields")
+        return data
+    for v in range(version + 1, SCHEMA_VERSION + 1):
+        data = UPGRADES[v](data)
+    return data
@@ -0,0 +1,19 @@
+"""Payload signing - verify the HMAC-SHA256 agents send with each report."""
+import hashlib
+import hmac
+
+# Header carrying "sha256=<hex>" over the (decompressed) request body
+SIGNATURE_HEADER = "X-TatuScan-Signature"
+
+
+def payload_signature(body: bytes, secret: str) -> str:
+    """Return the signature header value of body under secret."""
+    digest = hmac.new(secret.encode(), body, hashlib.sha256).hexdigest()
+    return f"sha256={digest}"
+
+
+def verify_signature(body: bytes, signature: str | None, secret: str) -> bool:
+    """Check a signature header value in constant time."""
+    if not signature:
+        return False
+    return hmac.compare_digest(signature.strip(), payload_signature(body, secret))